@@ -0,0 +1,705 @@
+package quark
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LetsEncryptURL is the ACME directory of Let's Encrypt.
+const LetsEncryptURL = "https://acme-v02.api.letsencrypt.org/directory"
+
+// acmeChallengePath is the path prefix of HTTP-01 challenges.
+const acmeChallengePath = "/.well-known/acme-challenge/"
+
+// acmeManager obtains and renews certificates from an ACME certificate
+// authority (RFC 8555) using HTTP-01 challenges, caching them on disk.
+type acmeManager struct {
+	config       AutoTLSConfig
+	hosts        map[string]bool
+	client       *http.Client
+	pollInterval time.Duration
+
+	mu     sync.Mutex
+	certs  map[string]*tls.Certificate
+	tokens map[string]string // challenge token -> key authorization
+	nonce  string
+
+	// Background renewals, one per domain, backing off after failures
+	renewing map[string]bool
+	failures map[string]int
+	retryAt  map[string]time.Time
+	onError  func(domain string, err error)
+
+	// Certificates are issued one at a time
+	issueMu sync.Mutex
+	key     *ecdsa.PrivateKey // account key
+	kid     string            // account URL
+	dir     acmeDirectory
+}
+
+// acmeDirectory holds the endpoints of an ACME server.
+type acmeDirectory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+}
+
+// acmeOrder is an ACME order.
+type acmeOrder struct {
+	Status         string   `json:"status"`
+	Authorizations []string `json:"authorizations"`
+	Finalize       string   `json:"finalize"`
+	Certificate    string   `json:"certificate"`
+}
+
+// acmeAuthorization is an ACME authorization of a domain.
+type acmeAuthorization struct {
+	Status     string          `json:"status"`
+	Challenges []acmeChallenge `json:"challenges"`
+}
+
+// acmeChallenge is an ACME challenge.
+type acmeChallenge struct {
+	Type   string       `json:"type"`
+	URL    string       `json:"url"`
+	Token  string       `json:"token"`
+	Status string       `json:"status"`
+	Error  *acmeProblem `json:"error,omitempty"`
+}
+
+// acmeProblem is an ACME error document (RFC 7807).
+type acmeProblem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+func (p *acmeProblem) Error() string {
+	return fmt.Sprintf("acme: %s: %s", p.Type, p.Detail)
+}
+
+// newACMEManager creates a manager for the given domains.
+func newACMEManager(config AutoTLSConfig, domains []string) *acmeManager {
+	hosts := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		hosts[strings.ToLower(d)] = true
+	}
+	return &acmeManager{
+		config:       config,
+		hosts:        hosts,
+		client:       &http.Client{Timeout: 30 * time.Second},
+		pollInterval: 2 * time.Second,
+		certs:        make(map[string]*tls.Certificate),
+		tokens:       make(map[string]string),
+		renewing:     make(map[string]bool),
+		failures:     make(map[string]int),
+		retryAt:      make(map[string]time.Time),
+	}
+}
+
+// GetCertificate returns the certificate of the requested domain,
+// obtaining it on first use. It is a tls.Config.GetCertificate function.
+// A valid certificate is returned immediately; one due for renewal is
+// renewed in the background, so handshakes only wait for the certificate
+// authority when there is no valid certificate.
+func (m *acmeManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	domain := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if domain == "" {
+		return nil, errors.New("acme: missing server name")
+	}
+	if !m.hosts[domain] {
+		return nil, fmt.Errorf("acme: host %q not configured", domain)
+	}
+
+	m.mu.Lock()
+	cert, ok := m.certs[domain]
+	m.mu.Unlock()
+	if ok && certificateValid(cert) {
+		if m.needsRenewal(cert) {
+			m.renewInBackground(domain)
+		}
+		return cert, nil
+	}
+
+	// Issuance outlives the handshake that triggered it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return m.certificate(ctx, domain)
+}
+
+// certificate returns a valid certificate of domain from memory, the
+// cache directory or the certificate authority.
+func (m *acmeManager) certificate(ctx context.Context, domain string) (*tls.Certificate, error) {
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+
+	// Another handshake may have obtained it meanwhile
+	m.mu.Lock()
+	cert, ok := m.certs[domain]
+	m.mu.Unlock()
+	if !ok || !certificateValid(cert) {
+		cert, ok = nil, false
+		if cached, err := m.loadCertificate(domain); err == nil && certificateValid(cached) {
+			m.storeCertificate(domain, cached)
+			cert, ok = cached, true
+		}
+	}
+	if ok {
+		if m.needsRenewal(cert) {
+			m.renewInBackground(domain)
+		}
+		return cert, nil
+	}
+
+	issued, err := m.obtain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	m.storeCertificate(domain, issued)
+	return issued, nil
+}
+
+// renew obtains a new certificate of domain unless the current one, in
+// memory or in the cache directory, is not due for renewal.
+func (m *acmeManager) renew(ctx context.Context, domain string) error {
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+
+	m.mu.Lock()
+	cert, ok := m.certs[domain]
+	m.mu.Unlock()
+	if ok && !m.needsRenewal(cert) {
+		return nil
+	}
+	if cached, err := m.loadCertificate(domain); err == nil && !m.needsRenewal(cached) {
+		m.storeCertificate(domain, cached)
+		return nil
+	}
+
+	issued, err := m.obtain(ctx, domain)
+	if err != nil {
+		return err
+	}
+	m.storeCertificate(domain, issued)
+	return nil
+}
+
+// renewInBackground starts renewing the certificate of domain unless a
+// renewal is already running or a failed one is backing off. Failures are
+// reported to onError and retried after an increasing delay.
+func (m *acmeManager) renewInBackground(domain string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.renewing[domain] || time.Now().Before(m.retryAt[domain]) {
+		return
+	}
+	m.renewing[domain] = true
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		err := m.renew(ctx, domain)
+
+		m.mu.Lock()
+		delete(m.renewing, domain)
+		if err != nil {
+			m.failures[domain]++
+			m.retryAt[domain] = time.Now().Add(renewalBackoff(m.failures[domain]))
+		} else {
+			delete(m.failures, domain)
+			delete(m.retryAt, domain)
+		}
+		m.mu.Unlock()
+
+		if err != nil && m.onError != nil {
+			m.onError(domain, err)
+		}
+	}()
+}
+
+// renewalBackoff returns the delay before retrying a renewal after the
+// given number of consecutive failures: a minute, doubling up to an hour.
+func renewalBackoff(failures int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < failures && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
+
+// storeCertificate keeps a certificate in memory.
+func (m *acmeManager) storeCertificate(domain string, cert *tls.Certificate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs[domain] = cert
+}
+
+// needsRenewal reports whether a certificate expires within RenewBefore.
+func (m *acmeManager) needsRenewal(cert *tls.Certificate) bool {
+	return cert.Leaf == nil || time.Now().Add(m.config.RenewBefore).After(cert.Leaf.NotAfter)
+}
+
+// certificateValid reports whether a certificate has not expired yet.
+func certificateValid(cert *tls.Certificate) bool {
+	return cert.Leaf != nil && time.Now().Before(cert.Leaf.NotAfter)
+}
+
+// renewLoop renews the certificates of all domains every interval until
+// ctx is done.
+func (m *acmeManager) renewLoop(ctx context.Context, interval time.Duration, onError func(domain string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for domain := range m.hosts {
+				if err := m.renew(ctx, domain); err != nil {
+					onError(domain, err)
+				}
+			}
+		}
+	}
+}
+
+// HTTPHandler answers HTTP-01 challenges and passes other requests to
+// fallback.
+func (m *acmeManager) HTTPHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, acmeChallengePath) {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		m.mu.Lock()
+		keyAuth, ok := m.tokens[strings.TrimPrefix(r.URL.Path, acmeChallengePath)]
+		m.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, keyAuth)
+	})
+}
+
+// obtain orders a certificate for domain and saves it in the cache
+// directory.
+func (m *acmeManager) obtain(ctx context.Context, domain string) (*tls.Certificate, error) {
+	if err := m.register(ctx); err != nil {
+		return nil, err
+	}
+
+	var order acmeOrder
+	header, _, err := m.post(ctx, m.dir.NewOrder, map[string]interface{}{
+		"identifiers": []map[string]string{{"type": "dns", "value": domain}},
+	}, &order)
+	if err != nil {
+		return nil, fmt.Errorf("acme: new order: %w", err)
+	}
+	orderURL := header.Get("Location")
+
+	for _, authzURL := range order.Authorizations {
+		if err := m.authorize(ctx, authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	// Finalize with a CSR for a new certificate key
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, certKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := m.post(ctx, order.Finalize, map[string]string{"csr": base64url(csr)}, &order); err != nil {
+		return nil, fmt.Errorf("acme: finalize: %w", err)
+	}
+
+	for order.Status != "valid" {
+		if order.Status == "invalid" {
+			return nil, fmt.Errorf("acme: order for %s is invalid", domain)
+		}
+		if err := m.wait(ctx); err != nil {
+			return nil, err
+		}
+		if _, _, err := m.post(ctx, orderURL, nil, &order); err != nil {
+			return nil, fmt.Errorf("acme: order: %w", err)
+		}
+	}
+
+	_, chain, err := m.post(ctx, order.Certificate, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("acme: certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, err
+	}
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), chain...)
+	cert, err := parseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.writeCache(domain+".pem", data); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// authorize completes the HTTP-01 challenge of an authorization.
+func (m *acmeManager) authorize(ctx context.Context, authzURL string) error {
+	var authz acmeAuthorization
+	if _, _, err := m.post(ctx, authzURL, nil, &authz); err != nil {
+		return fmt.Errorf("acme: authorization: %w", err)
+	}
+	if authz.Status == "valid" {
+		return nil
+	}
+
+	var challenge *acmeChallenge
+	for i := range authz.Challenges {
+		if authz.Challenges[i].Type == "http-01" {
+			challenge = &authz.Challenges[i]
+		}
+	}
+	if challenge == nil {
+		return errors.New("acme: no http-01 challenge offered")
+	}
+
+	thumbprint, err := jwkThumbprint(&m.key.PublicKey)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.tokens[challenge.Token] = challenge.Token + "." + thumbprint
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.tokens, challenge.Token)
+		m.mu.Unlock()
+	}()
+
+	// Tell the server the challenge is ready, then poll the authorization
+	if _, _, err := m.post(ctx, challenge.URL, struct{}{}, nil); err != nil {
+		return fmt.Errorf("acme: challenge: %w", err)
+	}
+	for {
+		if _, _, err := m.post(ctx, authzURL, nil, &authz); err != nil {
+			return fmt.Errorf("acme: authorization: %w", err)
+		}
+		switch authz.Status {
+		case "valid":
+			return nil
+		case "pending", "processing":
+			if err := m.wait(ctx); err != nil {
+				return err
+			}
+		default:
+			for _, c := range authz.Challenges {
+				if c.Error != nil {
+					return c.Error
+				}
+			}
+			return fmt.Errorf("acme: authorization is %s", authz.Status)
+		}
+	}
+}
+
+// wait pauses between polls.
+func (m *acmeManager) wait(ctx context.Context) error {
+	timer := time.NewTimer(m.pollInterval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// register loads the directory and the account key, creating the account
+// on first use.
+func (m *acmeManager) register(ctx context.Context) error {
+	if m.kid != "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.config.DirectoryURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("acme: directory: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&m.dir); err != nil {
+		return fmt.Errorf("acme: directory: %w", err)
+	}
+
+	if m.key, err = m.accountKey(); err != nil {
+		return err
+	}
+
+	account := map[string]interface{}{"termsOfServiceAgreed": true}
+	if m.config.Email != "" {
+		account["contact"] = []string{"mailto:" + m.config.Email}
+	}
+	header, _, err := m.post(ctx, m.dir.NewAccount, account, nil)
+	if err != nil {
+		return fmt.Errorf("acme: account: %w", err)
+	}
+	m.kid = header.Get("Location")
+	return nil
+}
+
+// accountKey loads the account key from the cache directory, creating it
+// on first use.
+func (m *acmeManager) accountKey() (*ecdsa.PrivateKey, error) {
+	if data, err := os.ReadFile(filepath.Join(m.config.CacheDir, "acme_account.key")); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("acme: invalid account key")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.writeCache("acme_account.key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// loadCertificate loads the certificate of domain from the cache directory.
+func (m *acmeManager) loadCertificate(domain string) (*tls.Certificate, error) {
+	data, err := os.ReadFile(filepath.Join(m.config.CacheDir, domain+".pem"))
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificate(data)
+}
+
+// writeCache writes a private file to the cache directory.
+func (m *acmeManager) writeCache(name string, data []byte) error {
+	if err := os.MkdirAll(m.config.CacheDir, 0700); err != nil {
+		return fmt.Errorf("acme: cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.config.CacheDir, name), data, 0600); err != nil {
+		return fmt.Errorf("acme: cache: %w", err)
+	}
+	return nil
+}
+
+// post sends a JWS-signed request and returns the response headers and
+// body, decoding the JSON body into out when not nil. A nil payload sends
+// a POST-as-GET request. A bad nonce is retried once.
+func (m *acmeManager) post(ctx context.Context, url string, payload, out interface{}) (http.Header, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := m.postOnce(ctx, url, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if resp.StatusCode >= 400 {
+			problem := &acmeProblem{}
+			json.Unmarshal(body, problem)
+			if problem.Type == "urn:ietf:params:acme:error:badNonce" && attempt == 0 {
+				continue
+			}
+			if problem.Type == "" {
+				problem.Type = resp.Status
+			}
+			return nil, nil, problem
+		}
+
+		if out != nil {
+			if err := json.Unmarshal(body, out); err != nil {
+				return nil, nil, err
+			}
+		}
+		return resp.Header, body, nil
+	}
+}
+
+// postOnce signs and sends a request.
+func (m *acmeManager) postOnce(ctx context.Context, url string, payload interface{}) (*http.Response, error) {
+	nonce, err := m.takeNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := map[string]interface{}{
+		"alg":   "ES256",
+		"nonce": nonce,
+		"url":   url,
+	}
+	if m.kid != "" {
+		protected["kid"] = m.kid
+	} else {
+		protected["jwk"] = jwk(&m.key.PublicKey)
+	}
+
+	var body []byte
+	if payload != nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+	jws, err := signJWS(m.key, protected, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jws))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/jose+json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.nonce = resp.Header.Get("Replay-Nonce")
+	m.mu.Unlock()
+	return resp, nil
+}
+
+// takeNonce returns the nonce of the last response, or a new one.
+func (m *acmeManager) takeNonce(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	nonce := m.nonce
+	m.nonce = ""
+	m.mu.Unlock()
+	if nonce != "" {
+		return nonce, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, m.dir.NewNonce, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("acme: nonce: %w", err)
+	}
+	resp.Body.Close()
+	if nonce = resp.Header.Get("Replay-Nonce"); nonce == "" {
+		return "", errors.New("acme: no nonce")
+	}
+	return nonce, nil
+}
+
+// signJWS returns a flattened JSON JWS of payload signed with ES256.
+func signJWS(key *ecdsa.PrivateKey, protected map[string]interface{}, payload []byte) ([]byte, error) {
+	header, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+	encodedHeader := base64url(header)
+	encodedPayload := base64url(payload)
+
+	digest := sha256.Sum256([]byte(encodedHeader + "." + encodedPayload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return json.Marshal(map[string]string{
+		"protected": encodedHeader,
+		"payload":   encodedPayload,
+		"signature": base64url(sig),
+	})
+}
+
+// jwk returns the JSON Web Key of a P-256 public key, with its members in
+// the lexicographic order required for thumbprints.
+func jwk(pub *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"crv": "P-256",
+		"kty": "EC",
+		"x":   base64url(padInt(pub.X, 32)),
+		"y":   base64url(padInt(pub.Y, 32)),
+	}
+}
+
+// jwkThumbprint returns the RFC 7638 thumbprint of a public key.
+func jwkThumbprint(pub *ecdsa.PublicKey) (string, error) {
+	// encoding/json sorts map keys, giving the canonical form
+	data, err := json.Marshal(jwk(pub))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64url(sum[:]), nil
+}
+
+// padInt returns n as a big-endian byte slice of length size.
+func padInt(n *big.Int, size int) []byte {
+	return n.FillBytes(make([]byte, size))
+}
+
+// base64url encodes data as unpadded base64url.
+func base64url(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseCertificate parses a PEM private key followed by a certificate
+// chain.
+func parseCertificate(data []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return nil, fmt.Errorf("acme: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("acme: %w", err)
+		}
+	}
+	return &cert, nil
+}
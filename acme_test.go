@@ -0,0 +1,372 @@
+package quark
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeACME is a minimal ACME server verifying request signatures and
+// validating HTTP-01 challenges against the manager under test.
+type fakeACME struct {
+	t       *testing.T
+	server  *httptest.Server
+	manager *acmeManager
+	caKey   *ecdsa.PrivateKey
+	caCert  *x509.Certificate
+
+	mu         sync.Mutex
+	nonces     int
+	accountKey *ecdsa.PublicKey
+	badNonce   bool
+	validated  bool
+	orders     int
+	certPEM    []byte
+}
+
+func newFakeACME(t *testing.T) *fakeACME {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Fake CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(der)
+
+	f := &fakeACME{t: t, caKey: caKey, caCert: caCert, badNonce: true}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeACME) url(path string) string {
+	return f.server.URL + path
+}
+
+func (f *fakeACME) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nonces++
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", f.nonces))
+
+	switch r.URL.Path {
+	case "/directory":
+		json.NewEncoder(w).Encode(acmeDirectory{
+			NewNonce:   f.url("/new-nonce"),
+			NewAccount: f.url("/new-account"),
+			NewOrder:   f.url("/new-order"),
+		})
+		return
+	case "/new-nonce":
+		return
+	}
+
+	payload, ok := f.verify(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(acmeProblem{Type: "urn:ietf:params:acme:error:malformed"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/new-account":
+		if f.badNonce {
+			f.badNonce = false
+			f.accountKey = nil
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(acmeProblem{Type: "urn:ietf:params:acme:error:badNonce"})
+			return
+		}
+		w.Header().Set("Location", f.url("/account/1"))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "{}")
+
+	case "/new-order":
+		f.orders++
+		w.Header().Set("Location", f.url("/order/1"))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.order())
+
+	case "/order/1":
+		json.NewEncoder(w).Encode(f.order())
+
+	case "/authz/1":
+		status := "pending"
+		if f.validated {
+			status = "valid"
+		}
+		json.NewEncoder(w).Encode(acmeAuthorization{
+			Status: status,
+			Challenges: []acmeChallenge{
+				{Type: "dns-01", URL: f.url("/challenge/dns"), Token: "dns-token"},
+				{Type: "http-01", URL: f.url("/challenge/1"), Token: "http-token"},
+			},
+		})
+
+	case "/challenge/1":
+		// Validate through the manager's challenge handler
+		rec := httptest.NewRecorder()
+		f.manager.HTTPHandler(http.NotFoundHandler()).ServeHTTP(rec,
+			httptest.NewRequest(http.MethodGet, acmeChallengePath+"http-token", nil))
+		thumbprint, _ := jwkThumbprint(f.accountKey)
+		f.validated = rec.Body.String() == "http-token."+thumbprint
+		io.WriteString(w, "{}")
+
+	case "/finalize/1":
+		var req struct{ CSR string }
+		json.Unmarshal(payload, &req)
+		der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil || !f.validated {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(acmeProblem{Type: "urn:ietf:params:acme:error:unauthorized"})
+			return
+		}
+		f.certPEM = f.issue(csr)
+		json.NewEncoder(w).Encode(f.order())
+
+	case "/cert/1":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(f.certPEM)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeACME) order() acmeOrder {
+	order := acmeOrder{
+		Status:         "pending",
+		Authorizations: []string{f.url("/authz/1")},
+		Finalize:       f.url("/finalize/1"),
+	}
+	if f.certPEM != nil {
+		order.Status = "valid"
+		order.Certificate = f.url("/cert/1")
+	}
+	return order
+}
+
+// verify checks the JWS of a request and returns its payload.
+func (f *fakeACME) verify(r *http.Request) ([]byte, bool) {
+	var jws struct{ Protected, Payload, Signature string }
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		return nil, false
+	}
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(jws.Protected)
+	var header struct {
+		Alg, Nonce, URL, Kid string
+		JWK                  map[string]string
+	}
+	json.Unmarshal(headerJSON, &header)
+	if header.Alg != "ES256" || header.Nonce == "" || header.URL != f.url(r.URL.Path) {
+		return nil, false
+	}
+
+	key := f.accountKey
+	if header.JWK != nil {
+		x, _ := base64.RawURLEncoding.DecodeString(header.JWK["x"])
+		y, _ := base64.RawURLEncoding.DecodeString(header.JWK["y"])
+		key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		f.accountKey = key
+	} else if header.Kid != f.url("/account/1") {
+		return nil, false
+	}
+	if key == nil {
+		return nil, false
+	}
+
+	sig, _ := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if len(sig) != 64 {
+		return nil, false
+	}
+	digest := sha256.Sum256([]byte(jws.Protected + "." + jws.Payload))
+	if !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		return nil, false
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(jws.Payload)
+	return payload, true
+}
+
+// issue signs a certificate for a CSR and returns the PEM chain.
+func (f *fakeACME) issue(csr *x509.CertificateRequest) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, f.caCert, csr.PublicKey, f.caKey)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw})...)
+}
+
+func TestACMEManagerObtainsCertificate(t *testing.T) {
+	ca := newFakeACME(t)
+	cfg := AutoTLSConfig{
+		CacheDir:     t.TempDir(),
+		Email:        "ops@example.com",
+		DirectoryURL: ca.url("/directory"),
+		RenewBefore:  30 * 24 * time.Hour,
+	}
+
+	m := newACMEManager(cfg, []string{"Example.com"})
+	m.pollInterval = time.Millisecond
+	ca.manager = m
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("GetCertificate: unexpected error: %v", err)
+	}
+	if len(cert.Leaf.DNSNames) != 1 || cert.Leaf.DNSNames[0] != "example.com" {
+		t.Errorf("GetCertificate: unexpected names %v", cert.Leaf.DNSNames)
+	}
+	if len(cert.Certificate) != 2 {
+		t.Errorf("GetCertificate: expected the chain, got %d certificates", len(cert.Certificate))
+	}
+
+	if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"}); err == nil {
+		t.Error("GetCertificate: expected error for unconfigured host")
+	}
+
+	// Served from memory, then from the cache directory after a restart
+	if again, _ := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"}); again != cert {
+		t.Error("GetCertificate: expected cached certificate")
+	}
+	restarted := newACMEManager(cfg, []string{"example.com"})
+	if _, err := restarted.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"}); err != nil {
+		t.Fatalf("GetCertificate: unexpected error after restart: %v", err)
+	}
+	if ca.orders != 1 {
+		t.Errorf("expected one order, got %d", ca.orders)
+	}
+}
+
+func TestACMEManagerRenewsInBackground(t *testing.T) {
+	ca := newFakeACME(t)
+	cfg := AutoTLSConfig{
+		CacheDir:     t.TempDir(),
+		Email:        "ops@example.com",
+		DirectoryURL: ca.url("/directory"),
+		RenewBefore:  30 * 24 * time.Hour,
+	}
+
+	m := newACMEManager(cfg, []string{"example.com"})
+	m.pollInterval = time.Millisecond
+	ca.manager = m
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("GetCertificate: unexpected error: %v", err)
+	}
+
+	// The 90-day certificate is now due for renewal. Handshakes keep
+	// getting it while the certificate authority does not answer.
+	m.config.RenewBefore = 100 * 24 * time.Hour
+	ca.mu.Lock()
+	for i := 0; i < 10; i++ {
+		got, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+		if err != nil || got != cert {
+			ca.mu.Unlock()
+			t.Fatalf("GetCertificate: expected the current certificate, got %v", err)
+		}
+	}
+	ca.mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.mu.Lock()
+		renewed, renewing := m.certs["example.com"] != cert, m.renewing["example.com"]
+		m.mu.Unlock()
+		if renewed && !renewing {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the certificate to be renewed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.orders != 2 {
+		t.Errorf("expected one renewal order, got %d orders", ca.orders)
+	}
+}
+
+func TestRenewalBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := renewalBackoff(tt.failures); got != tt.want {
+			t.Errorf("renewalBackoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestACMEManagerChallengeHandler(t *testing.T) {
+	m := newACMEManager(AutoTLSConfig{}, []string{"example.com"})
+	m.tokens["token"] = "token.thumbprint"
+
+	handler := m.HTTPHandler(http.HandlerFunc(redirectHTTPS))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/.well-known/acme-challenge/token", nil))
+	if rec.Body.String() != "token.thumbprint" {
+		t.Errorf("expected key authorization, got %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com:80/users?page=2", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "https://example.com/users?page=2" {
+		t.Errorf("expected redirect to HTTPS, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 7638 canonical member order
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	data, _ := json.Marshal(jwk(&key.PublicKey))
+	if !strings.HasPrefix(string(data), `{"crv":"P-256","kty":"EC","x":"`) {
+		t.Errorf("unexpected JWK encoding %s", data)
+	}
+}
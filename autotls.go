@@ -0,0 +1,129 @@
+package quark
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AutoTLSConfig configures the certificates RunAutoTLS obtains from an
+// ACME certificate authority such as Let's Encrypt.
+type AutoTLSConfig struct {
+	// Addr is the address of the HTTPS server (default: ":443").
+	Addr string
+
+	// HTTPAddr is the address of the HTTP server answering ACME
+	// challenges and redirecting other requests to HTTPS (default: ":80").
+	HTTPAddr string
+
+	// CacheDir stores the account key and certificates across restarts
+	// (default: "certs"). It must be private to the application.
+	CacheDir string
+
+	// Email is the contact address of the ACME account, used for expiry
+	// notices.
+	Email string
+
+	// DirectoryURL is the ACME directory of the certificate authority
+	// (default: LetsEncryptURL).
+	DirectoryURL string
+
+	// RenewBefore renews certificates this long before they expire
+	// (default: 30 days). Renewals run in the background while the
+	// current certificate is served.
+	RenewBefore time.Duration
+}
+
+// WithAutoTLS configures RunAutoTLS.
+//
+// Example:
+//
+//	app := quark.New(quark.WithAutoTLS(quark.AutoTLSConfig{
+//	    CacheDir: "/var/lib/myapp/certs",
+//	    Email:    "ops@example.com",
+//	}))
+//	app.RunAutoTLS("example.com", "www.example.com")
+func WithAutoTLS(cfg AutoTLSConfig) Option {
+	return func(a *App) {
+		a.autoTLS = cfg
+	}
+}
+
+// RunAutoTLS starts an HTTPS server for the given domains with
+// certificates obtained and renewed automatically from an ACME
+// certificate authority, Let's Encrypt by default. Certificates are
+// requested on the first connection to each domain using HTTP-01
+// challenges, which requires the HTTP server (port 80 by default) to be
+// reachable from the internet; it also redirects other requests to HTTPS.
+// See WithAutoTLS for the cache directory and other settings.
+func (a *App) RunAutoTLS(domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("autotls: no domains")
+	}
+	cfg := a.autoTLS
+	if cfg.Addr == "" {
+		cfg.Addr = ":443"
+	}
+	if cfg.HTTPAddr == "" {
+		cfg.HTTPAddr = ":80"
+	}
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = "certs"
+	}
+	if cfg.DirectoryURL == "" {
+		cfg.DirectoryURL = LetsEncryptURL
+	}
+	if cfg.RenewBefore == 0 {
+		cfg.RenewBefore = 30 * 24 * time.Hour
+	}
+
+	if err := a.prepare(cfg.Addr); err != nil {
+		return err
+	}
+
+	manager := newACMEManager(cfg, domains)
+	if a.server.TLSConfig == nil {
+		a.server.TLSConfig = &tls.Config{
+			NextProtos: []string{"h2", "http/1.1"},
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	a.server.TLSConfig.GetCertificate = manager.GetCertificate
+	challengeServer := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           manager.HTTPHandler(http.HandlerFunc(redirectHTTPS)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	defer challengeServer.Close()
+
+	manager.onError = func(domain string, err error) {
+		a.logAt(slog.LevelError, "certificate renewal failed", "domain", domain, "error", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.renewLoop(ctx, 12*time.Hour, manager.onError)
+
+	serverErrors := make(chan error, 2)
+	go func() {
+		serverErrors <- challengeServer.ListenAndServe()
+	}()
+	go func() {
+		a.logAt(slog.LevelInfo, "starting TLS server", "addr", cfg.Addr, "domains", strings.Join(domains, ","))
+		serverErrors <- a.server.ListenAndServeTLS("", "")
+	}()
+	return <-serverErrors
+}
+
+// redirectHTTPS redirects a request to HTTPS.
+func redirectHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templates holds the project, handler and resource templates.
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"pascal": pascal,
+	"camel":  camel,
+	"snake":  snake,
+	"kebab":  kebab,
+	"plural": plural,
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// projectData is the data of the project templates.
+type projectData struct {
+	Name         string // Project directory name
+	Module       string // Go module path
+	QuarkVersion string
+}
+
+// resourceData is the data of the resource templates.
+type resourceData struct {
+	Package  string // Go package name, e.g. "notes"
+	Type     string // Resource type, e.g. "Note"
+	Var      string // Variable name, e.g. "note"
+	Path     string // Route group path, e.g. "/notes"
+	Singular string // Human name, e.g. "note"
+	Plural   string // Human plural, e.g. "notes"
+}
+
+// handlerData is the data of the handler templates.
+type handlerData struct {
+	Package string // Go package name
+	Name    string // Handler function name, e.g. "CreateInvoice"
+	Path    string // Suggested route path, e.g. "/create-invoice"
+}
+
+// runNew implements "quark new".
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	module := fs.String("module", "", "Go module path (default: the project name)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: quark new <name> [-module path]")
+		fs.PrintDefaults()
+	}
+	names := parseArgs(fs, args)
+	if len(names) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := names[0]
+	dir := filepath.Clean(name)
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory %s already exists and is not empty", dir)
+	}
+	if *module == "" {
+		*module = filepath.Base(dir)
+	}
+
+	data := projectData{Name: filepath.Base(dir), Module: *module, QuarkVersion: "v" + quark.Version}
+	files := []struct{ path, template string }{
+		{"go.mod", "go.mod.tmpl"},
+		{"main.go", "main.go.tmpl"},
+		{".env.example", "env.example.tmpl"},
+		{"Dockerfile", "Dockerfile.tmpl"},
+		{".gitignore", "gitignore.tmpl"},
+	}
+	for _, f := range files {
+		if err := render(filepath.Join(dir, f.path), f.template, data); err != nil {
+			return err
+		}
+	}
+	if err := generateResource(filepath.Join(dir, "internal", "notes"), "note"); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nCreated %s. Next steps:\n\n\tcd %s\n\tgo mod tidy\n\tgo run .\n", dir, dir)
+	return nil
+}
+
+// runGen implements "quark gen".
+func runGen(args []string) error {
+	if len(args) == 0 {
+		return errors.New(`usage: quark gen handler|resource <name> [-dir dir]`)
+	}
+	kind := args[0]
+
+	fs := flag.NewFlagSet("gen "+kind, flag.ExitOnError)
+	dir := fs.String("dir", "", "output directory")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: quark gen %s <name> [-dir dir]\n", kind)
+		fs.PrintDefaults()
+	}
+	names := parseArgs(fs, args[1:])
+	if len(names) != 1 || pascal(names[0]) == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := names[0]
+
+	switch kind {
+	case "handler":
+		if *dir == "" {
+			*dir = "."
+		}
+		return generateHandler(*dir, name)
+	case "resource":
+		if *dir == "" {
+			*dir = filepath.Join("internal", packageName(plural(strings.Join(words(name), ""))))
+		}
+		if err := generateResource(*dir, name); err != nil {
+			return err
+		}
+		if module, err := modulePath(*dir); err == nil {
+			pkg := packageName(plural(strings.Join(words(name), "")))
+			fmt.Printf("\nRegister the module in main.go:\n\n\timport %q\n\n\tapp.Register(%s.Module{})\n", module, pkg)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown generator %q, expected handler or resource", kind)
+}
+
+// generateResource writes a CRUD module for a resource into dir.
+func generateResource(dir, name string) error {
+	ws := words(name)
+	pluralName := plural(strings.Join(ws, " "))
+	data := resourceData{
+		Package:  packageName(pluralName),
+		Type:     pascal(name),
+		Var:      camel(name),
+		Path:     "/" + kebab(pluralName),
+		Singular: strings.Join(ws, " "),
+		Plural:   pluralName,
+	}
+	files := []struct{ path, template string }{
+		{"module.go", "resource_module.go.tmpl"},
+		{"handlers.go", "resource_handlers.go.tmpl"},
+		{"handlers_test.go", "resource_test.go.tmpl"},
+	}
+	for _, f := range files {
+		if err := render(filepath.Join(dir, f.path), f.template, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateHandler writes a handler and its test into the package in dir.
+func generateHandler(dir, name string) error {
+	pkg, err := dirPackage(dir)
+	if err != nil {
+		return err
+	}
+	data := handlerData{Package: pkg, Name: pascal(name), Path: "/" + kebab(name)}
+
+	file := snake(name)
+	if err := render(filepath.Join(dir, file+".go"), "handler.go.tmpl", data); err != nil {
+		return err
+	}
+	if err := render(filepath.Join(dir, file+"_test.go"), "handler_test.go.tmpl", data); err != nil {
+		return err
+	}
+	fmt.Printf("\nMount the handler on a route group:\n\n\tr.POST(%q, %s)\n", data.Path, data.Name)
+	return nil
+}
+
+// render executes a template into a new file, formatting Go sources. It
+// never overwrites existing files.
+func render(path, name string, data interface{}) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	if strings.HasSuffix(path, ".go") {
+		formatted, err := format.Source(out)
+		if err != nil {
+			return fmt.Errorf("formatting %s: %w", path, err)
+		}
+		out = formatted
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return err
+	}
+	fmt.Println("created", path)
+	return nil
+}
+
+// parseArgs parses flags placed before or after positional arguments,
+// returning the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// dirPackage returns the package name of the Go files in dir, or a name
+// derived from the directory for a new package.
+func dirPackage(dir string) (string, error) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "package ") {
+				f.Close()
+				return strings.TrimSpace(strings.TrimPrefix(line, "package ")), nil
+			}
+		}
+		f.Close()
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if pkg := packageName(filepath.Base(abs)); pkg != "" {
+		return pkg, nil
+	}
+	return "main", nil
+}
+
+// modulePath returns the import path of dir from the enclosing go.mod.
+func modulePath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, err := filepath.Rel(root, abs)
+					if err != nil {
+						return "", err
+					}
+					return strings.TrimSpace(module) + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod not found")
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Command quark is the Quark framework command-line tool. It scaffolds new
+// projects and generates handlers and resources wired to route groups.
+//
+// Usage:
+//
+//	quark new <name> [-module path]          create a project skeleton
+//	quark gen handler <Name> [-dir dir]      generate a handler with input and test
+//	quark gen resource <name> [-dir dir]     generate a CRUD module with tests
+//	quark routes [-dir dir | -url url]       list routes and report unreachable ones
+//	quark version                            print the Quark version
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+const usage = `Quark is a tool for Quark framework projects.
+
+Usage:
+
+	quark <command> [arguments]
+
+Commands:
+
+	new <name>            create a project skeleton in ./<name>
+	gen handler <Name>    generate a handler, its input struct and a test
+	gen resource <name>   generate a CRUD module with handlers and tests
+	routes                list routes, reporting duplicate, shadowed and unnamed ones
+	version               print the Quark version
+
+Run "quark <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "new":
+		err = runNew(args)
+	case "gen":
+		err = runGen(args)
+	case "routes":
+		err = runRoutes(args)
+	case "version":
+		fmt.Println("quark", quark.Version)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "quark: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "quark:", err)
+		os.Exit(1)
+	}
+}
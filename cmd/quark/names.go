@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits an identifier such as "userProfile", "UserProfile",
+// "user_profile" or "user-profile" into lower-case words.
+func words(name string) []string {
+	var parts []string
+	var current []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			if len(current) > 0 {
+				parts = append(parts, string(current))
+				current = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			parts = append(parts, string(current))
+			current = nil
+		}
+		current = append(current, unicode.ToLower(r))
+	}
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+	return parts
+}
+
+// pascal returns name in PascalCase, e.g. "UserProfile".
+func pascal(name string) string {
+	var b strings.Builder
+	for _, w := range words(name) {
+		if w == "id" || w == "url" || w == "api" || w == "http" {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// camel returns name in camelCase, e.g. "userProfile".
+func camel(name string) string {
+	p := pascal(name)
+	if p == "" {
+		return p
+	}
+	ws := words(name)
+	return ws[0] + p[len(ws[0]):]
+}
+
+// snake returns name in snake_case, e.g. "user_profile".
+func snake(name string) string {
+	return strings.Join(words(name), "_")
+}
+
+// kebab returns name in kebab-case, e.g. "user-profile".
+func kebab(name string) string {
+	return strings.Join(words(name), "-")
+}
+
+// plural returns the English plural of a lower-case word.
+func plural(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// packageName returns a Go package name for name, e.g. "userprofiles".
+func packageName(name string) string {
+	return strings.Join(words(name), "")
+}
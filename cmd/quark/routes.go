@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// runRoutes implements "quark routes". It prints the route table of the
+// application in dir, built with the quarkdebug tag, or of a running debug
+// build, and exits with an error when routes can never match.
+func runRoutes(args []string) error {
+	fs := flag.NewFlagSet("routes", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory of the application's main package")
+	url := fs.String("url", "", "base URL of a running quarkdebug build, instead of building dir")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	unnamed := fs.Bool("unnamed", true, "report routes without a name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: quark routes [-dir dir | -url url] [-json] [-unnamed=false]")
+		fs.PrintDefaults()
+	}
+	if len(parseArgs(fs, args)) != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var report quark.RouteReport
+	var err error
+	if *url != "" {
+		report, err = fetchRoutes(*url)
+	} else {
+		report, err = buildRoutes(*dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*unnamed {
+		issues := report.Issues[:0]
+		for _, issue := range report.Issues {
+			if issue.Kind != quark.RouteUnnamed {
+				issues = append(issues, issue)
+			}
+		}
+		report.Issues = issues
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printRoutes(report)
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Kind != quark.RouteUnnamed {
+			return errors.New("unreachable routes found")
+		}
+	}
+	return nil
+}
+
+// buildRoutes runs the application in dir with the quarkdebug tag, which
+// writes the route report instead of serving.
+func buildRoutes(dir string) (quark.RouteReport, error) {
+	var report quark.RouteReport
+
+	out, err := os.MkdirTemp("", "quark-routes")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(out)
+	file := filepath.Join(out, "routes.json")
+
+	var output bytes.Buffer
+	cmd := exec.Command("go", "run", "-tags", "quarkdebug", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), quark.RoutesOutEnv+"="+file)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	runErr := cmd.Run()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		os.Stderr.Write(output.Bytes())
+		if runErr != nil {
+			return report, fmt.Errorf("running %s: %w", dir, runErr)
+		}
+		return report, errors.New("the application exited without reporting its routes; it must call one of the App.Run methods")
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("reading route report: %w", err)
+	}
+	return report, nil
+}
+
+// fetchRoutes reads the route report from the debug endpoint of a running
+// application.
+func fetchRoutes(base string) (quark.RouteReport, error) {
+	var report quark.RouteReport
+
+	resp, err := http.Get(strings.TrimSuffix(base, "/") + quark.DebugRoutesPath)
+	if err != nil {
+		return report, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return report, fmt.Errorf("%s returned %s; is the application built with -tags quarkdebug?", quark.DebugRoutesPath, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return report, fmt.Errorf("reading route report: %w", err)
+	}
+	return report, nil
+}
+
+// printRoutes prints the route table and its issues.
+func printRoutes(report quark.RouteReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATTERN\tNAME")
+	for _, route := range report.Routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Pattern, route.Name)
+	}
+	w.Flush()
+
+	if len(report.Issues) == 0 {
+		return
+	}
+	fmt.Printf("\n%d issue(s):\n\n", len(report.Issues))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, issue := range report.Issues {
+		fmt.Fprintf(w, "  %s\t%s\n", issue.Kind, issue)
+	}
+	w.Flush()
+}
@@ -0,0 +1,211 @@
+package quark
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Codec marshals and unmarshals messages of a wire format for binding and
+// responses.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// NewCodec returns a Codec from a pair of functions, such as the Marshal
+// and Unmarshal functions of a JSON package.
+func NewCodec(marshal func(v interface{}) ([]byte, error), unmarshal func(data []byte, v interface{}) error) Codec {
+	return funcCodec{marshal: marshal, unmarshal: unmarshal}
+}
+
+// funcCodec is a Codec made of functions.
+type funcCodec struct {
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+}
+
+func (f funcCodec) Marshal(v interface{}) ([]byte, error)      { return f.marshal(v) }
+func (f funcCodec) Unmarshal(data []byte, v interface{}) error { return f.unmarshal(data, v) }
+
+// Content types with helpers on Context. Codecs other than the JSON one are
+// registered by the application, keeping Quark free of serialization
+// dependencies.
+const (
+	MIMEJSON     = "application/json"
+	MIMEProtobuf = "application/x-protobuf"
+	MIMEMsgPack  = "application/msgpack"
+)
+
+// defaultJSONCodec is the encoding/json codec.
+var defaultJSONCodec = NewCodec(json.Marshal, json.Unmarshal)
+
+// WithCodec registers the codec of a content type, see App.RegisterCodec.
+func WithCodec(contentType string, codec Codec) Option {
+	return func(a *App) {
+		a.RegisterCodec(contentType, codec)
+	}
+}
+
+// WithJSONCodec sets the codec used for JSON, see App.SetJSONCodec.
+func WithJSONCodec(codec Codec) Option {
+	return func(a *App) {
+		a.SetJSONCodec(codec)
+	}
+}
+
+// WithProtoCodec sets the codec used for Protocol Buffers messages, see
+// App.SetProtoCodec.
+func WithProtoCodec(codec Codec) Option {
+	return func(a *App) {
+		a.SetProtoCodec(codec)
+	}
+}
+
+// RegisterCodec registers the codec of a content type. Context.Bind decodes
+// request bodies of that type with it, and Context.Encode sends responses
+// with it, so formats such as MessagePack or CBOR can be added without
+// wrapping Bind.
+//
+// Example:
+//
+//	type msgpackCodec struct{}
+//
+//	func (msgpackCodec) Marshal(v interface{}) ([]byte, error)   { return msgpack.Marshal(v) }
+//	func (msgpackCodec) Unmarshal(b []byte, v interface{}) error { return msgpack.Unmarshal(b, v) }
+//
+//	app.RegisterCodec(quark.MIMEMsgPack, msgpackCodec{})
+//	app.RegisterCodec("application/x-msgpack", msgpackCodec{})
+func (a *App) RegisterCodec(contentType string, codec Codec) {
+	if a.codecs == nil {
+		a.codecs = make(map[string]Codec)
+	}
+	a.codecs[strings.ToLower(contentType)] = codec
+}
+
+// Codec returns the codec registered for a content type, or nil.
+func (a *App) Codec(contentType string) Codec {
+	return a.codecs[strings.ToLower(contentType)]
+}
+
+// SetJSONCodec replaces encoding/json for Context.JSON, Context.BindJSON and
+// the other JSON helpers, e.g. with a faster encoder or one with custom
+// time and number formatting.
+//
+// Example:
+//
+//	app.SetJSONCodec(quark.NewCodec(sonic.Marshal, sonic.Unmarshal))
+func (a *App) SetJSONCodec(codec Codec) {
+	a.RegisterCodec(MIMEJSON, codec)
+}
+
+// SetProtoCodec sets the codec used by Context.BindProto, Context.Proto and
+// Context.Bind for Protocol Buffers messages. Quark does not depend on the
+// protobuf runtime, so applications plug it in:
+//
+//	type protoCodec struct{}
+//
+//	func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+//	    return proto.Marshal(v.(proto.Message))
+//	}
+//
+//	func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+//	    return proto.Unmarshal(data, v.(proto.Message))
+//	}
+//
+//	app.SetProtoCodec(protoCodec{})
+func (a *App) SetProtoCodec(codec Codec) {
+	a.RegisterCodec(MIMEProtobuf, codec)
+	a.RegisterCodec("application/protobuf", codec)
+}
+
+// codec returns the codec registered for a content type, or nil.
+func (c *Context) codec(contentType string) Codec {
+	if c.app == nil {
+		return nil
+	}
+	return c.app.Codec(contentType)
+}
+
+// jsonCodec returns the JSON codec of the App, encoding/json by default.
+func (c *Context) jsonCodec() Codec {
+	if codec := c.codec(MIMEJSON); codec != nil {
+		return codec
+	}
+	return defaultJSONCodec
+}
+
+// BindProto decodes a Protocol Buffers message from the request body.
+func (c *Context) BindProto(msg interface{}) error {
+	codec := c.codec(MIMEProtobuf)
+	if codec == nil {
+		return ErrInternal("no protobuf codec configured")
+	}
+	return c.bindCodec(codec, msg, "invalid protobuf message")
+}
+
+// Proto sends a Protocol Buffers response with the given status code.
+func (c *Context) Proto(code int, msg interface{}) error {
+	return c.Encode(code, MIMEProtobuf, msg)
+}
+
+// BindMsgPack decodes a MessagePack request body into v with the codec
+// registered for MIMEMsgPack.
+func (c *Context) BindMsgPack(v interface{}) error {
+	codec := c.codec(MIMEMsgPack)
+	if codec == nil {
+		return ErrInternal("no msgpack codec configured")
+	}
+	return c.bindCodec(codec, v, "invalid MessagePack body")
+}
+
+// MsgPack sends a MessagePack response with the codec registered for
+// MIMEMsgPack.
+func (c *Context) MsgPack(code int, v interface{}) error {
+	return c.Encode(code, MIMEMsgPack, v)
+}
+
+// Encode sends v encoded with the codec registered for contentType.
+func (c *Context) Encode(code int, contentType string, v interface{}) error {
+	codec := c.codec(contentType)
+	if codec == nil {
+		return ErrInternal("no codec registered for " + contentType)
+	}
+	return c.renderCodec(code, contentType, codec, v)
+}
+
+// bindCodec decodes the request body into v with codec.
+func (c *Context) bindCodec(codec Codec, v interface{}, invalid string) error {
+	if c.Request.Body == nil {
+		return ErrBadRequest("empty request body")
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return readBodyError(err)
+	}
+
+	if err := applyDefaults(v); err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(body, v); err != nil {
+		return WrapError(http.StatusBadRequest, invalid, err)
+	}
+	return nil
+}
+
+// renderCodec encodes v with codec and sends it. The message is encoded
+// before the status is written, so encoding errors can still be reported.
+func (c *Context) renderCodec(code int, contentType string, codec Codec, v interface{}) error {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "failed to encode response", err)
+	}
+
+	c.SetHeader("Content-Type", contentType)
+	c.Writer.WriteHeader(code)
+	c.markWritten()
+	_, err = c.Writer.Write(data)
+	return err
+}
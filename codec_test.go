@@ -0,0 +1,191 @@
+package quark
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testMessage is encoded by lineCodec as its name.
+type testMessage struct {
+	Name string
+}
+
+// lineCodec is a Codec standing in for a real wire format.
+type lineCodec struct{}
+
+func (lineCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(*testMessage)
+	if !ok {
+		return nil, errors.New("not a test message")
+	}
+	return []byte(msg.Name), nil
+}
+
+func (lineCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(*testMessage)
+	if !ok {
+		return errors.New("not a test message")
+	}
+	if strings.Contains(string(data), "\n") {
+		return errors.New("invalid message")
+	}
+	msg.Name = string(data)
+	return nil
+}
+
+func TestContextProto(t *testing.T) {
+	app := New(WithProtoCodec(lineCodec{}))
+
+	t.Run("bind", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("john"))
+		req.Header.Set("Content-Type", MIMEProtobuf)
+		c := newContext(httptest.NewRecorder(), req, app)
+
+		var msg testMessage
+		if err := c.Bind(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Name != "john" {
+			t.Errorf("expected john, got %q", msg.Name)
+		}
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a\nb"))
+		c := newContext(httptest.NewRecorder(), req, app)
+
+		var he *HTTPError
+		if err := c.BindProto(&testMessage{}); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %v", err)
+		}
+	})
+
+	t.Run("render", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), app)
+
+		if err := c.Proto(http.StatusCreated, &testMessage{Name: "jane"}); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusCreated || rec.Body.String() != "jane" {
+			t.Errorf("unexpected response %d %q", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != MIMEProtobuf {
+			t.Errorf("expected %s, got %s", MIMEProtobuf, ct)
+		}
+	})
+
+	t.Run("encoding error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), app)
+
+		if err := c.Proto(http.StatusOK, "not a message"); err == nil {
+			t.Fatal("expected an error")
+		}
+		if c.IsWritten() {
+			t.Error("expected nothing to be written")
+		}
+	})
+}
+
+func TestContextProtoWithoutCodec(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("john"))
+	req.Header.Set("Content-Type", MIMEProtobuf)
+	c := newContext(httptest.NewRecorder(), req, New())
+
+	var he *HTTPError
+	if err := c.Bind(&testMessage{}); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("expected unsupported content type, got %v", err)
+	}
+	if err := c.Proto(http.StatusOK, &testMessage{}); !errors.As(err, &he) || he.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %v", err)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	app := New(WithCodec(MIMEMsgPack, lineCodec{}))
+	app.POST("/echo", func(c *Context) error {
+		var msg testMessage
+		if err := c.Bind(&msg); err != nil {
+			return err
+		}
+		return c.MsgPack(http.StatusOK, &msg)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("john"))
+	req.Header.Set("Content-Type", "Application/MsgPack; charset=binary")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "john" {
+		t.Errorf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != MIMEMsgPack {
+		t.Errorf("expected %s, got %s", MIMEMsgPack, ct)
+	}
+
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), app)
+	var he *HTTPError
+	if err := c.Encode(http.StatusOK, "application/cbor", &testMessage{}); !errors.As(err, &he) || he.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for an unregistered content type, got %v", err)
+	}
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	var marshals, unmarshals int
+	codec := NewCodec(func(v interface{}) ([]byte, error) {
+		marshals++
+		return json.Marshal(M{"wrapped": v})
+	}, func(data []byte, v interface{}) error {
+		unmarshals++
+		return json.Unmarshal(data, v)
+	})
+
+	app := New(WithJSONCodec(codec))
+	app.POST("/echo", func(c *Context) error {
+		var input M
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, input)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if want := "{\"wrapped\":{\"id\":1}}\n"; rec.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, rec.Body.String())
+	}
+	if marshals != 1 || unmarshals != 1 {
+		t.Errorf("expected the codec to be used once each way, got %d marshals and %d unmarshals", marshals, unmarshals)
+	}
+
+	rec = httptest.NewRecorder()
+	c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), app)
+	if err := c.NDJSON(http.StatusOK, slices.Values([]int{1, 2})); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"wrapped\":1}\n{\"wrapped\":2}\n"; rec.Body.String() != want {
+		t.Errorf("expected NDJSON to use the codec, got %q", rec.Body.String())
+	}
+}
+
+func TestContextJSONEncodingError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	var he *HTTPError
+	if err := c.JSON(http.StatusOK, func() {}); !errors.As(err, &he) || he.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %v", err)
+	}
+	if c.IsWritten() {
+		t.Error("expected nothing to be written")
+	}
+}
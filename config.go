@@ -11,7 +11,7 @@ import (
 
 // Config holds the application configuration.
 type Config struct {
-	Port            string        `env:"PORT" default:"8080"`
+	Port            string        `env:"PORT" default:"8080" validate:"port"`
 	Host            string        `env:"HOST" default:"0.0.0.0"`
 	Environment     string        `env:"ENV" default:"development"`
 	Debug           bool          `env:"DEBUG" default:"false"`
@@ -19,6 +19,18 @@ type Config struct {
 	WriteTimeout    time.Duration `env:"WRITE_TIMEOUT" default:"30s"`
 	IdleTimeout     time.Duration `env:"IDLE_TIMEOUT" default:"120s"`
 	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	DrainDelay      time.Duration `env:"DRAIN_DELAY" default:"0s"`
+
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" default:"10s"`
+	MaxHeaderBytes    int           `env:"MAX_HEADER_BYTES" default:"1048576"`
+	DisableKeepAlives bool          `env:"DISABLE_KEEP_ALIVES" default:"false"`
+
+	// TrustedProxies lists the CIDRs or IPs of reverse proxies whose
+	// forwarded headers are honored by Context.RealIP and Context.Scheme
+	// (default: loopback only). List the addresses of your load balancers
+	// rather than whole private networks, whose other hosts could
+	// otherwise spoof client IPs.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" default:"127.0.0.0/8,::1/128"`
 }
 
 // IsDevelopment returns true if running in development mode.
@@ -47,6 +59,8 @@ func LoadConfig() (*Config, error) {
 
 // LoadFromEnv loads configuration from environment variables into any struct.
 // It uses the `env` tag to map environment variables and `default` tag for defaults.
+// The loaded struct is then checked with Validate, honoring `validate` tags;
+// the returned error wraps the ValidationErrors.
 //
 // Supported types: string, bool, int, int64, uint, uint64, float64, time.Duration
 //
@@ -54,10 +68,30 @@ func LoadConfig() (*Config, error) {
 //
 //	type MyConfig struct {
 //	    DatabaseURL string        `env:"DATABASE_URL" default:"postgres://localhost/db"`
-//	    MaxRetries  int           `env:"MAX_RETRIES" default:"3"`
+//	    DBPassword  string        `env:"DB_PASSWORD" validate:"required"`
+//	    MaxRetries  int           `env:"MAX_RETRIES" default:"3" validate:"gte:0"`
 //	    Timeout     time.Duration `env:"TIMEOUT" default:"10s"`
 //	}
 func LoadFromEnv(cfg interface{}) error {
+	return LoadFromEnvPrefix("", cfg)
+}
+
+// LoadFromEnvPrefix loads configuration like LoadFromEnv, prepending
+// prefix to every environment variable name. It allows several instances
+// of the same config struct, such as one per database connection.
+//
+// Example:
+//
+//	// Reads ANALYTICS_DB_HOST, ANALYTICS_DB_PORT, ...
+//	var cfg database.Config
+//	err := quark.LoadFromEnvPrefix("ANALYTICS_", &cfg)
+func LoadFromEnvPrefix(prefix string, cfg interface{}) error {
+	return loadConfig(prefix, cfg, nil, nil)
+}
+
+// loadConfig loads cfg from, in increasing precedence, `default` tags,
+// file values, prefixed environment variables and set flags.
+func loadConfig(prefix string, cfg interface{}, file map[string]interface{}, flags map[string]string) error {
 	v := reflect.ValueOf(cfg)
 	if v.Kind() != reflect.Ptr || v.IsNil() {
 		return fmt.Errorf("cfg must be a non-nil pointer to a struct")
@@ -68,6 +102,19 @@ func LoadFromEnv(cfg interface{}) error {
 		return fmt.Errorf("cfg must be a pointer to a struct")
 	}
 
+	if err := loadStruct(v, prefix, file, flags); err != nil {
+		return err
+	}
+
+	// Fail fast on invalid settings rather than mid-request
+	if errs := Validate(cfg); errs.HasErrors() {
+		return fmt.Errorf("invalid configuration: %w", errs)
+	}
+	return nil
+}
+
+// loadStruct loads the fields of a struct value.
+func loadStruct(v reflect.Value, prefix string, file map[string]interface{}, flags map[string]string) error {
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
@@ -79,23 +126,46 @@ func LoadFromEnv(cfg interface{}) error {
 		}
 
 		envKey := field.Tag.Get("env")
-		if envKey == "" {
+		key := fileKey(field)
+		if envKey == "" && fieldValue.Kind() == reflect.Struct {
 			// If no env tag, try to load nested struct
-			if fieldValue.Kind() == reflect.Struct {
-				if err := LoadFromEnv(fieldValue.Addr().Interface()); err != nil {
-					return err
-				}
+			section, _ := lookupKey(file, key).(map[string]interface{})
+			if err := loadStruct(fieldValue, prefix, section, flags); err != nil {
+				return err
 			}
 			continue
 		}
 
-		defaultValue := field.Tag.Get("default")
-		value := os.Getenv(envKey)
-
-		if value == "" {
-			value = defaultValue
+		var value string
+		if envKey != "" || field.Tag.Get("config") != "" {
+			value = field.Tag.Get("default")
+		}
+		var items []string // File lists for string slices, kept whole
+		if fv := lookupKey(file, key); fv != nil {
+			if list, ok := fv.([]interface{}); ok && fieldValue.Type() == reflect.TypeOf(items) {
+				items = make([]string, len(list))
+				for i, item := range list {
+					items[i] = fileString(item)
+				}
+			} else {
+				value = fileString(fv)
+			}
+		}
+		if envKey != "" {
+			if env := os.Getenv(prefix + envKey); env != "" {
+				value, items = env, nil
+			}
+		}
+		if name := field.Tag.Get("flag"); name != "" {
+			if flagValue, ok := flags[name]; ok {
+				value, items = flagValue, nil
+			}
 		}
 
+		if items != nil {
+			fieldValue.Set(reflect.ValueOf(items))
+			continue
+		}
 		if value == "" {
 			continue
 		}
@@ -0,0 +1,78 @@
+package quark
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces secret values in configuration dumps.
+const redacted = "[REDACTED]"
+
+// secretKeyFragments mark fields as secret by their key when they have no
+// `secret` tag.
+var secretKeyFragments = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key", "credential", "dsn"}
+
+// DumpConfig writes the resolved configuration as "key = value" lines, with
+// the keys used by LoadConfigFrom, so deployments can check what was
+// loaded. Values of fields tagged `secret:"true"`, or whose key contains
+// "password", "secret", "token", "api_key", "private_key", "credential" or
+// "dsn", are replaced by [REDACTED] unless tagged `secret:"false"`. In
+// debug mode the app logs its own configuration at startup.
+//
+// Example:
+//
+//	type AppConfig struct {
+//	    DatabaseURL string `env:"DATABASE_URL" secret:"true"` // Embeds credentials
+//	    DBPassword  string `env:"DB_PASSWORD"`                // Redacted by name
+//	}
+//
+//	quark.DumpConfig(os.Stdout, &cfg)
+//	// database_url = [REDACTED]
+//	// db_password = [REDACTED]
+func DumpConfig(w io.Writer, cfg interface{}) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cfg must be a struct or a pointer to a struct")
+	}
+
+	var err error
+	walkConfig(v, "", func(key string, field reflect.StructField, value reflect.Value) {
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "%s = %s\n", key, dumpValue(key, field, value))
+	})
+	return err
+}
+
+// dumpValue formats a config value, redacting secrets.
+func dumpValue(key string, field reflect.StructField, value reflect.Value) string {
+	if isSecret(key, field) && !value.IsZero() {
+		return redacted
+	}
+	if value.Kind() == reflect.String {
+		return strconv.Quote(value.String())
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// isSecret reports whether a config field holds a secret.
+func isSecret(key string, field reflect.StructField) bool {
+	if tag, ok := field.Tag.Lookup("secret"); ok {
+		secret, _ := strconv.ParseBool(tag)
+		return secret
+	}
+	key = strings.ToLower(key)
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,568 @@
+package quark
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// LoadConfigFrom loads configuration from a JSON, YAML or TOML file, chosen
+// by extension, into any struct. Values are applied in increasing
+// precedence:
+//
+//  1. `default` tags
+//  2. the file
+//  3. environment variables (`env` tags)
+//  4. command-line flags set on flag.CommandLine (`flag` tags), once
+//     flag.Parse has been called
+//
+// A field's file key is its `config` tag, otherwise its lowercased `env`
+// tag, otherwise its snake_cased name; nested structs are read from a
+// section of the same name. Keys match case-insensitively, and lists fill
+// string slices.
+//
+// YAML and TOML support is minimal: nested mappings/tables, block and
+// flow lists of scalars, quoted strings and comments. Anchors, multi-line
+// strings and arrays of tables are not supported.
+//
+// Example:
+//
+//	type AppConfig struct {
+//	    Port     string        `env:"PORT" default:"8080" flag:"port"`
+//	    Timeout  time.Duration `env:"TIMEOUT" default:"30s"`
+//	    Database struct {
+//	        URL      string `config:"url" env:"DATABASE_URL"`
+//	        MaxConns int    `config:"max_conns" default:"10"`
+//	    }
+//	}
+//
+//	// config.yaml:
+//	//   port: 9000
+//	//   timeout: 1m
+//	//   database:
+//	//     url: postgres://localhost/app
+//	//     max_conns: 20
+//
+//	flag.String("port", "", "listen port")
+//	flag.Parse()
+//
+//	var cfg AppConfig
+//	err := quark.LoadConfigFrom("config.yaml", &cfg)
+func LoadConfigFrom(path string, cfg interface{}) error {
+	return LoadConfigFromPrefix(path, "", cfg)
+}
+
+// LoadConfigFromPrefix loads configuration like LoadConfigFrom, prepending
+// prefix to every environment variable name.
+func LoadConfigFromPrefix(path, prefix string, cfg interface{}) error {
+	file, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return loadConfig(prefix, cfg, file, setFlags())
+}
+
+// readConfigFile reads and parses a configuration file.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var file map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&file)
+	case ".yaml", ".yml":
+		file, err = parseYAML(data)
+	case ".toml":
+		file, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// setFlags returns the values of the flags set on the command line.
+func setFlags() map[string]string {
+	flags := make(map[string]string)
+	if !flag.Parsed() {
+		return flags
+	}
+	flag.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// fileKey returns the configuration file key of a field.
+func fileKey(field reflect.StructField) string {
+	if key := field.Tag.Get("config"); key != "" {
+		return key
+	}
+	if env := field.Tag.Get("env"); env != "" {
+		return strings.ToLower(env)
+	}
+	return snakeCase(field.Name)
+}
+
+// lookupKey returns the value of key in m, matched case-insensitively.
+func lookupKey(m map[string]interface{}, key string) interface{} {
+	if m == nil || key == "-" {
+		return nil
+	}
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// fileString converts a file value to the string form parsed by setField.
+// Lists are joined with commas, as in environment variables.
+func fileString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fileString(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// snakeCase converts a Go field name to snake_case ("ReadTimeout" becomes
+// "read_timeout", "DatabaseURL" becomes "database_url").
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// yamlLine is a significant line of a YAML document.
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML parses a minimal subset of YAML: nested mappings, block and
+// flow lists, and scalars, which are all kept as strings.
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var lines []*yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := strings.TrimRight(stripComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, &yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[p.pos].num)
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("line %d: document must be a mapping", lines[0].num)
+	}
+	return m, nil
+}
+
+// yamlParser parses YAML lines.
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+// block parses a mapping or list at indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isListItem(p.lines[p.pos].text) {
+		return p.list(indent)
+	}
+	return p.mapping(indent)
+}
+
+// mapping parses the keys of a mapping at indent.
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent || isListItem(line.text) {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.num)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := yamlScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.num, err)
+			}
+			m[key] = value
+			continue
+		}
+
+		// Nested block, or a list indented like its key
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && isListItem(next.text)) {
+				value, err := p.block(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = value
+				continue
+			}
+		}
+		m[key] = nil
+	}
+	return m, nil
+}
+
+// list parses the items of a list at indent.
+func (p *yamlParser) list(indent int) ([]interface{}, error) {
+	var items []interface{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && !isListItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		switch {
+		case rest == "":
+			// Nested block on the following lines
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				items = append(items, nil)
+				continue
+			}
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+
+		case isMappingEntry(rest):
+			// "- key: value" starts a mapping indented like its first key
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			value, err := p.mapping(line.indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+
+		default:
+			value, err := yamlScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.num, err)
+			}
+			items = append(items, value)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+// isListItem reports whether a line starts a list item.
+func isListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry reports whether text is a "key: value" entry.
+func isMappingEntry(text string) bool {
+	if text[0] == '[' || text[0] == '{' {
+		return false
+	}
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits a "key: value" line.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return "", "", false
+		}
+		key, _ = unquoteConfig(text[:end+2])
+		return key, strings.TrimSpace(text[end+3:]), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// yamlScalar parses a scalar or flow list.
+func yamlScalar(s string) (interface{}, error) {
+	switch {
+	case s == "~" || s == "null":
+		return nil, nil
+	case s == "|" || s == ">" || strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">"):
+		return nil, fmt.Errorf("block scalars are not supported")
+	case strings.HasPrefix(s, "&") || strings.HasPrefix(s, "*"):
+		return nil, fmt.Errorf("anchors and aliases are not supported")
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("flow mappings are not supported")
+	case strings.HasPrefix(s, "["):
+		return flowList(s)
+	default:
+		return unquoteConfig(s)
+	}
+}
+
+// parseTOML parses a minimal subset of TOML: tables, dotted keys, and
+// values that are scalars or single-line arrays, all kept as strings.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	table := root
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		num := i + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[[") {
+			return nil, fmt.Errorf("line %d: arrays of tables are not supported", num)
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header", num)
+			}
+			var err error
+			table, err = tomlTable(root, line[1:len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", num)
+		}
+		keys, err := tomlKeys(line[:eq])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		target, err := tomlTable(table, strings.Join(keys[:len(keys)-1], "."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		value, err := tomlValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		target[keys[len(keys)-1]] = value
+	}
+	return root, nil
+}
+
+// tomlTable returns the table at a dotted path below m, creating it.
+func tomlTable(m map[string]interface{}, path string) (map[string]interface{}, error) {
+	if strings.TrimSpace(path) == "" {
+		return m, nil
+	}
+	keys, err := tomlKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		next, ok := m[key]
+		if !ok {
+			table := make(map[string]interface{})
+			m[key] = table
+			m = table
+			continue
+		}
+		table, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a table", key)
+		}
+		m = table
+	}
+	return m, nil
+}
+
+// tomlKeys splits a dotted key.
+func tomlKeys(s string) ([]string, error) {
+	var keys []string
+	for _, part := range splitOutsideQuotes(s, '.') {
+		key, err := unquoteConfig(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty key in %q", s)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// tomlValue parses a value.
+func tomlValue(s string) (interface{}, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("inline tables are not supported")
+	case strings.HasPrefix(s, "["):
+		return flowList(s)
+	default:
+		return unquoteConfig(s)
+	}
+}
+
+// flowList parses a single-line "[a, b, c]" list of scalars.
+func flowList(s string) ([]interface{}, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("lists must be on a single line")
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	items := []interface{}{}
+	if inner == "" {
+		return items, nil
+	}
+	for _, part := range splitOutsideQuotes(inner, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue // Trailing comma
+		}
+		if strings.HasPrefix(part, "[") || strings.HasPrefix(part, "{") {
+			return nil, fmt.Errorf("nested lists are not supported")
+		}
+		item, err := unquoteConfig(part)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// unquoteConfig unquotes a double- or single-quoted string, returning
+// other values as is.
+func unquoteConfig(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return unquoted, nil
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
+
+// stripComment removes a "#" comment outside quotes from a line.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitOutsideQuotes splits s at sep characters outside quotes.
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,153 @@
+package quark
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fileTestConfig struct {
+	Port     string        `env:"QUARK_TEST_PORT" default:"8080" flag:"quark-test-port"`
+	Host     string        `env:"QUARK_TEST_HOST" default:"localhost"`
+	Timeout  time.Duration `env:"QUARK_TEST_TIMEOUT" default:"30s"`
+	Debug    bool          `env:"QUARK_TEST_DEBUG"`
+	Tags     []string      `config:"tags"`
+	Database struct {
+		URL      string `config:"url"`
+		MaxConns int    `config:"max_conns" default:"10" validate:"gte:0"`
+		Replicas []string
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFromFormats(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+# Server
+quark_test_port: "9000"
+quark_test_timeout: 1m   # inline comment
+quark_test_debug: true
+tags: [web, "api, v2"]
+database:
+  url: postgres://localhost/app#main
+  max_conns: 20
+  replicas:
+    - db1
+    - 'db2'
+`,
+		"config.toml": `
+# Server
+quark_test_port = "9000"
+quark_test_timeout = "1m"
+quark_test_debug = true
+tags = ["web", "api, v2"]
+
+[database]
+url = "postgres://localhost/app#main" # comment
+max_conns = 20
+replicas = ["db1", 'db2']
+`,
+		"config.json": `{
+  "QUARK_TEST_PORT": 9000,
+  "quark_test_timeout": "1m",
+  "quark_test_debug": true,
+  "tags": ["web", "api, v2"],
+  "database": {"url": "postgres://localhost/app#main", "max_conns": 20, "replicas": ["db1", "db2"]}
+}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			var cfg fileTestConfig
+			if err := LoadConfigFrom(writeConfigFile(t, name, content), &cfg); err != nil {
+				t.Fatalf("LoadConfigFrom: unexpected error: %v", err)
+			}
+
+			if cfg.Port != "9000" || cfg.Host != "localhost" || cfg.Timeout != time.Minute || !cfg.Debug {
+				t.Errorf("unexpected values: %+v", cfg)
+			}
+			if cfg.Database.URL != "postgres://localhost/app#main" || cfg.Database.MaxConns != 20 {
+				t.Errorf("unexpected database values: %+v", cfg.Database)
+			}
+			if !reflect.DeepEqual(cfg.Database.Replicas, []string{"db1", "db2"}) {
+				t.Errorf("unexpected replicas: %v", cfg.Database.Replicas)
+			}
+			if !reflect.DeepEqual(cfg.Tags, []string{"web", "api, v2"}) {
+				t.Errorf("unexpected tags: %v", cfg.Tags)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "quark_test_port: 9000\nquark_test_host: file\n")
+
+	t.Setenv("QUARK_TEST_PORT", "9100")
+	var cfg fileTestConfig
+	if err := LoadConfigFrom(path, &cfg); err != nil {
+		t.Fatalf("LoadConfigFrom: unexpected error: %v", err)
+	}
+	if cfg.Port != "9100" || cfg.Host != "file" || cfg.Database.MaxConns != 10 {
+		t.Errorf("expected env over file over defaults, got %+v", cfg)
+	}
+
+	flag.String("quark-test-port", "", "test flag")
+	if err := flag.Set("quark-test-port", "9200"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfigFrom(path, &cfg); err != nil {
+		t.Fatalf("LoadConfigFrom: unexpected error: %v", err)
+	}
+	if cfg.Port != "9200" {
+		t.Errorf("expected flag over env, got %s", cfg.Port)
+	}
+}
+
+func TestLoadConfigFromErrors(t *testing.T) {
+	tests := map[string]string{
+		"config.ini":   "port=1",
+		"bad.yaml":     "port: 1\n  host: x\n",
+		"block.yaml":   "text: |\n  line\n",
+		"list.yaml":    "- a\n- b\n",
+		"table.toml":   "[[servers]]\nname = \"a\"\n",
+		"value.toml":   "port\n",
+		"array.toml":   "tags = [\"a\",\n\"b\"]\n",
+		"broken.json":  "{",
+		"invalid.yaml": "database:\n  max_conns: -1\n",
+	}
+	for name, content := range tests {
+		var cfg fileTestConfig
+		if err := LoadConfigFrom(writeConfigFile(t, name, content), &cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	var cfg fileTestConfig
+	if err := LoadConfigFrom(filepath.Join(t.TempDir(), "missing.yaml"), &cfg); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"Port":        "port",
+		"ReadTimeout": "read_timeout",
+		"DatabaseURL": "database_url",
+		"URLPrefix":   "url_prefix",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
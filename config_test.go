@@ -0,0 +1,52 @@
+package quark
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type validatedTestConfig struct {
+	Password string `env:"QUARK_TEST_PASSWORD" validate:"required"`
+	Port     int    `env:"QUARK_TEST_VPORT" default:"8080" validate:"port"`
+}
+
+func TestLoadFromEnvValidation(t *testing.T) {
+	t.Setenv("QUARK_TEST_VPORT", "70000")
+
+	var cfg validatedTestConfig
+	err := LoadFromEnv(&cfg)
+	if err == nil {
+		t.Fatal("LoadFromEnv: expected validation error")
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("LoadFromEnv: expected 2 aggregated validation errors, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid configuration: ") {
+		t.Errorf("LoadFromEnv: unexpected message %q", err.Error())
+	}
+
+	t.Setenv("QUARK_TEST_PASSWORD", "secret")
+	t.Setenv("QUARK_TEST_VPORT", "5432")
+	if err := LoadFromEnv(&cfg); err != nil {
+		t.Errorf("LoadFromEnv: unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	t.Setenv("PORT", "http")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("LoadConfig: expected error for invalid port")
+	}
+
+	t.Setenv("PORT", "3000")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: unexpected error: %v", err)
+	}
+	if cfg.Port != "3000" {
+		t.Errorf("LoadConfig: expected port 3000, got %s", cfg.Port)
+	}
+}
@@ -0,0 +1,218 @@
+package quark
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConfigWatcher reloads a configuration file when it changes and notifies
+// handlers of the changed values, so settings such as log levels, feature
+// flags and rate limits can change without a restart. Each reload builds a
+// new config, so snapshots returned by Config are never modified.
+type ConfigWatcher[T any] struct {
+	path     string
+	prefix   string
+	interval time.Duration
+
+	mu       sync.RWMutex
+	current  *T
+	data     []byte
+	handlers []func(key string, old, new interface{})
+	onError  []func(error)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// WatchConfig loads a configuration file like LoadConfigFrom and checks it
+// for changes every interval (default: 1s) until the watcher is closed. A
+// file that fails to load keeps the previous config and is reported to the
+// OnError handlers.
+//
+// Example:
+//
+//	watcher, err := quark.WatchConfig[AppConfig]("config.yaml", 5*time.Second)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer watcher.Close()
+//
+//	watcher.OnChange(func(key string, old, new interface{}) {
+//	    if key == "log_level" {
+//	        logger.SetLevel(new.(string))
+//	    }
+//	})
+//
+//	limit := watcher.Config().RateLimit
+func WatchConfig[T any](path string, interval time.Duration) (*ConfigWatcher[T], error) {
+	return WatchConfigPrefix[T](path, "", interval)
+}
+
+// WatchConfigPrefix watches a configuration file like WatchConfig,
+// prepending prefix to every environment variable name.
+func WatchConfigPrefix[T any](path, prefix string, interval time.Duration) (*ConfigWatcher[T], error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &ConfigWatcher[T]{
+		path:     path,
+		prefix:   prefix,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+	go w.watch()
+	return w, nil
+}
+
+// Config returns the current configuration.
+func (w *ConfigWatcher[T]) Config() *T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnChange registers a handler called with the key, previous and new
+// value of every changed setting. Keys are file keys, dotted for nested
+// structs ("database.max_conns").
+func (w *ConfigWatcher[T]) OnChange(fn func(key string, old, new interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// OnError registers a handler called when the file fails to reload.
+func (w *ConfigWatcher[T]) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = append(w.onError, fn)
+}
+
+// Reload loads the file, notifies the handlers of changed values and
+// returns whether the configuration changed. An unchanged file is not
+// loaded again.
+func (w *ConfigWatcher[T]) Reload() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("config file: %w", err)
+	}
+
+	w.mu.RLock()
+	unchanged := w.current != nil && bytes.Equal(data, w.data)
+	w.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	next := new(T)
+	if err := LoadConfigFromPrefix(w.path, w.prefix, next); err != nil {
+		return false, err
+	}
+
+	w.mu.Lock()
+	previous := w.current
+	w.current = next
+	w.data = data
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	if previous == nil {
+		return true, nil
+	}
+
+	changes := diffConfig(reflect.ValueOf(previous).Elem(), reflect.ValueOf(next).Elem())
+	for _, change := range changes {
+		for _, fn := range handlers {
+			fn(change.key, change.old, change.new)
+		}
+	}
+	return len(changes) > 0, nil
+}
+
+// Close stops watching the file.
+func (w *ConfigWatcher[T]) Close() error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	return nil
+}
+
+// watch reloads the file every interval.
+func (w *ConfigWatcher[T]) watch() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if _, err := w.Reload(); err != nil {
+				w.mu.RLock()
+				onError := w.onError
+				w.mu.RUnlock()
+				for _, fn := range onError {
+					fn(err)
+				}
+			}
+		}
+	}
+}
+
+// configChange is a changed configuration value.
+type configChange struct {
+	key      string
+	old, new interface{}
+}
+
+// diffConfig returns the changed values of two configs, sorted by key.
+func diffConfig(old, new reflect.Value) []configChange {
+	oldValues := make(map[string]interface{})
+	newValues := make(map[string]interface{})
+	flattenConfig(old, "", oldValues)
+	flattenConfig(new, "", newValues)
+
+	var changes []configChange
+	for key, value := range newValues {
+		if !reflect.DeepEqual(oldValues[key], value) {
+			changes = append(changes, configChange{key: key, old: oldValues[key], new: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].key < changes[j].key
+	})
+	return changes
+}
+
+// flattenConfig collects the loadable fields of a config struct by dotted
+// file key.
+func flattenConfig(v reflect.Value, prefix string, values map[string]interface{}) {
+	walkConfig(v, prefix, func(key string, _ reflect.StructField, value reflect.Value) {
+		values[key] = value.Interface()
+	})
+}
+
+// walkConfig calls fn with the dotted file key of each loadable field of a
+// config struct, in field order.
+func walkConfig(v reflect.Value, prefix string, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+		if !fieldValue.CanInterface() {
+			continue
+		}
+
+		key := prefix + fileKey(field)
+		if field.Tag.Get("env") == "" && fieldValue.Kind() == reflect.Struct {
+			walkConfig(fieldValue, key+".", fn)
+			continue
+		}
+		fn(key, field, fieldValue)
+	}
+}
@@ -0,0 +1,80 @@
+package quark
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcherReload(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "quark_test_host: a\ndatabase:\n  max_conns: 5\n")
+
+	w, err := WatchConfig[fileTestConfig](path, time.Hour)
+	if err != nil {
+		t.Fatalf("WatchConfig: unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	first := w.Config()
+	if first.Host != "a" || first.Database.MaxConns != 5 {
+		t.Fatalf("unexpected initial config: %+v", first)
+	}
+
+	var changes []string
+	w.OnChange(func(key string, old, new interface{}) {
+		changes = append(changes, key)
+		if key == "quark_test_host" && (old != "a" || new != "b") {
+			t.Errorf("unexpected change %v -> %v", old, new)
+		}
+	})
+
+	if changed, err := w.Reload(); err != nil || changed {
+		t.Errorf("Reload: expected unchanged file, got %v, %v", changed, err)
+	}
+
+	os.WriteFile(path, []byte("quark_test_host: b\ndatabase:\n  max_conns: 7\n"), 0o600)
+	if changed, err := w.Reload(); err != nil || !changed {
+		t.Fatalf("Reload: expected change, got %v, %v", changed, err)
+	}
+	if len(changes) != 2 || changes[0] != "database.max_conns" || changes[1] != "quark_test_host" {
+		t.Errorf("expected sorted changed keys, got %v", changes)
+	}
+	if first.Host != "a" || w.Config().Host != "b" {
+		t.Error("expected a new snapshot per reload")
+	}
+
+	os.WriteFile(path, []byte("quark_test_host: [broken\n"), 0o600)
+	if _, err := w.Reload(); err == nil {
+		t.Error("Reload: expected error for invalid file")
+	}
+	if w.Config().Host != "b" {
+		t.Error("expected previous config kept on error")
+	}
+}
+
+func TestConfigWatcherPolling(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"quark_test_host": "a"}`)
+
+	w, err := WatchConfig[fileTestConfig](path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchConfig: unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	var once sync.Once
+	changed := make(chan interface{})
+	w.OnChange(func(key string, old, new interface{}) {
+		once.Do(func() { changed <- new })
+	})
+
+	os.WriteFile(path, []byte(`{"quark_test_host": "b"}`), 0o600)
+	select {
+	case value := <-changed:
+		if value != "b" {
+			t.Errorf("expected new value b, got %v", value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected change notification")
+	}
+}
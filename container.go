@@ -1,26 +1,53 @@
 package quark
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 )
 
 // ServiceFactory is a function that creates a service instance.
 type ServiceFactory func(*Container) (interface{}, error)
 
+// ServiceExtender is a function that decorates a service instance.
+type ServiceExtender func(existing interface{}, c *Container) (interface{}, error)
+
+// ErrCircularDependency is returned when services depend on each other.
+var ErrCircularDependency = errors.New("circular dependency")
+
 // Container is a simple dependency injection container with generics support.
 type Container struct {
+	*registry
+	chain []string // Services being resolved by the caller, for cycle detection
+}
+
+// registry holds the state of a container, shared by the views passed to
+// factories.
+type registry struct {
 	factories map[string]ServiceFactory
 	instances map[string]interface{}
+	scoped    map[string]bool              // Factories creating one instance per scope
+	tags      map[string][]string          // Service names by tag
+	types     map[string]reflect.Type      // Declared types of services, for wiring by type
+	extenders map[string][]ServiceExtender // Decorators applied to created instances
+	unowned   map[string]bool              // Extended instances registered by the caller
+	deferred  map[string]*deferredBoot     // Pending provider boots by service name
+	created   []string                     // Names of instances created by factories, in order
+	parent    *Container                   // Parent of a scope
 	mu        sync.RWMutex
 }
 
 // NewContainer creates a new DI container.
 func NewContainer() *Container {
-	return &Container{
+	return &Container{registry: &registry{
 		factories: make(map[string]ServiceFactory),
 		instances: make(map[string]interface{}),
-	}
+	}}
 }
 
 // Register registers a service factory under the given name.
@@ -29,6 +56,43 @@ func (c *Container) Register(name string, factory ServiceFactory) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.factories[name] = factory
+	delete(c.types, name)
+	delete(c.unowned, name)
+}
+
+// RegisterScoped registers a factory creating one instance per scope, such
+// as a request-scoped transaction or the current user. Scoped services are
+// resolved from a scope (see Scope and Context.Container); resolving them
+// from the root container fails.
+func (c *Container) RegisterScoped(name string, factory ServiceFactory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.factories[name] = factory
+	delete(c.types, name)
+	delete(c.unowned, name)
+	if c.scoped == nil {
+		c.scoped = make(map[string]bool)
+	}
+	c.scoped[name] = true
+}
+
+// Scope creates a child container for a unit of work such as a request.
+// Scoped services get one instance per scope, services and instances
+// registered on the scope itself are only visible to it, and all other
+// services are resolved from the parent. Close the scope when done to
+// close its instances.
+//
+// Example:
+//
+//	// In a background job
+//	scope := app.Container().Scope()
+//	defer scope.Close(ctx)
+//	scope.RegisterInstance("tenant", tenant)
+//	reports, err := quark.Resolve[*ReportService](scope, "reports")
+func (c *Container) Scope() *Container {
+	scope := NewContainer()
+	scope.parent = &Container{registry: c.registry}
+	return scope
 }
 
 // RegisterInstance registers a pre-created instance.
@@ -36,58 +100,235 @@ func (c *Container) RegisterInstance(name string, instance interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.instances[name] = instance
+	c.forgetCreated(name)
+	delete(c.types, name)
+	delete(c.unowned, name)
+	if instance != nil {
+		c.setType(name, reflect.TypeOf(instance))
+	}
+	if len(c.extenders[name]) > 0 {
+		c.deferInstance(name)
+	}
+}
+
+// setType records the declared type of a service. The caller must hold
+// the lock.
+func (c *Container) setType(name string, t reflect.Type) {
+	if c.types == nil {
+		c.types = make(map[string]reflect.Type)
+	}
+	c.types[name] = t
+}
+
+// declare records the declared type of a service.
+func (c *Container) declare(name string, t reflect.Type) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setType(name, t)
 }
 
 // Get retrieves a service by name.
 // If the service hasn't been instantiated yet, the factory is called.
-// Instances are cached (singleton behavior).
+// Instances are cached (singleton behavior). Services depending on each
+// other fail with ErrCircularDependency and the dependency chain.
 func (c *Container) Get(name string) (interface{}, error) {
+	return c.resolve(name, c.chain)
+}
+
+// resolve retrieves a service requested while resolving the services of
+// chain.
+func (c *Container) resolve(name string, chain []string) (interface{}, error) {
+	// Boot deferred providers of the service first
+	if err := c.bootDeferred(name, chain); err != nil {
+		return nil, err
+	}
+
 	// Check if already instantiated
 	c.mu.RLock()
 	if instance, ok := c.instances[name]; ok {
 		c.mu.RUnlock()
 		return instance, nil
 	}
+	factory, ok := c.factories[name]
+	scoped := c.scoped[name]
 	c.mu.RUnlock()
 
-	// Check if factory exists and get it
-	c.mu.Lock()
-
-	// Double-check after acquiring write lock
-	if instance, ok := c.instances[name]; ok {
-		c.mu.Unlock()
-		return instance, nil
-	}
-
-	factory, ok := c.factories[name]
 	if !ok {
-		c.mu.Unlock()
-		return nil, fmt.Errorf("service not found: %s", name)
+		if c.parent == nil {
+			return nil, fmt.Errorf("service not found: %s", name)
+		}
+		// Scoped services of ancestors get an instance in this scope,
+		// everything else is shared with the parent
+		factory = c.parent.scopedFactory(name)
+		if factory == nil {
+			return c.parent.resolve(name, chain)
+		}
+	} else if scoped && c.parent == nil {
+		return nil, fmt.Errorf("service %s is scoped and must be resolved from a scope", name)
 	}
 
-	// Release lock before calling factory to prevent deadlock
-	// when factory calls Get() for dependencies
-	c.mu.Unlock()
+	for _, pending := range chain {
+		if pending == name {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrCircularDependency, strings.Join(chain, " -> "), name)
+		}
+	}
 
-	// Create instance (without holding lock)
-	instance, err := factory(c)
+	// Create instance without holding the lock, so the factory can call
+	// Get() for its dependencies. The factory gets a view of the container
+	// that records the dependency chain.
+	view := &Container{
+		registry: c.registry,
+		chain:    append(chain[:len(chain):len(chain)], name),
+	}
+	instance, err := factory(view)
 	if err != nil {
+		if errors.Is(err, ErrCircularDependency) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create service %s: %w", name, err)
 	}
+	for _, extend := range c.extendersOf(name) {
+		instance, err = extend(instance, view)
+		if err != nil {
+			if errors.Is(err, ErrCircularDependency) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to extend service %s: %w", name, err)
+		}
+	}
 
 	// Re-acquire lock to cache the instance
 	c.mu.Lock()
-	// Check if another goroutine created it while we were waiting
+	// Check if another goroutine created it while we were waiting; the
+	// duplicate is closed, so a second DB pool or worker does not leak
 	if existing, ok := c.instances[name]; ok {
+		owned := !c.unowned[name]
 		c.mu.Unlock()
+		if owned && !sameInstance(instance, existing) {
+			closeInstance(context.Background(), instance)
+		}
 		return existing, nil
 	}
 	c.instances[name] = instance
+	if !c.unowned[name] {
+		c.created = append(c.created, name)
+	}
 	c.mu.Unlock()
 
 	return instance, nil
 }
 
+// Extend adds a decorator to a service, applied when the service is
+// created, so services can be wrapped (a caching repository, an
+// instrumented HTTP client) without replacing their factory. Decorators
+// run in the order they were added and must return a value of the
+// service's type. An instance that already exists is decorated on the next
+// Get.
+//
+// Example:
+//
+//	c.Extend("users", func(existing interface{}, c *quark.Container) (interface{}, error) {
+//	    return NewCachedUserRepository(existing.(UserRepository), cache), nil
+//	})
+func (c *Container) Extend(name string, extender ServiceExtender) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.extenders == nil {
+		c.extenders = make(map[string][]ServiceExtender)
+	}
+	c.extenders[name] = append(c.extenders[name], extender)
+	if _, ok := c.instances[name]; ok {
+		c.deferInstance(name)
+	}
+}
+
+// deferInstance turns an existing instance into a factory returning it, so it is
+// decorated when next resolved. Registered instances stay owned by the
+// caller. The caller must hold the lock.
+func (c *Container) deferInstance(name string) {
+	instance := c.instances[name]
+	delete(c.instances, name)
+	c.factories[name] = func(*Container) (interface{}, error) {
+		return instance, nil
+	}
+
+	if c.forgetCreated(name) {
+		return
+	}
+	if c.unowned == nil {
+		c.unowned = make(map[string]bool)
+	}
+	c.unowned[name] = true
+}
+
+// forgetCreated removes a service from the instances closed by Close,
+// reporting whether it was there. The caller must hold the lock.
+func (c *Container) forgetCreated(name string) bool {
+	for i, created := range c.created {
+		if created == name {
+			c.created = append(c.created[:i:i], c.created[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// extendersOf returns the decorators of a service registered on c and its
+// ancestors, outermost ancestor first.
+func (c *Container) extendersOf(name string) []ServiceExtender {
+	var extenders []ServiceExtender
+	if c.parent != nil {
+		extenders = c.parent.extendersOf(name)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append(extenders, c.extenders[name]...)
+}
+
+// scopedFactory returns the factory of a scoped service registered on c or
+// its ancestors, or nil.
+func (c *Container) scopedFactory(name string) ServiceFactory {
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.mu.RLock()
+		factory, ok := cur.factories[name]
+		scoped := cur.scoped[name]
+		cur.mu.RUnlock()
+		if ok {
+			if scoped {
+				return factory
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// Warmup creates the named services, or all services except scoped ones
+// when no name is given, so construction errors surface at startup rather
+// than on the first request needing them. All services are attempted and
+// their errors joined.
+func (c *Container) Warmup(names ...string) error {
+	if len(names) == 0 {
+		c.mu.RLock()
+		for name := range c.factories {
+			if !c.scoped[name] {
+				names = append(names, name)
+			}
+		}
+		c.mu.RUnlock()
+		sort.Strings(names)
+	}
+
+	var errs []error
+	for _, name := range names {
+		if _, err := c.Get(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // MustGet retrieves a service by name or panics if not found.
 func (c *Container) MustGet(name string) interface{} {
 	instance, err := c.Get(name)
@@ -97,16 +338,18 @@ func (c *Container) MustGet(name string) interface{} {
 	return instance
 }
 
-// Has checks if a service is registered.
+// Has checks if a service is registered, on the container or, for a
+// scope, its ancestors.
 func (c *Container) Has(name string) bool {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	_, instance := c.instances[name]
+	_, factory := c.factories[name]
+	c.mu.RUnlock()
 
-	if _, ok := c.instances[name]; ok {
+	if instance || factory {
 		return true
 	}
-	_, ok := c.factories[name]
-	return ok
+	return c.parent != nil && c.parent.Has(name)
 }
 
 // Reset clears all instances but keeps factories.
@@ -115,6 +358,7 @@ func (c *Container) Reset() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.instances = make(map[string]interface{})
+	c.created = nil
 }
 
 // Clear removes all factories and instances.
@@ -123,6 +367,133 @@ func (c *Container) Clear() {
 	defer c.mu.Unlock()
 	c.factories = make(map[string]ServiceFactory)
 	c.instances = make(map[string]interface{})
+	c.scoped = nil
+	c.tags = nil
+	c.types = nil
+	c.extenders = nil
+	c.unowned = nil
+	c.deferred = nil
+	c.created = nil
+}
+
+// Tag adds services to a tag, so subsystems can collect the services of a
+// kind registered by different modules. Services are returned by
+// GetTagged in the order they were tagged.
+//
+// Example:
+//
+//	c.Tag("health_checker", "db", "cache")
+//	checkers, err := quark.ResolveTagged[HealthChecker](c, "health_checker")
+func (c *Container) Tag(tag string, names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tags == nil {
+		c.tags = make(map[string][]string)
+	}
+	for _, name := range names {
+		if !contains(c.tags[tag], name) {
+			c.tags[tag] = append(c.tags[tag], name)
+		}
+	}
+}
+
+// Tagged returns the names of the services with a tag, including those
+// tagged on the ancestors of a scope.
+func (c *Container) Tagged(tag string) []string {
+	var names []string
+	if c.parent != nil {
+		names = c.parent.Tagged(tag)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, name := range c.tags[tag] {
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetTagged retrieves the services with a tag.
+func (c *Container) GetTagged(tag string) ([]interface{}, error) {
+	names := c.Tagged(tag)
+	instances := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		instance, err := c.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// contains reports whether names contains name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Shutdowner is implemented by services needing a context to shut down,
+// such as worker pools draining their queue. Container.Close prefers it
+// over io.Closer.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Close shuts down the instances created by the container's factories
+// that implement Shutdowner or io.Closer, in reverse creation order so
+// services are closed before their dependencies, and forgets them.
+// Registered instances are left to their owner. Closing a scope does not
+// affect its parent.
+//
+// The app closes its container on shutdown, after the server has stopped,
+// so DB pools, caches and workers created by factories need no OnShutdown
+// callback.
+func (c *Container) Close(ctx context.Context) error {
+	c.mu.Lock()
+	created := c.created
+	instances := make([]interface{}, len(created))
+	for i, name := range created {
+		instances[i] = c.instances[name]
+		delete(c.instances, name)
+	}
+	c.created = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(instances) - 1; i >= 0; i-- {
+		if err := closeInstance(ctx, instances[i]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close service %s: %w", created[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeInstance shuts down an instance implementing Shutdowner or
+// io.Closer.
+func closeInstance(ctx context.Context, instance interface{}) error {
+	switch instance := instance.(type) {
+	case Shutdowner:
+		return instance.Shutdown(ctx)
+	case io.Closer:
+		return instance.Close()
+	}
+	return nil
+}
+
+// sameInstance reports whether two created values are the same instance,
+// for factories returning a shared value.
+func sameInstance(a, b interface{}) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
 }
 
 // Provide registers a typed service factory.
@@ -131,11 +502,34 @@ func Provide[T any](c *Container, name string, factory func(*Container) (T, erro
 	c.Register(name, func(cont *Container) (interface{}, error) {
 		return factory(cont)
 	})
+	c.declare(name, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// ProvideScoped registers a typed scoped service factory.
+// This is the generic version of RegisterScoped.
+func ProvideScoped[T any](c *Container, name string, factory func(*Container) (T, error)) {
+	c.RegisterScoped(name, func(cont *Container) (interface{}, error) {
+		return factory(cont)
+	})
+	c.declare(name, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Decorate adds a typed decorator to a service.
+// This is the generic version of Extend.
+func Decorate[T any](c *Container, name string, decorator func(T, *Container) (T, error)) {
+	c.Extend(name, func(existing interface{}, cont *Container) (interface{}, error) {
+		typed, ok := existing.(T)
+		if !ok {
+			return nil, fmt.Errorf("service %s is not of expected type", name)
+		}
+		return decorator(typed, cont)
+	})
 }
 
 // ProvideValue registers a pre-created typed instance.
 func ProvideValue[T any](c *Container, name string, value T) {
 	c.RegisterInstance(name, value)
+	c.declare(name, reflect.TypeOf((*T)(nil)).Elem())
 }
 
 // Resolve retrieves a typed service from the container.
@@ -163,6 +557,51 @@ func MustResolve[T any](c *Container, name string) T {
 	return result
 }
 
+// ProvideType registers a typed service factory keyed by its type rather
+// than a name, for the common one-implementation-per-type case. The
+// service is registered under TypeName of T, like services added by Wire.
+//
+// Example:
+//
+//	quark.ProvideType(c, func(c *quark.Container) (UserRepository, error) {
+//	    return NewSQLUserRepository(db), nil
+//	})
+//	repo, err := quark.ResolveType[UserRepository](c)
+func ProvideType[T any](c *Container, factory func(*Container) (T, error)) {
+	Provide(c, typeName[T](), factory)
+}
+
+// ResolveType retrieves a typed service registered with ProvideType or
+// Wire.
+func ResolveType[T any](c *Container) (T, error) {
+	return Resolve[T](c, typeName[T]())
+}
+
+// MustResolveType retrieves a typed service registered with ProvideType or
+// Wire or panics.
+func MustResolveType[T any](c *Container) T {
+	return MustResolve[T](c, typeName[T]())
+}
+
+// typeName returns the service name of type T.
+func typeName[T any]() string {
+	return TypeName(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// ResolveTagged retrieves the typed services with a tag.
+func ResolveTagged[T any](c *Container, tag string) ([]T, error) {
+	names := c.Tagged(tag)
+	typed := make([]T, 0, len(names))
+	for _, name := range names {
+		instance, err := Resolve[T](c, name)
+		if err != nil {
+			return nil, err
+		}
+		typed = append(typed, instance)
+	}
+	return typed, nil
+}
+
 // ServiceProvider is an interface for service providers.
 // Service providers encapsulate service registration logic.
 type ServiceProvider interface {
@@ -173,8 +612,43 @@ type ServiceProvider interface {
 	Boot(*Container) error
 }
 
-// RegisterProviders registers multiple service providers.
+// PrioritizedProvider is implemented by service providers that must be
+// registered and booted before or after others, e.g. a metrics provider
+// depending on the config and logger providers. Providers with a higher
+// priority go first; others have priority 0 and keep their order.
+type PrioritizedProvider interface {
+	ServiceProvider
+	Priority() int
+}
+
+// DeferredProvider is implemented by service providers whose boot is
+// deferred until one of the listed services is first resolved, for
+// expensive setup that many requests never need. Such providers are still
+// registered up front.
+type DeferredProvider interface {
+	ServiceProvider
+	DeferredUntil() []string
+}
+
+// deferredBoot is the pending boot of a deferred provider.
+type deferredBoot struct {
+	provider ServiceProvider
+	services []string
+	marker   string // Dependency chain entry of the boot
+	started  bool
+	done     chan struct{}
+	err      error
+}
+
+// RegisterProviders registers multiple service providers in priority
+// order (see PrioritizedProvider), then boots them in the same order,
+// except deferred providers (see DeferredProvider).
 func (c *Container) RegisterProviders(providers ...ServiceProvider) error {
+	providers = append([]ServiceProvider(nil), providers...)
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providerPriority(providers[i]) > providerPriority(providers[j])
+	})
+
 	// First, register all providers
 	for _, p := range providers {
 		if err := p.Register(c); err != nil {
@@ -184,6 +658,10 @@ func (c *Container) RegisterProviders(providers ...ServiceProvider) error {
 
 	// Then, boot all providers
 	for _, p := range providers {
+		if d, ok := p.(DeferredProvider); ok && len(d.DeferredUntil()) > 0 {
+			c.deferBoot(p, d.DeferredUntil())
+			continue
+		}
 		if err := p.Boot(c); err != nil {
 			return fmt.Errorf("provider boot failed: %w", err)
 		}
@@ -192,6 +670,79 @@ func (c *Container) RegisterProviders(providers ...ServiceProvider) error {
 	return nil
 }
 
+// providerPriority returns the priority of a provider.
+func providerPriority(p ServiceProvider) int {
+	if pp, ok := p.(PrioritizedProvider); ok {
+		return pp.Priority()
+	}
+	return 0
+}
+
+// deferBoot defers the boot of a provider until one of services is
+// resolved.
+func (c *Container) deferBoot(p ServiceProvider, services []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deferred == nil {
+		c.deferred = make(map[string]*deferredBoot)
+	}
+	boot := &deferredBoot{
+		provider: p,
+		services: services,
+		marker:   fmt.Sprintf("provider %T", p),
+		done:     make(chan struct{}),
+	}
+	for _, name := range services {
+		c.deferred[name] = boot
+	}
+}
+
+// bootDeferred boots the deferred provider of a service, on c or its
+// ancestors, and waits for it. Resolutions made by the boot itself do not
+// wait. A failed boot fails every resolution of its services.
+func (c *Container) bootDeferred(name string, chain []string) error {
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.mu.RLock()
+		boot := cur.deferred[name]
+		cur.mu.RUnlock()
+		if boot == nil {
+			continue
+		}
+
+		cur.mu.Lock()
+		if boot.started {
+			cur.mu.Unlock()
+			if contains(chain, boot.marker) {
+				return nil
+			}
+			<-boot.done
+			return boot.err
+		}
+
+		boot.started = true
+		cur.mu.Unlock()
+
+		view := &Container{
+			registry: cur.registry,
+			chain:    append(chain[:len(chain):len(chain)], boot.marker),
+		}
+		if err := boot.provider.Boot(view); err != nil {
+			boot.err = fmt.Errorf("provider boot failed: %w", err)
+		} else {
+			cur.mu.Lock()
+			for _, service := range boot.services {
+				if cur.deferred[service] == boot {
+					delete(cur.deferred, service)
+				}
+			}
+			cur.mu.Unlock()
+		}
+		close(boot.done)
+		return boot.err
+	}
+	return nil
+}
+
 // BaseProvider provides a default implementation of ServiceProvider.
 type BaseProvider struct{}
 
@@ -212,17 +763,19 @@ func (c *Container) Alias(alias, target string) {
 	})
 }
 
-// Keys returns all registered service names.
+// Keys returns all registered service names, including those of the
+// ancestors of a scope.
 func (c *Container) Keys() []string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
 	seen := make(map[string]bool)
-	for name := range c.factories {
-		seen[name] = true
-	}
-	for name := range c.instances {
-		seen[name] = true
+	for cur := c; cur != nil; cur = cur.parent {
+		cur.mu.RLock()
+		for name := range cur.factories {
+			seen[name] = true
+		}
+		for name := range cur.instances {
+			seen[name] = true
+		}
+		cur.mu.RUnlock()
 	}
 
 	keys := make([]string, 0, len(seen))
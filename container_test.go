@@ -1,7 +1,12 @@
 package quark
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -219,6 +224,232 @@ func TestContainerDependencies(t *testing.T) {
 	}
 }
 
+func TestContainerCircularDependency(t *testing.T) {
+	c := NewContainer()
+
+	c.Register("a", func(c *Container) (interface{}, error) {
+		return c.Get("b")
+	})
+	c.Register("b", func(c *Container) (interface{}, error) {
+		return c.Get("c")
+	})
+	c.Register("c", func(c *Container) (interface{}, error) {
+		return c.Get("a")
+	})
+	c.Register("self", func(c *Container) (interface{}, error) {
+		return c.Get("self")
+	})
+
+	_, err := c.Get("a")
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("Get: expected ErrCircularDependency, got %v", err)
+	}
+	if err.Error() != "circular dependency: a -> b -> c -> a" {
+		t.Errorf("Get: unexpected error message %q", err.Error())
+	}
+
+	if _, err := c.Get("self"); !errors.Is(err, ErrCircularDependency) {
+		t.Errorf("Get: expected ErrCircularDependency for self dependency, got %v", err)
+	}
+}
+
+func TestContainerSharedDependency(t *testing.T) {
+	c := NewContainer()
+
+	c.RegisterInstance("config", "config")
+	c.Register("repo", func(c *Container) (interface{}, error) {
+		return c.Get("config")
+	})
+	c.Register("cache", func(c *Container) (interface{}, error) {
+		return c.Get("config")
+	})
+	c.Register("service", func(c *Container) (interface{}, error) {
+		if _, err := c.Get("repo"); err != nil {
+			return nil, err
+		}
+		return c.Get("cache")
+	})
+
+	if _, err := c.Get("service"); err != nil {
+		t.Errorf("Get: unexpected error for shared dependency: %v", err)
+	}
+}
+
+func TestContainerTags(t *testing.T) {
+	c := NewContainer()
+
+	c.RegisterInstance("db", "db")
+	c.Register("cache", func(c *Container) (interface{}, error) {
+		return "cache", nil
+	})
+	c.RegisterScoped("session", func(c *Container) (interface{}, error) {
+		return "session", nil
+	})
+	c.Tag("checker", "db", "cache")
+	c.Tag("checker", "db")
+
+	instances, err := c.GetTagged("checker")
+	if err != nil {
+		t.Fatalf("GetTagged: unexpected error: %v", err)
+	}
+	if len(instances) != 2 || instances[0] != "db" || instances[1] != "cache" {
+		t.Errorf("GetTagged: expected [db cache], got %v", instances)
+	}
+
+	scope := c.Scope()
+	scope.Tag("checker", "session")
+	names, err := ResolveTagged[string](scope, "checker")
+	if err != nil {
+		t.Fatalf("ResolveTagged: unexpected error: %v", err)
+	}
+	if len(names) != 3 || names[2] != "session" {
+		t.Errorf("ResolveTagged: expected [db cache session], got %v", names)
+	}
+
+	if _, err := ResolveTagged[int](c, "checker"); err == nil {
+		t.Error("ResolveTagged: expected error for type mismatch")
+	}
+
+	c.Tag("missing", "unknown")
+	if _, err := c.GetTagged("missing"); err == nil {
+		t.Error("GetTagged: expected error for unknown service")
+	}
+
+	if empty, err := c.GetTagged("none"); err != nil || len(empty) != 0 {
+		t.Errorf("GetTagged: expected no services, got %v, %v", empty, err)
+	}
+}
+
+func TestContainerExtend(t *testing.T) {
+	c := NewContainer()
+
+	c.RegisterInstance("suffix", "!")
+	c.Register("greeting", func(c *Container) (interface{}, error) {
+		return "hello", nil
+	})
+	c.Extend("greeting", func(existing interface{}, c *Container) (interface{}, error) {
+		return existing.(string) + " world", nil
+	})
+	Decorate(c, "greeting", func(existing string, c *Container) (string, error) {
+		suffix := MustResolve[string](c, "suffix")
+		return existing + suffix, nil
+	})
+
+	result, err := c.Get("greeting")
+	if err != nil {
+		t.Fatalf("Get: unexpected error: %v", err)
+	}
+	if result != "hello world!" {
+		t.Errorf("Get: expected decorators applied in order, got %v", result)
+	}
+
+	// Replacing the factory keeps the decorators
+	c.Register("greeting", func(c *Container) (interface{}, error) {
+		return "bye", nil
+	})
+	c.Reset()
+	c.RegisterInstance("suffix", "!")
+	if result, _ := c.Get("greeting"); result != "bye world!" {
+		t.Errorf("Get: expected decorated replacement, got %v", result)
+	}
+
+	c.Extend("suffix", func(existing interface{}, c *Container) (interface{}, error) {
+		return nil, errors.New("decorator failed")
+	})
+	if _, err := c.Get("suffix"); err == nil || err.Error() != "failed to extend service suffix: decorator failed" {
+		t.Errorf("Get: expected decorator error, got %v", err)
+	}
+}
+
+func TestContainerExtendInstance(t *testing.T) {
+	c := NewContainer()
+
+	var closed []string
+	c.RegisterInstance("registered", &closeRecorder{name: "registered", closed: &closed})
+	c.Register("created", func(c *Container) (interface{}, error) {
+		return &closeRecorder{name: "created", closed: &closed}, nil
+	})
+	c.Get("created")
+
+	decorated := 0
+	decorate := func(existing interface{}, c *Container) (interface{}, error) {
+		decorated++
+		return existing, nil
+	}
+	c.Extend("registered", decorate)
+	c.Extend("created", decorate)
+	c.Get("registered")
+	c.Get("created")
+	c.Get("created")
+	if decorated != 2 {
+		t.Errorf("expected existing instances decorated once, got %d", decorated)
+	}
+
+	c.Close(context.Background())
+	if len(closed) != 1 || closed[0] != "created" {
+		t.Errorf("Close: expected only created instance closed, got %v", closed)
+	}
+}
+
+func TestContainerWarmup(t *testing.T) {
+	c := NewContainer()
+
+	var created []string
+	for _, name := range []string{"db", "cache"} {
+		name := name
+		c.Register(name, func(c *Container) (interface{}, error) {
+			created = append(created, name)
+			return name, nil
+		})
+	}
+	c.RegisterScoped("request", func(c *Container) (interface{}, error) {
+		created = append(created, "request")
+		return "request", nil
+	})
+
+	if err := c.Warmup("db"); err != nil {
+		t.Fatalf("Warmup: unexpected error: %v", err)
+	}
+	if len(created) != 1 || created[0] != "db" {
+		t.Errorf("Warmup: expected [db], got %v", created)
+	}
+
+	if err := c.Warmup(); err != nil {
+		t.Fatalf("Warmup: unexpected error: %v", err)
+	}
+	if len(created) != 2 || created[1] != "cache" {
+		t.Errorf("Warmup: expected all singletons created once, got %v", created)
+	}
+
+	c.Register("failing", func(c *Container) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err := c.Warmup("failing", "missing"); err == nil ||
+		err.Error() != "failed to create service failing: connection refused\nservice not found: missing" {
+		t.Errorf("Warmup: expected joined errors, got %v", err)
+	}
+}
+
+func TestWithEagerServices(t *testing.T) {
+	app := New(WithEagerServices("db"))
+
+	created := false
+	app.Container().Register("db", func(c *Container) (interface{}, error) {
+		created = true
+		return nil, errors.New("unreachable")
+	})
+
+	var err error
+	for _, fn := range app.onStart {
+		if err = fn(app); err != nil {
+			break
+		}
+	}
+	if !created || err == nil {
+		t.Errorf("expected eager service created at start with its error, got %v", err)
+	}
+}
+
 // Generic helpers tests
 
 type TestService struct {
@@ -367,3 +598,364 @@ func TestContainerConcurrency(t *testing.T) {
 		<-done
 	}
 }
+
+// Scope tests
+
+type closeRecorder struct {
+	name   string
+	closed *[]string
+}
+
+func (r *closeRecorder) Close() error {
+	*r.closed = append(*r.closed, r.name)
+	return nil
+}
+
+func TestContainerScope(t *testing.T) {
+	c := NewContainer()
+
+	singletons := 0
+	c.Register("config", func(c *Container) (interface{}, error) {
+		singletons++
+		return "config", nil
+	})
+	scoped := 0
+	c.RegisterScoped("request", func(c *Container) (interface{}, error) {
+		scoped++
+		user, err := c.Get("user")
+		if err != nil {
+			return nil, err
+		}
+		return "request for " + user.(string), nil
+	})
+
+	if _, err := c.Get("request"); err == nil {
+		t.Error("Get: expected error for scoped service on root container")
+	}
+
+	scope1 := c.Scope()
+	scope1.RegisterInstance("user", "alice")
+	scope2 := c.Scope()
+	scope2.RegisterInstance("user", "bob")
+
+	r1, err := scope1.Get("request")
+	if err != nil {
+		t.Fatalf("Get: unexpected error: %v", err)
+	}
+	scope1.Get("request")
+	r2, _ := scope2.Get("request")
+	if r1 != "request for alice" || r2 != "request for bob" {
+		t.Errorf("Get: unexpected scoped instances %v, %v", r1, r2)
+	}
+	if scoped != 2 {
+		t.Errorf("expected one scoped instance per scope, created %d", scoped)
+	}
+
+	scope1.Get("config")
+	scope2.Get("config")
+	if singletons != 1 {
+		t.Errorf("expected singleton shared by scopes, created %d", singletons)
+	}
+	if !scope1.Has("config") || c.Has("user") {
+		t.Error("Has: unexpected visibility of services")
+	}
+}
+
+func TestContainerScopeClose(t *testing.T) {
+	c := NewContainer()
+
+	var closed []string
+	c.Register("singleton", func(c *Container) (interface{}, error) {
+		return &closeRecorder{name: "singleton", closed: &closed}, nil
+	})
+	c.RegisterScoped("a", func(c *Container) (interface{}, error) {
+		return &closeRecorder{name: "a", closed: &closed}, nil
+	})
+	c.RegisterScoped("b", func(c *Container) (interface{}, error) {
+		c.Get("a")
+		return &closeRecorder{name: "b", closed: &closed}, nil
+	})
+
+	scope := c.Scope()
+	scope.RegisterInstance("instance", &closeRecorder{name: "instance", closed: &closed})
+	scope.Get("b")
+	scope.Get("singleton")
+
+	if err := scope.Close(context.Background()); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	if len(closed) != 2 || closed[0] != "b" || closed[1] != "a" {
+		t.Errorf("Close: expected [b a], got %v", closed)
+	}
+}
+
+func TestContextContainer(t *testing.T) {
+	app := New()
+
+	var closed []string
+	created := 0
+	ProvideScoped(app.Container(), "service", func(c *Container) (*closeRecorder, error) {
+		created++
+		return &closeRecorder{name: "service", closed: &closed}, nil
+	})
+
+	app.GET("/", func(c *Context) error {
+		s1 := MustResolve[*closeRecorder](c.Container(), "service")
+		s2 := MustResolve[*closeRecorder](c.Container(), "service")
+		if s1 != s2 {
+			t.Error("expected one instance per request")
+		}
+		return c.NoContent()
+	})
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	if created != 2 {
+		t.Errorf("expected 2 instances, got %d", created)
+	}
+	if len(closed) != 2 {
+		t.Errorf("expected instances closed after each request, got %v", closed)
+	}
+}
+
+type shutdownRecorder struct {
+	closeRecorder
+	ctx context.Context
+}
+
+func (r *shutdownRecorder) Shutdown(ctx context.Context) error {
+	r.ctx = ctx
+	*r.closed = append(*r.closed, r.name+" shutdown")
+	return nil
+}
+
+func TestContainerCloseShutdowner(t *testing.T) {
+	c := NewContainer()
+
+	var closed []string
+	worker := &shutdownRecorder{closeRecorder: closeRecorder{name: "worker", closed: &closed}}
+	c.Register("db", func(c *Container) (interface{}, error) {
+		return &closeRecorder{name: "db", closed: &closed}, nil
+	})
+	c.Register("failing", func(c *Container) (interface{}, error) {
+		return failingCloser{}, nil
+	})
+	c.Register("worker", func(c *Container) (interface{}, error) {
+		c.Get("db")
+		c.Get("failing")
+		return worker, nil
+	})
+	c.Get("worker")
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, true)
+	err := c.Close(ctx)
+	if err == nil || err.Error() != "failed to close service failing: close failed" {
+		t.Errorf("Close: unexpected error %v", err)
+	}
+	if len(closed) != 2 || closed[0] != "worker shutdown" || closed[1] != "db" {
+		t.Errorf("Close: expected [worker shutdown db], got %v", closed)
+	}
+	if worker.ctx != ctx {
+		t.Error("Close: expected context passed to Shutdown")
+	}
+	if !c.Has("worker") {
+		t.Error("Close: expected factories kept")
+	}
+}
+
+// countingCloser counts its Close calls.
+type countingCloser struct {
+	closes atomic.Int32
+}
+
+func (c *countingCloser) Close() error {
+	c.closes.Add(1)
+	return nil
+}
+
+func TestContainerCloseConcurrentDuplicate(t *testing.T) {
+	c := NewContainer()
+
+	// Both goroutines enter the factory before either caches its instance
+	var entered sync.WaitGroup
+	entered.Add(2)
+	var mu sync.Mutex
+	var pools []*countingCloser
+	c.Register("db", func(c *Container) (interface{}, error) {
+		pool := &countingCloser{}
+		mu.Lock()
+		pools = append(pools, pool)
+		mu.Unlock()
+		entered.Done()
+		entered.Wait()
+		return pool, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.Get("db")
+		}()
+	}
+	wg.Wait()
+
+	if len(pools) != 2 {
+		t.Fatalf("expected the factory to run twice, got %d", len(pools))
+	}
+	if results[0] != results[1] {
+		t.Error("expected both callers to get the cached instance")
+	}
+	kept := results[0].(*countingCloser)
+	for _, pool := range pools {
+		want := int32(1)
+		if pool == kept {
+			want = 0
+		}
+		if got := pool.closes.Load(); got != want {
+			t.Errorf("expected %d closes before Close, got %d", want, got)
+		}
+	}
+
+	c.Close(context.Background())
+	for _, pool := range pools {
+		if got := pool.closes.Load(); got != 1 {
+			t.Errorf("expected each instance closed once, got %d", got)
+		}
+	}
+}
+
+func TestContainerCloseSkipsReplacedInstance(t *testing.T) {
+	c := NewContainer()
+	created := &countingCloser{}
+	c.Register("db", func(c *Container) (interface{}, error) {
+		return created, nil
+	})
+	c.Get("db")
+
+	owned := &countingCloser{}
+	c.RegisterInstance("db", owned)
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close: unexpected error %v", err)
+	}
+	if owned.closes.Load() != 0 {
+		t.Error("expected the registered instance left to its owner")
+	}
+}
+
+type failingCloser struct{}
+
+func (failingCloser) Close() error {
+	return errors.New("close failed")
+}
+
+func TestAppShutdownClosesContainer(t *testing.T) {
+	app := New()
+
+	var closed []string
+	Provide(app.Container(), "db", func(c *Container) (*closeRecorder, error) {
+		return &closeRecorder{name: "db", closed: &closed}, nil
+	})
+	MustResolve[*closeRecorder](app.Container(), "db")
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: unexpected error: %v", err)
+	}
+	if len(closed) != 1 {
+		t.Errorf("Shutdown: expected container closed, got %v", closed)
+	}
+}
+
+type orderedProvider struct {
+	BaseProvider
+	name     string
+	priority int
+	deferred []string
+	bootErr  error
+	log      *[]string
+}
+
+func (p *orderedProvider) Register(c *Container) error {
+	*p.log = append(*p.log, "register "+p.name)
+	c.RegisterInstance(p.name, p.name)
+	return nil
+}
+
+func (p *orderedProvider) Boot(c *Container) error {
+	*p.log = append(*p.log, "boot "+p.name)
+	// Resolving its own services during boot must not wait for the boot
+	c.Get(p.name)
+	return p.bootErr
+}
+
+func (p *orderedProvider) Priority() int {
+	return p.priority
+}
+
+func (p *orderedProvider) DeferredUntil() []string {
+	return p.deferred
+}
+
+func TestServiceProviderPriority(t *testing.T) {
+	c := NewContainer()
+
+	var log []string
+	err := c.RegisterProviders(
+		&orderedProvider{name: "metrics", log: &log},
+		&orderedProvider{name: "logger", priority: 50, log: &log},
+		&orderedProvider{name: "config", priority: 100, log: &log},
+		&orderedProvider{name: "routes", priority: -10, log: &log},
+	)
+	if err != nil {
+		t.Fatalf("RegisterProviders: unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"register config", "register logger", "register metrics", "register routes",
+		"boot config", "boot logger", "boot metrics", "boot routes",
+	}
+	if len(log) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, log)
+	}
+	for i := range expected {
+		if log[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, log)
+		}
+	}
+}
+
+func TestServiceProviderDeferred(t *testing.T) {
+	c := NewContainer()
+
+	var log []string
+	err := c.RegisterProviders(&orderedProvider{name: "search", deferred: []string{"search"}, log: &log})
+	if err != nil {
+		t.Fatalf("RegisterProviders: unexpected error: %v", err)
+	}
+	if len(log) != 1 || log[0] != "register search" {
+		t.Fatalf("expected boot deferred, got %v", log)
+	}
+
+	if _, err := c.Scope().Get("search"); err != nil {
+		t.Fatalf("Get: unexpected error: %v", err)
+	}
+	c.Get("search")
+	if len(log) != 2 || log[1] != "boot search" {
+		t.Errorf("expected one boot on first use, got %v", log)
+	}
+
+	failing := NewContainer()
+	bootErr := errors.New("index unavailable")
+	failing.RegisterProviders(&orderedProvider{name: "search", deferred: []string{"search"}, bootErr: bootErr, log: &log})
+	for i := 0; i < 2; i++ {
+		if _, err := failing.Get("search"); !errors.Is(err, bootErr) {
+			t.Errorf("Get: expected boot error, got %v", err)
+		}
+	}
+}
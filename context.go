@@ -2,11 +2,15 @@ package quark
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Context wraps the HTTP request and response with helper methods.
@@ -14,9 +18,11 @@ type Context struct {
 	Request  *http.Request
 	Writer   http.ResponseWriter
 	params   map[string]string
+	route    string // pattern of the matched route
 	store    map[string]interface{}
 	app      *App
-	response bool // tracks if response has been written
+	scope    *Container // request-scoped container, created on first use
+	response bool       // tracks if response has been written
 }
 
 // newContext creates a new Context for the given request/response.
@@ -35,7 +41,9 @@ func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	c.Request = r
 	c.Writer = w
 	c.params = make(map[string]string)
+	c.route = ""
 	c.store = make(map[string]interface{})
+	c.scope = nil
 	c.response = false
 }
 
@@ -44,6 +52,35 @@ func (c *Context) App() *App {
 	return c.app
 }
 
+// Container returns the request's scope of the app container, created on
+// first use and closed when the request ends. Scoped services resolved
+// from it get one instance per request.
+//
+// Example:
+//
+//	// Middleware registers the authenticated user for the request
+//	c.Container().RegisterInstance("user", user)
+//
+//	// A scoped service depending on it
+//	quark.ProvideScoped(app.Container(), "orders", func(c *quark.Container) (*OrderService, error) {
+//	    user, err := quark.Resolve[*User](c, "user")
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return NewOrderService(db, user), nil
+//	})
+//
+//	func listOrders(c *quark.Context) error {
+//	    orders := quark.MustResolve[*OrderService](c.Container(), "orders")
+//	    ...
+//	}
+func (c *Context) Container() *Container {
+	if c.scope == nil {
+		c.scope = c.app.container.Scope()
+	}
+	return c.scope
+}
+
 // Context returns the request's context.Context.
 func (c *Context) Context() context.Context {
 	return c.Request.Context()
@@ -60,6 +97,26 @@ func (c *Context) SetParams(params map[string]string) {
 	c.params = params
 }
 
+// Route returns the pattern of the matched route, such as
+// "/users/{id}", or an empty string when no route matched. Global
+// middleware can read it after calling the next handler, to group requests
+// by route rather than by raw path.
+func (c *Context) Route() string {
+	return c.route
+}
+
+// TraceID returns the W3C trace ID of the request, set by tracing
+// middleware such as middleware.Trace, or an empty string.
+func (c *Context) TraceID() string {
+	return c.GetString("trace_id")
+}
+
+// SpanID returns the ID of the request's span, set by tracing middleware
+// such as middleware.Trace, or an empty string.
+func (c *Context) SpanID() string {
+	return c.GetString("span_id")
+}
+
 // Param returns a path parameter by name.
 func (c *Context) Param(name string) string {
 	return c.params[name]
@@ -83,6 +140,20 @@ func (c *Context) ParamIntDefault(name string, def int64) int64 {
 	return val
 }
 
+// ParamUUID returns a path parameter holding a UUID in the canonical
+// 8-4-4-4-12 form, lower-cased, or a 400 error when it is missing or
+// malformed.
+func (c *Context) ParamUUID(name string) (string, error) {
+	val := c.params[name]
+	if val == "" {
+		return "", ErrBadRequest("missing parameter: " + name)
+	}
+	if !uuidPattern.MatchString(val) {
+		return "", ErrBadRequest("invalid UUID parameter: " + name)
+	}
+	return strings.ToLower(val), nil
+}
+
 // Query returns a query parameter by name.
 func (c *Context) Query(name string) string {
 	return c.Request.URL.Query().Get(name)
@@ -123,6 +194,54 @@ func (c *Context) QueryInt64(name string, def int64) int64 {
 	return i
 }
 
+// QueryFloat returns a query parameter as float64.
+func (c *Context) QueryFloat(name string, def float64) float64 {
+	val := c.Query(name)
+	if val == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// QueryDuration returns a query parameter parsed by time.ParseDuration,
+// such as "90s" or "1h30m".
+func (c *Context) QueryDuration(name string, def time.Duration) time.Duration {
+	val := c.Query(name)
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// QueryTime returns a query parameter parsed with layout, RFC 3339 when
+// layout is empty.
+//
+// Example:
+//
+//	since := c.QueryTime("since", time.DateOnly, time.Now().AddDate(0, -1, 0))
+func (c *Context) QueryTime(name, layout string, def time.Time) time.Time {
+	val := c.Query(name)
+	if val == "" {
+		return def
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, val)
+	if err != nil {
+		return def
+	}
+	return t
+}
+
 // QueryBool returns a query parameter as bool.
 func (c *Context) QueryBool(name string) bool {
 	val := strings.ToLower(c.Query(name))
@@ -154,7 +273,10 @@ func (c *Context) ContentType() string {
 }
 
 // Bind decodes the request body into v based on Content-Type.
-// Currently supports JSON only.
+// Supports JSON (the default when no Content-Type is sent), URL-encoded
+// forms, multipart forms and the content types with a codec registered by
+// App.RegisterCodec, such as Protocol Buffers and MessagePack. Struct
+// fields missing from the body get the value of their `default` tag.
 func (c *Context) Bind(v interface{}) error {
 	if c.Request.Body == nil {
 		return ErrBadRequest("empty request body")
@@ -164,12 +286,16 @@ func (c *Context) Bind(v interface{}) error {
 	switch ct {
 	case "application/json", "":
 		return c.BindJSON(v)
-	default:
-		return ErrBadRequest("unsupported content type: " + ct)
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return c.BindForm(v)
 	}
+	if codec := c.codec(ct); codec != nil {
+		return c.bindCodec(codec, v, "invalid "+ct+" body")
+	}
+	return ErrBadRequest("unsupported content type: " + ct)
 }
 
-// BindJSON decodes JSON from the request body.
+// BindJSON decodes JSON from the request body with the App's JSON codec.
 func (c *Context) BindJSON(v interface{}) error {
 	if c.Request.Body == nil {
 		return ErrBadRequest("empty request body")
@@ -177,25 +303,287 @@ func (c *Context) BindJSON(v interface{}) error {
 
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		return WrapError(http.StatusBadRequest, "failed to read request body", err)
+		return readBodyError(err)
 	}
 
 	if len(body) == 0 {
 		return ErrBadRequest("empty request body")
 	}
 
-	if err := json.Unmarshal(body, v); err != nil {
+	if err := applyDefaults(v); err != nil {
+		return err
+	}
+	if err := c.jsonCodec().Unmarshal(body, v); err != nil {
 		return WrapError(http.StatusBadRequest, "invalid JSON", err)
 	}
 
 	return nil
 }
 
+// readBodyError reports a failure to read the request body, as 413 Request
+// Entity Too Large when a body size limit was hit.
+func readBodyError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return WrapError(http.StatusRequestEntityTooLarge, "request body too large", err)
+	}
+	return WrapError(http.StatusBadRequest, "failed to read request body", err)
+}
+
+// BindForm decodes URL-encoded or multipart form values into the struct
+// pointed to by v. Fields are matched using the `form` tag, falling back to
+// the `json` tag and then the field name. Slice fields receive every value
+// submitted for their key.
+func (c *Context) BindForm(v interface{}) error {
+	var err error
+	if c.ContentType() == "multipart/form-data" {
+		err = c.Request.ParseMultipartForm(32 << 20)
+	} else {
+		err = c.Request.ParseForm()
+	}
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return readBodyError(err)
+	}
+	if err != nil {
+		return WrapError(http.StatusBadRequest, "invalid form data", err)
+	}
+
+	return bindValues(v, c.Request.Form, "form")
+}
+
+// BindQuery decodes the URL query parameters into the struct pointed to by
+// v. Fields are matched using the `query` tag, falling back to the `form`
+// tag, the `json` tag and then the field name.
+func (c *Context) BindQuery(v interface{}) error {
+	return bindValues(v, c.Request.URL.Query(), "query")
+}
+
+// BindAndValidate binds the request into v and validates it, collapsing the
+// usual bind/validate/respond boilerplate of write handlers into one call.
+//
+// Requests without a body (GET, HEAD, DELETE or an empty body) are bound from
+// the query string; other requests are bound with Bind according to their
+// Content-Type. Validation runs with ValidateCtx using the request context.
+//
+// Binding errors are returned as 400 errors. Validation failures are returned
+// as a 422 *HTTPError whose Details hold the field-to-message map, ready to
+// be returned from the handler.
+//
+// Example:
+//
+//	app.POST("/users", func(c *quark.Context) error {
+//	    var input CreateUserInput
+//	    if err := c.BindAndValidate(&input); err != nil {
+//	        return err
+//	    }
+//	    // input is bound and valid
+//	    return c.Created(input)
+//	})
+func (c *Context) BindAndValidate(v interface{}) error {
+	var err error
+	switch {
+	case c.Request.Method == http.MethodGet,
+		c.Request.Method == http.MethodHead,
+		c.Request.Method == http.MethodDelete,
+		c.Request.Body == nil || c.Request.Body == http.NoBody:
+		err = c.BindQuery(v)
+	default:
+		err = c.Bind(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	errs, err := ValidateCtx(c.Context(), v)
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "validation could not be completed", err)
+	}
+	if errs.HasErrors() {
+		httpErr := ErrUnprocessableEntity("Validation failed")
+		httpErr.Details = errs.ToMap()
+		return httpErr
+	}
+
+	return nil
+}
+
+// bindValues assigns url.Values to the exported fields of the struct pointed
+// to by v. The field key is read from tagName, then the `form` and `json`
+// tags, and finally the field name. Nested structs without a key are bound
+// from the same values.
+func bindValues(v interface{}, values url.Values, tagName string) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return ErrInternal("bind target must be a non-nil pointer to a struct")
+	}
+	if err := defaultStruct(val.Elem()); err != nil {
+		return err
+	}
+	return bindStruct(val.Elem(), values, tagName)
+}
+
+// applyDefaults sets the zero fields of the struct pointed to by v from
+// their `default` tags, the convention of Config loading, so optional
+// request fields need no fixups after binding. Values in the request
+// override the defaults. Other targets are left untouched.
+//
+// Example:
+//
+//	type ListInput struct {
+//	    Page    int      `query:"page" default:"1"`
+//	    PerPage int      `query:"per_page" default:"25"`
+//	    Sort    []string `query:"sort" default:"name,id"`
+//	}
+func applyDefaults(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return defaultStruct(val.Elem())
+}
+
+// defaultStruct applies `default` tags to a struct value and the structs
+// nested in it. Slice defaults are comma-separated.
+func defaultStruct(val reflect.Value) error {
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		def, ok := field.Tag.Lookup("default")
+		if !ok {
+			if fieldVal.Kind() == reflect.Struct && fieldVal.Type() != timeType {
+				if err := defaultStruct(fieldVal); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !fieldVal.IsZero() {
+			continue
+		}
+
+		input := []string{def}
+		if fieldVal.Kind() == reflect.Slice {
+			input = strings.Split(def, ",")
+			for i := range input {
+				input[i] = strings.TrimSpace(input[i])
+			}
+		}
+		if err := setBindingField(fieldVal, input); err != nil {
+			return WrapError(http.StatusInternalServerError, "invalid default for field "+field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// bindStruct binds url.Values into a struct value.
+func bindStruct(val reflect.Value, values url.Values, tagName string) error {
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		key, explicit := bindingKey(field, tagName)
+		if key == "-" {
+			continue
+		}
+
+		// Nested structs share the same values unless explicitly keyed
+		if fieldVal.Kind() == reflect.Struct && fieldVal.Type() != timeType && !explicit {
+			if err := bindStruct(fieldVal, values, tagName); err != nil {
+				return err
+			}
+			continue
+		}
+
+		input, ok := values[key]
+		if !ok || len(input) == 0 {
+			continue
+		}
+
+		if err := setBindingField(fieldVal, input); err != nil {
+			return WrapError(http.StatusBadRequest, "invalid value for "+key, err)
+		}
+	}
+
+	return nil
+}
+
+// bindingKey returns the request key for a struct field and whether it was
+// set explicitly through a tag.
+func bindingKey(field reflect.StructField, tagName string) (string, bool) {
+	for _, name := range []string{tagName, "form", "json"} {
+		if tag := field.Tag.Get(name); tag != "" {
+			if key := strings.Split(tag, ",")[0]; key != "" {
+				return key, true
+			}
+		}
+	}
+	return field.Name, false
+}
+
+// setBindingField converts request values into a field, dereferencing
+// pointers and filling slices from repeated keys. Scalars use the first
+// value and are converted like environment variables (see LoadFromEnv);
+// time.Time values are parsed as RFC 3339.
+func setBindingField(field reflect.Value, input []string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setBindingField(elem.Elem(), input); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, input[0])
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(input), len(input))
+		for i, s := range input {
+			if err := setField(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setField(field, input[0])
+}
+
 // Get retrieves a value from the context store.
 func (c *Context) Get(key string) interface{} {
 	return c.store[key]
 }
 
+// GetOk retrieves a value from the context store and reports whether the
+// key is set.
+func (c *Context) GetOk(key string) (interface{}, bool) {
+	val, ok := c.store[key]
+	return val, ok
+}
+
 // Set stores a value in the context store.
 func (c *Context) Set(key string, value interface{}) {
 	c.store[key] = value
@@ -225,6 +613,35 @@ func (c *Context) GetInt64(key string) int64 {
 	return 0
 }
 
+// Get retrieves a typed value from the context store. It reports false
+// when the key is not set or holds another type, so values passed from
+// middleware to handlers need no unchecked type assertions.
+//
+// Example:
+//
+//	user, ok := quark.Get[*User](c, "user")
+//	if !ok {
+//	    return quark.ErrUnauthorized("not signed in")
+//	}
+func Get[T any](c *Context, key string) (T, bool) {
+	val, ok := c.store[key].(T)
+	return val, ok
+}
+
+// MustGet retrieves a typed value from the context store or panics, for
+// values a middleware guarantees to set.
+func MustGet[T any](c *Context, key string) T {
+	val, ok := c.store[key]
+	if !ok {
+		panic(fmt.Sprintf("quark: context key %q is not set", key))
+	}
+	typed, ok := val.(T)
+	if !ok {
+		panic(fmt.Sprintf("quark: context key %q holds %T, not %s", key, val, reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	return typed
+}
+
 // PaginationParams holds pagination parameters.
 type PaginationParams struct {
 	Page    int
@@ -261,8 +678,17 @@ func (c *Context) Pagination(defaultPerPage, maxPerPage int) PaginationParams {
 }
 
 // RealIP returns the client's real IP address.
-// Checks X-Real-IP, X-Forwarded-For, and falls back to RemoteAddr.
+// X-Real-IP and X-Forwarded-For are only honored when the request comes
+// from a trusted proxy (see Config.TrustedProxies); otherwise it returns
+// the IP of RemoteAddr. X-Forwarded-For is read from the right, skipping
+// trusted proxies, so clients cannot spoof it through the proxy chain.
 func (c *Context) RealIP() string {
+	remote := c.remoteIP()
+	nets := c.proxyNets()
+	if !isTrustedProxy(remote, nets) {
+		return remote
+	}
+
 	// X-Real-IP
 	if ip := c.Header("X-Real-IP"); ip != "" {
 		return ip
@@ -270,18 +696,35 @@ func (c *Context) RealIP() string {
 
 	// X-Forwarded-For
 	if xff := c.Header("X-Forwarded-For"); xff != "" {
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if i == 0 || !isTrustedProxy(hop, nets) {
+				return hop
+			}
 		}
-		return xff
 	}
 
-	// RemoteAddr
-	addr := c.Request.RemoteAddr
-	if idx := strings.LastIndex(addr, ":"); idx != -1 {
-		return addr[:idx]
+	return remote
+}
+
+// Scheme returns the request scheme, "http" or "https". X-Forwarded-Proto
+// is honored when the request comes from a trusted proxy, such as a load
+// balancer terminating TLS.
+func (c *Context) Scheme() string {
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	if c.fromTrustedProxy() {
+		proto := c.Header("X-Forwarded-Proto")
+		if idx := strings.Index(proto, ","); idx != -1 {
+			proto = proto[:idx]
+		}
+		if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "https" || proto == "http" {
+			return proto
+		}
 	}
-	return addr
+	return "http"
 }
 
 // Method returns the request HTTP method.
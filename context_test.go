@@ -2,10 +2,12 @@ package quark
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestContextParams(t *testing.T) {
@@ -179,6 +181,57 @@ func TestContextBind(t *testing.T) {
 	}
 }
 
+func TestContextTypedQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test?ratio=0.75&bad=x&wait=1m30s&since=2024-01-02&at=2024-01-02T03:04:05Z", nil)
+	c := &Context{Request: req}
+
+	if got := c.QueryFloat("ratio", 1); got != 0.75 {
+		t.Errorf("QueryFloat(ratio): expected 0.75, got %v", got)
+	}
+	if got := c.QueryFloat("bad", 1); got != 1 {
+		t.Errorf("QueryFloat(bad): expected default, got %v", got)
+	}
+	if got := c.QueryDuration("wait", 0); got != 90*time.Second {
+		t.Errorf("QueryDuration(wait): expected 1m30s, got %v", got)
+	}
+	if got := c.QueryDuration("bad", time.Second); got != time.Second {
+		t.Errorf("QueryDuration(bad): expected default, got %v", got)
+	}
+
+	def := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := c.QueryTime("since", time.DateOnly, def); !got.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("QueryTime(since): expected 2024-01-02, got %v", got)
+	}
+	if got := c.QueryTime("at", "", def); !got.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("QueryTime(at): expected RFC 3339 time, got %v", got)
+	}
+	if got := c.QueryTime("since", "", def); !got.Equal(def) {
+		t.Errorf("QueryTime(since): expected default for a layout mismatch, got %v", got)
+	}
+	if got := c.QueryTime("missing", "", def); !got.Equal(def) {
+		t.Errorf("QueryTime(missing): expected default, got %v", got)
+	}
+}
+
+func TestContextParamUUID(t *testing.T) {
+	c := &Context{
+		params: map[string]string{
+			"id":  "3F2504E0-4F89-11D3-9A0C-0305E82C3301",
+			"bad": "3f2504e0-4f89-11d3-9a0c",
+		},
+	}
+
+	if got, err := c.ParamUUID("id"); err != nil || got != "3f2504e0-4f89-11d3-9a0c-0305e82c3301" {
+		t.Errorf("ParamUUID(id): expected lower-cased UUID, got %q %v", got, err)
+	}
+	for _, name := range []string{"bad", "missing"} {
+		var he *HTTPError
+		if _, err := c.ParamUUID(name); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+			t.Errorf("ParamUUID(%s): expected 400, got %v", name, err)
+		}
+	}
+}
+
 func TestContextStore(t *testing.T) {
 	c := &Context{
 		store: make(map[string]interface{}),
@@ -213,6 +266,50 @@ func TestContextStore(t *testing.T) {
 	}
 }
 
+func TestContextTypedStore(t *testing.T) {
+	type user struct{ Name string }
+	c := &Context{
+		store: make(map[string]interface{}),
+	}
+	c.Set("user", &user{Name: "john"})
+	c.Set("count", 42)
+	c.Set("nothing", nil)
+
+	if u, ok := Get[*user](c, "user"); !ok || u.Name != "john" {
+		t.Errorf("Get[*user]: expected john, got %v %v", u, ok)
+	}
+	if _, ok := Get[string](c, "count"); ok {
+		t.Error("Get[string](count): expected false for an int")
+	}
+	if _, ok := Get[int](c, "missing"); ok {
+		t.Error("Get[int](missing): expected false")
+	}
+	if got := MustGet[int](c, "count"); got != 42 {
+		t.Errorf("MustGet[int](count): expected 42, got %d", got)
+	}
+
+	if val, ok := c.GetOk("nothing"); !ok || val != nil {
+		t.Errorf("GetOk(nothing): expected a set nil value, got %v %v", val, ok)
+	}
+	if _, ok := c.GetOk("missing"); ok {
+		t.Error("GetOk(missing): expected false")
+	}
+
+	for _, tt := range []struct{ key, want string }{
+		{"missing", `quark: context key "missing" is not set`},
+		{"count", `quark: context key "count" holds int, not string`},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != tt.want {
+					t.Errorf("MustGet[string](%s): expected panic %q, got %v", tt.key, tt.want, r)
+				}
+			}()
+			MustGet[string](c, tt.key)
+		}()
+	}
+}
+
 func TestContextPagination(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -318,10 +415,34 @@ func TestContextRealIP(t *testing.T) {
 		},
 		{
 			name:       "X-Forwarded-For multiple",
-			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 2.2.2.2, 3.3.3.3"},
+			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 127.0.0.2, ::1"},
 			remoteAddr: "127.0.0.1:8080",
 			expected:   "1.1.1.1",
 		},
+		{
+			name:       "X-Forwarded-For spoofed through proxy",
+			headers:    map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2, 127.0.0.3"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "2.2.2.2",
+		},
+		{
+			name:       "private network not trusted by default",
+			headers:    map[string]string{"X-Real-IP": "1.2.3.4"},
+			remoteAddr: "10.0.0.1:4000",
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "untrusted remote ignores headers",
+			headers:    map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "5.6.7.8"},
+			remoteAddr: "203.0.113.9:4000",
+			expected:   "203.0.113.9",
+		},
+		{
+			name:       "IPv6 RemoteAddr",
+			headers:    map[string]string{},
+			remoteAddr: "[2001:db8::1]:443",
+			expected:   "2001:db8::1",
+		},
 		{
 			name:       "fallback to RemoteAddr",
 			headers:    map[string]string{},
@@ -386,6 +507,33 @@ func TestContextMethodAndPath(t *testing.T) {
 	}
 }
 
+func TestContextRoute(t *testing.T) {
+	app := New()
+
+	var route string
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			route = c.Route()
+			return err
+		}
+	})
+	api := app.Group("/api")
+	api.GET("/users/{id}", func(c *Context) error {
+		return c.NoContent()
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	if route != "/api/users/{id}" {
+		t.Errorf("Route(): expected /api/users/{id}, got %q", route)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if route != "" {
+		t.Errorf("Route(): expected empty for unmatched path, got %q", route)
+	}
+}
+
 func TestContextReset(t *testing.T) {
 	req1 := httptest.NewRequest(http.MethodGet, "/first", nil)
 	rec1 := httptest.NewRecorder()
@@ -439,3 +587,224 @@ func TestContextBindJSON(t *testing.T) {
 		t.Errorf("BindJSON: expected 'test', got %s", data.Value)
 	}
 }
+
+func TestContextBindJSONTooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"value":"too long"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(w, req.Body, 8)
+
+	c := &Context{Request: req, Writer: w}
+
+	var data struct {
+		Value string `json:"value"`
+	}
+	err := c.BindJSON(&data)
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("BindJSON: expected a 413 error, got %v", err)
+	}
+}
+
+func TestContextBindForm(t *testing.T) {
+	type Input struct {
+		Name    string   `form:"name"`
+		Age     int      `json:"age"`
+		Tags    []string `form:"tag"`
+		Active  *bool    `form:"active"`
+		Ignored string   `form:"-"`
+	}
+
+	body := "name=John&age=30&tag=a&tag=b&active=true&Ignored=x"
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := &Context{Request: req, params: make(map[string]string)}
+
+	var input Input
+	if err := c.Bind(&input); err != nil {
+		t.Fatalf("Bind: unexpected error: %v", err)
+	}
+	if input.Name != "John" || input.Age != 30 {
+		t.Errorf("unexpected scalar values: %+v", input)
+	}
+	if len(input.Tags) != 2 || input.Tags[1] != "b" {
+		t.Errorf("expected tags [a b], got %v", input.Tags)
+	}
+	if input.Active == nil || !*input.Active {
+		t.Error("expected active pointer to be set")
+	}
+	if input.Ignored != "" {
+		t.Error("expected ignored field to stay empty")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("age=old"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c = &Context{Request: req, params: make(map[string]string)}
+	if err := c.Bind(&input); err == nil {
+		t.Error("expected error for invalid integer")
+	}
+}
+
+func TestContextBindQuery(t *testing.T) {
+	type Filter struct {
+		Search string    `query:"q"`
+		Page   int       `json:"page"`
+		Since  time.Time `query:"since"`
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/test?q=go&page=2&since=2024-01-02T03:04:05Z", nil)
+	c := &Context{Request: req, params: make(map[string]string)}
+
+	var f Filter
+	if err := c.BindQuery(&f); err != nil {
+		t.Fatalf("BindQuery: unexpected error: %v", err)
+	}
+	if f.Search != "go" || f.Page != 2 || f.Since.Year() != 2024 {
+		t.Errorf("unexpected values: %+v", f)
+	}
+}
+
+func TestContextBindDefaults(t *testing.T) {
+	type Paging struct {
+		PerPage int `query:"per_page" json:"per_page" default:"25"`
+	}
+	type Input struct {
+		Page    int           `query:"page" json:"page" default:"1"`
+		Sort    []string      `query:"sort" json:"sort" default:"name, id"`
+		Limit   *int          `query:"limit" json:"limit" default:"10"`
+		Timeout time.Duration `query:"timeout" json:"timeout" default:"5s"`
+		Search  string        `query:"q" json:"q"`
+		Paging
+	}
+
+	t.Run("query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/test?page=3&q=go", nil)
+		c := &Context{Request: req}
+
+		var input Input
+		if err := c.BindQuery(&input); err != nil {
+			t.Fatalf("BindQuery: unexpected error: %v", err)
+		}
+		if input.Page != 3 || input.Search != "go" {
+			t.Errorf("expected request values to override defaults, got %+v", input)
+		}
+		if len(input.Sort) != 2 || input.Sort[1] != "id" || input.Limit == nil || *input.Limit != 10 ||
+			input.Timeout != 5*time.Second || input.PerPage != 25 {
+			t.Errorf("expected defaults for missing fields, got %+v", input)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"page":0,"per_page":50}`))
+		req.Header.Set("Content-Type", "application/json")
+		c := &Context{Request: req}
+
+		var input Input
+		if err := c.Bind(&input); err != nil {
+			t.Fatalf("Bind: unexpected error: %v", err)
+		}
+		if input.Page != 0 || input.PerPage != 50 {
+			t.Errorf("expected explicit values to override defaults, got %+v", input)
+		}
+		if input.Limit == nil || *input.Limit != 10 || len(input.Sort) != 2 {
+			t.Errorf("expected defaults for missing fields, got %+v", input)
+		}
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		var input struct {
+			Page int `query:"page" default:"first"`
+		}
+		c := &Context{Request: httptest.NewRequest(http.MethodGet, "/test", nil)}
+
+		var he *HTTPError
+		if err := c.BindQuery(&input); !errors.As(err, &he) || he.Code != http.StatusInternalServerError {
+			t.Errorf("expected 500 for an invalid default, got %v", err)
+		}
+	})
+}
+
+func TestContextBindAndValidate(t *testing.T) {
+	type Input struct {
+		Name  string `json:"name" validate:"required,min:2"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	t.Run("valid JSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"John","email":"john@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		c := &Context{Request: req, params: make(map[string]string)}
+
+		var input Input
+		if err := c.BindAndValidate(&input); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"J"}`))
+		req.Header.Set("Content-Type", "application/json")
+		c := &Context{Request: req, params: make(map[string]string)}
+
+		var input Input
+		err := c.BindAndValidate(&input)
+		httpErr, ok := err.(*HTTPError)
+		if !ok {
+			t.Fatalf("expected *HTTPError, got %T", err)
+		}
+		if httpErr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", httpErr.Code)
+		}
+		details, ok := httpErr.Details.(map[string]string)
+		if !ok || details["name"] == "" || details["email"] == "" {
+			t.Errorf("expected field map in details, got %v", httpErr.Details)
+		}
+	})
+
+	t.Run("query for GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/test?name=John&email=john@example.com", nil)
+		c := &Context{Request: req, params: make(map[string]string)}
+
+		var input Input
+		if err := c.BindAndValidate(&input); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if input.Name != "John" {
+			t.Errorf("expected name from query, got %q", input.Name)
+		}
+	})
+
+	t.Run("bind error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":`))
+		req.Header.Set("Content-Type", "application/json")
+		c := &Context{Request: req, params: make(map[string]string)}
+
+		var input Input
+		err := c.BindAndValidate(&input)
+		if httpErr, ok := err.(*HTTPError); !ok || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 error, got %v", err)
+		}
+	})
+}
+
+func TestHandleErrorWithDetails(t *testing.T) {
+	app := New()
+	app.POST("/users", func(c *Context) error {
+		var input struct {
+			Name string `json:"name" validate:"required"`
+		}
+		return c.BindAndValidate(&input)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"details":{"name":"name is required"}`) {
+		t.Errorf("expected details in body, got %s", rec.Body.String())
+	}
+}
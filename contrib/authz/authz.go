@@ -0,0 +1,181 @@
+// Package authz provides policy-based authorization for the Quark framework.
+// Policies are named functions that decide whether a subject (the current
+// user) may act on a resource. They are enforced with the Require middleware
+// or checked in handlers with Can and Authorize.
+//
+// Basic usage:
+//
+//	authz.Register("post.edit", func(c *quark.Context, subject, resource interface{}) bool {
+//	    user, ok := subject.(*User)
+//	    post, isPost := resource.(*Post)
+//	    return ok && isPost && (user.IsAdmin || post.AuthorID == user.ID)
+//	})
+//
+//	// Policies without a resource work as middleware
+//	admin.Use(authz.Require("admin.access"))
+//
+//	// Resource-specific checks in handlers
+//	app.PUT("/posts/{id}", func(c *quark.Context) error {
+//	    post := loadPost(c.Param("id"))
+//	    if err := authz.Authorize(c, "post.edit", post); err != nil {
+//	        return err
+//	    }
+//	    // Update the post...
+//	})
+//
+// The subject is read from the request context, "user" by default, falling
+// back to the JWT claims stored under "claims". Use SetSubjectFunc to
+// change it.
+package authz
+
+import (
+	"sync"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Policy decides whether subject may act on resource. resource is nil for
+// policies enforced by middleware without a ResourceFunc.
+type Policy func(c *quark.Context, subject, resource interface{}) bool
+
+// SubjectFunc returns the subject of the current request, or nil for
+// anonymous requests.
+type SubjectFunc func(c *quark.Context) interface{}
+
+// ResourceFunc loads the resource a middleware-enforced policy applies to,
+// e.g. from a path parameter.
+type ResourceFunc func(c *quark.Context) (interface{}, error)
+
+// Registry holds named policies. Most applications use the package-level
+// functions, which share a default registry.
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+	subject  SubjectFunc
+}
+
+// NewRegistry creates an empty registry that reads the subject with
+// DefaultSubject.
+func NewRegistry() *Registry {
+	return &Registry{
+		policies: make(map[string]Policy),
+		subject:  DefaultSubject,
+	}
+}
+
+// DefaultSubject returns the "user" context value, or the "claims" value
+// stored by the JWT middleware when no user is set.
+func DefaultSubject(c *quark.Context) interface{} {
+	if user := c.Get("user"); user != nil {
+		return user
+	}
+	return c.Get("claims")
+}
+
+// Register adds or replaces a named policy. A nil policy removes it.
+func (r *Registry) Register(name string, policy Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if policy == nil {
+		delete(r.policies, name)
+		return
+	}
+	r.policies[name] = policy
+}
+
+// SetSubjectFunc changes how the subject is read from the request.
+func (r *Registry) SetSubjectFunc(fn SubjectFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fn == nil {
+		fn = DefaultSubject
+	}
+	r.subject = fn
+}
+
+// Can reports whether the current subject passes the named policy for
+// resource. Unknown policies and anonymous subjects are denied.
+func (r *Registry) Can(c *quark.Context, name string, resource interface{}) bool {
+	return r.Authorize(c, name, resource) == nil
+}
+
+// Authorize checks the named policy and returns a 401 error for anonymous
+// requests or a 403 error when the policy denies access.
+func (r *Registry) Authorize(c *quark.Context, name string, resource interface{}) error {
+	r.mu.RLock()
+	policy, ok := r.policies[name]
+	subjectFunc := r.subject
+	r.mu.RUnlock()
+
+	subject := subjectFunc(c)
+	if subject == nil {
+		return quark.ErrUnauthorized("authentication required")
+	}
+	if !ok || !policy(c, subject, resource) {
+		return quark.ErrForbidden("insufficient permissions")
+	}
+	return nil
+}
+
+// Require returns a middleware that enforces the named policy. The policy
+// receives the resource loaded by the optional ResourceFunc, or nil.
+func (r *Registry) Require(name string, resource ...ResourceFunc) quark.MiddlewareFunc {
+	var load ResourceFunc
+	if len(resource) > 0 {
+		load = resource[0]
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			var res interface{}
+			if load != nil {
+				var err error
+				if res, err = load(c); err != nil {
+					return err
+				}
+			}
+
+			if err := r.Authorize(c, name, res); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// defaultRegistry is used by the package-level functions.
+var defaultRegistry = NewRegistry()
+
+// Default returns the registry used by the package-level functions.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds or replaces a named policy in the default registry.
+func Register(name string, policy Policy) {
+	defaultRegistry.Register(name, policy)
+}
+
+// SetSubjectFunc changes how the default registry reads the subject.
+func SetSubjectFunc(fn SubjectFunc) {
+	defaultRegistry.SetSubjectFunc(fn)
+}
+
+// Can reports whether the current subject passes the named policy of the
+// default registry.
+func Can(c *quark.Context, name string, resource interface{}) bool {
+	return defaultRegistry.Can(c, name, resource)
+}
+
+// Authorize checks the named policy of the default registry.
+func Authorize(c *quark.Context, name string, resource interface{}) error {
+	return defaultRegistry.Authorize(c, name, resource)
+}
+
+// Require returns a middleware that enforces the named policy of the
+// default registry.
+func Require(name string, resource ...ResourceFunc) quark.MiddlewareFunc {
+	return defaultRegistry.Require(name, resource...)
+}
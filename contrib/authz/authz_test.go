@@ -0,0 +1,243 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+type testUser struct {
+	ID    string
+	Admin bool
+}
+
+type testPost struct {
+	AuthorID string
+}
+
+// newPolicyRegistry returns a registry with admin and post ownership
+// policies.
+func newPolicyRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("admin.access", func(c *quark.Context, subject, resource interface{}) bool {
+		user, ok := subject.(*testUser)
+		return ok && user.Admin
+	})
+	r.Register("post.edit", func(c *quark.Context, subject, resource interface{}) bool {
+		user, ok := subject.(*testUser)
+		post, isPost := resource.(*testPost)
+		return ok && isPost && (user.Admin || post.AuthorID == user.ID)
+	})
+	return r
+}
+
+// newAuthzApp returns an app whose requests act as the user named by the
+// X-User header, "admin" being an administrator.
+func newAuthzApp(r *Registry) *quark.App {
+	app := quark.New()
+	app.Use(func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if id := c.Header("X-User"); id != "" {
+				c.Set("user", &testUser{ID: id, Admin: id == "admin"})
+			}
+			return next(c)
+		}
+	})
+	ok := func(c *quark.Context) error { return c.String(http.StatusOK, "ok") }
+
+	app.GET("/admin", ok, r.Require("admin.access"))
+	app.GET("/missing-policy", ok, r.Require("unknown"))
+	loadPost := func(c *quark.Context) (interface{}, error) {
+		if c.Param("author") == "none" {
+			return nil, quark.ErrNotFound("post not found")
+		}
+		return &testPost{AuthorID: c.Param("author")}, nil
+	}
+	app.PUT("/posts/{author}", ok, r.Require("post.edit", loadPost))
+	app.GET("/posts/{author}/can-edit", func(c *quark.Context) error {
+		post := &testPost{AuthorID: c.Param("author")}
+		if r.Can(c, "post.edit", post) {
+			return c.String(http.StatusOK, "yes")
+		}
+		return c.String(http.StatusOK, "no")
+	})
+	return app
+}
+
+func TestRequire(t *testing.T) {
+	app := newAuthzApp(newPolicyRegistry())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		want   int
+	}{
+		{"admin allowed", http.MethodGet, "/admin", "admin", http.StatusOK},
+		{"user denied", http.MethodGet, "/admin", "alice", http.StatusForbidden},
+		{"anonymous", http.MethodGet, "/admin", "", http.StatusUnauthorized},
+		{"unknown policy denied", http.MethodGet, "/missing-policy", "admin", http.StatusForbidden},
+		{"author allowed", http.MethodPut, "/posts/alice", "alice", http.StatusOK},
+		{"other user denied", http.MethodPut, "/posts/alice", "bob", http.StatusForbidden},
+		{"admin edits any post", http.MethodPut, "/posts/alice", "admin", http.StatusOK},
+		{"resource error", http.MethodPut, "/posts/none", "alice", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCan(t *testing.T) {
+	app := newAuthzApp(newPolicyRegistry())
+
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"alice", "yes"},
+		{"bob", "no"},
+		{"", "no"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/posts/alice/can-edit", nil)
+		if tt.user != "" {
+			req.Header.Set("X-User", tt.user)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Body.String() != tt.want {
+			t.Errorf("user %q: expected %s, got %s", tt.user, tt.want, rec.Body.String())
+		}
+	}
+}
+
+func TestRegisterNilRemoves(t *testing.T) {
+	r := newPolicyRegistry()
+	r.Register("admin.access", nil)
+	app := newAuthzApp(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-User", "admin")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected a removed policy to deny, got %d", rec.Code)
+	}
+}
+
+func TestSubjectFunc(t *testing.T) {
+	r := newPolicyRegistry()
+	r.SetSubjectFunc(func(c *quark.Context) interface{} {
+		if c.Header("X-Admin-Token") == "secret" {
+			return &testUser{ID: "ops", Admin: true}
+		}
+		return nil
+	})
+	app := newAuthzApp(r)
+
+	tests := []struct {
+		header map[string]string
+		want   int
+	}{
+		{map[string]string{"X-Admin-Token": "secret"}, http.StatusOK},
+		{map[string]string{"X-User": "admin"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%v: expected status %d, got %d", tt.header, tt.want, rec.Code)
+		}
+	}
+
+	r.SetSubjectFunc(nil)
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-User", "admin")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected a nil SubjectFunc to restore DefaultSubject, got %d", rec.Code)
+	}
+}
+
+func TestDefaultSubject(t *testing.T) {
+	app := quark.New()
+	var subjects []interface{}
+	app.GET("/", func(c *quark.Context) error {
+		if claims := c.Header("X-Claims"); claims != "" {
+			c.Set("claims", claims)
+		}
+		if user := c.Header("X-User"); user != "" {
+			c.Set("user", user)
+		}
+		subjects = append(subjects, DefaultSubject(c))
+		return nil
+	})
+
+	for _, header := range []map[string]string{
+		{"X-User": "alice", "X-Claims": "jwt"},
+		{"X-Claims": "jwt"},
+		{},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		app.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(subjects) != 3 || subjects[0] != "alice" || subjects[1] != "jwt" || subjects[2] != nil {
+		t.Errorf("expected the user, then the claims, then nil, got %v", subjects)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	Register("test.default", func(c *quark.Context, subject, resource interface{}) bool {
+		return strings.HasPrefix(subject.(*testUser).ID, "a")
+	})
+	defer Register("test.default", nil)
+
+	app := quark.New()
+	app.Use(func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			c.Set("user", &testUser{ID: c.Header("X-User")})
+			return next(c)
+		}
+	})
+	app.GET("/", func(c *quark.Context) error {
+		if err := Authorize(c, "test.default", nil); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "ok")
+	}, Require("test.default"))
+
+	for user, want := range map[string]int{"alice": http.StatusOK, "bob": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: expected status %d, got %d", user, want, rec.Code)
+		}
+	}
+	if Default() != defaultRegistry {
+		t.Error("expected Default to return the package registry")
+	}
+}
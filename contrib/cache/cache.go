@@ -0,0 +1,230 @@
+// Package cache provides a unified caching interface for the Quark framework.
+// Values are encoded with a Codec (JSON by default) and kept in a pluggable
+// Store, so the in-memory store can be swapped for Redis, memcached or any
+// other backend by implementing three methods.
+//
+// Basic usage:
+//
+//	c := cache.New(cache.Config{
+//	    Store:      cache.NewMemory(cache.MemoryConfig{MaxEntries: 10000}),
+//	    DefaultTTL: 5 * time.Minute,
+//	})
+//
+//	// Typed helpers
+//	user, err := cache.GetOrSet(ctx, c, "user:42", time.Minute, func(ctx context.Context) (*User, error) {
+//	    return repo.FindUser(ctx, 42)
+//	})
+//
+//	// Untyped API
+//	c.Set(ctx, "greeting", "hello", 0) // 0 uses DefaultTTL
+//	var greeting string
+//	err := c.Get(ctx, "greeting", &greeting)
+//
+// Concurrent GetOrSet calls for the same missing key run the loader once
+// and share its result.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a key is not cached or has expired.
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store is the backend adapter interface. Implementations must be safe for
+// concurrent use and return ErrNotFound for missing or expired keys.
+type Store interface {
+	// Get returns the value stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key. A ttl of zero or less means no expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Codec encodes values for storage.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes values as JSON.
+type JSONCodec struct{}
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// Config holds cache configuration.
+type Config struct {
+	// Store is the backend (default: NewMemory with default settings).
+	Store Store
+
+	// Codec encodes values (default: JSONCodec).
+	Codec Codec
+
+	// Prefix is prepended to every key, to share a backend between
+	// applications or versions.
+	Prefix string
+
+	// DefaultTTL is used when Set or GetOrSet is given a zero ttl. Zero
+	// means entries do not expire.
+	DefaultTTL time.Duration
+}
+
+// Cache encodes values and stores them in a Store.
+type Cache struct {
+	store      Store
+	codec      Codec
+	prefix     string
+	defaultTTL time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*call // In-flight GetOrSet loaders
+}
+
+// call is an in-flight GetOrSet loader shared by concurrent callers.
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// New creates a cache.
+func New(config Config) *Cache {
+	if config.Store == nil {
+		config.Store = NewMemory(MemoryConfig{})
+	}
+	if config.Codec == nil {
+		config.Codec = JSONCodec{}
+	}
+	return &Cache{
+		store:      config.Store,
+		codec:      config.Codec,
+		prefix:     config.Prefix,
+		defaultTTL: config.DefaultTTL,
+		calls:      make(map[string]*call),
+	}
+}
+
+// Store returns the underlying backend.
+func (c *Cache) Store() Store {
+	return c.store
+}
+
+// Get decodes the value stored under key into dest. It returns ErrNotFound
+// for missing keys.
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.store.Get(ctx, c.prefix+key)
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(data, dest)
+}
+
+// Set encodes value and stores it under key. A zero ttl uses DefaultTTL.
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, c.prefix+key, data, c.ttl(ttl))
+}
+
+// Delete removes key.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.store.Delete(ctx, c.prefix+key)
+}
+
+// GetOrSet decodes the value stored under key into dest. On a miss it calls
+// load, caches the result for ttl (zero uses DefaultTTL) and decodes it into
+// dest. Loader errors are returned and not cached.
+func (c *Cache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) error {
+	data, err := c.store.Get(ctx, c.prefix+key)
+	if err == nil {
+		return c.codec.Unmarshal(data, dest)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	data, err = c.load(ctx, key, ttl, load)
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(data, dest)
+}
+
+// load runs a loader once per key across concurrent callers and stores its
+// encoded result.
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) ([]byte, error) {
+	c.mu.Lock()
+	if inflight, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-inflight.done:
+			return inflight.value, inflight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+
+	value, err := load(ctx)
+	if err != nil {
+		cl.err = err
+		return nil, err
+	}
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		cl.err = err
+		return nil, err
+	}
+	cl.value = data
+
+	// The loaded value is still usable even if it could not be cached
+	_ = c.store.Set(ctx, c.prefix+key, data, c.ttl(ttl))
+	return data, nil
+}
+
+// ttl applies the default TTL to a zero ttl.
+func (c *Cache) ttl(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return c.defaultTTL
+	}
+	return ttl
+}
+
+// Get returns the value stored under key, decoded as T.
+func Get[T any](ctx context.Context, c *Cache, key string) (T, error) {
+	var value T
+	err := c.Get(ctx, key, &value)
+	return value, err
+}
+
+// GetOrSet returns the value stored under key, decoded as T, loading and
+// caching it on a miss.
+func GetOrSet[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := c.GetOrSet(ctx, key, &value, ttl, func(ctx context.Context) (interface{}, error) {
+		return load(ctx)
+	})
+	return value, err
+}
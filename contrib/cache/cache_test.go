@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cachedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestCacheGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory(MemoryConfig{})
+	c := New(Config{Store: store, Prefix: "app:"})
+
+	if err := c.Set(ctx, "user:1", cachedUser{ID: 1, Name: "Ada"}, 0); err != nil {
+		t.Fatalf("Set: unexpected error: %v", err)
+	}
+	if _, err := store.Get(ctx, "app:user:1"); err != nil {
+		t.Errorf("expected the key stored with its prefix, got %v", err)
+	}
+
+	var user cachedUser
+	if err := c.Get(ctx, "user:1", &user); err != nil || user.Name != "Ada" {
+		t.Errorf("expected Ada, got %+v (%v)", user, err)
+	}
+	if user, err := Get[cachedUser](ctx, c, "user:1"); err != nil || user.ID != 1 {
+		t.Errorf("expected the typed value, got %+v (%v)", user, err)
+	}
+
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete: unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, "user:1", &user); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+
+	if err := c.Set(ctx, "bad", make(chan int), 0); err == nil {
+		t.Error("expected an encoding error")
+	}
+	if c.Store() != store {
+		t.Error("expected Store to return the backend")
+	}
+}
+
+func TestCacheDefaultTTL(t *testing.T) {
+	ctx := context.Background()
+	c := New(Config{DefaultTTL: time.Millisecond})
+
+	c.Set(ctx, "default", "value", 0)
+	c.Set(ctx, "explicit", "value", time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	var value string
+	if err := c.Get(ctx, "default", &value); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a zero ttl to use DefaultTTL, got %v", err)
+	}
+	if err := c.Get(ctx, "explicit", &value); err != nil {
+		t.Errorf("expected an explicit ttl to win, got %v", err)
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	ctx := context.Background()
+	c := New(Config{})
+
+	calls := 0
+	load := func(ctx context.Context) (cachedUser, error) {
+		calls++
+		return cachedUser{ID: 42, Name: "Ada"}, nil
+	}
+	for i := 0; i < 3; i++ {
+		user, err := GetOrSet(ctx, c, "user:42", time.Minute, load)
+		if err != nil || user.Name != "Ada" {
+			t.Fatalf("expected Ada, got %+v (%v)", user, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the loader to run once, got %d", calls)
+	}
+}
+
+func TestGetOrSetErrorNotCached(t *testing.T) {
+	ctx := context.Background()
+	c := New(Config{})
+	failure := errors.New("database down")
+
+	calls := 0
+	load := func(ctx context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", failure
+		}
+		return "ok", nil
+	}
+	if _, err := GetOrSet(ctx, c, "key", 0, load); !errors.Is(err, failure) {
+		t.Errorf("expected the loader error, got %v", err)
+	}
+	if value, err := GetOrSet(ctx, c, "key", 0, load); err != nil || value != "ok" {
+		t.Errorf("expected the loader to run again, got %q (%v)", value, err)
+	}
+}
+
+// failingStore fails every operation.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+func (failingStore) Delete(context.Context, string) error { return nil }
+
+func TestGetOrSetStoreError(t *testing.T) {
+	c := New(Config{Store: failingStore{}})
+	_, err := GetOrSet(context.Background(), c, "key", 0, func(ctx context.Context) (string, error) {
+		t.Error("expected the loader not to run on store errors")
+		return "", nil
+	})
+	if err == nil || err.Error() != "connection refused" {
+		t.Errorf("expected the store error, got %v", err)
+	}
+}
+
+func TestGetOrSetSingleFlight(t *testing.T) {
+	ctx := context.Background()
+	c := New(Config{})
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}
+
+	const callers = 10
+	var started, done sync.WaitGroup
+	results := make([]int, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i], errs[i] = GetOrSet(ctx, c, "answer", 0, load)
+		}(i)
+	}
+	started.Wait()
+	time.Sleep(10 * time.Millisecond) // Let the callers reach the loader
+	close(release)
+	done.Wait()
+
+	// Callers arriving after the loader see the cached value
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the loader to run once, got %d", n)
+	}
+	for i := range results {
+		if results[i] != 7 || errs[i] != nil {
+			t.Errorf("caller %d: expected 7, got %d (%v)", i, results[i], errs[i])
+		}
+	}
+}
+
+func TestGetOrSetWaiterContext(t *testing.T) {
+	c := New(Config{})
+	release := make(chan struct{})
+	defer close(release)
+
+	entered := make(chan struct{})
+	go GetOrSet(context.Background(), c, "slow", 0, func(ctx context.Context) (int, error) {
+		close(entered)
+		<-release
+		return 1, nil
+	})
+	<-entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetOrSet(ctx, c, "slow", 0, func(ctx context.Context) (int, error) {
+		t.Error("expected the waiter not to run its loader")
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the waiter to stop with its context, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"github.com/AchrafSoltani/quark"
+)
+
+// memoContextKey is the context store key of the request memo table.
+const memoContextKey = "cache.memo"
+
+// memoEntry is a memoized result.
+type memoEntry struct {
+	value interface{}
+	err   error
+}
+
+// Memoize returns the result of fn for key, calling fn at most once per
+// request. It suits values needed by several middleware and handlers of one
+// request, such as the current user or tenant, without caching them across
+// requests. Errors are memoized too.
+//
+// Example:
+//
+//	user, err := cache.Memoize(c, "current_user", func() (*User, error) {
+//	    return repo.FindUser(c.Context(), jwt.GetUserID(c))
+//	})
+func Memoize[T any](c *quark.Context, key string, fn func() (T, error)) (T, error) {
+	memo, _ := c.Get(memoContextKey).(map[string]memoEntry)
+	if memo == nil {
+		memo = make(map[string]memoEntry)
+		c.Set(memoContextKey, memo)
+	}
+
+	if entry, ok := memo[key]; ok {
+		value, _ := entry.value.(T)
+		return value, entry.err
+	}
+
+	value, err := fn()
+	memo[key] = memoEntry{value: value, err: err}
+	return value, err
+}
+
+// Forget removes a memoized result from the current request, so the next
+// Memoize call for key runs its function again.
+func Forget(c *quark.Context, key string) {
+	if memo, ok := c.Get(memoContextKey).(map[string]memoEntry); ok {
+		delete(memo, key)
+	}
+}
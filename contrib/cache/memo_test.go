@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := 0
+	load := func() (string, error) {
+		calls++
+		return "Ada", nil
+	}
+	failure := errors.New("not found")
+
+	app := quark.New()
+	app.GET("/", func(c *quark.Context) error {
+		for i := 0; i < 3; i++ {
+			if user, err := Memoize(c, "user", load); err != nil || user != "Ada" {
+				t.Errorf("expected Ada, got %q (%v)", user, err)
+			}
+		}
+
+		Forget(c, "user")
+		Memoize(c, "user", load)
+
+		failed := 0
+		for i := 0; i < 2; i++ {
+			_, err := Memoize(c, "tenant", func() (int, error) {
+				failed++
+				return 0, failure
+			})
+			if !errors.Is(err, failure) {
+				t.Errorf("expected the memoized error, got %v", err)
+			}
+		}
+		if failed != 1 {
+			t.Errorf("expected errors memoized too, got %d calls", failed)
+		}
+		return nil
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if calls != 2 {
+		t.Errorf("expected 1 call plus 1 after Forget, got %d", calls)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if calls != 4 {
+		t.Errorf("expected results not shared across requests, got %d calls", calls)
+	}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// MemoryConfig configures the in-memory store.
+type MemoryConfig struct {
+	// Shards is the number of independently locked shards (default: 16).
+	// More shards reduce lock contention under concurrent access.
+	Shards int
+
+	// MaxEntries is the total number of entries kept before the least
+	// recently used ones are evicted (default: 10000). Zero or less uses
+	// the default.
+	MaxEntries int
+}
+
+// Memory is an in-memory Store with per-shard LRU eviction and TTLs.
+// Expired entries are removed when they are read or evicted.
+type Memory struct {
+	shards []*memoryShard
+}
+
+// memoryShard is an LRU list of entries guarded by its own lock.
+type memoryShard struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // Front is most recently used
+}
+
+// memoryEntry is a cached value.
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Zero means no expiry
+}
+
+// NewMemory creates an in-memory store.
+func NewMemory(config MemoryConfig) *Memory {
+	if config.Shards <= 0 {
+		config.Shards = 16
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+
+	capacity := config.MaxEntries / config.Shards
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	m := &Memory{shards: make([]*memoryShard, config.Shards)}
+	for i := range m.shards {
+		m.shards[i] = &memoryShard{
+			capacity: capacity,
+			items:    make(map[string]*list.Element),
+			order:    list.New(),
+		}
+	}
+	return m
+}
+
+// shard returns the shard responsible for key.
+func (m *Memory) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return m.shards[h.Sum32()%uint32(len(m.shards))]
+}
+
+// Get implements Store.
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		s.remove(elem)
+		return nil, ErrNotFound
+	}
+
+	s.order.MoveToFront(elem)
+	return append([]byte(nil), entry.value...), nil
+}
+
+// Set implements Store.
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := &memoryEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		elem.Value = entry
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired entries that have
+// not been removed yet.
+func (m *Memory) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		n += s.order.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Clear removes all entries.
+func (m *Memory) Clear() {
+	for _, s := range m.shards {
+		s.mu.Lock()
+		s.items = make(map[string]*list.Element)
+		s.order.Init()
+		s.mu.Unlock()
+	}
+}
+
+// remove deletes an element. The caller must hold the shard lock.
+func (s *memoryShard) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryEntry).key)
+}
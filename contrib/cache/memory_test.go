@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryLRU(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(MemoryConfig{Shards: 1, MaxEntries: 2})
+
+	m.Set(ctx, "a", []byte("1"), 0)
+	m.Set(ctx, "b", []byte("2"), 0)
+	m.Get(ctx, "a") // a is now the most recently used
+	m.Set(ctx, "c", []byte("3"), 0)
+
+	if _, err := m.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the least recently used entry evicted, got %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := m.Get(ctx, key); err != nil {
+			t.Errorf("expected %s kept, got %v", key, err)
+		}
+	}
+
+	m.Set(ctx, "a", []byte("updated"), 0) // Replacing does not evict
+	if m.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", m.Len())
+	}
+	if value, _ := m.Get(ctx, "a"); string(value) != "updated" {
+		t.Errorf("expected the updated value, got %q", value)
+	}
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(MemoryConfig{Shards: 4, MaxEntries: 40})
+	for i := 0; i < 1000; i++ {
+		m.Set(ctx, fmt.Sprintf("key%d", i), []byte("v"), 0)
+	}
+	if n := m.Len(); n > 40 {
+		t.Errorf("expected at most 40 entries, got %d", n)
+	}
+
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("expected no entries after Clear, got %d", m.Len())
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(MemoryConfig{})
+
+	m.Set(ctx, "short", []byte("1"), time.Millisecond)
+	m.Set(ctx, "long", []byte("2"), time.Hour)
+	m.Set(ctx, "forever", []byte("3"), 0)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := m.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the expired entry to be missing, got %v", err)
+	}
+	if m.Len() != 2 {
+		t.Errorf("expected the expired entry removed on read, got %d entries", m.Len())
+	}
+	for _, key := range []string{"long", "forever"} {
+		if _, err := m.Get(ctx, key); err != nil {
+			t.Errorf("expected %s kept, got %v", key, err)
+		}
+	}
+}
+
+func TestMemoryCopiesValues(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(MemoryConfig{})
+
+	value := []byte("abc")
+	m.Set(ctx, "key", value, 0)
+	value[0] = 'x'
+
+	got, _ := m.Get(ctx, "key")
+	got[1] = 'y'
+	if again, _ := m.Get(ctx, "key"); string(again) != "abc" {
+		t.Errorf("expected the stored value isolated from callers, got %q", again)
+	}
+}
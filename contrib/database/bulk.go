@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BulkOption configures BulkInsert and BulkInsertReturning.
+type BulkOption func(*bulkOptions)
+
+// bulkOptions holds bulk insert options.
+type bulkOptions struct {
+	batchSize int
+	suffix    string
+	driver    string
+}
+
+// BulkBatchSize sets the maximum number of rows per INSERT statement. By
+// default batches are as large as the driver's parameter limit allows.
+func BulkBatchSize(n int) BulkOption {
+	return func(o *bulkOptions) {
+		o.batchSize = n
+	}
+}
+
+// BulkSuffix appends a clause to each INSERT statement, such as
+// "ON CONFLICT (email) DO NOTHING" or "ON DUPLICATE KEY UPDATE ...".
+func BulkSuffix(clause string) BulkOption {
+	return func(o *bulkOptions) {
+		o.suffix = clause
+	}
+}
+
+// BulkDriver sets the driver whose placeholders and parameter limit are
+// used. It is required when inserting through a plain *sql.DB or *sql.Tx;
+// a DB, Tx or Cluster of this package reports its own driver.
+func BulkDriver(name string) BulkOption {
+	return func(o *bulkOptions) {
+		o.driver = name
+	}
+}
+
+// maxParams returns the maximum number of bind parameters per statement
+// of driver.
+func maxParams(driver string) int {
+	switch driver {
+	case "postgres", "postgresql", "pgx", "mysql":
+		return 65535
+	case "sqlserver", "mssql":
+		return 2100
+	default:
+		return 999 // SQLite before 3.32
+	}
+}
+
+// BulkInsert inserts rows into table with multi-row INSERT statements,
+// chunked to stay within the driver's parameter limit, and returns the
+// number of inserted rows. Each row holds one value per column. The table
+// and column names are inserted as is and must not come from user input.
+// Queriers other than a DB, Tx or Cluster need the BulkDriver option.
+//
+// Batches are separate statements: run BulkInsert in a transaction for
+// all-or-nothing imports.
+//
+// Example:
+//
+//	err := db.WithTx(ctx, func(tx *database.Tx) error {
+//	    _, err := database.BulkInsert(ctx, tx, "users",
+//	        []string{"name", "email"},
+//	        [][]interface{}{
+//	            {"Alice", "alice@example.com"},
+//	            {"Bob", "bob@example.com"},
+//	        },
+//	        database.BulkSuffix("ON CONFLICT (email) DO NOTHING"))
+//	    return err
+//	})
+func BulkInsert(ctx context.Context, q Querier, table string, columns []string, rows [][]interface{}, opts ...BulkOption) (int64, error) {
+	var total int64
+	err := bulkInsert(q, table, columns, rows, "", opts, func(query string, args []interface{}) error {
+		result, err := q.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		total += n
+		return nil
+	})
+	return total, err
+}
+
+// BulkInsertReturning is like BulkInsert with a RETURNING clause, scanning
+// the returned rows into a T following the rules of Select. RETURNING is
+// supported by PostgreSQL, SQLite 3.35+ and MariaDB 10.5+.
+//
+// Example:
+//
+//	ids, err := database.BulkInsertReturning[int64](ctx, db, "users",
+//	    []string{"name", "email"}, rows, "id")
+func BulkInsertReturning[T any](ctx context.Context, q Querier, table string, columns []string, rows [][]interface{}, returning string, opts ...BulkOption) ([]T, error) {
+	var items []T
+	err := bulkInsert(q, table, columns, rows, returning, opts, func(query string, args []interface{}) error {
+		batch, err := Select[T](ctx, q, query, args...)
+		if err != nil {
+			return err
+		}
+		items = append(items, batch...)
+		return nil
+	})
+	return items, err
+}
+
+// bulkInsert builds the INSERT statements of rows and runs each with exec.
+func bulkInsert(q Querier, table string, columns []string, rows [][]interface{}, returning string, opts []BulkOption, exec func(string, []interface{}) error) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("bulk insert into %s: no columns", table)
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("bulk insert into %s: row %d has %d values, expected %d", table, i, len(row), len(columns))
+		}
+	}
+
+	o := bulkOptions{driver: driverOf(q)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	driver := o.driver
+	if driver == "" {
+		return fmt.Errorf("bulk insert into %s: unknown driver, set it with BulkDriver", table)
+	}
+	batchSize := maxParams(driver) / len(columns)
+	if o.batchSize > 0 && o.batchSize < batchSize {
+		batchSize = o.batchSize
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("bulk insert into %s: too many columns", table)
+	}
+
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var b strings.Builder
+		b.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			for j, value := range row {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				args = append(args, value)
+				b.WriteString(Placeholder(driver, len(args)))
+			}
+			b.WriteByte(')')
+		}
+		if o.suffix != "" {
+			b.WriteString(" " + o.suffix)
+		}
+		if returning != "" {
+			b.WriteString(" RETURNING " + returning)
+		}
+
+		if err := exec(b.String(), args); err != nil {
+			return fmt.Errorf("bulk insert into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// driverOf returns the driver name of a DB or Tx, or an empty string for
+// other queriers.
+func driverOf(q Querier) string {
+	if d, ok := q.(interface{ Driver() string }); ok {
+		return d.Driver()
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// bulkRows returns n rows of two values.
+func bulkRows(n int) [][]interface{} {
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = []interface{}{fmt.Sprintf("user%d", i), fmt.Sprintf("user%d@example.com", i)}
+	}
+	return rows
+}
+
+// respondInserted makes srv report one affected row, with an id, per two
+// arguments.
+func respondInserted(srv *fakeServer) {
+	srv.respond = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		rows := make([][]driver.Value, len(args)/2)
+		for i := range rows {
+			rows[i] = []driver.Value{int64(i + 1)}
+		}
+		return []string{"id"}, rows, nil
+	}
+}
+
+func TestBulkInsertPlaceholders(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"postgres", "INSERT INTO users (name, email) VALUES ($1, $2), ($3, $4)"},
+		{"pgx", "INSERT INTO users (name, email) VALUES ($1, $2), ($3, $4)"},
+		{"mysql", "INSERT INTO users (name, email) VALUES (?, ?), (?, ?)"},
+		{"sqlite", "INSERT INTO users (name, email) VALUES (?, ?), (?, ?)"},
+		{"sqlserver", "INSERT INTO users (name, email) VALUES (@p1, @p2), (@p3, @p4)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			db, srv := newFakeDB(t, tt.driver)
+			respondInserted(srv)
+
+			n, err := BulkInsert(context.Background(), db, "users", []string{"name", "email"}, bulkRows(2))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n != 2 {
+				t.Errorf("expected 2 inserted rows, got %d", n)
+			}
+			if queries := srv.queries(); len(queries) != 1 || queries[0] != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, queries)
+			}
+			if args := srv.statements[0].args; fmt.Sprint(args) != "[user0 user0@example.com user1 user1@example.com]" {
+				t.Errorf("unexpected args %v", args)
+			}
+		})
+	}
+}
+
+func TestBulkInsertBatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		rows   int
+		opts   []BulkOption
+		want   []int // Rows per statement
+	}{
+		{"single batch", "postgres", 10, nil, []int{10}},
+		{"batch size", "postgres", 5, []BulkOption{BulkBatchSize(2)}, []int{2, 2, 1}},
+		{"exact batches", "postgres", 4, []BulkOption{BulkBatchSize(2)}, []int{2, 2}},
+		{"sqlite limit", "sqlite", 1000, nil, []int{499, 499, 2}},
+		{"sqlserver limit", "sqlserver", 2200, nil, []int{1050, 1050, 100}},
+		{"batch size capped by limit", "sqlite", 600, []BulkOption{BulkBatchSize(1000)}, []int{499, 101}},
+		{"no rows", "postgres", 0, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, srv := newFakeDB(t, tt.driver)
+			respondInserted(srv)
+
+			n, err := BulkInsert(context.Background(), db, "users", []string{"name", "email"}, bulkRows(tt.rows), tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n != int64(tt.rows) {
+				t.Errorf("expected %d inserted rows, got %d", tt.rows, n)
+			}
+			var got []int
+			for _, st := range srv.statements {
+				got = append(got, len(st.args)/2)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected batches %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBulkInsertDriver(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	respondInserted(srv)
+	ctx := context.Background()
+	columns := []string{"name", "email"}
+
+	_, err := BulkInsert(ctx, db.DB, "users", columns, bulkRows(1))
+	if err == nil || !strings.Contains(err.Error(), "unknown driver") {
+		t.Errorf("expected an unknown driver error for a *sql.DB, got %v", err)
+	}
+	if len(srv.statements) != 0 {
+		t.Errorf("expected no statement, got %q", srv.queries())
+	}
+
+	if _, err := BulkInsert(ctx, db.DB, "users", columns, bulkRows(1), BulkDriver("postgres")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queries := srv.queries(); len(queries) != 1 || !strings.HasSuffix(queries[0], "($1, $2)") {
+		t.Errorf("expected PostgreSQL placeholders, got %q", queries)
+	}
+
+	err = db.WithTx(ctx, func(tx *Tx) error {
+		_, err := BulkInsert(ctx, tx, "users", columns, bulkRows(1))
+		return err
+	})
+	if err != nil {
+		t.Errorf("expected a Tx to report its driver, got %v", err)
+	}
+}
+
+func TestBulkInsertReturning(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	respondInserted(srv)
+
+	ids, err := BulkInsertReturning[int64](context.Background(), db, "users", []string{"name", "email"}, bulkRows(3), "id",
+		BulkBatchSize(2), BulkSuffix("ON CONFLICT (email) DO NOTHING"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(ids) != "[1 2 1]" {
+		t.Errorf("expected the ids of each batch, got %v", ids)
+	}
+	want := "INSERT INTO users (name, email) VALUES ($1, $2) ON CONFLICT (email) DO NOTHING RETURNING id"
+	if queries := srv.queries(); len(queries) != 2 || queries[1] != want {
+		t.Errorf("expected the last statement %q, got %q", want, queries)
+	}
+}
+
+func TestBulkInsertErrors(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		columns []string
+		rows    [][]interface{}
+		want    string
+	}{
+		{"no columns", nil, bulkRows(1), "bulk insert into users: no columns"},
+		{"short row", []string{"name", "email"}, [][]interface{}{{"Ada"}}, "row 0 has 1 values, expected 2"},
+		{"too many columns", make([]string, 70000), nil, "too many columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BulkInsert(ctx, db, "users", tt.columns, tt.rows)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	srv.respond = func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+		return nil, nil, fmt.Errorf("duplicate key")
+	}
+	_, err := BulkInsert(ctx, db, "users", []string{"name", "email"}, bulkRows(1))
+	if err == nil || err.Error() != "bulk insert into users: duplicate key" {
+		t.Errorf("expected the wrapped statement error, got %v", err)
+	}
+}
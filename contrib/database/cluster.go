@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cluster routes statements between a primary database and read replicas:
+// queries go to healthy replicas in round-robin order, while statements
+// and transactions go to the primary. It implements Querier, so it can be
+// used wherever a DB is.
+//
+// Example:
+//
+//	cluster := database.NewCluster(primary, replica1, replica2)
+//	cluster.StartHealthChecks(10 * time.Second)
+//	defer cluster.Close()
+//
+//	users, err := database.Select[User](ctx, cluster, "SELECT * FROM users") // Replica
+//	_, err = cluster.ExecContext(ctx, "UPDATE users SET ...")                // Primary
+//
+//	// Read your own writes
+//	user, err := database.Get[User](database.UsePrimary(ctx), cluster, query, id)
+type Cluster struct {
+	primary  *DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// replica is a read replica and its health.
+type replica struct {
+	db      *DB
+	healthy atomic.Bool
+}
+
+// NewCluster creates a cluster. Replicas start healthy; without replicas
+// every statement goes to the primary.
+func NewCluster(primary *DB, replicas ...*DB) *Cluster {
+	c := &Cluster{primary: primary, stop: make(chan struct{})}
+	for _, db := range replicas {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+	return c
+}
+
+// Ensure Cluster implements Querier
+var _ Querier = (*Cluster)(nil)
+
+// primaryKey is the context key set by UsePrimary.
+type primaryKey struct{}
+
+// UsePrimary returns a context whose queries through a Cluster go to the
+// primary, e.g. to read data just written before replicas catch up.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Primary returns the primary database.
+func (c *Cluster) Primary() *DB {
+	return c.primary
+}
+
+// Replica returns the next healthy replica, or the primary when no
+// replica is healthy.
+func (c *Cluster) Replica() *DB {
+	n := len(c.replicas)
+	if n == 0 {
+		return c.primary
+	}
+
+	start := c.next.Add(1)
+	for i := 0; i < n; i++ {
+		r := c.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.primary
+}
+
+// reader returns the database for a query.
+func (c *Cluster) reader(ctx context.Context) *DB {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return c.primary
+	}
+	return c.Replica()
+}
+
+// Driver returns the database driver name of the primary.
+func (c *Cluster) Driver() string {
+	return c.primary.Driver()
+}
+
+// ExecContext executes a statement on the primary.
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query on a replica.
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query expected to return at most one row on
+// a replica.
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// NamedExecContext executes a statement with named parameters on the
+// primary.
+func (c *Cluster) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return c.primary.NamedExecContext(ctx, query, arg)
+}
+
+// NamedQueryContext executes a query with named parameters on a replica.
+func (c *Cluster) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return c.reader(ctx).NamedQueryContext(ctx, query, arg)
+}
+
+// BeginTx starts a transaction on the primary.
+func (c *Cluster) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	return c.primary.BeginTx(ctx, opts)
+}
+
+// WithTx executes a function within a transaction on the primary.
+func (c *Cluster) WithTx(ctx context.Context, fn func(*Tx) error) error {
+	return c.primary.WithTx(ctx, fn)
+}
+
+// WithTxOpts executes a function within a transaction on the primary with
+// custom options.
+func (c *Cluster) WithTxOpts(ctx context.Context, opts *TxOptions, fn func(*Tx) error) error {
+	return c.primary.WithTxOpts(ctx, opts, fn)
+}
+
+// HealthCheck pings the replicas, updating their health, and returns the
+// result of pinging the primary.
+func (c *Cluster) HealthCheck(ctx context.Context) error {
+	for _, r := range c.replicas {
+		r.healthy.Store(r.db.PingContext(ctx) == nil)
+	}
+	return c.primary.PingContext(ctx)
+}
+
+// StartHealthChecks checks the replicas every interval in the background
+// until the cluster is closed, so failed replicas are taken out of
+// rotation and recovered ones put back.
+func (c *Cluster) StartHealthChecks(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				c.HealthCheck(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// Close stops the health checks and closes all databases.
+func (c *Cluster) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	errs := []error{c.primary.Close()}
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeNode is a database of a cluster answering queries with its name.
+type fakeNode struct {
+	name string
+	db   *DB
+	srv  *fakeServer
+}
+
+// newFakeNode returns a PostgreSQL database named name on a new fake
+// server.
+func newFakeNode(t *testing.T, name string) *fakeNode {
+	t.Helper()
+	dsn := t.Name() + "/" + name
+	srv := newFakeServer(t, dsn)
+	srv.respond = func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"node"}, [][]driver.Value{{name}}, nil
+	}
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return &fakeNode{name: name, db: &DB{DB: sqlDB, driver: "postgres"}, srv: srv}
+}
+
+// setDown makes the node fail pings.
+func (n *fakeNode) setDown(down bool) {
+	n.srv.mu.Lock()
+	defer n.srv.mu.Unlock()
+	n.srv.pingErr = nil
+	if down {
+		n.srv.pingErr = errors.New("connection refused")
+	}
+}
+
+// queryNode returns the name of the node serving a query.
+func queryNode(t *testing.T, ctx context.Context, c *Cluster) string {
+	t.Helper()
+	name, err := Get[string](ctx, c, "SELECT node")
+	if err != nil {
+		t.Fatalf("query: unexpected error: %v", err)
+	}
+	return name
+}
+
+func TestClusterRouting(t *testing.T) {
+	primary, r1, r2 := newFakeNode(t, "primary"), newFakeNode(t, "r1"), newFakeNode(t, "r2")
+	c := NewCluster(primary.db, r1.db, r2.db)
+	ctx := context.Background()
+
+	counts := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		counts[queryNode(t, ctx, c)]++
+	}
+	if counts["r1"] != 2 || counts["r2"] != 2 {
+		t.Errorf("expected queries spread over the replicas, got %v", counts)
+	}
+
+	if got := queryNode(t, UsePrimary(ctx), c); got != "primary" {
+		t.Errorf("expected UsePrimary to query the primary, got %s", got)
+	}
+
+	if _, err := c.ExecContext(ctx, "UPDATE users SET name = $1", "Ada"); err != nil {
+		t.Fatalf("exec: unexpected error: %v", err)
+	}
+	if _, err := c.NamedExecContext(ctx, "UPDATE users SET name = :name", map[string]interface{}{"name": "Ada"}); err != nil {
+		t.Fatalf("named exec: unexpected error: %v", err)
+	}
+	if err := c.WithTx(ctx, func(tx *Tx) error { return nil }); err != nil {
+		t.Fatalf("tx: unexpected error: %v", err)
+	}
+	if len(primary.srv.statements) != 3 || len(primary.srv.txs) != 1 {
+		t.Errorf("expected statements and transactions on the primary, got %q and %v", primary.srv.queries(), primary.srv.txs)
+	}
+	for _, r := range []*fakeNode{r1, r2} {
+		for _, q := range r.srv.queries() {
+			if q != "SELECT node" {
+				t.Errorf("expected only queries on %s, got %q", r.name, q)
+			}
+		}
+	}
+
+	if c.Driver() != "postgres" {
+		t.Errorf("expected the primary's driver, got %q", c.Driver())
+	}
+}
+
+func TestClusterFailover(t *testing.T) {
+	primary, r1, r2 := newFakeNode(t, "primary"), newFakeNode(t, "r1"), newFakeNode(t, "r2")
+	c := NewCluster(primary.db, r1.db, r2.db)
+	ctx := context.Background()
+
+	r1.setDown(true)
+	if err := c.HealthCheck(ctx); err != nil {
+		t.Fatalf("expected a healthy primary, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if got := queryNode(t, ctx, c); got != "r2" {
+			t.Errorf("expected the healthy replica, got %s", got)
+		}
+	}
+
+	r2.setDown(true)
+	c.HealthCheck(ctx)
+	if got := queryNode(t, ctx, c); got != "primary" {
+		t.Errorf("expected a fallback to the primary, got %s", got)
+	}
+
+	r1.setDown(false)
+	c.HealthCheck(ctx)
+	if got := queryNode(t, ctx, c); got != "r1" {
+		t.Errorf("expected the recovered replica, got %s", got)
+	}
+
+	primary.setDown(true)
+	if err := c.HealthCheck(ctx); err == nil {
+		t.Error("expected the primary's ping error")
+	}
+}
+
+func TestClusterWithoutReplicas(t *testing.T) {
+	primary := newFakeNode(t, "primary")
+	c := NewCluster(primary.db)
+	if got := queryNode(t, context.Background(), c); got != "primary" {
+		t.Errorf("expected the primary, got %s", got)
+	}
+}
+
+func TestClusterHealthChecks(t *testing.T) {
+	primary, r1 := newFakeNode(t, "primary"), newFakeNode(t, "r1")
+	c := NewCluster(primary.db, r1.db)
+	r1.setDown(true)
+	c.StartHealthChecks(5 * time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for c.replicas[0].healthy.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the background health check to take the replica out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("close: unexpected error: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("expected closing twice to be safe, got %v", err)
+	}
+	for _, n := range []*fakeNode{primary, r1} {
+		if err := n.db.PingContext(context.Background()); err == nil {
+			t.Errorf("expected %s to be closed", n.name)
+		}
+	}
+}
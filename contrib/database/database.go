@@ -16,6 +16,7 @@ import (
 type DB struct {
 	*sql.DB
 	driver string
+	hooks  []QueryHook
 }
 
 // Config holds database connection configuration.
@@ -166,3 +167,5 @@ type Querier interface {
 // Ensure DB implements Querier
 var _ Querier = (*sql.DB)(nil)
 var _ Querier = (*sql.Tx)(nil)
+var _ Querier = (*DB)(nil)
+var _ Querier = (*Tx)(nil)
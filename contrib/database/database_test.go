@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// The fake driver is registered as "sqlite" so Config and BuildDSN accept
+// it; the DSN names a fakeServer.
+func init() {
+	sql.Register("sqlite", fakeDriver{})
+}
+
+var (
+	fakeServersMu sync.Mutex
+	fakeServers   = make(map[string]*fakeServer)
+)
+
+// fakeStatement is a statement received by a fakeServer.
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeServer is an in-memory database answering queries with respond.
+type fakeServer struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+	txs        []string // "commit" or "rollback" of each transaction
+	pingErr    error
+	closed     int
+
+	// respond returns the columns and rows of a query, or an error.
+	// Statements affect as many rows as respond returns.
+	respond func(query string, args []driver.Value) ([]string, [][]driver.Value, error)
+}
+
+// newFakeServer registers a server under dsn.
+func newFakeServer(t *testing.T, dsn string) *fakeServer {
+	t.Helper()
+	srv := &fakeServer{}
+	fakeServersMu.Lock()
+	fakeServers[dsn] = srv
+	fakeServersMu.Unlock()
+	t.Cleanup(func() {
+		fakeServersMu.Lock()
+		delete(fakeServers, dsn)
+		fakeServersMu.Unlock()
+	})
+	return srv
+}
+
+// newFakeDB returns a DB of driver connected to a new fake server.
+func newFakeDB(t *testing.T, driverName string) (*DB, *fakeServer) {
+	t.Helper()
+	dsn := t.Name() + "/" + driverName
+	srv := newFakeServer(t, dsn)
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return &DB{DB: sqlDB, driver: driverName}, srv
+}
+
+// record logs a statement and returns its response.
+func (s *fakeServer) record(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	s.mu.Lock()
+	s.statements = append(s.statements, fakeStatement{query, values})
+	respond := s.respond
+	s.mu.Unlock()
+
+	if respond == nil {
+		return nil, nil, nil
+	}
+	return respond(query, values)
+}
+
+// queries returns the statements received so far.
+func (s *fakeServer) queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queries := make([]string, len(s.statements))
+	for i, st := range s.statements {
+		queries[i] = st.query
+	}
+	return queries
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeServersMu.Lock()
+	srv, ok := fakeServers[dsn]
+	fakeServersMu.Unlock()
+	if !ok {
+		return nil, errors.New("fake: unknown server " + dsn)
+	}
+	return &fakeConn{srv: srv}, nil
+}
+
+type fakeConn struct {
+	srv *fakeServer
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fake: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	c.srv.mu.Lock()
+	c.srv.closed++
+	c.srv.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{srv: c.srv}, nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+	return c.srv.pingErr
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, rows, err := c.srv.record(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.srv.record(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeTx struct {
+	srv *fakeServer
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.srv.mu.Lock()
+	tx.srv.txs = append(tx.srv.txs, "commit")
+	tx.srv.mu.Unlock()
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.srv.mu.Lock()
+	tx.srv.txs = append(tx.srv.txs, "rollback")
+	tx.srv.mu.Unlock()
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "postgres",
+			cfg:  Config{Driver: "postgres", Host: "db", Port: 5432, Username: "app", Password: "secret", Database: "shop", SSLMode: "disable"},
+			want: "host=db port=5432 user=app password=secret dbname=shop sslmode=disable",
+		},
+		{
+			name: "mysql",
+			cfg:  Config{Driver: "mysql", Host: "db", Port: 3306, Username: "app", Password: "secret", Database: "shop"},
+			want: "app:secret@tcp(db:3306)/shop?parseTime=true",
+		},
+		{name: "sqlite", cfg: Config{Driver: "sqlite3", Database: "app.db"}, want: "app.db"},
+		{name: "unsupported", cfg: Config{Driver: "oracle"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildDSN(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expected %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryEvent describes a statement executed through DB or Tx.
+type QueryEvent struct {
+	Op       string        // "exec", "query" or "query_row"
+	Query    string        // SQL statement
+	Args     []interface{} // Statement arguments
+	Duration time.Duration // Time taken by the statement
+	Err      error         // Error returned by the statement, if any
+	InTx     bool          // Whether the statement ran in a transaction
+}
+
+// QueryHook is called after each statement executed through DB or Tx, for
+// slow query logging, metrics or tracing. Hooks run synchronously on the
+// calling goroutine and should be fast.
+type QueryHook func(ctx context.Context, event QueryEvent)
+
+// AddHook registers a hook called after every Exec, Query and QueryRow
+// through the DB and its transactions, so existing call sites are
+// instrumented without changes. Hooks must be added before the DB is used
+// concurrently.
+//
+// Example:
+//
+//	db.AddHook(database.LogSlowQueries(200*time.Millisecond, log.Printf))
+//
+//	db.AddHook(func(ctx context.Context, e database.QueryEvent) {
+//	    queryDuration.Observe(e.Duration.Seconds())
+//	})
+func (db *DB) AddHook(hook QueryHook) {
+	db.hooks = append(db.hooks, hook)
+}
+
+// LogSlowQueries returns a hook that logs statements taking at least
+// threshold, and failed statements. Arguments are not logged as they may
+// contain sensitive data.
+func LogSlowQueries(threshold time.Duration, logf func(format string, args ...interface{})) QueryHook {
+	return func(ctx context.Context, e QueryEvent) {
+		switch {
+		case e.Err != nil && e.Err != sql.ErrNoRows:
+			logf("query failed after %v: %s: %v", e.Duration, e.Query, e.Err)
+		case e.Duration >= threshold:
+			logf("slow query (%v): %s", e.Duration, e.Query)
+		}
+	}
+}
+
+// observe runs the hooks for a statement that started at start.
+func (db *DB) observe(ctx context.Context, op, query string, args []interface{}, start time.Time, err error, inTx bool) {
+	if len(db.hooks) == 0 {
+		return
+	}
+
+	event := QueryEvent{
+		Op:       op,
+		Query:    query,
+		Args:     args,
+		Duration: time.Since(start),
+		Err:      err,
+		InTx:     inTx,
+	}
+	for _, hook := range db.hooks {
+		hook(ctx, event)
+	}
+}
+
+// ExecContext executes a statement, running the query hooks.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	db.observe(ctx, "exec", query, args, start, err, false)
+	return result, err
+}
+
+// Exec executes a statement, running the query hooks.
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query, running the query hooks.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	db.observe(ctx, "query", query, args, start, err, false)
+	return rows, err
+}
+
+// Query executes a query, running the query hooks.
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query expected to return at most one row,
+// running the query hooks.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	db.observe(ctx, "query_row", query, args, start, row.Err(), false)
+	return row
+}
+
+// QueryRow executes a query expected to return at most one row, running
+// the query hooks.
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a statement in the transaction, running the query
+// hooks.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	tx.db.observe(ctx, "exec", query, args, start, err, true)
+	return result, err
+}
+
+// Exec executes a statement in the transaction, running the query hooks.
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query in the transaction, running the query
+// hooks.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	tx.db.observe(ctx, "query", query, args, start, err, true)
+	return rows, err
+}
+
+// Query executes a query in the transaction, running the query hooks.
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query expected to return at most one row in
+// the transaction, running the query hooks.
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	tx.db.observe(ctx, "query_row", query, args, start, row.Err(), true)
+	return row
+}
+
+// QueryRow executes a query expected to return at most one row in the
+// transaction, running the query hooks.
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	failure := errors.New("relation does not exist")
+	srv.respond = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "missing") {
+			return nil, nil, failure
+		}
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	}
+
+	var order []string
+	var events []QueryEvent
+	db.AddHook(func(ctx context.Context, e QueryEvent) {
+		order = append(order, "first")
+		events = append(events, e)
+	})
+	db.AddHook(func(ctx context.Context, e QueryEvent) {
+		order = append(order, "second")
+	})
+
+	ctx := context.Background()
+	db.ExecContext(ctx, "UPDATE users SET name = $1", "Ada")
+	rows, _ := db.QueryContext(ctx, "SELECT id FROM users")
+	rows.Close()
+	var id int64
+	db.QueryRowContext(ctx, "SELECT id FROM missing").Scan(&id)
+
+	err := db.WithTx(ctx, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: unexpected error: %v", err)
+	}
+
+	want := []QueryEvent{
+		{Op: "exec", Query: "UPDATE users SET name = $1", Args: []interface{}{"Ada"}},
+		{Op: "query", Query: "SELECT id FROM users"},
+		{Op: "query_row", Query: "SELECT id FROM missing", Err: failure},
+		{Op: "exec", Query: "DELETE FROM users WHERE id = $1", Args: []interface{}{1}, InTx: true},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, w := range want {
+		got := events[i]
+		if got.Op != w.Op || got.Query != w.Query || got.InTx != w.InTx || !errors.Is(got.Err, w.Err) ||
+			fmt.Sprint(got.Args) != fmt.Sprint(w.Args) {
+			t.Errorf("event %d: expected %+v, got %+v", i, w, got)
+		}
+		if got.Duration <= 0 {
+			t.Errorf("event %d: expected a duration", i)
+		}
+	}
+	if strings.Join(order[:4], ",") != "first,second,first,second" {
+		t.Errorf("expected hooks run in order, got %v", order)
+	}
+}
+
+func TestLogSlowQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		event QueryEvent
+		want  string
+	}{
+		{"fast", QueryEvent{Query: "SELECT 1", Duration: 10}, ""},
+		{"slow", QueryEvent{Query: "SELECT 1", Duration: 100}, "slow query (100ns): SELECT 1"},
+		{"failed", QueryEvent{Query: "SELECT 1", Duration: 10, Err: errors.New("boom")}, "query failed after 10ns: SELECT 1: boom"},
+		{"no rows", QueryEvent{Query: "SELECT 1", Duration: 10, Err: sql.ErrNoRows}, ""},
+		{"args not logged", QueryEvent{Query: "SELECT $1", Args: []interface{}{"secret"}, Duration: 100}, "slow query (100ns): SELECT $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			hook := LogSlowQueries(100, func(format string, args ...interface{}) {
+				got = fmt.Sprintf(format, args...)
+			})
+			hook(context.Background(), tt.event)
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// DefaultConnection is the name of the default connection of a Manager.
+const DefaultConnection = "default"
+
+// ErrConnectionNotFound is returned for unknown connection names.
+var ErrConnectionNotFound = errors.New("database connection not found")
+
+// Manager holds named database connections, such as "default",
+// "analytics" or one per tenant.
+//
+// Example:
+//
+//	// Opens "default" from DB_HOST, DB_PORT, ... and "analytics" from
+//	// ANALYTICS_DB_HOST, ANALYTICS_DB_PORT, ...
+//	dbs := database.NewManager()
+//	if err := dbs.OpenFromEnv(database.DefaultConnection, "analytics"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	dbs.Register(app) // Container services and close on shutdown
+//
+//	analytics := quark.MustResolve[*database.DB](app.Container(), "db.analytics")
+type Manager struct {
+	mu    sync.RWMutex
+	conns map[string]*DB
+}
+
+// NewManager creates an empty connection manager.
+func NewManager() *Manager {
+	return &Manager{conns: make(map[string]*DB)}
+}
+
+// Add adds an open connection under name, replacing any previous one
+// without closing it.
+func (m *Manager) Add(name string, db *DB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conns[name] = db
+}
+
+// Open opens a connection with cfg and adds it under name.
+func (m *Manager) Open(name string, cfg Config) (*DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("database %q: %w", name, err)
+	}
+	m.Add(name, db)
+	return db, nil
+}
+
+// OpenFromEnv opens the named connections with configurations loaded from
+// the environment (see EnvPrefix), closing the ones already opened if one
+// fails.
+func (m *Manager) OpenFromEnv(names ...string) error {
+	var opened []string
+	for _, name := range names {
+		var cfg Config
+		if err := quark.LoadFromEnvPrefix(EnvPrefix(name), &cfg); err != nil {
+			m.closeNames(opened)
+			return fmt.Errorf("database %q: %w", name, err)
+		}
+		if _, err := m.Open(name, cfg); err != nil {
+			m.closeNames(opened)
+			return err
+		}
+		opened = append(opened, name)
+	}
+	return nil
+}
+
+// EnvPrefix returns the environment variable prefix of a connection: none
+// for the default connection, and the upper-cased name followed by an
+// underscore otherwise ("tenant-x" reads TENANT_X_DB_HOST).
+func EnvPrefix(name string) string {
+	if name == DefaultConnection {
+		return ""
+	}
+	prefix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return prefix + "_"
+}
+
+// Get returns the connection named name.
+func (m *Manager) Get(name string) (*DB, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	db, ok := m.conns[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConnectionNotFound, name)
+	}
+	return db, nil
+}
+
+// MustGet returns the connection named name or panics.
+func (m *Manager) MustGet(name string) *DB {
+	db, err := m.Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// Default returns the default connection, or nil if there is none.
+func (m *Manager) Default() *DB {
+	db, _ := m.Get(DefaultConnection)
+	return db
+}
+
+// Names returns the connection names in sorted order.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.conns))
+	for name := range m.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register registers the manager and its connections in the app container
+// and closes the connections on shutdown. The manager is registered as
+// "db.manager", each connection as "db.<name>", and the default
+// connection also as "db".
+func (m *Manager) Register(app *quark.App) {
+	c := app.Container()
+	quark.ProvideValue(c, "db.manager", m)
+	for _, name := range m.Names() {
+		quark.ProvideValue(c, "db."+name, m.MustGet(name))
+	}
+	if db := m.Default(); db != nil {
+		quark.ProvideValue(c, "db", db)
+	}
+
+	app.OnShutdown(func(*quark.App) error {
+		return m.Close()
+	})
+}
+
+// Close closes all connections and removes them from the manager.
+func (m *Manager) Close() error {
+	return m.closeNames(m.Names())
+}
+
+// closeNames closes and removes the named connections.
+func (m *Manager) closeNames(names []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if db, ok := m.conns[name]; ok {
+			if err := db.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("database %q: %w", name, err))
+			}
+			delete(m.conns, name)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	tests := map[string]string{
+		DefaultConnection: "",
+		"analytics":       "ANALYTICS_",
+		"tenant-x":        "TENANT_X_",
+		"Reports2":        "REPORTS2_",
+		"eu.west":         "EU_WEST_",
+	}
+	for name, want := range tests {
+		if got := EnvPrefix(name); got != want {
+			t.Errorf("EnvPrefix(%q): expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+// setFakeEnv points the connection with prefix at a new fake server.
+func setFakeEnv(t *testing.T, prefix string) *fakeServer {
+	t.Helper()
+	dsn := t.Name() + "/" + prefix
+	srv := newFakeServer(t, dsn)
+	t.Setenv(prefix+"DB_DRIVER", "sqlite")
+	t.Setenv(prefix+"DB_DATABASE", dsn)
+	return srv
+}
+
+func TestManagerOpenFromEnv(t *testing.T) {
+	setFakeEnv(t, "")
+	setFakeEnv(t, "ANALYTICS_")
+
+	m := NewManager()
+	if err := m.OpenFromEnv(DefaultConnection, "analytics"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := m.Names(); fmt.Sprint(names) != "[analytics default]" {
+		t.Errorf("expected [analytics default], got %v", names)
+	}
+	if m.Default() == nil || m.MustGet("analytics").Driver() != "sqlite" {
+		t.Error("expected both connections with their driver")
+	}
+
+	if _, err := m.Get("reports"); !errors.Is(err, ErrConnectionNotFound) {
+		t.Errorf("expected ErrConnectionNotFound, got %v", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("close: unexpected error: %v", err)
+	}
+	if len(m.Names()) != 0 || m.Default() != nil {
+		t.Errorf("expected the connections removed on close, got %v", m.Names())
+	}
+}
+
+func TestManagerOpenFromEnvFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"invalid port", map[string]string{"ANALYTICS_DB_DRIVER": "sqlite", "ANALYTICS_DB_PORT": "abc"}, `database "analytics"`},
+		{"unreachable", map[string]string{"ANALYTICS_DB_DRIVER": "sqlite", "ANALYTICS_DB_DATABASE": "nowhere"}, "failed to ping database"},
+		{"unsupported driver", map[string]string{"ANALYTICS_DB_DRIVER": "oracle"}, "unsupported driver: oracle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := setFakeEnv(t, "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			m := NewManager()
+			err := m.OpenFromEnv(DefaultConnection, "analytics")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, err)
+			}
+			if names := m.Names(); len(names) != 0 {
+				t.Errorf("expected no connections left, got %v", names)
+			}
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed == 0 {
+				t.Error("expected the default connection to be closed")
+			}
+		})
+	}
+}
+
+func TestManagerRegister(t *testing.T) {
+	primary, _ := newFakeDB(t, "postgres")
+	analytics, _ := newFakeDB(t, "mysql")
+	m := NewManager()
+	m.Add(DefaultConnection, primary)
+	m.Add("analytics", analytics)
+
+	app := quark.New()
+	m.Register(app)
+	c := app.Container()
+
+	if quark.MustResolve[*DB](c, "db") != primary || quark.MustResolve[*DB](c, "db.default") != primary {
+		t.Error("expected the default connection as db and db.default")
+	}
+	if quark.MustResolve[*DB](c, "db.analytics") != analytics {
+		t.Error("expected the analytics connection as db.analytics")
+	}
+	if quark.MustResolve[*Manager](c, "db.manager") != m {
+		t.Error("expected the manager as db.manager")
+	}
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldCache caches column-to-field mappings by struct type.
+var fieldCache sync.Map // map[reflect.Type]map[string][]int
+
+// structFields returns the column names of a struct type mapped to field
+// index paths. Columns are named by the `db:"col"` tag, or the lowercased
+// field name without one; `db:"-"` skips a field. Fields of embedded
+// structs are included as if declared in the outer struct.
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+	collectFields(t, nil, fields)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// collectFields adds the fields of t, reached through index, to fields.
+// Outer fields win over embedded ones with the same column.
+func collectFields(t reflect.Type, index []int, fields map[string][]int) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, f)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if _, ok := fields[name]; !ok {
+			fields[name] = append(append([]int(nil), index...), i)
+		}
+	}
+
+	for _, f := range embedded {
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		collectFields(ft, append(append([]int(nil), index...), f.Index...), fields)
+	}
+}
+
+// fieldByIndex returns the field at index, allocating nil embedded struct
+// pointers along the way when alloc is set. It reports false when a nil
+// pointer is reached without alloc, or cannot be allocated because its
+// struct type is unexported.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
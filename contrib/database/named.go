@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholder returns the bind parameter of the nth argument (starting at
+// 1) in the SQL dialect of driver: $n for PostgreSQL, @pn for SQL Server
+// and ? for MySQL, SQLite and others.
+func Placeholder(driver string, n int) string {
+	switch driver {
+	case "postgres", "postgresql", "pgx":
+		return "$" + strconv.Itoa(n)
+	case "sqlserver", "mssql":
+		return "@p" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// BindNamed rewrites the :name parameters of query into the positional
+// placeholders of driver and returns the matching arguments, read from a
+// map with string keys or a struct. Struct fields are named by their
+// `db:"col"` tag, or their lowercased name without one. Quoted strings and
+// identifiers, -- and /* */ comments, and PostgreSQL :: casts are left
+// untouched.
+//
+// Example:
+//
+//	query, args, err := database.BindNamed("postgres",
+//	    "UPDATE users SET name = :name WHERE id = :id", user)
+//	// UPDATE users SET name = $1 WHERE id = $2
+func BindNamed(driver, query string, arg interface{}) (string, []interface{}, error) {
+	bound, names := compileNamed(driver, query)
+	args, err := namedValues(names, arg)
+	if err != nil {
+		return "", nil, err
+	}
+	return bound, args, nil
+}
+
+// NamedExecContext executes a statement with :name parameters bound from
+// a struct or map.
+//
+// Example:
+//
+//	_, err := db.NamedExecContext(ctx,
+//	    "INSERT INTO users (name, email) VALUES (:name, :email)", user)
+func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExec(ctx, db, db.driver, query, arg)
+}
+
+// NamedQueryContext executes a query with :name parameters bound from a
+// struct or map.
+func (db *DB) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return namedQuery(ctx, db, db.driver, query, arg)
+}
+
+// NamedExecContext executes a statement in the transaction with :name
+// parameters bound from a struct or map.
+func (tx *Tx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExec(ctx, tx, tx.db.driver, query, arg)
+}
+
+// NamedQueryContext executes a query in the transaction with :name
+// parameters bound from a struct or map.
+func (tx *Tx) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return namedQuery(ctx, tx, tx.db.driver, query, arg)
+}
+
+// namedExec binds and executes a statement.
+func namedExec(ctx context.Context, q Querier, driver, query string, arg interface{}) (sql.Result, error) {
+	bound, args, err := BindNamed(driver, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return q.ExecContext(ctx, bound, args...)
+}
+
+// namedQuery binds and executes a query.
+func namedQuery(ctx context.Context, q Querier, driver, query string, arg interface{}) (*sql.Rows, error) {
+	bound, args, err := BindNamed(driver, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return q.QueryContext(ctx, bound, args...)
+}
+
+// compileNamed replaces the :name parameters of query with placeholders
+// and returns the parameter names in order.
+func compileNamed(driver, query string) (string, []string) {
+	var b strings.Builder
+	var names []string
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			// Copy quoted strings and identifiers verbatim; doubled quotes
+			// are handled as two adjacent quoted sections
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				b.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			// Copy line comments verbatim, so quotes and colons in them
+			// are not parsed
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				b.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 3
+		case ch == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::")
+			i++
+		case ch == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isNameChar(query[j]) {
+				j++
+			}
+			names = append(names, query[i+1:j])
+			b.WriteString(Placeholder(driver, len(names)))
+			i = j - 1
+		default:
+			b.WriteByte(ch)
+		}
+	}
+
+	return b.String(), names
+}
+
+// namedValues returns the values of names from a struct or map.
+func namedValues(names []string, arg interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("named parameters: nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+
+	args := make([]interface{}, 0, len(names))
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("named parameters: map keys must be strings, got %s", v.Type().Key())
+		}
+		for _, name := range names {
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, fmt.Errorf("named parameter %q not found", name)
+			}
+			args = append(args, value.Interface())
+		}
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		for _, name := range names {
+			index, ok := fields[name]
+			if !ok {
+				return nil, fmt.Errorf("named parameter %q not found in %s", name, v.Type())
+			}
+			field, ok := fieldByIndex(v, index, false)
+			if !ok {
+				// Field of a nil embedded struct
+				args = append(args, nil)
+				continue
+			}
+			args = append(args, field.Interface())
+		}
+	default:
+		if len(names) > 0 {
+			return nil, fmt.Errorf("named parameters: unsupported argument type %T", arg)
+		}
+	}
+
+	return args, nil
+}
+
+// isNameStart reports whether ch can start a parameter name.
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// isNameChar reports whether ch can appear in a parameter name.
+func isNameChar(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type namedAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type namedUser struct {
+	ID    int `db:"id"`
+	Name  string
+	Email string `db:"email"`
+	*namedAudit
+}
+
+func TestBindNamed(t *testing.T) {
+	user := namedUser{ID: 7, Name: "Ada", Email: "ada@example.com", namedAudit: &namedAudit{CreatedBy: "admin"}}
+
+	tests := []struct {
+		name      string
+		driver    string
+		query     string
+		arg       interface{}
+		wantQuery string
+		wantArgs  []interface{}
+		wantErr   string
+	}{
+		{
+			name:      "postgres struct",
+			driver:    "postgres",
+			query:     "UPDATE users SET name = :name WHERE id = :id",
+			arg:       user,
+			wantQuery: "UPDATE users SET name = $1 WHERE id = $2",
+			wantArgs:  []interface{}{"Ada", 7},
+		},
+		{
+			name:      "mysql map",
+			driver:    "mysql",
+			query:     "SELECT * FROM users WHERE email = :email AND id > :id",
+			arg:       map[string]interface{}{"email": "ada@example.com", "id": 3},
+			wantQuery: "SELECT * FROM users WHERE email = ? AND id > ?",
+			wantArgs:  []interface{}{"ada@example.com", 3},
+		},
+		{
+			name:      "sqlserver pointer",
+			driver:    "sqlserver",
+			query:     "INSERT INTO users (email, created_by) VALUES (:email, :created_by)",
+			arg:       &user,
+			wantQuery: "INSERT INTO users (email, created_by) VALUES (@p1, @p2)",
+			wantArgs:  []interface{}{"ada@example.com", "admin"},
+		},
+		{
+			name:      "repeated name",
+			driver:    "postgres",
+			query:     "SELECT :id, :id",
+			arg:       map[string]int{"id": 1},
+			wantQuery: "SELECT $1, $2",
+			wantArgs:  []interface{}{1, 1},
+		},
+		{
+			name:      "nil embedded struct",
+			driver:    "postgres",
+			query:     "SELECT :created_by",
+			arg:       namedUser{},
+			wantQuery: "SELECT $1",
+			wantArgs:  []interface{}{nil},
+		},
+		{
+			name:      "quoted strings and identifiers",
+			driver:    "postgres",
+			query:     `SELECT ':skip', "col:x", ` + "`a:b`" + `, 'it''s :also' FROM t WHERE id = :id`,
+			arg:       user,
+			wantQuery: `SELECT ':skip', "col:x", ` + "`a:b`" + `, 'it''s :also' FROM t WHERE id = $1`,
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "casts",
+			driver:    "postgres",
+			query:     "SELECT :id::text, created_at::date FROM users",
+			arg:       user,
+			wantQuery: "SELECT $1::text, created_at::date FROM users",
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "line comment",
+			driver:    "postgres",
+			query:     "SELECT id -- don't bind :name here\nFROM users WHERE id = :id",
+			arg:       user,
+			wantQuery: "SELECT id -- don't bind :name here\nFROM users WHERE id = $1",
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "trailing line comment",
+			driver:    "mysql",
+			query:     "SELECT :id -- lookup by :id",
+			arg:       user,
+			wantQuery: "SELECT ? -- lookup by :id",
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "block comment",
+			driver:    "postgres",
+			query:     "SELECT /* it's :name\n   on two lines */ :id",
+			arg:       user,
+			wantQuery: "SELECT /* it's :name\n   on two lines */ $1",
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "unterminated block comment",
+			driver:    "postgres",
+			query:     "SELECT :id /* :name",
+			arg:       user,
+			wantQuery: "SELECT $1 /* :name",
+			wantArgs:  []interface{}{7},
+		},
+		{
+			name:      "minus and division",
+			driver:    "postgres",
+			query:     "SELECT :id - 1, :id / 2",
+			arg:       user,
+			wantQuery: "SELECT $1 - 1, $2 / 2",
+			wantArgs:  []interface{}{7, 7},
+		},
+		{
+			name:      "no parameters",
+			driver:    "postgres",
+			query:     "SELECT 1",
+			arg:       nil,
+			wantQuery: "SELECT 1",
+			wantArgs:  []interface{}{},
+		},
+		{name: "missing map key", driver: "postgres", query: "SELECT :age", arg: map[string]interface{}{}, wantErr: `named parameter "age" not found`},
+		{name: "missing struct field", driver: "postgres", query: "SELECT :age", arg: user, wantErr: `named parameter "age" not found in database.namedUser`},
+		{name: "non-string map keys", driver: "postgres", query: "SELECT :id", arg: map[int]int{1: 1}, wantErr: "map keys must be strings"},
+		{name: "nil pointer", driver: "postgres", query: "SELECT :id", arg: (*namedUser)(nil), wantErr: "nil *database.namedUser"},
+		{name: "unsupported argument", driver: "postgres", query: "SELECT :id", arg: 7, wantErr: "unsupported argument type int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := BindNamed(tt.driver, tt.query, tt.arg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("expected query %q, got %q", tt.wantQuery, query)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("expected args %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
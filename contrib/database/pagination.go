@@ -141,8 +141,12 @@ type Paginator[T any] struct {
 	params   PaginationParams
 }
 
-// NewPaginator creates a new paginator.
+// NewPaginator creates a new paginator. A nil scanner maps columns onto
+// struct fields with ScanRow.
 func NewPaginator[T any](db Querier, scanner func(*sql.Rows) (T, error), params PaginationParams) *Paginator[T] {
+	if scanner == nil {
+		scanner = ScanRow[T]
+	}
 	return &Paginator[T]{
 		db:      db,
 		scanner: scanner,
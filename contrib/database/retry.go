@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls retries of transient database errors.
+type RetryPolicy struct {
+	MaxAttempts    int                  // Total attempts including the first (default: 3)
+	InitialBackoff time.Duration        // Delay before the first retry (default: 50ms)
+	MaxBackoff     time.Duration        // Upper bound of the delay (default: 2s)
+	Multiplier     float64              // Delay growth per retry (default: 2)
+	Retryable      func(err error) bool // Errors worth retrying (default: IsRetryable)
+}
+
+// DefaultRetryPolicy returns the default retry policy.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Retryable:      IsRetryable,
+	}
+}
+
+// WithRetry runs fn, retrying it with exponential backoff and jitter while
+// it fails with a retryable error. fn must be safe to run several times;
+// wrap a whole transaction rather than single statements of one. A nil
+// policy uses DefaultRetryPolicy.
+//
+// Example:
+//
+//	err := db.WithRetry(ctx, nil, func() error {
+//	    return db.WithTxOpts(ctx, &database.TxOptions{Isolation: sql.LevelSerializable},
+//	        func(tx *database.Tx) error {
+//	            return transfer(ctx, tx, from, to, amount)
+//	        })
+//	})
+//
+// TxOptions.Retry does the same for a single transaction.
+func (db *DB) WithRetry(ctx context.Context, policy *RetryPolicy, fn func() error) error {
+	return Retry(ctx, policy, fn)
+}
+
+// Retry runs fn with the retry policy, as DB.WithRetry does.
+func Retry(ctx context.Context, policy *RetryPolicy, fn func() error) error {
+	p := DefaultRetryPolicy()
+	if policy != nil {
+		if policy.MaxAttempts > 0 {
+			p.MaxAttempts = policy.MaxAttempts
+		}
+		if policy.InitialBackoff > 0 {
+			p.InitialBackoff = policy.InitialBackoff
+		}
+		if policy.MaxBackoff > 0 {
+			p.MaxBackoff = policy.MaxBackoff
+		}
+		if policy.Multiplier >= 1 {
+			p.Multiplier = policy.Multiplier
+		}
+		if policy.Retryable != nil {
+			p.Retryable = policy.Retryable
+		}
+	}
+
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return err
+		}
+
+		// Equal jitter: wait between half and all of the backoff
+		delay := backoff/2 + rand.N(backoff/2+1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// retryableStates are the SQLSTATE codes of transient failures:
+// serialization failure and deadlock.
+var retryableStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected (PostgreSQL)
+}
+
+// retryableMessages are fragments of transient error messages, for drivers
+// that expose no error code through a method.
+var retryableMessages = []string{
+	"deadlock",                 // MySQL 1213, SQL Server 1205, PostgreSQL
+	"could not serialize",      // PostgreSQL serialization failures
+	"lock wait timeout",        // MySQL 1205
+	"database is locked",       // SQLite busy
+	"connection reset by peer", // Dropped connections
+	"broken pipe",
+}
+
+// IsRetryable reports whether err is a transient database error worth
+// retrying: serialization failures, deadlocks, lock timeouts and dropped
+// connections. Errors exposing a SQLSTATE through a SQLState() method
+// (pgx and lib/pq) are matched by code, others by message.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var coded interface{ SQLState() string }
+	if errors.As(err, &coded) {
+		return retryableStates[coded.SQLState()]
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range retryableMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
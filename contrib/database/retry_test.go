@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// sqlStateError is a driver error carrying a SQLSTATE, like pgx and lib/pq
+// errors.
+type sqlStateError struct {
+	code string
+}
+
+func (e sqlStateError) Error() string    { return "pq: error " + e.code }
+func (e sqlStateError) SQLState() string { return e.code }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad connection", driver.ErrBadConn, true},
+		{"wrapped bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"broken pipe errno", syscall.EPIPE, true},
+		{"serialization failure", sqlStateError{"40001"}, true},
+		{"deadlock state", sqlStateError{"40P01"}, true},
+		{"unique violation", sqlStateError{"23505"}, false},
+		{"code wins over message", sqlStateError{"23505 deadlock"}, false},
+		{"mysql deadlock", errors.New("Error 1213: Deadlock found when trying to get lock"), true},
+		{"lock wait timeout", errors.New("Error 1205: Lock wait timeout exceeded"), true},
+		{"serialize message", errors.New("could not serialize access due to concurrent update"), true},
+		{"sqlite busy", errors.New("database is locked"), true},
+		{"reset message", errors.New("read tcp: connection reset by peer"), true},
+		{"syntax error", errors.New("syntax error at or near \"SELEC\""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	deadlock := sqlStateError{"40P01"}
+	fast := &RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		policy       *RetryPolicy
+		errs         []error // Results of successive attempts
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", fast, []error{nil}, 1, nil},
+		{"retried until success", fast, []error{deadlock, deadlock, nil}, 3, nil},
+		{"attempts exhausted", fast, []error{deadlock, deadlock, deadlock, nil}, 3, deadlock},
+		{"max attempts", &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}, []error{deadlock, deadlock, deadlock, deadlock, nil}, 5, nil},
+		{"not retryable", fast, []error{sqlStateError{"23505"}, nil}, 1, sqlStateError{"23505"}},
+		{"custom retryable", &RetryPolicy{InitialBackoff: time.Millisecond, Retryable: func(err error) bool { return false }}, []error{deadlock, nil}, 1, deadlock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), tt.policy, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if err != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	// Equal jitter waits at least half of each backoff: 10ms, then 20ms,
+	// then 40ms capped to 30ms
+	policy := &RetryPolicy{MaxAttempts: 4, InitialBackoff: 20 * time.Millisecond, MaxBackoff: 60 * time.Millisecond, Multiplier: 2}
+	var times []time.Time
+	Retry(context.Background(), policy, func() error {
+		times = append(times, time.Now())
+		return driver.ErrBadConn
+	})
+	if len(times) != 4 {
+		t.Fatalf("expected 4 attempts, got %d", len(times))
+	}
+	for i, min := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond} {
+		if gap := times[i+1].Sub(times[i]); gap < min {
+			t.Errorf("retry %d: expected a delay of at least %v, got %v", i+1, min, gap)
+		}
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	start := time.Now()
+	err := Retry(ctx, &RetryPolicy{InitialBackoff: time.Hour}, func() error {
+		attempts++
+		return driver.ErrBadConn
+	})
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected the backoff to stop on cancellation, got %d attempts in %v", attempts, time.Since(start))
+	}
+}
+
+func TestWithTxOptsRetry(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+
+	attempts := 0
+	err := db.WithTxOpts(context.Background(), &TxOptions{Retry: &RetryPolicy{InitialBackoff: time.Millisecond}}, func(tx *Tx) error {
+		attempts++
+		if attempts < 3 {
+			return sqlStateError{"40001"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if fmt.Sprint(srv.txs) != "[rollback rollback commit]" {
+		t.Errorf("expected each failed attempt rolled back, got %v", srv.txs)
+	}
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Select runs a query and scans every row into a T. Struct types are
+// mapped column by column onto fields named by their `db:"col"` tag, or
+// their lowercased name without one; columns without a matching field are
+// ignored. Other types, such as int64 or string, receive the single
+// column of the row.
+//
+// Example:
+//
+//	type User struct {
+//	    ID        int64     `db:"id"`
+//	    Name      string    `db:"name"`
+//	    CreatedAt time.Time `db:"created_at"`
+//	}
+//
+//	users, err := database.Select[User](ctx, db,
+//	    "SELECT id, name, created_at FROM users WHERE active = $1", true)
+//
+//	ids, err := database.Select[int64](ctx, db, "SELECT id FROM users")
+func Select[T any](ctx context.Context, q Querier, query string, args ...interface{}) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := ScanRow[T](rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Get runs a query and scans its first row into a T, following the rules
+// of Select. It returns sql.ErrNoRows when the query returns no rows.
+//
+// Example:
+//
+//	user, err := database.Get[User](ctx, db, "SELECT * FROM users WHERE id = $1", id)
+//	if errors.Is(err, sql.ErrNoRows) {
+//	    return quark.ErrNotFound("user not found")
+//	}
+func Get[T any](ctx context.Context, q Querier, query string, args ...interface{}) (T, error) {
+	var zero T
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+
+	item, err := ScanRow[T](rows)
+	if err != nil {
+		return zero, err
+	}
+	return item, rows.Close()
+}
+
+// ScanRow scans the current row into a T, following the rules of Select.
+// It has the signature of a Paginator scanner, replacing hand-written
+// ones:
+//
+//	paginator := database.NewPaginator(db, database.ScanRow[User], params)
+func ScanRow[T any](rows *sql.Rows) (T, error) {
+	var item T
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return item, err
+	}
+
+	v := reflect.ValueOf(&item).Elem()
+	target := v
+	if v.Kind() == reflect.Ptr && isStruct(v.Type().Elem()) {
+		v.Set(reflect.New(v.Type().Elem()))
+		target = v.Elem()
+	}
+
+	if !isStruct(target.Type()) {
+		if len(columns) != 1 {
+			return item, fmt.Errorf("scan into %T: expected 1 column, got %d", item, len(columns))
+		}
+		if err := rows.Scan(target.Addr().Interface()); err != nil {
+			return item, fmt.Errorf("scan into %T: %w", item, err)
+		}
+		return item, nil
+	}
+
+	fields := structFields(target.Type())
+	dest := make([]interface{}, len(columns))
+	for i, col := range columns {
+		index, ok := fields[col]
+		if !ok {
+			index, ok = fields[strings.ToLower(col)]
+		}
+		var field reflect.Value
+		if ok {
+			field, ok = fieldByIndex(target, index, true)
+		}
+		if !ok {
+			dest[i] = new(interface{}) // Discard unmapped columns
+			continue
+		}
+		dest[i] = field.Addr().Interface()
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return item, fmt.Errorf("scan into %T: %w", item, err)
+	}
+	return item, nil
+}
+
+// scannerType is the sql.Scanner interface type.
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isStruct reports whether t is a struct mapped field by field, as
+// opposed to a struct scanned as a single value such as time.Time or
+// sql.NullString.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t == reflect.TypeOf(time.Time{}) || reflect.PointerTo(t).Implements(scannerType) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ScanTimestamps struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type scanAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type scanUser struct {
+	ID       int64  `db:"id"`
+	Name     string // mapped as "name"
+	Nickname *string
+	Email    sql.NullString `db:"email"`
+	Password string         `db:"-"`
+	*ScanTimestamps
+	*scanAudit // unexported, so it cannot be allocated
+}
+
+// respondWith makes srv answer every query with columns and rows.
+func respondWith(srv *fakeServer, columns []string, rows ...[]driver.Value) {
+	srv.respond = func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+		return columns, rows, nil
+	}
+}
+
+func TestSelectStruct(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	created := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	respondWith(srv,
+		[]string{"id", "NAME", "nickname", "email", "password", "created_at", "created_by", "unmapped"},
+		[]driver.Value{int64(1), "Ada", "ada", "ada@example.com", "secret", created, "admin", "ignored"},
+		[]driver.Value{int64(2), "Alan", nil, nil, "secret", created, "admin", "ignored"},
+	)
+
+	users, err := Select[scanUser](context.Background(), db, "SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+
+	ada := users[0]
+	if ada.ID != 1 || ada.Name != "Ada" || ada.Nickname == nil || *ada.Nickname != "ada" {
+		t.Errorf("unexpected user %+v", ada)
+	}
+	if !ada.Email.Valid || ada.Email.String != "ada@example.com" {
+		t.Errorf("expected email ada@example.com, got %+v", ada.Email)
+	}
+	if ada.Password != "" {
+		t.Errorf("expected the db:\"-\" field to be skipped, got %q", ada.Password)
+	}
+	if ada.ScanTimestamps == nil || !ada.CreatedAt.Equal(created) {
+		t.Errorf("expected the embedded struct to be allocated and filled, got %+v", ada.ScanTimestamps)
+	}
+	if ada.scanAudit != nil {
+		t.Errorf("expected the unexported embedded struct to be skipped, got %+v", ada.scanAudit)
+	}
+
+	alan := users[1]
+	if alan.Nickname != nil || alan.Email.Valid {
+		t.Errorf("expected NULL columns to be nil and invalid, got %+v", alan)
+	}
+}
+
+func TestSelectEmpty(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	respondWith(srv, []string{"id"})
+
+	users, err := Select[scanUser](context.Background(), db, "SELECT id FROM users")
+	if err != nil || users == nil || len(users) != 0 {
+		t.Errorf("expected an empty non-nil slice, got %v (%v)", users, err)
+	}
+}
+
+func TestSelectScalars(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	respondWith(srv, []string{"id"}, []driver.Value{int64(3)}, []driver.Value{int64(5)})
+
+	ids, err := Select[int64](context.Background(), db, "SELECT id FROM users")
+	if err != nil || len(ids) != 2 || ids[0] != 3 || ids[1] != 5 {
+		t.Errorf("expected [3 5], got %v (%v)", ids, err)
+	}
+
+	created := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	respondWith(srv, []string{"created_at"}, []driver.Value{created})
+	got, err := Get[time.Time](context.Background(), db, "SELECT created_at FROM users")
+	if err != nil || !got.Equal(created) {
+		t.Errorf("expected time.Time to be scanned as a single value, got %v (%v)", got, err)
+	}
+
+	respondWith(srv, []string{"id", "name"}, []driver.Value{int64(1), "Ada"})
+	_, err = Select[int64](context.Background(), db, "SELECT id, name FROM users")
+	if err == nil || !strings.Contains(err.Error(), "expected 1 column, got 2") {
+		t.Errorf("expected a column count error, got %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	ctx := context.Background()
+
+	respondWith(srv, []string{"id", "name"}, []driver.Value{int64(1), "Ada"}, []driver.Value{int64(2), "Alan"})
+	user, err := Get[*scanUser](ctx, db, "SELECT id, name FROM users")
+	if err != nil || user == nil || user.ID != 1 || user.Name != "Ada" {
+		t.Errorf("expected the first row in a new struct, got %+v (%v)", user, err)
+	}
+
+	respondWith(srv, []string{"id", "name"})
+	_, err = Get[scanUser](ctx, db, "SELECT id, name FROM users WHERE id = $1", 9)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	respondWith(srv, []string{"id"}, []driver.Value{"not a number"})
+	_, err = Get[scanUser](ctx, db, "SELECT id FROM users")
+	if err == nil || !strings.Contains(err.Error(), "scan into database.scanUser") {
+		t.Errorf("expected a conversion error naming the type, got %v", err)
+	}
+
+	failure := errors.New("connection refused")
+	srv.respond = func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+		return nil, nil, failure
+	}
+	if _, err := Get[scanUser](ctx, db, "SELECT id FROM users"); !errors.Is(err, failure) {
+		t.Errorf("expected the query error, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// HealthChecker is implemented by DB and Cluster. Its HealthCheck method
+// is a quark.HealthCheckFunc:
+//
+//	app.HealthChecks().Register("database", db.HealthCheck)
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Ensure DB and Cluster implement HealthChecker
+var (
+	_ HealthChecker = (*DB)(nil)
+	_ HealthChecker = (*Cluster)(nil)
+)
+
+// PoolStats is the JSON form of sql.DBStats.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+// NewPoolStats converts connection pool statistics to PoolStats.
+func NewPoolStats(s sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
+
+// StatsHandler returns a handler rendering the connection pool statistics
+// of the DB as JSON, for operational dashboards. A growing wait count or
+// wait duration means the pool is too small for the load. The statistics
+// reveal capacity details, so protect the route like other internal
+// endpoints.
+//
+// Example:
+//
+//	admin := app.Group("/admin", requireAdmin)
+//	admin.GET("/db/stats", db.StatsHandler())
+func (db *DB) StatsHandler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		return c.JSON(http.StatusOK, NewPoolStats(db.Stats()))
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+func TestNewPoolStats(t *testing.T) {
+	got := NewPoolStats(sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          7,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxIdleClosed:      2,
+		MaxIdleTimeClosed:  5,
+		MaxLifetimeClosed:  6,
+	})
+	want := PoolStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          7,
+		WaitDurationMs:     1500,
+		MaxIdleClosed:      2,
+		MaxIdleTimeClosed:  5,
+		MaxLifetimeClosed:  6,
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	db, _ := newFakeDB(t, "postgres")
+	db.SetMaxOpenConns(5)
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	app := quark.New()
+	app.GET("/db/stats", db.StatsHandler())
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/db/stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	want := map[string]float64{
+		"max_open_connections": 5,
+		"open_connections":     1,
+		"in_use":               0,
+		"idle":                 1,
+		"wait_count":           0,
+		"wait_duration_ms":     0,
+		"max_idle_closed":      0,
+		"max_idle_time_closed": 0,
+		"max_lifetime_closed":  0,
+	}
+	if len(body) != len(want) {
+		t.Errorf("expected %d keys, got %v", len(want), body)
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("expected %s = %v, got %v", key, value, body[key])
+		}
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	db, srv := newFakeDB(t, "postgres")
+	registry := quark.NewHealthRegistry()
+	registry.Register("database", db.HealthCheck)
+
+	if report := registry.Ready(context.Background()); report.Status != quark.HealthUp {
+		t.Errorf("expected the database up, got %+v", report)
+	}
+
+	srv.pingErr = errors.New("connection refused")
+	report := registry.Ready(context.Background())
+	result := report.Checks["database"]
+	if report.Status != quark.HealthDown || result.Error != "connection refused" {
+		t.Errorf("expected the ping error to report the database down, got %+v", report)
+	}
+
+	db.Close()
+	if err := db.HealthCheck(context.Background()); err == nil {
+		t.Error("expected a closed database to fail its health check")
+	}
+}
@@ -29,6 +29,7 @@ type Tx struct {
 type TxOptions struct {
 	Isolation sql.IsolationLevel // Transaction isolation level
 	ReadOnly  bool               // Whether the transaction is read-only
+	Retry     *RetryPolicy       // Retries of WithTxOpts on transient errors (nil: no retries)
 }
 
 // DefaultTxOptions returns default transaction options.
@@ -62,6 +63,11 @@ func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
 	return &Tx{Tx: tx, db: db}, nil
 }
 
+// Driver returns the database driver name.
+func (tx *Tx) Driver() string {
+	return tx.db.driver
+}
+
 // Commit commits the transaction.
 func (tx *Tx) Commit() error {
 	if err := tx.Tx.Commit(); err != nil {
@@ -111,18 +117,31 @@ func (db *DB) WithTx(ctx context.Context, fn func(*Tx) error) error {
 }
 
 // WithTxOpts executes a function within a transaction with custom options.
-// Allows specifying isolation level and read-only mode.
+// Allows specifying isolation level and read-only mode. With a Retry
+// policy, the whole transaction is retried on serialization failures,
+// deadlocks and dropped connections, so fn must be safe to run again.
 //
 // Example:
 //
 //	opts := &database.TxOptions{
 //	    Isolation: sql.LevelSerializable,
+//	    Retry:     database.DefaultRetryPolicy(),
 //	}
 //	err := db.WithTxOpts(ctx, opts, func(tx *database.Tx) error {
 //	    // Transaction with serializable isolation
 //	    return updateInventory(ctx, tx, productID, quantity)
 //	})
 func (db *DB) WithTxOpts(ctx context.Context, opts *TxOptions, fn func(*Tx) error) error {
+	if opts != nil && opts.Retry != nil {
+		return Retry(ctx, opts.Retry, func() error {
+			return db.withTx(ctx, opts, fn)
+		})
+	}
+	return db.withTx(ctx, opts, fn)
+}
+
+// withTx runs fn in a single transaction attempt.
+func (db *DB) withTx(ctx context.Context, opts *TxOptions, fn func(*Tx) error) error {
 	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
@@ -0,0 +1,283 @@
+// Package i18n provides message catalogs and per-request locale selection
+// for the Quark framework. Messages are loaded from JSON or TOML files, one
+// per locale, and translated with fmt verbs and CLDR plural categories.
+//
+// Basic usage:
+//
+//	bundle := i18n.NewBundle("en")
+//	if err := bundle.LoadDir("locales"); err != nil { // en.json, fr.toml, pt-BR.json...
+//	    log.Fatal(err)
+//	}
+//
+//	app.Use(i18n.Middleware(bundle))
+//
+//	app.GET("/", func(c *quark.Context) error {
+//	    return c.JSON(200, quark.M{
+//	        "message": i18n.T(c, "welcome", user.Name),
+//	        "inbox":   i18n.TN(c, "inbox", unread),
+//	    })
+//	})
+//
+//	app.GET("/orders/{id}", func(c *quark.Context) error {
+//	    order, ok := orders[c.Param("id")]
+//	    if !ok {
+//	        return i18n.Error(c, 404, "order.not_found")
+//	    }
+//	    return c.JSON(200, order)
+//	})
+//
+// Catalog files map keys to messages; nested objects and TOML tables
+// produce dotted keys, and plural forms are stored under the CLDR
+// categories zero, one, two, few, many and other:
+//
+//	# locales/fr.toml
+//	welcome = "Bienvenue, %s !"
+//
+//	[inbox]
+//	one   = "%d nouveau message"
+//	other = "%d nouveaux messages"
+//
+//	[order]
+//	not_found = "Commande introuvable"
+//
+// A Bundle is a Catalog for the template package, so templates share the
+// messages and the locale picked by the middleware:
+//
+//	engine, err := template.New(template.Config{Dir: "templates", Catalog: bundle})
+//
+//	// <h1>{{t "welcome" .User.Name}}</h1>
+//	// <p>{{tn "inbox" .Unread}}</p>
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// bundleKey is the context store key of the Bundle used by the middleware.
+const bundleKey = "i18n.bundle"
+
+// Bundle holds the message catalogs of the supported locales. It is safe
+// for concurrent use.
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	locales       map[string]string            // Normalized tag to tag as added
+	messages      map[string]map[string]string // Normalized tag to messages
+	rules         map[string]PluralRule        // Language to plural rule
+}
+
+// NewBundle returns an empty Bundle. Messages missing from a locale are
+// looked up in its base language, then in defaultLocale.
+func NewBundle(defaultLocale string) *Bundle {
+	b := &Bundle{
+		defaultLocale: defaultLocale,
+		locales:       make(map[string]string),
+		messages:      make(map[string]map[string]string),
+		rules:         make(map[string]PluralRule),
+	}
+	b.locales[normalize(defaultLocale)] = defaultLocale
+	return b
+}
+
+// DefaultLocale returns the locale used when no other matches.
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// AddMessages adds messages to the catalog of locale, replacing existing
+// messages with the same keys.
+//
+// Example:
+//
+//	bundle.AddMessages("en", map[string]string{
+//	    "welcome":     "Welcome, %s!",
+//	    "inbox.one":   "%d new message",
+//	    "inbox.other": "%d new messages",
+//	})
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tag := normalize(locale)
+	if _, ok := b.locales[tag]; !ok {
+		b.locales[tag] = locale
+	}
+	catalog := b.messages[tag]
+	if catalog == nil {
+		catalog = make(map[string]string, len(messages))
+		b.messages[tag] = catalog
+	}
+	for key, msg := range messages {
+		catalog[key] = msg
+	}
+}
+
+// Locales returns the supported locales, the default locale first and the
+// others in alphabetical order, as expected by quark.Context.Locale.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.locales))
+	for tag, locale := range b.locales {
+		if tag != normalize(b.defaultLocale) {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{b.defaultLocale}, locales...)
+}
+
+// Match returns the supported locale matching tag exactly or by base
+// language, so "pt-BR" matches "pt" and "en" matches "en-US", or "" when
+// none does.
+func (b *Bundle) Match(tag string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	tag = normalize(tag)
+	if locale, ok := b.locales[tag]; ok {
+		return locale
+	}
+	base := baseLanguage(tag)
+	if locale, ok := b.locales[base]; ok {
+		return locale
+	}
+	// Prefer the default locale among regional variants of the language
+	if baseLanguage(normalize(b.defaultLocale)) == base {
+		return b.defaultLocale
+	}
+	for _, locale := range b.sortedLocales() {
+		if baseLanguage(normalize(locale)) == base {
+			return locale
+		}
+	}
+	return ""
+}
+
+// sortedLocales returns the locales in alphabetical order. Callers hold
+// the lock.
+func (b *Bundle) sortedLocales() []string {
+	locales := make([]string, 0, len(b.locales))
+	for _, locale := range b.locales {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Translate returns the message for key in locale, formatted with args.
+// Missing messages render as the key, so untranslated strings are easy to
+// spot.
+func (b *Bundle) Translate(locale, key string, args ...interface{}) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return format(msg, args)
+}
+
+// TranslatePlural returns the plural form of key for count in locale,
+// formatted with count followed by args. The form is chosen by the plural
+// rule of the locale's language and stored under key+"."+category, falling
+// back to key+".other", then to key itself.
+func (b *Bundle) TranslatePlural(locale, key string, count int, args ...interface{}) string {
+	args = append([]interface{}{count}, args...)
+	for _, form := range []string{key + "." + b.PluralRule(locale)(count), key + ".other", key} {
+		if msg, ok := b.lookup(locale, form); ok {
+			return format(msg, args)
+		}
+	}
+	return key
+}
+
+// lookup finds the message for key in locale, its base language or the
+// default locale.
+func (b *Bundle) lookup(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	tag := normalize(locale)
+	for _, candidate := range []string{tag, baseLanguage(tag), normalize(b.defaultLocale)} {
+		if msg, ok := b.messages[candidate][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// format applies args to msg when it contains verbs.
+func format(msg string, args []interface{}) string {
+	if len(args) == 0 || !strings.Contains(msg, "%") {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// normalize lower-cases a language tag and uses "-" as separator.
+func normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// baseLanguage returns the language subtag of a normalized tag, e.g. "pt"
+// for "pt-br".
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return base
+}
+
+// BundleOf returns the Bundle installed by Middleware, or nil.
+func BundleOf(c *quark.Context) *Bundle {
+	b, _ := c.Get(bundleKey).(*Bundle)
+	return b
+}
+
+// Locale returns the locale of the request, as picked by Middleware.
+func Locale(c *quark.Context) string {
+	if locale := c.GetString(quark.LocaleKey); locale != "" {
+		return locale
+	}
+	if b := BundleOf(c); b != nil {
+		return b.defaultLocale
+	}
+	return ""
+}
+
+// T returns the message for key in the request locale, formatted with
+// args. Without Middleware the key is returned.
+func T(c *quark.Context, key string, args ...interface{}) string {
+	b := BundleOf(c)
+	if b == nil {
+		return key
+	}
+	return b.Translate(Locale(c), key, args...)
+}
+
+// TN returns the plural form of key for count in the request locale,
+// formatted with count followed by args.
+func TN(c *quark.Context, key string, count int, args ...interface{}) string {
+	b := BundleOf(c)
+	if b == nil {
+		return key
+	}
+	return b.TranslatePlural(Locale(c), key, count, args...)
+}
+
+// Error returns an HTTP error whose message is key translated in the
+// request locale. The key is kept in the error's Details, so clients can
+// still tell errors apart whatever the language.
+//
+// Example:
+//
+//	return i18n.Error(c, http.StatusConflict, "user.email_taken", req.Email)
+//	// {"error":{"code":409,"details":{"key":"user.email_taken"},"message":"Cette adresse est déjà utilisée : a@b.c"}}
+func Error(c *quark.Context, code int, key string, args ...interface{}) *quark.HTTPError {
+	err := quark.NewHTTPError(code, T(c, key, args...))
+	err.Details = map[string]string{"key": key}
+	return err
+}
@@ -0,0 +1,230 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadFile loads the catalog of one locale from a JSON or TOML file named
+// after the locale, such as "fr.toml" or "pt-BR.json".
+func (b *Bundle) LoadFile(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	return b.load(filepath.Base(name), data)
+}
+
+// LoadDir loads every JSON and TOML catalog in dir, see LoadFile.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS loads every JSON and TOML catalog in a directory of fsys, such as
+// an embedded filesystem.
+//
+// Example:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	err := bundle.LoadFS(locales, "locales")
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if entry.IsDir() || ext != ".json" && ext != ".toml" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		if err := b.load(entry.Name(), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load parses a catalog file and adds its messages to the locale named by
+// the file.
+func (b *Bundle) load(name string, data []byte) error {
+	ext := path.Ext(name)
+	locale := strings.TrimSuffix(name, ext)
+	if locale == "" {
+		return fmt.Errorf("i18n: %s: file name must be a locale", name)
+	}
+
+	messages := make(map[string]string)
+	var err error
+	switch strings.ToLower(ext) {
+	case ".json":
+		var tree map[string]interface{}
+		if err = json.Unmarshal(data, &tree); err == nil {
+			err = flatten(messages, "", tree)
+		}
+	case ".toml":
+		err = parseTOML(messages, data)
+	default:
+		err = fmt.Errorf("unsupported format %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: %s: %w", name, err)
+	}
+
+	b.AddMessages(locale, messages)
+	return nil
+}
+
+// flatten adds the strings of a JSON object to messages under dotted keys.
+func flatten(messages map[string]string, prefix string, tree map[string]interface{}) error {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			messages[key] = v
+		case map[string]interface{}:
+			if err := flatten(messages, key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %s must be a string or an object", key)
+		}
+	}
+	return nil
+}
+
+// parseTOML adds the strings of a TOML catalog to messages under dotted
+// keys. Only tables, dotted keys and single-line strings are supported.
+func parseTOML(messages map[string]string, data []byte) error {
+	table := ""
+	for i, raw := range strings.Split(string(data), "\n") {
+		num := i + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") || !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: invalid table header", num)
+			}
+			keys, err := tomlKey(line[1 : len(line)-1])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", num, err)
+			}
+			table = keys
+			continue
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return fmt.Errorf("line %d: expected \"key = value\"", num)
+		}
+		key, err := tomlKey(line[:eq])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", num, err)
+		}
+		value, err := tomlString(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", num, err)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+		messages[key] = value
+	}
+	return nil
+}
+
+// tomlKey parses a bare, quoted or dotted key into its dotted form.
+func tomlKey(s string) (string, error) {
+	var keys []string
+	for _, part := range splitKey(s) {
+		part = strings.TrimSpace(part)
+		if len(part) >= 2 && (part[0] == '"' || part[0] == '\'') {
+			unquoted, err := tomlString(part)
+			if err != nil {
+				return "", err
+			}
+			part = unquoted
+		}
+		if part == "" {
+			return "", fmt.Errorf("empty key in %q", s)
+		}
+		keys = append(keys, part)
+	}
+	return strings.Join(keys, "."), nil
+}
+
+// tomlString parses a single-line basic or literal string.
+func tomlString(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return unquoted, nil
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], nil
+	}
+	return "", fmt.Errorf("message %s must be a string", s)
+}
+
+// splitKey splits a dotted key at dots outside quotes.
+func splitKey(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stripComment removes a "#" comment outside quotes from a line.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
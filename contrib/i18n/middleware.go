@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"net/http"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Config holds i18n middleware configuration.
+type Config struct {
+	// Bundle holds the messages and supported locales (required).
+	Bundle *Bundle
+
+	// QueryParam is the query parameter overriding the locale, e.g.
+	// "?lang=fr" (default: "lang"). Set to "-" to disable.
+	QueryParam string
+
+	// CookieName is the cookie holding the preferred locale
+	// (default: "lang"). Set to "-" to disable.
+	CookieName string
+
+	// SetCookie remembers a locale chosen with the query parameter in the
+	// cookie for later requests (default: false).
+	SetCookie bool
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+}
+
+// Middleware returns a middleware that picks the locale of each request
+// with the default configuration, see MiddlewareWithConfig.
+func Middleware(bundle *Bundle) quark.MiddlewareFunc {
+	return MiddlewareWithConfig(Config{Bundle: bundle})
+}
+
+// MiddlewareWithConfig returns a middleware that picks the locale of each
+// request among the locales of the bundle: from the query parameter, then
+// the cookie, then the Accept-Language header, falling back to the default
+// locale. The locale is stored under quark.LocaleKey, where T, TN and the
+// template package read it, and sent in the Content-Language header.
+func MiddlewareWithConfig(config Config) quark.MiddlewareFunc {
+	if config.Bundle == nil {
+		panic("i18n middleware requires a Bundle")
+	}
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			locale := ""
+			if config.QueryParam != "-" {
+				if locale = config.Bundle.Match(c.Query(config.QueryParam)); locale != "" && config.SetCookie && config.CookieName != "-" {
+					http.SetCookie(c.Writer, &http.Cookie{
+						Name:     config.CookieName,
+						Value:    locale,
+						Path:     "/",
+						MaxAge:   365 * 24 * 60 * 60,
+						HttpOnly: true,
+						SameSite: http.SameSiteLaxMode,
+					})
+				}
+			}
+			if locale == "" && config.CookieName != "-" {
+				if cookie, err := c.Request.Cookie(config.CookieName); err == nil {
+					locale = config.Bundle.Match(cookie.Value)
+				}
+			}
+			if locale == "" {
+				c.Writer.Header().Add("Vary", "Accept-Language")
+				locale = c.Locale(config.Bundle.Locales()...)
+			}
+
+			c.Set(quark.LocaleKey, locale)
+			c.Set(bundleKey, config.Bundle)
+			c.SetHeader("Content-Language", locale)
+			return next(c)
+		}
+	}
+}
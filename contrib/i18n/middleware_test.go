@@ -0,0 +1,149 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// newTestBundle returns a bundle supporting en, fr and pt-BR.
+func newTestBundle() *Bundle {
+	b := NewBundle("en")
+	b.AddMessages("en", map[string]string{"welcome": "Welcome, %s!"})
+	b.AddMessages("fr", map[string]string{"welcome": "Bienvenue, %s !"})
+	b.AddMessages("pt-BR", map[string]string{"welcome": "Bem-vindo, %s!"})
+	return b
+}
+
+func TestMatch(t *testing.T) {
+	b := newTestBundle()
+	b.AddMessages("en-GB", nil)
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"fr", "fr"},
+		{"FR", "fr"},
+		{"fr-CA", "fr"},
+		{"pt_br", "pt-BR"},
+		{"pt", "pt-BR"},
+		{"pt-PT", "pt-BR"},
+		{"en-US", "en"},
+		{"en-GB", "en-GB"},
+		{"de", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := b.Match(tt.tag); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if got := b.Locales(); len(got) != 4 || got[0] != "en" || got[1] != "en-GB" || got[2] != "fr" || got[3] != "pt-BR" {
+		t.Errorf("expected the default locale first, got %v", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         Config
+		url            string
+		acceptLanguage string
+		cookie         string
+		want           string
+		wantMessage    string
+		wantVary       bool
+		wantSetCookie  bool
+	}{
+		{name: "default", url: "/", want: "en", wantMessage: "Welcome, Ada!", wantVary: true},
+		{name: "accept language", url: "/", acceptLanguage: "de;q=0.9, fr-CH;q=0.8, en;q=0.5", want: "fr", wantMessage: "Bienvenue, Ada !", wantVary: true},
+		{name: "base language", url: "/", acceptLanguage: "pt", want: "pt-BR", wantMessage: "Bem-vindo, Ada!", wantVary: true},
+		{name: "refused language", url: "/", acceptLanguage: "fr;q=0, de", want: "en", wantMessage: "Welcome, Ada!", wantVary: true},
+		{name: "cookie", url: "/", acceptLanguage: "fr", cookie: "pt-BR", want: "pt-BR", wantMessage: "Bem-vindo, Ada!"},
+		{name: "unsupported cookie", url: "/", acceptLanguage: "fr", cookie: "de", want: "fr", wantMessage: "Bienvenue, Ada !", wantVary: true},
+		{name: "query", url: "/?lang=fr", acceptLanguage: "pt", cookie: "pt-BR", want: "fr", wantMessage: "Bienvenue, Ada !"},
+		{name: "query sets cookie", config: Config{SetCookie: true}, url: "/?lang=fr", want: "fr", wantMessage: "Bienvenue, Ada !", wantSetCookie: true},
+		{name: "unsupported query", config: Config{SetCookie: true}, url: "/?lang=de", want: "en", wantMessage: "Welcome, Ada!", wantVary: true},
+		{name: "custom query", config: Config{QueryParam: "locale"}, url: "/?locale=fr&lang=pt", want: "fr", wantMessage: "Bienvenue, Ada !"},
+		{name: "query disabled", config: Config{QueryParam: "-"}, url: "/?lang=fr", want: "en", wantMessage: "Welcome, Ada!", wantVary: true},
+		{name: "cookie disabled", config: Config{CookieName: "-"}, url: "/", cookie: "fr", want: "en", wantMessage: "Welcome, Ada!", wantVary: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Bundle = newTestBundle()
+
+			app := quark.New()
+			app.Use(MiddlewareWithConfig(config))
+			app.GET("/", func(c *quark.Context) error {
+				return c.JSON(http.StatusOK, quark.M{"locale": Locale(c), "message": T(c, "welcome", "Ada")})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			want := `{"locale":"` + tt.want + `","message":"` + tt.wantMessage + `"}` + "\n"
+			if rec.Body.String() != want {
+				t.Errorf("expected %s, got %s", want, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Language"); got != tt.want {
+				t.Errorf("expected Content-Language %q, got %q", tt.want, got)
+			}
+			if vary := rec.Header().Get("Vary") == "Accept-Language"; vary != tt.wantVary {
+				t.Errorf("expected Vary on Accept-Language %v, got %q", tt.wantVary, rec.Header().Get("Vary"))
+			}
+			cookies := rec.Result().Cookies()
+			if tt.wantSetCookie != (len(cookies) == 1 && cookies[0].Name == "lang" && cookies[0].Value == tt.want) {
+				t.Errorf("expected a lang cookie %v, got %v", tt.wantSetCookie, cookies)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSkipper(t *testing.T) {
+	app := quark.New()
+	app.Use(MiddlewareWithConfig(Config{
+		Bundle:  newTestBundle(),
+		Skipper: func(c *quark.Context) bool { return true },
+	}))
+	app.GET("/", func(c *quark.Context) error {
+		return c.String(http.StatusOK, Locale(c)+"|"+T(c, "welcome"))
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "|welcome" {
+		t.Errorf("expected no locale and untranslated keys, got %q", rec.Body.String())
+	}
+}
+
+func TestError(t *testing.T) {
+	app := quark.New()
+	app.Use(Middleware(newTestBundle()))
+	app.GET("/", func(c *quark.Context) error {
+		return Error(c, http.StatusNotFound, "welcome", "Ada")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	want := `{"error":{"code":404,"details":{"key":"welcome"},"message":"Bienvenue, Ada !"}}` + "\n"
+	if rec.Code != http.StatusNotFound || rec.Body.String() != want {
+		t.Errorf("expected 404 %s, got %d %s", want, rec.Code, rec.Body.String())
+	}
+}
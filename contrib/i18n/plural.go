@@ -0,0 +1,163 @@
+package i18n
+
+// CLDR plural categories, the suffixes of plural message keys.
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// PluralRule returns the plural category of a count.
+type PluralRule func(n int) string
+
+// Plural rules of common languages, following the CLDR rules for integers.
+var (
+	// PluralOneOther is the rule of English, German, Spanish and most
+	// Germanic and Romance languages: one for 1, other otherwise.
+	PluralOneOther PluralRule = func(n int) string {
+		if n == 1 {
+			return One
+		}
+		return Other
+	}
+
+	// PluralZeroOne is the rule of French and Portuguese: one for 0 and 1.
+	PluralZeroOne PluralRule = func(n int) string {
+		if n == 0 || n == 1 {
+			return One
+		}
+		return Other
+	}
+
+	// PluralEastSlavic is the rule of Russian, Ukrainian and the
+	// Serbo-Croatian languages: one for 1, 21, 31..., few for 2-4, 22-24...,
+	// many otherwise.
+	PluralEastSlavic PluralRule = func(n int) string {
+		n = abs(n)
+		switch mod10, mod100 := n%10, n%100; {
+		case mod10 == 1 && mod100 != 11:
+			return One
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return Few
+		}
+		return Many
+	}
+
+	// PluralPolish is the rule of Polish: one for 1 only, few for 2-4,
+	// 22-24..., many otherwise.
+	PluralPolish PluralRule = func(n int) string {
+		n = abs(n)
+		switch mod10, mod100 := n%10, n%100; {
+		case n == 1:
+			return One
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return Few
+		}
+		return Many
+	}
+
+	// PluralWestSlavic is the rule of Czech and Slovak: one for 1, few for
+	// 2-4, other otherwise.
+	PluralWestSlavic PluralRule = func(n int) string {
+		switch {
+		case n == 1:
+			return One
+		case n >= 2 && n <= 4:
+			return Few
+		}
+		return Other
+	}
+
+	// PluralArabic is the rule of Arabic, which uses all six categories.
+	PluralArabic PluralRule = func(n int) string {
+		n = abs(n)
+		switch mod100 := n % 100; {
+		case n == 0:
+			return Zero
+		case n == 1:
+			return One
+		case n == 2:
+			return Two
+		case mod100 >= 3 && mod100 <= 10:
+			return Few
+		case mod100 >= 11:
+			return Many
+		}
+		return Other
+	}
+
+	// PluralNone is the rule of languages without plural forms, such as
+	// Chinese, Japanese and Korean: always other.
+	PluralNone PluralRule = func(n int) string {
+		return Other
+	}
+)
+
+// pluralRules maps languages to their plural rule. Languages not listed
+// use PluralOneOther.
+var pluralRules = map[string]PluralRule{
+	"fr": PluralZeroOne,
+	"pt": PluralZeroOne,
+	"ru": PluralEastSlavic,
+	"uk": PluralEastSlavic,
+	"be": PluralEastSlavic,
+	"sr": PluralEastSlavic,
+	"hr": PluralEastSlavic,
+	"bs": PluralEastSlavic,
+	"pl": PluralPolish,
+	"cs": PluralWestSlavic,
+	"sk": PluralWestSlavic,
+	"ar": PluralArabic,
+	"zh": PluralNone,
+	"ja": PluralNone,
+	"ko": PluralNone,
+	"vi": PluralNone,
+	"th": PluralNone,
+	"id": PluralNone,
+	"ms": PluralNone,
+}
+
+// SetPluralRule sets the plural rule of a language or locale, overriding
+// the built-in one.
+//
+// Example:
+//
+//	bundle.SetPluralRule("pt-PT", i18n.PluralOneOther) // 0 is plural in European Portuguese
+func (b *Bundle) SetPluralRule(locale string, rule PluralRule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rules[normalize(locale)] = rule
+}
+
+// PluralRule returns the plural rule of a locale: the one set for the
+// locale or its base language, otherwise the built-in rule of the
+// language.
+func (b *Bundle) PluralRule(locale string) PluralRule {
+	tag := normalize(locale)
+	base := baseLanguage(tag)
+
+	b.mu.RLock()
+	rule, ok := b.rules[tag]
+	if !ok {
+		rule, ok = b.rules[base]
+	}
+	b.mu.RUnlock()
+	if ok {
+		return rule
+	}
+	if rule, ok := pluralRules[base]; ok {
+		return rule
+	}
+	return PluralOneOther
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
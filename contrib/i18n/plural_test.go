@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule PluralRule
+		want map[int]string
+	}{
+		{"one other", PluralOneOther, map[int]string{0: Other, 1: One, 2: Other, 11: Other, 21: Other}},
+		{"zero one", PluralZeroOne, map[int]string{0: One, 1: One, 2: Other, 100: Other}},
+		{"east slavic", PluralEastSlavic, map[int]string{
+			0: Many, 1: One, 2: Few, 4: Few, 5: Many, 11: Many, 12: Many, 14: Many,
+			21: One, 22: Few, 25: Many, 101: One, 111: Many, 112: Many, 122: Few, -1: One, -3: Few,
+		}},
+		{"polish", PluralPolish, map[int]string{
+			0: Many, 1: One, 2: Few, 4: Few, 5: Many, 12: Many, 21: Many, 22: Few, 112: Many, 124: Few,
+		}},
+		{"west slavic", PluralWestSlavic, map[int]string{0: Other, 1: One, 2: Few, 4: Few, 5: Other, 22: Other}},
+		{"arabic", PluralArabic, map[int]string{
+			0: Zero, 1: One, 2: Two, 3: Few, 10: Few, 11: Many, 99: Many, 100: Other, 101: Other, 102: Other, 103: Few, 111: Many,
+		}},
+		{"none", PluralNone, map[int]string{0: Other, 1: Other, 2: Other}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for n, want := range tt.want {
+				if got := tt.rule(n); got != want {
+					t.Errorf("%d: expected %s, got %s", n, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBundlePluralRule(t *testing.T) {
+	b := NewBundle("en")
+	b.SetPluralRule("pt-PT", PluralOneOther)
+	b.SetPluralRule("de", PluralNone)
+
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 0, Other},
+		{"fr", 0, One},
+		{"fr-CA", 0, One},
+		{"pt_BR", 0, One},
+		{"pt-PT", 0, Other}, // Set for the locale
+		{"de-AT", 1, Other}, // Set for the base language
+		{"RU", 21, One},
+		{"xx", 1, One}, // Unknown languages use one/other
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.locale, tt.n), func(t *testing.T) {
+			if got := b.PluralRule(tt.locale)(tt.n); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTranslatePlural(t *testing.T) {
+	b := NewBundle("en")
+	b.AddMessages("en", map[string]string{
+		"inbox.one":   "%d new message",
+		"inbox.other": "%d new messages",
+		"files.other": "%d files in %s",
+		"apples":      "apples",
+	})
+	b.AddMessages("fr", map[string]string{
+		"inbox.one":   "%d nouveau message",
+		"inbox.other": "%d nouveaux messages",
+	})
+	b.AddMessages("ru", map[string]string{
+		"inbox.one":  "%d новое сообщение",
+		"inbox.few":  "%d новых сообщения",
+		"inbox.many": "%d новых сообщений",
+	})
+
+	tests := []struct {
+		locale string
+		key    string
+		count  int
+		args   []interface{}
+		want   string
+	}{
+		{"en", "inbox", 1, nil, "1 new message"},
+		{"en", "inbox", 0, nil, "0 new messages"},
+		{"fr", "inbox", 0, nil, "0 nouveau message"},
+		{"fr", "inbox", 2, nil, "2 nouveaux messages"},
+		{"ru", "inbox", 21, nil, "21 новое сообщение"},
+		{"ru", "inbox", 3, nil, "3 новых сообщения"},
+		{"ru", "inbox", 5, nil, "5 новых сообщений"},
+		{"en", "files", 1, []interface{}{"docs"}, "1 files in docs"}, // Falls back to other
+		{"fr", "files", 2, []interface{}{"docs"}, "2 files in docs"}, // Falls back to the default locale
+		{"en", "apples", 3, nil, "apples"},                           // Falls back to the key's message
+		{"en", "missing", 3, nil, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s/%d", tt.locale, tt.key, tt.count), func(t *testing.T) {
+			if got := b.TranslatePlural(tt.locale, tt.key, tt.count, tt.args...); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // Register SHA-384 and SHA-512 for crypto.Hash
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidKey is returned when the configured key does not match the
+// signing algorithm (e.g., an RSA key used with ES256).
+var ErrInvalidKey = errors.New("invalid key for algorithm")
+
+// signingMethod signs and verifies JWS signing inputs for one algorithm.
+type signingMethod interface {
+	// sign returns the raw signature of input using key.
+	sign(input []byte, key interface{}) ([]byte, error)
+	// verify checks a raw signature of input using key.
+	verify(input, signature []byte, key interface{}) error
+}
+
+// signingMethods maps the supported "alg" header values to their
+// implementation.
+var signingMethods = map[string]signingMethod{
+	AlgorithmHS256: hmacMethod{crypto.SHA256},
+	AlgorithmHS384: hmacMethod{crypto.SHA384},
+	AlgorithmHS512: hmacMethod{crypto.SHA512},
+	AlgorithmRS256: rsaMethod{crypto.SHA256},
+	AlgorithmRS384: rsaMethod{crypto.SHA384},
+	AlgorithmRS512: rsaMethod{crypto.SHA512},
+	AlgorithmES256: ecdsaMethod{crypto.SHA256, 32},
+	AlgorithmES384: ecdsaMethod{crypto.SHA384, 48},
+	AlgorithmES512: ecdsaMethod{crypto.SHA512, 66},
+	AlgorithmEdDSA: eddsaMethod{},
+}
+
+// IsSymmetric reports whether alg is an HMAC algorithm that signs and
+// verifies with a shared secret.
+func IsSymmetric(alg string) bool {
+	_, ok := signingMethods[alg].(hmacMethod)
+	return ok
+}
+
+// hmacMethod implements HS256, HS384 and HS512. Keys are []byte secrets.
+type hmacMethod struct {
+	hash crypto.Hash
+}
+
+func (m hmacMethod) sign(input []byte, key interface{}) ([]byte, error) {
+	secret, ok := key.([]byte)
+	if !ok || len(secret) == 0 {
+		return nil, ErrInvalidKey
+	}
+	h := hmac.New(m.hash.New, secret)
+	h.Write(input)
+	return h.Sum(nil), nil
+}
+
+func (m hmacMethod) verify(input, signature []byte, key interface{}) error {
+	expected, err := m.sign(input, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(signature, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// rsaMethod implements RS256, RS384 and RS512 (RSASSA-PKCS1-v1_5).
+// Signing takes an *rsa.PrivateKey, verification an *rsa.PublicKey.
+type rsaMethod struct {
+	hash crypto.Hash
+}
+
+func (m rsaMethod) sign(input []byte, key interface{}) ([]byte, error) {
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return rsa.SignPKCS1v15(rand.Reader, priv, m.hash, digest(m.hash, input))
+}
+
+func (m rsaMethod) verify(input, signature []byte, key interface{}) error {
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidKey
+	}
+	if err := rsa.VerifyPKCS1v15(pub, m.hash, digest(m.hash, input), signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ecdsaMethod implements ES256, ES384 and ES512. Signatures use the JWS
+// fixed-size R || S encoding rather than ASN.1. Signing takes an
+// *ecdsa.PrivateKey, verification an *ecdsa.PublicKey.
+type ecdsaMethod struct {
+	hash    crypto.Hash
+	keySize int
+}
+
+func (m ecdsaMethod) sign(input []byte, key interface{}) ([]byte, error) {
+	priv, ok := key.(*ecdsa.PrivateKey)
+	if !ok || (priv.Curve.Params().BitSize+7)/8 != m.keySize {
+		return nil, ErrInvalidKey
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest(m.hash, input))
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 2*m.keySize)
+	r.FillBytes(signature[:m.keySize])
+	s.FillBytes(signature[m.keySize:])
+	return signature, nil
+}
+
+func (m ecdsaMethod) verify(input, signature []byte, key interface{}) error {
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok || (pub.Curve.Params().BitSize+7)/8 != m.keySize {
+		return ErrInvalidKey
+	}
+	if len(signature) != 2*m.keySize {
+		return ErrInvalidSignature
+	}
+
+	r := new(big.Int).SetBytes(signature[:m.keySize])
+	s := new(big.Int).SetBytes(signature[m.keySize:])
+	if !ecdsa.Verify(pub, digest(m.hash, input), r, s) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// eddsaMethod implements EdDSA with Ed25519 keys. Signing takes an
+// ed25519.PrivateKey, verification an ed25519.PublicKey.
+type eddsaMethod struct{}
+
+func (eddsaMethod) sign(input []byte, key interface{}) ([]byte, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok || len(priv) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.Sign(priv, input), nil
+}
+
+func (eddsaMethod) verify(input, signature []byte, key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return ErrInvalidKey
+	}
+	if !ed25519.Verify(pub, input, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// digest hashes input with the given hash function.
+func digest(hash crypto.Hash, input []byte) []byte {
+	h := hash.New()
+	h.Write(input)
+	return h.Sum(nil)
+}
+
+// lookupSigningMethod returns the implementation of alg.
+func lookupSigningMethod(alg string) (signingMethod, error) {
+	method, ok := signingMethods[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+	return method, nil
+}
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,11 @@ type Claims struct {
 	IssuedAt  int64  `json:"iat,omitempty"`
 	ID        string `json:"jti,omitempty"`
 
+	// Audiences are additional audiences. With any, the aud claim is
+	// encoded as an array holding Audience followed by Audiences; an aud
+	// array is decoded the same way.
+	Audiences []string `json:"-"`
+
 	// Custom claims (arbitrary data)
 	Custom map[string]interface{} `json:"-"`
 }
@@ -31,8 +37,10 @@ func (c Claims) MarshalJSON() ([]byte, error) {
 	if c.Subject != "" {
 		m["sub"] = c.Subject
 	}
-	if c.Audience != "" {
-		m["aud"] = c.Audience
+	if audiences := c.AllAudiences(); len(audiences) == 1 {
+		m["aud"] = audiences[0]
+	} else if len(audiences) > 1 {
+		m["aud"] = audiences
 	}
 	if c.ExpiresAt != 0 {
 		m["exp"] = c.ExpiresAt
@@ -70,8 +78,19 @@ func (c *Claims) UnmarshalJSON(data []byte) error {
 	if v, ok := m["sub"].(string); ok {
 		c.Subject = v
 	}
-	if v, ok := m["aud"].(string); ok {
+	switch v := m["aud"].(type) {
+	case string:
 		c.Audience = v
+	case []interface{}:
+		for _, item := range v {
+			if aud, ok := item.(string); ok {
+				if c.Audience == "" {
+					c.Audience = aud
+				} else {
+					c.Audiences = append(c.Audiences, aud)
+				}
+			}
+		}
 	}
 	if v, ok := m["exp"].(float64); ok {
 		c.ExpiresAt = int64(v)
@@ -102,6 +121,22 @@ func (c *Claims) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// AllAudiences returns the audiences of the aud claim: Audience followed
+// by Audiences, without empty values.
+func (c *Claims) AllAudiences() []string {
+	return acceptedValues(c.Audience, c.Audiences)
+}
+
+// HasAudience reports whether the token is intended for aud.
+func (c *Claims) HasAudience(aud string) bool {
+	for _, a := range c.AllAudiences() {
+		if a == aud {
+			return true
+		}
+	}
+	return false
+}
+
 // NewClaims creates a new Claims with the given subject and expiration.
 func NewClaims(subject string, expiresIn time.Duration) Claims {
 	now := time.Now()
@@ -203,6 +238,43 @@ func (c *Claims) GetStringSlice(key string) []string {
 	}
 }
 
+// Scopes returns the token's scopes and permissions: the space-delimited
+// "scope" claim (RFC 8693), the "scp" claim as a string or array, and the
+// "permissions" array, without duplicates.
+func (c *Claims) Scopes() []string {
+	var scopes []string
+	seen := make(map[string]bool)
+	add := func(values ...string) {
+		for _, v := range values {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				scopes = append(scopes, v)
+			}
+		}
+	}
+
+	for _, key := range []string{"scope", "scp"} {
+		if s, ok := c.Get(key).(string); ok {
+			add(strings.Fields(s)...)
+		} else {
+			add(c.GetStringSlice(key)...)
+		}
+	}
+	add(c.GetStringSlice("permissions")...)
+
+	return scopes
+}
+
+// HasScope reports whether the token grants the given scope or permission.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // IsExpired checks if the token has expired.
 func (c *Claims) IsExpired() bool {
 	if c.ExpiresAt == 0 {
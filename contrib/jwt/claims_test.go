@@ -0,0 +1,145 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClaimsAudienceJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims Claims
+		want   string
+	}{
+		{"none", Claims{Subject: "user1"}, `{"sub":"user1"}`},
+		{"single", Claims{Audience: "api"}, `{"aud":"api"}`},
+		{"several", Claims{Audience: "api", Audiences: []string{"admin-api"}}, `{"aud":["api","admin-api"]}`},
+		{"additional only", Claims{Audiences: []string{"admin-api"}}, `{"aud":"admin-api"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.claims)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestClaimsAudienceDecode(t *testing.T) {
+	tests := []struct {
+		name          string
+		json          string
+		wantAudience  string
+		wantAudiences []string
+	}{
+		{"string", `{"aud":"api"}`, "api", nil},
+		{"array", `{"aud":["api","admin-api","web"]}`, "api", []string{"admin-api", "web"}},
+		{"single element array", `{"aud":["api"]}`, "api", nil},
+		{"non-string elements", `{"aud":[1,"api",null,"web"]}`, "api", []string{"web"}},
+		{"missing", `{"sub":"user1"}`, "", nil},
+		{"invalid type", `{"aud":42}`, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims Claims
+			if err := json.Unmarshal([]byte(tt.json), &claims); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Audience != tt.wantAudience || fmt.Sprint(claims.Audiences) != fmt.Sprint(tt.wantAudiences) {
+				t.Errorf("expected %q and %v, got %q and %v", tt.wantAudience, tt.wantAudiences, claims.Audience, claims.Audiences)
+			}
+			if _, ok := claims.Custom["aud"]; ok {
+				t.Error("expected aud not to be a custom claim")
+			}
+		})
+	}
+}
+
+func TestClaimsHasAudience(t *testing.T) {
+	claims := Claims{Audience: "api", Audiences: []string{"", "admin-api"}}
+	if !claims.HasAudience("api") || !claims.HasAudience("admin-api") {
+		t.Error("expected both audiences")
+	}
+	if claims.HasAudience("web") || claims.HasAudience("") {
+		t.Error("expected unknown and empty audiences to be rejected")
+	}
+	if got := claims.AllAudiences(); fmt.Sprint(got) != "[api admin-api]" {
+		t.Errorf("expected [api admin-api], got %v", got)
+	}
+}
+
+func TestValidateIssuerAndAudience(t *testing.T) {
+	j := New(Config{
+		Secret:    testSecret,
+		Issuers:   []string{"https://auth.example.com", "https://legacy.example.com"},
+		Audience:  "api",
+		Audiences: []string{"admin-api"},
+	})
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr string
+	}{
+		{"issuer and audience", map[string]interface{}{"iss": "https://auth.example.com", "aud": "api", "exp": exp}, ""},
+		{"second issuer", map[string]interface{}{"iss": "https://legacy.example.com", "aud": "api", "exp": exp}, ""},
+		{"additional audience", map[string]interface{}{"iss": "https://auth.example.com", "aud": "admin-api", "exp": exp}, ""},
+		{"audience in array", map[string]interface{}{"iss": "https://auth.example.com", "aud": []string{"web", "admin-api"}, "exp": exp}, ""},
+		{"unknown issuer", map[string]interface{}{"iss": "https://evil.example.com", "aud": "api", "exp": exp},
+			"invalid issuer: expected https://auth.example.com or https://legacy.example.com, got https://evil.example.com"},
+		{"unknown audience", map[string]interface{}{"iss": "https://auth.example.com", "aud": []string{"web", "mobile"}, "exp": exp},
+			"invalid audience: expected api or admin-api, got web, mobile"},
+		{"missing audience", map[string]interface{}{"iss": "https://auth.example.com", "exp": exp}, "invalid audience"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := forge(map[string]interface{}{"alg": "HS256", "typ": "JWT"}, tt.claims, testSecret)
+			_, err := j.Parse(token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerateAudience(t *testing.T) {
+	j := New(Config{Secret: testSecret, Audience: "api", ExpiresIn: time.Hour})
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   string
+	}{
+		{"default audience", Claims{Subject: "user1"}, "[api]"},
+		{"explicit audiences kept", Claims{Subject: "user1", Audience: "api", Audiences: []string{"web"}}, "[api web]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := j.Generate(tt.claims)
+			if err != nil {
+				t.Fatalf("Generate: unexpected error: %v", err)
+			}
+			token, err := j.Parse(raw)
+			if err != nil {
+				t.Fatalf("Parse: unexpected error: %v", err)
+			}
+			if got := fmt.Sprint(token.Claims.AllAudiences()); got != tt.want {
+				t.Errorf("expected audiences %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// CookieOptions configures the cookie written by SetTokenCookie.
+//
+// Token cookies are always HttpOnly so scripts cannot read them, and Secure
+// unless Insecure is set. Zero values are replaced by the defaults below.
+//
+// Example:
+//
+//	app.POST("/login", func(c *quark.Context) error {
+//	    // Authenticate the user...
+//	    token, _ := jwtHandler.Generate(jwt.NewClaims(userID, time.Hour))
+//	    jwt.SetTokenCookie(c, token, jwt.CookieOptions{MaxAge: time.Hour})
+//	    return c.NoContent()
+//	})
+//
+//	// Read the token back from the cookie
+//	config := jwt.DefaultMiddlewareConfig(jwtHandler)
+//	config.TokenLookup = "cookie:token"
+//	app.Use(jwt.MiddlewareWithConfig(config))
+type CookieOptions struct {
+	// Name is the cookie name (default: "token", matching the
+	// "cookie:token" TokenLookup).
+	Name string
+
+	// Path is the cookie path (default: "/").
+	Path string
+
+	// Domain is the cookie domain (default: the request host).
+	Domain string
+
+	// MaxAge is the cookie lifetime. Zero makes a session cookie, which
+	// the browser drops when it closes.
+	MaxAge time.Duration
+
+	// SameSite is the SameSite policy (default: http.SameSiteLaxMode).
+	SameSite http.SameSite
+
+	// Insecure allows the cookie to be sent over plain HTTP, for local
+	// development only.
+	Insecure bool
+}
+
+// DefaultCookieOptions is the default token cookie configuration.
+var DefaultCookieOptions = CookieOptions{
+	Name:     "token",
+	Path:     "/",
+	SameSite: http.SameSiteLaxMode,
+}
+
+// SetTokenCookie writes token to an HttpOnly cookie.
+func SetTokenCookie(c *quark.Context, token string, opts CookieOptions) {
+	opts = opts.withDefaults()
+
+	cookie := opts.cookie(token)
+	if opts.MaxAge > 0 {
+		cookie.MaxAge = int(opts.MaxAge.Seconds())
+		cookie.Expires = time.Now().Add(opts.MaxAge)
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// ClearTokenCookie deletes the token cookie, e.g. on logout. opts must use
+// the same Name, Path and Domain as when the cookie was set.
+func ClearTokenCookie(c *quark.Context, opts CookieOptions) {
+	opts = opts.withDefaults()
+
+	cookie := opts.cookie("")
+	cookie.MaxAge = -1
+	cookie.Expires = time.Unix(0, 0)
+	http.SetCookie(c.Writer, cookie)
+}
+
+// withDefaults fills in zero fields from DefaultCookieOptions.
+func (o CookieOptions) withDefaults() CookieOptions {
+	if o.Name == "" {
+		o.Name = DefaultCookieOptions.Name
+	}
+	if o.Path == "" {
+		o.Path = DefaultCookieOptions.Path
+	}
+	if o.SameSite == 0 {
+		o.SameSite = DefaultCookieOptions.SameSite
+	}
+	return o
+}
+
+// cookie builds the token cookie without lifetime attributes.
+func (o CookieOptions) cookie(value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     o.Name,
+		Value:    value,
+		Path:     o.Path,
+		Domain:   o.Domain,
+		HttpOnly: true,
+		Secure:   !o.Insecure,
+		SameSite: o.SameSite,
+	}
+}
@@ -0,0 +1,392 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrVerifyOnly is returned when signing with a handler that only holds
+// verification keys, such as one created by NewFromJWKS.
+var ErrVerifyOnly = errors.New("handler can only verify tokens")
+
+// JWKSOptions configures a JWKS-backed JWT handler.
+type JWKSOptions struct {
+	// HTTPClient is used to download the key set (default: a client with a
+	// 10 second timeout).
+	HTTPClient *http.Client
+
+	// RefreshInterval is how long downloaded keys are used before the key
+	// set is downloaded again (default: 1 hour).
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval is the minimum time between downloads triggered by
+	// tokens with an unknown kid, protecting the provider from floods of
+	// forged tokens (default: 1 minute).
+	MinRefreshInterval time.Duration
+
+	// FetchTimeout bounds each download triggered by a lookup (default: 10
+	// seconds).
+	FetchTimeout time.Duration
+
+	// Issuer is the expected iss claim. Empty disables the check.
+	Issuer string
+
+	// Issuers are additional accepted issuers.
+	Issuers []string
+
+	// Audience is the expected aud claim. Empty disables the check.
+	Audience string
+
+	// Audiences are additional accepted audiences.
+	Audiences []string
+
+	// NotBeforeLeeway is the leeway for not before validation.
+	NotBeforeLeeway time.Duration
+
+	// ExpirationLeeway is the leeway for expiration validation.
+	ExpirationLeeway time.Duration
+}
+
+// JWK is a single JSON Web Key (RFC 7517). Only the members needed to
+// verify RSA, ECDSA and Ed25519 signatures are decoded.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS is a cache of verification keys downloaded from a JWKS endpoint.
+// Keys are downloaded again when the cache is older than RefreshInterval,
+// or when a token names a kid that is not cached (at most once per
+// MinRefreshInterval). Concurrent lookups share a single download.
+type JWKS struct {
+	url     string
+	options JWKSOptions
+
+	mu          sync.RWMutex
+	keyring     *Keyring
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	inflight    *jwksRefresh
+}
+
+// jwksRefresh is a download of the key set in progress.
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// errRefreshSkipped is returned by refresh when no download was made.
+var errRefreshSkipped = errors.New("JWKS refresh skipped")
+
+// NewFromJWKS creates a verify-only JWT handler whose keys come from a
+// JWKS endpoint, such as those published by Auth0, Keycloak or Cognito.
+// The key set is downloaded once before returning.
+//
+// Example:
+//
+//	verifier, err := jwt.NewFromJWKS(
+//	    "https://example.auth0.com/.well-known/jwks.json",
+//	    jwt.JWKSOptions{
+//	        Issuer:   "https://example.auth0.com/",
+//	        Audience: "https://api.example.com",
+//	    },
+//	)
+//	app.Use(jwt.Middleware(verifier))
+func NewFromJWKS(url string, opts JWKSOptions) (*JWT, error) {
+	jwks, err := NewJWKS(url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	j := New(Config{
+		Issuer:           opts.Issuer,
+		Issuers:          opts.Issuers,
+		Audience:         opts.Audience,
+		Audiences:        opts.Audiences,
+		NotBeforeLeeway:  opts.NotBeforeLeeway,
+		ExpirationLeeway: opts.ExpirationLeeway,
+	})
+	j.jwks = jwks
+	return j, nil
+}
+
+// NewJWKS creates a JWKS cache and downloads the key set.
+func NewJWKS(url string, opts JWKSOptions) (*JWKS, error) {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = time.Hour
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = time.Minute
+	}
+	if opts.FetchTimeout <= 0 {
+		opts.FetchTimeout = 10 * time.Second
+	}
+
+	jwks := &JWKS{url: url, options: opts}
+	if err := jwks.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+// Refresh downloads the key set and replaces the cached keys.
+func (s *JWKS) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.lastAttempt = time.Now()
+	s.mu.Unlock()
+	return s.fetch(ctx)
+}
+
+// refresh downloads the key set for a lookup, unless MinRefreshInterval
+// has not passed since the last attempt. The check and the claim of the
+// download are atomic, so concurrent lookups make a single download. With
+// wait, a lookup finding a download in progress waits for its result;
+// otherwise, like a throttled one, it gets errRefreshSkipped.
+func (s *JWKS) refresh(wait bool) error {
+	s.mu.Lock()
+	if call := s.inflight; call != nil {
+		s.mu.Unlock()
+		if !wait {
+			return errRefreshSkipped
+		}
+		<-call.done
+		return call.err
+	}
+	if time.Since(s.lastAttempt) < s.options.MinRefreshInterval {
+		s.mu.Unlock()
+		return errRefreshSkipped
+	}
+	call := &jwksRefresh{done: make(chan struct{})}
+	s.inflight = call
+	s.lastAttempt = time.Now()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.FetchTimeout)
+	call.err = s.fetch(ctx)
+	cancel()
+
+	s.mu.Lock()
+	s.inflight = nil
+	s.mu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// fetch downloads the key set and replaces the cached keys.
+func (s *JWKS) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keyring, err := set.Keyring()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keyring = keyring
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the verification key for a kid, downloading the key set
+// again when the cache is stale or the kid is unknown. An empty kid matches
+// the only key of a single-key set.
+func (s *JWKS) Lookup(kid string) (Key, error) {
+	s.mu.RLock()
+	stale := time.Since(s.fetchedAt) > s.options.RefreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		// The cached keys stay in use while another lookup downloads the
+		// key set, and after a failed download
+		_ = s.refresh(false)
+	}
+
+	key, err := s.lookup(kid)
+	if errors.Is(err, ErrKeyNotFound) {
+		switch refreshErr := s.refresh(true); {
+		case errors.Is(refreshErr, errRefreshSkipped):
+			return key, err
+		case refreshErr != nil:
+			return Key{}, refreshErr
+		}
+		key, err = s.lookup(kid)
+	}
+	return key, err
+}
+
+// lookup finds a key in the cached key set.
+func (s *JWKS) lookup(kid string) (Key, error) {
+	s.mu.RLock()
+	keyring := s.keyring
+	s.mu.RUnlock()
+
+	if kid == "" {
+		ids := keyring.IDs()
+		if len(ids) != 1 {
+			return Key{}, ErrMissingKeyID
+		}
+		kid = ids[0]
+	}
+	return keyring.Lookup(kid)
+}
+
+// Keyring converts the signature keys of the set into a keyring of
+// verification keys. Keys of unsupported types, or marked for encryption,
+// are skipped.
+func (set JWKSet) Keyring() (*Keyring, error) {
+	keyring := NewKeyring()
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.Key()
+		if err != nil {
+			if errors.Is(err, errUnsupportedJWK) {
+				continue
+			}
+			return nil, fmt.Errorf("invalid JWK %d: %w", i, err)
+		}
+		if key.ID == "" {
+			key.ID = fmt.Sprintf("#%d", i)
+		}
+		if err := keyring.Add(key); err != nil {
+			return nil, fmt.Errorf("invalid JWK %s: %w", key.ID, err)
+		}
+	}
+	return keyring, nil
+}
+
+// errUnsupportedJWK marks keys that cannot verify JWS signatures here.
+var errUnsupportedJWK = errors.New("unsupported JWK")
+
+// Key converts the JWK into a verification key. When the JWK has no alg
+// member, the algorithm is inferred from the key type and curve.
+func (k JWK) Key() (Key, error) {
+	key := Key{ID: k.KeyID, Algorithm: k.Algorithm}
+
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64URLDecode(k.N)
+		if err != nil || len(n) == 0 {
+			return Key{}, errors.New("invalid RSA modulus")
+		}
+		e, err := base64URLDecode(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return Key{}, errors.New("invalid RSA exponent")
+		}
+		key.PublicKey = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if key.Algorithm == "" {
+			key.Algorithm = AlgorithmRS256
+		}
+
+	case "EC":
+		curve, alg, size := ecCurve(k.Curve)
+		if curve == nil {
+			return Key{}, fmt.Errorf("%w: curve %q", errUnsupportedJWK, k.Curve)
+		}
+		x, errX := base64URLDecode(k.X)
+		y, errY := base64URLDecode(k.Y)
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return Key{}, errors.New("invalid EC coordinates")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid EC key: %w", err)
+		}
+		key.PublicKey = pub
+		if key.Algorithm == "" {
+			key.Algorithm = alg
+		}
+
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return Key{}, fmt.Errorf("%w: curve %q", errUnsupportedJWK, k.Curve)
+		}
+		x, err := base64URLDecode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return Key{}, errors.New("invalid Ed25519 key")
+		}
+		key.PublicKey = ed25519.PublicKey(x)
+		if key.Algorithm == "" {
+			key.Algorithm = AlgorithmEdDSA
+		}
+
+	default:
+		return Key{}, fmt.Errorf("%w: key type %q", errUnsupportedJWK, k.KeyType)
+	}
+
+	if _, ok := signingMethods[key.Algorithm]; !ok || IsSymmetric(key.Algorithm) {
+		return Key{}, fmt.Errorf("%w: algorithm %q", errUnsupportedJWK, key.Algorithm)
+	}
+	return key, nil
+}
+
+// ecCurve maps a JWK curve name to its curve, default algorithm and
+// coordinate size in bytes.
+func ecCurve(name string) (elliptic.Curve, string, int) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), AlgorithmES256, 32
+	case "P-384":
+		return elliptic.P384(), AlgorithmES384, 48
+	case "P-521":
+		return elliptic.P521(), AlgorithmES512, 66
+	default:
+		return nil, "", 0
+	}
+}
@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ecJWK returns the public JWK of an ECDSA P-256 key.
+func ecJWK(kid string, key *ecdsa.PrivateKey) JWK {
+	return JWK{
+		KeyType: "EC",
+		KeyID:   kid,
+		Curve:   "P-256",
+		X:       base64URLEncode(key.X.FillBytes(make([]byte, 32))),
+		Y:       base64URLEncode(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// jwksServer serves a key set, counting downloads and delaying each one.
+func jwksServer(t *testing.T, set JWKSet, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestJWKSConcurrentRefresh(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server, downloads := jwksServer(t, JWKSet{Keys: []JWK{ecJWK("k1", key)}}, 20*time.Millisecond)
+
+	jwks, err := NewJWKS(server.URL, JWKSOptions{MinRefreshInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("NewJWKS: unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jwks.Lookup("unknown"); !errors.Is(err, ErrKeyNotFound) {
+				t.Errorf("expected ErrKeyNotFound, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := downloads.Load(); n != 2 {
+		t.Errorf("expected one shared download after the initial one, got %d downloads", n)
+	}
+	if _, err := jwks.Lookup("k1"); err != nil {
+		t.Errorf("expected the known key, got %v", err)
+	}
+}
+
+func TestJWKSRefreshThrottled(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server, downloads := jwksServer(t, JWKSet{Keys: []JWK{ecJWK("k1", key)}}, 0)
+
+	jwks, err := NewJWKS(server.URL, JWKSOptions{})
+	if err != nil {
+		t.Fatalf("NewJWKS: unexpected error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := jwks.Lookup("forged"); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("expected ErrKeyNotFound, got %v", err)
+		}
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected unknown kids throttled by MinRefreshInterval, got %d downloads", n)
+	}
+}
+
+func TestJWKSFetchTimeout(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var hang atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hang.Load() {
+			<-r.Context().Done()
+			return
+		}
+		json.NewEncoder(w).Encode(JWKSet{Keys: []JWK{ecJWK("k1", key)}})
+	}))
+	defer server.Close()
+
+	jwks, err := NewJWKS(server.URL, JWKSOptions{
+		MinRefreshInterval: time.Nanosecond,
+		FetchTimeout:       20 * time.Millisecond,
+		HTTPClient:         &http.Client{},
+	})
+	if err != nil {
+		t.Fatalf("NewJWKS: unexpected error: %v", err)
+	}
+
+	// The provider stops answering
+	hang.Store(true)
+
+	start := time.Now()
+	if _, err := jwks.Lookup("unknown"); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected a download error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the download bounded by FetchTimeout, took %v", elapsed)
+	}
+	if _, err := jwks.Lookup("k1"); err != nil {
+		t.Errorf("expected the cached keys kept after a failed download, got %v", err)
+	}
+}
@@ -1,5 +1,6 @@
 // Package jwt provides JWT (JSON Web Token) utilities using only the standard library.
-// It implements HS256 (HMAC-SHA256) signing without external dependencies.
+// It implements HMAC (HS256/384/512), RSA (RS256/384/512), ECDSA (ES256/384/512)
+// and Ed25519 (EdDSA) signing without external dependencies.
 //
 // Basic usage:
 //
@@ -31,11 +32,19 @@
 //	    userID := claims.Subject
 //	    return c.JSON(200, quark.M{"user_id": userID})
 //	})
+//
+// Public-key algorithms:
+//
+//	privateKey, _ := jwt.LoadPrivateKeyFile("private.pem")
+//	issuer, _ := jwt.NewWithKeyPair(jwt.AlgorithmRS256, privateKey, nil)
+//
+//	// Services that only verify tokens need just the public key
+//	publicKey, _ := jwt.LoadPublicKeyFile("public.pem")
+//	verifier, _ := jwt.NewVerifier(jwt.AlgorithmRS256, publicKey)
 package jwt
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
+	"crypto"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -47,6 +56,15 @@ import (
 // Algorithm constants
 const (
 	AlgorithmHS256 = "HS256"
+	AlgorithmHS384 = "HS384"
+	AlgorithmHS512 = "HS512"
+	AlgorithmRS256 = "RS256"
+	AlgorithmRS384 = "RS384"
+	AlgorithmRS512 = "RS512"
+	AlgorithmES256 = "ES256"
+	AlgorithmES384 = "ES384"
+	AlgorithmES512 = "ES512"
+	AlgorithmEdDSA = "EdDSA"
 )
 
 // Common errors
@@ -62,6 +80,7 @@ var (
 type Header struct {
 	Algorithm string `json:"alg"`
 	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
 }
 
 // Token represents a parsed JWT.
@@ -75,15 +94,44 @@ type Token struct {
 
 // Config holds JWT configuration.
 type Config struct {
-	// Secret is the HMAC secret key.
+	// Algorithm is the signing algorithm (default: HS256). Tokens whose
+	// "alg" header differs are rejected.
+	Algorithm string
+
+	// Secret is the HMAC secret key (HS256, HS384, HS512).
 	Secret []byte
 
-	// Issuer is the token issuer (iss claim).
+	// PrivateKey is the signing key for RSA, ECDSA and EdDSA algorithms
+	// (*rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey).
+	// It is only required to issue tokens.
+	PrivateKey crypto.PrivateKey
+
+	// PublicKey is the verification key for RSA, ECDSA and EdDSA algorithms.
+	// When nil, it is derived from PrivateKey.
+	PublicKey crypto.PublicKey
+
+	// Keyring holds rotating keys identified by the "kid" header. When set,
+	// tokens are signed with the keyring's current key and verified with the
+	// key their kid names; Algorithm, Secret, PrivateKey and PublicKey are
+	// ignored.
+	Keyring *Keyring
+
+	// Issuer is the token issuer (iss claim). It is set on generated tokens
+	// and, when not empty, accepted during validation.
 	Issuer string
 
-	// Audience is the intended audience (aud claim).
+	// Issuers are additional accepted issuers. When Issuer or Issuers is
+	// set, tokens from any other issuer are rejected.
+	Issuers []string
+
+	// Audience is the intended audience (aud claim). It is set on generated
+	// tokens and, when not empty, accepted during validation.
 	Audience string
 
+	// Audiences are additional accepted audiences. When Audience or
+	// Audiences is set, a token must name at least one of them.
+	Audiences []string
+
 	// ExpiresIn is the token expiration duration.
 	ExpiresIn time.Duration
 
@@ -92,11 +140,17 @@ type Config struct {
 
 	// ExpirationLeeway is the leeway for expiration validation.
 	ExpirationLeeway time.Duration
+
+	// Revoker, when set, is consulted by Parse to reject revoked tokens.
+	// Generate then assigns a random jti to tokens without one so they can
+	// be revoked individually.
+	Revoker Revoker
 }
 
 // DefaultConfig returns a default JWT configuration.
 func DefaultConfig(secret []byte) Config {
 	return Config{
+		Algorithm:        AlgorithmHS256,
 		Secret:           secret,
 		ExpiresIn:        24 * time.Hour,
 		NotBeforeLeeway:  0,
@@ -107,10 +161,17 @@ func DefaultConfig(secret []byte) Config {
 // JWT is a JWT handler with configuration.
 type JWT struct {
 	config Config
+	jwks   *JWKS // Remote verification keys, set by NewFromJWKS
 }
 
 // New creates a new JWT handler with the given configuration.
 func New(config Config) *JWT {
+	if config.Algorithm == "" {
+		config.Algorithm = AlgorithmHS256
+	}
+	if config.PublicKey == nil && config.PrivateKey != nil {
+		config.PublicKey = publicKeyOf(config.PrivateKey)
+	}
 	return &JWT{config: config}
 }
 
@@ -119,6 +180,80 @@ func NewWithSecret(secret []byte) *JWT {
 	return New(DefaultConfig(secret))
 }
 
+// NewWithKeyPair creates a JWT handler that signs and verifies tokens with
+// an asymmetric key pair. publicKey may be nil, in which case it is derived
+// from privateKey. Other settings use the DefaultConfig values.
+func NewWithKeyPair(alg string, privateKey crypto.PrivateKey, publicKey crypto.PublicKey) (*JWT, error) {
+	config := DefaultConfig(nil)
+	config.Algorithm = alg
+	config.PrivateKey = privateKey
+	config.PublicKey = publicKey
+
+	j := New(config)
+	if err := j.checkKeys(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// NewVerifier creates a JWT handler that can only verify tokens, for
+// services that validate tokens issued elsewhere (e.g., by an identity
+// provider). Generate and Sign return an error.
+func NewVerifier(alg string, publicKey crypto.PublicKey) (*JWT, error) {
+	return NewWithKeyPair(alg, nil, publicKey)
+}
+
+// checkKeys verifies that the configured algorithm is supported and that
+// the configured keys suit it.
+func (j *JWT) checkKeys() error {
+	return j.configKey().check()
+}
+
+// configKey returns the key described by the handler configuration.
+func (j *JWT) configKey() Key {
+	return Key{
+		Algorithm:  j.config.Algorithm,
+		Secret:     j.config.Secret,
+		PrivateKey: j.config.PrivateKey,
+		PublicKey:  j.config.PublicKey,
+	}
+}
+
+// signingKey returns the key used to sign new tokens.
+func (j *JWT) signingKey() (Key, error) {
+	if j.jwks != nil {
+		return Key{}, ErrVerifyOnly
+	}
+	if j.config.Keyring != nil {
+		return j.config.Keyring.Current()
+	}
+	return j.configKey(), nil
+}
+
+// verificationKey returns the key used to verify a token with the given
+// header.
+func (j *JWT) verificationKey(header Header) (Key, error) {
+	if j.jwks != nil {
+		return j.jwks.Lookup(header.KeyID)
+	}
+	if j.config.Keyring == nil {
+		return j.configKey(), nil
+	}
+	if header.KeyID == "" {
+		return Key{}, ErrMissingKeyID
+	}
+	return j.config.Keyring.Lookup(header.KeyID)
+}
+
+// Algorithm returns the signing algorithm used by the handler. With a
+// keyring, it is the algorithm of the current key.
+func (j *JWT) Algorithm() string {
+	if key, err := j.signingKey(); err == nil {
+		return key.Algorithm
+	}
+	return j.config.Algorithm
+}
+
 // Generate creates a new JWT with the given claims.
 func (j *JWT) Generate(claims Claims) (string, error) {
 	now := time.Now()
@@ -133,18 +268,31 @@ func (j *JWT) Generate(claims Claims) (string, error) {
 	if claims.Issuer == "" && j.config.Issuer != "" {
 		claims.Issuer = j.config.Issuer
 	}
-	if claims.Audience == "" && j.config.Audience != "" {
+	if claims.Audience == "" && len(claims.Audiences) == 0 && j.config.Audience != "" {
 		claims.Audience = j.config.Audience
 	}
+	if claims.ID == "" && j.config.Revoker != nil {
+		id, err := randomToken()
+		if err != nil {
+			return "", err
+		}
+		claims.ID = id[:32]
+	}
 
 	return j.Sign(claims)
 }
 
 // Sign creates a JWT from claims.
 func (j *JWT) Sign(claims Claims) (string, error) {
+	key, err := j.signingKey()
+	if err != nil {
+		return "", err
+	}
+
 	header := Header{
-		Algorithm: AlgorithmHS256,
+		Algorithm: key.Algorithm,
 		Type:      "JWT",
+		KeyID:     key.ID,
 	}
 
 	headerJSON, err := json.Marshal(header)
@@ -161,64 +309,39 @@ func (j *JWT) Sign(claims Claims) (string, error) {
 	claimsEncoded := base64URLEncode(claimsJSON)
 
 	signingInput := headerEncoded + "." + claimsEncoded
-	signature := j.sign(signingInput)
+	signature, err := key.sign(signingInput)
+	if err != nil {
+		return "", err
+	}
 
 	return signingInput + "." + signature, nil
 }
 
 // Parse parses and validates a JWT string.
 func (j *JWT) Parse(tokenString string) (*Token, error) {
-	parts := strings.Split(tokenString, ".")
-	if len(parts) != 3 {
-		return nil, ErrInvalidToken
-	}
-
-	// Decode header
-	headerJSON, err := base64URLDecode(parts[0])
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode header: %w", err)
-	}
-
-	var header Header
-	if err := json.Unmarshal(headerJSON, &header); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal header: %w", err)
-	}
-
-	if header.Algorithm != AlgorithmHS256 {
-		return nil, fmt.Errorf("unsupported algorithm: %s", header.Algorithm)
-	}
-
-	// Decode claims
-	claimsJSON, err := base64URLDecode(parts[1])
+	token, err := j.parseWithoutValidation(tokenString)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode claims: %w", err)
-	}
-
-	var claims Claims
-	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal claims: %w", err)
-	}
-
-	// Verify signature
-	signingInput := parts[0] + "." + parts[1]
-	expectedSignature := j.sign(signingInput)
-
-	if !hmac.Equal([]byte(parts[2]), []byte(expectedSignature)) {
-		return nil, ErrInvalidSignature
+		return nil, err
 	}
 
 	// Validate claims
-	if err := j.validateClaims(&claims); err != nil {
+	if err := j.validateClaims(&token.Claims); err != nil {
 		return nil, err
 	}
 
-	return &Token{
-		Header:    header,
-		Claims:    claims,
-		Signature: parts[2],
-		Raw:       tokenString,
-		Valid:     true,
-	}, nil
+	// Check revocation
+	if j.config.Revoker != nil {
+		revoked, err := j.config.Revoker.IsRevoked(&token.Claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	token.Valid = true
+	return token, nil
 }
 
 // validateClaims validates the standard claims.
@@ -242,49 +365,39 @@ func (j *JWT) validateClaims(claims *Claims) error {
 	}
 
 	// Validate issuer if configured
-	if j.config.Issuer != "" && claims.Issuer != j.config.Issuer {
-		return fmt.Errorf("invalid issuer: expected %s, got %s", j.config.Issuer, claims.Issuer)
+	if issuers := acceptedValues(j.config.Issuer, j.config.Issuers); len(issuers) > 0 {
+		matched := false
+		for _, iss := range issuers {
+			if claims.Issuer == iss {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("invalid issuer: expected %s, got %s", strings.Join(issuers, " or "), claims.Issuer)
+		}
 	}
 
 	// Validate audience if configured
-	if j.config.Audience != "" && claims.Audience != j.config.Audience {
-		return fmt.Errorf("invalid audience: expected %s, got %s", j.config.Audience, claims.Audience)
-	}
-
-	return nil
-}
-
-// sign creates an HMAC-SHA256 signature.
-func (j *JWT) sign(input string) string {
-	h := hmac.New(sha256.New, j.config.Secret)
-	h.Write([]byte(input))
-	return base64URLEncode(h.Sum(nil))
-}
-
-// Refresh generates a new token with the same claims but extended expiration.
-func (j *JWT) Refresh(tokenString string) (string, error) {
-	token, err := j.Parse(tokenString)
-	if err != nil {
-		// Allow refreshing expired tokens
-		if !errors.Is(err, ErrExpiredToken) {
-			return "", err
+	if audiences := acceptedValues(j.config.Audience, j.config.Audiences); len(audiences) > 0 {
+		matched := false
+		for _, aud := range audiences {
+			if claims.HasAudience(aud) {
+				matched = true
+				break
+			}
 		}
-		// Re-parse without validation for refresh
-		token, err = j.parseWithoutValidation(tokenString)
-		if err != nil {
-			return "", err
+		if !matched {
+			return fmt.Errorf("invalid audience: expected %s, got %s",
+				strings.Join(audiences, " or "), strings.Join(claims.AllAudiences(), ", "))
 		}
 	}
 
-	// Update timestamps
-	now := time.Now()
-	token.Claims.IssuedAt = now.Unix()
-	token.Claims.ExpiresAt = now.Add(j.config.ExpiresIn).Unix()
-
-	return j.Sign(token.Claims)
+	return nil
 }
 
-// parseWithoutValidation parses a token without validating claims.
+// parseWithoutValidation parses a token and verifies its signature without
+// validating claims.
 func (j *JWT) parseWithoutValidation(tokenString string) (*Token, error) {
 	parts := strings.Split(tokenString, ".")
 	if len(parts) != 3 {
@@ -301,6 +414,15 @@ func (j *JWT) parseWithoutValidation(tokenString string) (*Token, error) {
 		return nil, fmt.Errorf("failed to unmarshal header: %w", err)
 	}
 
+	key, err := j.verificationKey(header)
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm != key.Algorithm {
+		return nil, fmt.Errorf("unsupported algorithm: %s", header.Algorithm)
+	}
+
+	// Decode claims
 	claimsJSON, err := base64URLDecode(parts[1])
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode claims: %w", err)
@@ -312,11 +434,8 @@ func (j *JWT) parseWithoutValidation(tokenString string) (*Token, error) {
 	}
 
 	// Verify signature
-	signingInput := parts[0] + "." + parts[1]
-	expectedSignature := j.sign(signingInput)
-
-	if !hmac.Equal([]byte(parts[2]), []byte(expectedSignature)) {
-		return nil, ErrInvalidSignature
+	if err := key.verify(header.Algorithm, parts[0]+"."+parts[1], parts[2]); err != nil {
+		return nil, err
 	}
 
 	return &Token{
@@ -328,6 +447,21 @@ func (j *JWT) parseWithoutValidation(tokenString string) (*Token, error) {
 	}, nil
 }
 
+// acceptedValues combines a single configured value with a list of
+// additional ones, ignoring empty strings.
+func acceptedValues(single string, more []string) []string {
+	var values []string
+	if single != "" {
+		values = append(values, single)
+	}
+	for _, v := range more {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // base64URLEncode encodes data using base64url encoding.
 func base64URLEncode(data []byte) string {
 	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
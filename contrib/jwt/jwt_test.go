@@ -0,0 +1,217 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// forge builds an HS256-signed token with an arbitrary header, for tokens
+// a handler would never issue.
+func forge(header, claims map[string]interface{}, secret []byte) string {
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	input := base64URLEncode(headerJSON) + "." + base64URLEncode(claimsJSON)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64URLEncode(mac.Sum(nil))
+}
+
+// mustSign signs claims with j.
+func mustSign(t *testing.T, j *JWT, claims Claims) string {
+	t.Helper()
+	token, err := j.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	return token
+}
+
+func TestParse(t *testing.T) {
+	j := New(Config{Secret: testSecret, Issuer: "quark", ExpirationLeeway: 30 * time.Second})
+	now := time.Now()
+	valid := Claims{Subject: "user1", Issuer: "quark", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	with := func(change func(*Claims)) Claims {
+		claims := valid
+		change(&claims)
+		return claims
+	}
+	hs512, _ := New(Config{Algorithm: AlgorithmHS512, Secret: testSecret}).Sign(valid)
+	tampered := mustSign(t, j, valid)
+	parts := strings.Split(tampered, ".")
+	tampered = parts[0] + "." + base64URLEncode([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+		errText string
+	}{
+		{name: "valid", token: mustSign(t, j, valid)},
+		{name: "expired", token: mustSign(t, j, with(func(c *Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() })), wantErr: ErrExpiredToken},
+		{name: "expired within leeway", token: mustSign(t, j, with(func(c *Claims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }))},
+		{name: "not yet valid", token: mustSign(t, j, with(func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() })), wantErr: ErrTokenNotYetValid},
+		{name: "wrong issuer", token: mustSign(t, j, with(func(c *Claims) { c.Issuer = "other" })), errText: "invalid issuer"},
+		{name: "other algorithm", token: hs512, errText: "unsupported algorithm"},
+		{name: "alg none", token: forge(map[string]interface{}{"alg": "none", "typ": "JWT"}, map[string]interface{}{"sub": "user1"}, nil), errText: "unsupported algorithm"},
+		{name: "tampered signature", token: tampered, wantErr: ErrInvalidSignature},
+		{name: "other secret", token: mustSign(t, New(Config{Secret: []byte("another secret of 32 bytes......")}), valid), wantErr: ErrInvalidSignature},
+		{name: "malformed", token: "not.a-token", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := j.Parse(tt.token)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("expected an error containing %q, got %v", tt.errText, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case !token.Valid || token.Claims.Subject != "user1":
+				t.Errorf("unexpected token %+v", token)
+			}
+		})
+	}
+}
+
+func TestAsymmetricAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherECKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPublic, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherEdKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		alg        string
+		private    crypto.PrivateKey
+		public     crypto.PublicKey
+		wrongKey   crypto.PublicKey
+		wantKeyErr bool
+	}{
+		{alg: AlgorithmRS256, private: rsaKey, public: &rsaKey.PublicKey},
+		{alg: AlgorithmRS512, private: rsaKey, public: &rsaKey.PublicKey},
+		{alg: AlgorithmES256, private: ecKey, public: &ecKey.PublicKey, wrongKey: &otherECKey.PublicKey},
+		{alg: AlgorithmES384, private: ec384Key, public: &ec384Key.PublicKey},
+		{alg: AlgorithmEdDSA, private: edKey, public: edPublic, wrongKey: otherEdKey.Public()},
+		{alg: AlgorithmES256, private: rsaKey, wantKeyErr: true},
+		{alg: AlgorithmRS256, private: edKey, wantKeyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %T", tt.alg, tt.private), func(t *testing.T) {
+			issuer, err := NewWithKeyPair(tt.alg, tt.private, nil)
+			if tt.wantKeyErr {
+				if err == nil {
+					t.Fatal("expected an error for a key of another type")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewWithKeyPair: unexpected error: %v", err)
+			}
+			token := mustSign(t, issuer, Claims{Subject: "user1"})
+
+			verifier, err := NewVerifier(tt.alg, tt.public)
+			if err != nil {
+				t.Fatalf("NewVerifier: unexpected error: %v", err)
+			}
+			parsed, err := verifier.Parse(token)
+			if err != nil || parsed.Claims.Subject != "user1" {
+				t.Fatalf("expected the token verified, got %v", err)
+			}
+			if _, err := verifier.Sign(Claims{Subject: "user1"}); err == nil {
+				t.Error("expected a verifier without a private key unable to sign")
+			}
+
+			if tt.wrongKey != nil {
+				other, err := NewVerifier(tt.alg, tt.wrongKey)
+				if err != nil {
+					t.Fatalf("NewVerifier: unexpected error: %v", err)
+				}
+				if _, err := other.Parse(token); !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("expected ErrInvalidSignature with another key, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAlgorithmConfusion(t *testing.T) {
+	// A token signed with HMAC using the public key as the secret must not
+	// pass an ECDSA verifier
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	verifier, err := NewVerifier(AlgorithmES256, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	token := forge(map[string]interface{}{"alg": "HS256", "typ": "JWT"}, map[string]interface{}{"sub": "admin"}, public)
+	if _, err := verifier.Parse(token); err == nil {
+		t.Error("expected an HS256 token rejected by an ES256 verifier")
+	}
+
+	issuer, _ := NewWithKeyPair(AlgorithmES256, key, nil)
+	if _, err := verifier.Parse(mustSign(t, issuer, Claims{Subject: "user1"})); err != nil {
+		t.Errorf("expected an ES256 token accepted, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	j := New(Config{Secret: testSecret, ExpiresIn: time.Hour})
+	app := quark.New()
+	app.Use(Middleware(j))
+	app.GET("/me", func(c *quark.Context) error {
+		return c.String(http.StatusOK, GetClaims(c).Subject)
+	})
+
+	token, _ := j.Generate(Claims{Subject: "user1"})
+	expired := mustSign(t, j, Claims{Subject: "user1", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK},
+		{"missing token", "", http.StatusUnauthorized},
+		{"other scheme", "Basic " + token, http.StatusUnauthorized},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package jwt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Keyring errors
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrMissingKeyID     = errors.New("token has no key id")
+	ErrNoCurrentKey     = errors.New("keyring has no current key")
+	ErrRemoveCurrentKey = errors.New("cannot remove the current key")
+)
+
+// Key is a signing or verification key identified by a key ID. The ID is
+// written to the "kid" header of signed tokens.
+type Key struct {
+	// ID is the key identifier (kid header).
+	ID string
+
+	// Algorithm is the signing algorithm used with this key (default: HS256).
+	Algorithm string
+
+	// Secret is the HMAC secret for HS256, HS384 and HS512.
+	Secret []byte
+
+	// PrivateKey is the signing key for asymmetric algorithms. It may be
+	// nil for keys that are only used to verify tokens.
+	PrivateKey crypto.PrivateKey
+
+	// PublicKey is the verification key for asymmetric algorithms. When nil,
+	// it is derived from PrivateKey.
+	PublicKey crypto.PublicKey
+}
+
+// normalize fills in the defaults of a key.
+func (k Key) normalize() Key {
+	if k.Algorithm == "" {
+		k.Algorithm = AlgorithmHS256
+	}
+	if k.PublicKey == nil && k.PrivateKey != nil {
+		k.PublicKey = publicKeyOf(k.PrivateKey)
+	}
+	return k
+}
+
+// check verifies that the algorithm is supported and that the key material
+// suits it. Private keys are only checked when present.
+func (k Key) check() error {
+	method, err := lookupSigningMethod(k.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	if IsSymmetric(k.Algorithm) {
+		if len(k.Secret) == 0 {
+			return fmt.Errorf("%w: %s requires a secret", ErrInvalidKey, k.Algorithm)
+		}
+		return nil
+	}
+
+	if k.PublicKey == nil {
+		return fmt.Errorf("%w: %s requires a public or private key", ErrInvalidKey, k.Algorithm)
+	}
+	// Verifying an empty signature exercises the key type check only
+	if err := method.verify(nil, nil, k.PublicKey); errors.Is(err, ErrInvalidKey) {
+		return fmt.Errorf("%w: %T cannot verify %s", ErrInvalidKey, k.PublicKey, k.Algorithm)
+	}
+	if k.PrivateKey != nil {
+		if _, err := method.sign(nil, k.PrivateKey); errors.Is(err, ErrInvalidKey) {
+			return fmt.Errorf("%w: %T cannot sign %s", ErrInvalidKey, k.PrivateKey, k.Algorithm)
+		}
+	}
+	return nil
+}
+
+// sign creates the encoded signature of a signing input.
+func (k Key) sign(input string) (string, error) {
+	method, err := lookupSigningMethod(k.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	key := interface{}(k.Secret)
+	if !IsSymmetric(k.Algorithm) {
+		if k.PrivateKey == nil {
+			return "", fmt.Errorf("%w: no private key configured for signing", ErrInvalidKey)
+		}
+		key = k.PrivateKey
+	}
+
+	signature, err := method.sign([]byte(input), key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return base64URLEncode(signature), nil
+}
+
+// verify checks the encoded signature of a signing input. alg is the
+// algorithm from the token header and must match the key's algorithm.
+func (k Key) verify(alg, input, signature string) error {
+	if alg != k.Algorithm {
+		return fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+	method, err := lookupSigningMethod(alg)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64URLDecode(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	key := interface{}(k.Secret)
+	if !IsSymmetric(alg) {
+		key = k.PublicKey
+	}
+	return method.verify([]byte(input), sig, key)
+}
+
+// Keyring holds the keys of a JWT handler for key rotation. Tokens are
+// signed with the current key and verified with whichever key their "kid"
+// header names, so rotating the current key does not invalidate tokens
+// issued with an older key until that key is removed.
+//
+// Example:
+//
+//	keyring := jwt.NewKeyring()
+//	keyring.Rotate(jwt.Key{ID: "2024-01", Secret: oldSecret})
+//
+//	handler := jwt.New(jwt.Config{Keyring: keyring, ExpiresIn: time.Hour})
+//
+//	// Later: sign new tokens with a new key, keep accepting the old one
+//	keyring.Rotate(jwt.Key{ID: "2024-02", Secret: newSecret})
+//
+//	// Once tokens signed with the old key have expired
+//	keyring.Remove("2024-01")
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]Key
+	current string
+}
+
+// NewKeyring creates an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]Key)}
+}
+
+// Add adds or replaces a verification key without changing the current key.
+func (k *Keyring) Add(key Key) error {
+	if key.ID == "" {
+		return fmt.Errorf("%w: key id is required", ErrInvalidKey)
+	}
+	key = key.normalize()
+	if err := key.check(); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key.ID] = key
+	return nil
+}
+
+// Rotate adds a key and makes it the current signing key.
+func (k *Keyring) Rotate(key Key) error {
+	if err := k.Add(key); err != nil {
+		return err
+	}
+	return k.SetCurrent(key.ID)
+}
+
+// SetCurrent makes an existing key the current signing key.
+func (k *Keyring) SetCurrent(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	k.current = id
+	return nil
+}
+
+// Remove removes a key. Tokens signed with it no longer verify. The current
+// key cannot be removed; rotate to another key first.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id == k.current {
+		return ErrRemoveCurrentKey
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// Current returns the current signing key.
+func (k *Keyring) Current() (Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.current == "" {
+		return Key{}, ErrNoCurrentKey
+	}
+	return k.keys[k.current], nil
+}
+
+// Lookup returns the key with the given ID.
+func (k *Keyring) Lookup(id string) (Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
+
+// IDs returns the IDs of all keys in the keyring, sorted.
+func (k *Keyring) IDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
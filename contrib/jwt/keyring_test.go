@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseKeyring(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.Add(Key{ID: "old", Secret: []byte("old secret of at least 32 bytes.")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Rotate(Key{ID: "new", Secret: []byte("new secret of at least 32 bytes.")}); err != nil {
+		t.Fatal(err)
+	}
+	j := New(Config{Keyring: keyring})
+	claims := Claims{Subject: "user1", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	claimsMap := map[string]interface{}{"sub": "user1"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"current key", mustSign(t, j, claims), nil},
+		{"previous key", forge(map[string]interface{}{"alg": "HS256", "kid": "old"}, claimsMap, []byte("old secret of at least 32 bytes.")), nil},
+		{"kid of another key", forge(map[string]interface{}{"alg": "HS256", "kid": "new"}, claimsMap, []byte("old secret of at least 32 bytes.")), ErrInvalidSignature},
+		{"unknown kid", forge(map[string]interface{}{"alg": "HS256", "kid": "removed"}, claimsMap, testSecret), ErrKeyNotFound},
+		{"missing kid", forge(map[string]interface{}{"alg": "HS256"}, claimsMap, testSecret), ErrMissingKeyID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := j.Parse(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
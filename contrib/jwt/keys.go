@@ -0,0 +1,122 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrInvalidPEM is returned when PEM data contains no usable key.
+var ErrInvalidPEM = errors.New("invalid PEM key")
+
+// ParsePrivateKeyPEM parses an RSA, ECDSA or Ed25519 private key from PEM
+// data. PKCS#1 ("RSA PRIVATE KEY"), SEC 1 ("EC PRIVATE KEY") and PKCS#8
+// ("PRIVATE KEY") encodings are supported.
+//
+// Example:
+//
+//	pemData, _ := os.ReadFile("private.pem")
+//	key, err := jwt.ParsePrivateKeyPEM(pemData)
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeKey(key)
+	default:
+		return nil, fmt.Errorf("%w: unsupported block type %q", ErrInvalidPEM, block.Type)
+	}
+}
+
+// ParsePublicKeyPEM parses an RSA, ECDSA or Ed25519 public key from PEM
+// data. PKIX ("PUBLIC KEY"), PKCS#1 ("RSA PUBLIC KEY") and X.509
+// certificates ("CERTIFICATE") are supported.
+//
+// Example:
+//
+//	pemData, _ := os.ReadFile("public.pem")
+//	key, err := jwt.ParsePublicKeyPEM(pemData)
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeKey(key)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeKey(cert.PublicKey)
+	default:
+		return nil, fmt.Errorf("%w: unsupported block type %q", ErrInvalidPEM, block.Type)
+	}
+}
+
+// LoadPrivateKeyFile reads and parses a PEM-encoded private key file.
+func LoadPrivateKeyFile(path string) (crypto.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+// LoadPublicKeyFile reads and parses a PEM-encoded public key or
+// certificate file.
+func LoadPublicKeyFile(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	return ParsePublicKeyPEM(data)
+}
+
+// normalizeKey checks that a parsed key is of a supported type. Ed25519
+// keys parsed from PKCS#8/PKIX are already returned by value.
+func normalizeKey(key interface{}) (interface{}, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey, *ecdsa.PrivateKey, *ecdsa.PublicKey,
+		ed25519.PrivateKey, ed25519.PublicKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidPEM, key)
+	}
+}
+
+// publicKeyOf returns the public half of a private key, or nil if the key
+// does not expose one.
+func publicKeyOf(key crypto.PrivateKey) crypto.PublicKey {
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+	return nil
+}
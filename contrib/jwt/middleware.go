@@ -308,3 +308,61 @@ func RequireAnyRole(roles ...string) quark.MiddlewareFunc {
 		}
 	}
 }
+
+// RequireScopes returns a middleware that requires the token to grant all of
+// the given scopes, read from the "scope", "scp" and "permissions" claims.
+//
+// Example:
+//
+//	api.GET("/users", listUsers, jwt.RequireScopes("users:read"))
+//	api.POST("/users", createUser, jwt.RequireScopes("users:read", "users:write"))
+func RequireScopes(scopes ...string) quark.MiddlewareFunc {
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			claims := GetClaims(c)
+			if claims == nil {
+				return quark.ErrUnauthorized("authentication required")
+			}
+
+			granted := scopeSet(claims)
+			for _, required := range scopes {
+				if !granted[required] {
+					return quark.ErrForbidden("insufficient scope")
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireAnyScope returns a middleware that requires the token to grant at
+// least one of the given scopes.
+func RequireAnyScope(scopes ...string) quark.MiddlewareFunc {
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			claims := GetClaims(c)
+			if claims == nil {
+				return quark.ErrUnauthorized("authentication required")
+			}
+
+			granted := scopeSet(claims)
+			for _, s := range scopes {
+				if granted[s] {
+					return next(c)
+				}
+			}
+
+			return quark.ErrForbidden("insufficient scope")
+		}
+	}
+}
+
+// scopeSet returns the scopes granted by claims as a set.
+func scopeSet(claims *Claims) map[string]bool {
+	set := make(map[string]bool)
+	for _, s := range claims.Scopes() {
+		set[s] = true
+	}
+	return set
+}
@@ -0,0 +1,327 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Refresh token errors
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenExpired  = errors.New("refresh token has expired")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+)
+
+// RefreshToken is the stored state of an issued refresh token. Only a hash
+// of the opaque token is stored, so a leaked store cannot be replayed.
+type RefreshToken struct {
+	// ID is the SHA-256 hash of the opaque token (hex encoded).
+	ID string
+
+	// Family identifies the rotation chain the token belongs to. All tokens
+	// derived from one login share a family and are revoked together.
+	Family string
+
+	// Claims are the claims used for access tokens issued with this token.
+	Claims Claims
+
+	// CreatedAt is when the token was issued.
+	CreatedAt time.Time
+
+	// ExpiresAt is when the token stops being accepted.
+	ExpiresAt time.Time
+
+	// UsedAt is when the token was exchanged, zero if it has not been.
+	UsedAt time.Time
+}
+
+// Store persists refresh tokens. Implementations must be safe for
+// concurrent use; Consume in particular must be atomic so that two
+// concurrent exchanges of one token are detected as reuse.
+type Store interface {
+	// Save stores a newly issued refresh token.
+	Save(ctx context.Context, token *RefreshToken) error
+
+	// Consume marks the token as used and returns it as it was before the
+	// call. It returns ErrRefreshTokenNotFound for unknown tokens.
+	Consume(ctx context.Context, id string) (*RefreshToken, error)
+
+	// RevokeFamily deletes every token of a rotation chain.
+	RevokeFamily(ctx context.Context, family string) error
+}
+
+// RefreshConfig configures a RefreshManager.
+type RefreshConfig struct {
+	// JWT issues the access tokens.
+	JWT *JWT
+
+	// Store persists refresh tokens (default: a new MemoryStore).
+	Store Store
+
+	// TTL is the refresh token lifetime (default: 30 days).
+	TTL time.Duration
+}
+
+// TokenPair is an access token with its refresh token, as returned to
+// clients.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+}
+
+// RefreshManager issues short-lived access tokens paired with opaque,
+// long-lived refresh tokens. Refresh tokens are single use: exchanging one
+// returns a new pair, and presenting an already exchanged token revokes its
+// whole rotation chain, since it means the token was stolen.
+//
+// Example:
+//
+//	manager := jwt.NewRefreshManager(jwt.RefreshConfig{JWT: jwtHandler})
+//
+//	app.POST("/auth/login", func(c *quark.Context) error {
+//	    // Authenticate the user...
+//	    pair, err := manager.Issue(c.Request.Context(), jwt.NewClaims(userID, 0))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(200, pair)
+//	})
+//	app.POST("/auth/refresh", manager.RefreshHandler())
+//	app.POST("/auth/logout", manager.RevokeHandler())
+type RefreshManager struct {
+	jwt   *JWT
+	store Store
+	ttl   time.Duration
+}
+
+// NewRefreshManager creates a refresh manager.
+func NewRefreshManager(config RefreshConfig) *RefreshManager {
+	if config.JWT == nil {
+		panic("jwt refresh manager requires a JWT handler")
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 30 * 24 * time.Hour
+	}
+	return &RefreshManager{jwt: config.JWT, store: config.Store, ttl: config.TTL}
+}
+
+// Issue starts a new rotation chain, typically after a login, and returns
+// an access token for claims with its refresh token.
+func (m *RefreshManager) Issue(ctx context.Context, claims Claims) (*TokenPair, error) {
+	family, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return m.issue(ctx, family, claims)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented
+// token is invalidated; presenting it again returns ErrRefreshTokenReused
+// and revokes every token of its chain.
+func (m *RefreshManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := m.store.Consume(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if !stored.UsedAt.IsZero() {
+		if err := m.store.RevokeFamily(ctx, stored.Family); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	return m.issue(ctx, stored.Family, stored.Claims)
+}
+
+// Revoke revokes the rotation chain of a refresh token, e.g. on logout.
+// Unknown tokens are ignored.
+func (m *RefreshManager) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := m.store.Consume(ctx, hashToken(refreshToken))
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return m.store.RevokeFamily(ctx, stored.Family)
+}
+
+// issue generates an access token and stores a new refresh token in family.
+func (m *RefreshManager) issue(ctx context.Context, family string, claims Claims) (*TokenPair, error) {
+	// Let Generate fill in fresh time-based claims
+	claims.IssuedAt = 0
+	claims.ExpiresAt = 0
+	claims.NotBefore = 0
+
+	accessToken, err := m.jwt.Generate(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = m.store.Save(ctx, &RefreshToken{
+		ID:        hashToken(refreshToken),
+		Family:    family,
+		Claims:    claims,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.ttl),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(m.jwt.config.ExpiresIn.Seconds()),
+	}, nil
+}
+
+// refreshRequest is the body accepted by the refresh and revoke handlers.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+// RefreshHandler returns a handler that exchanges the "refresh_token" field
+// of a JSON or form body for a new token pair.
+func (m *RefreshManager) RefreshHandler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		var req refreshRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		if req.RefreshToken == "" {
+			return quark.ErrBadRequest("missing refresh token")
+		}
+
+		pair, err := m.Refresh(c.Request.Context(), req.RefreshToken)
+		switch {
+		case errors.Is(err, ErrRefreshTokenNotFound),
+			errors.Is(err, ErrRefreshTokenExpired),
+			errors.Is(err, ErrRefreshTokenReused):
+			return quark.ErrUnauthorized(err.Error())
+		case err != nil:
+			return err
+		}
+
+		c.SetHeader("Cache-Control", "no-store")
+		return c.JSON(200, pair)
+	}
+}
+
+// RevokeHandler returns a handler that revokes the rotation chain of the
+// "refresh_token" field of a JSON or form body and responds 204.
+func (m *RefreshManager) RevokeHandler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		var req refreshRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		if req.RefreshToken == "" {
+			return quark.ErrBadRequest("missing refresh token")
+		}
+
+		if err := m.Revoke(c.Request.Context(), req.RefreshToken); err != nil {
+			return err
+		}
+		return c.NoContent()
+	}
+}
+
+// randomToken returns 32 random bytes, hex encoded.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the storage ID of an opaque token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryStore is an in-memory Store for development, tests and
+// single-instance deployments. Expired tokens are purged as new ones are
+// saved.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]*RefreshToken
+}
+
+// NewMemoryStore creates an empty in-memory refresh token store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]*RefreshToken)}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, t := range s.tokens {
+		if now.After(t.ExpiresAt) {
+			delete(s.tokens, id)
+		}
+	}
+
+	stored := *token
+	s.tokens[token.ID] = &stored
+	return nil
+}
+
+// Consume implements Store.
+func (s *MemoryStore) Consume(ctx context.Context, id string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	previous := *token
+	if token.UsedAt.IsZero() {
+		token.UsedAt = time.Now()
+	}
+	return &previous, nil
+}
+
+// RevokeFamily implements Store.
+func (s *MemoryStore) RevokeFamily(ctx context.Context, family string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, t := range s.tokens {
+		if t.Family == family {
+			delete(s.tokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenRevoked is returned by Parse for tokens rejected by the
+// configured Revoker.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Revoker decides whether an otherwise valid token has been revoked, e.g.
+// after a logout or a credential compromise. It is consulted by Parse after
+// the signature and standard claims have been checked.
+type Revoker interface {
+	// IsRevoked reports whether the token with the given claims is revoked.
+	// An error makes Parse reject the token.
+	IsRevoked(claims *Claims) (bool, error)
+}
+
+// MemoryRevoker is an in-memory Revoker that revokes individual tokens by
+// jti, or every token of a subject issued up to the second of revocation.
+// Entries are kept only as long as the tokens they revoke can be valid.
+//
+// Example:
+//
+//	revoker := jwt.NewMemoryRevoker()
+//	jwtHandler := jwt.New(jwt.Config{
+//	    Secret:    secret,
+//	    ExpiresIn: time.Hour,
+//	    Revoker:   revoker,
+//	})
+//
+//	app.POST("/logout", func(c *quark.Context) error {
+//	    revoker.Revoke(jwt.GetClaims(c))
+//	    return c.NoContent()
+//	})
+//
+//	// Log a user out everywhere
+//	revoker.RevokeSubject("user123", time.Hour)
+type MemoryRevoker struct {
+	mu       sync.RWMutex
+	ids      map[string]time.Time      // jti -> entry expiry
+	subjects map[string]subjectRevoked // sub -> revocation
+}
+
+// subjectRevoked records a subject-wide revocation.
+type subjectRevoked struct {
+	issuedUntil int64     // Tokens issued up to this Unix second are revoked
+	expiresAt   time.Time // When the entry can be dropped
+}
+
+// NewMemoryRevoker creates an empty in-memory revoker.
+func NewMemoryRevoker() *MemoryRevoker {
+	return &MemoryRevoker{
+		ids:      make(map[string]time.Time),
+		subjects: make(map[string]subjectRevoked),
+	}
+}
+
+// Revoke revokes the token with the given claims by its jti until it
+// expires. Tokens without a jti cannot be revoked individually and return
+// ErrMissingClaims; use RevokeSubject instead.
+func (r *MemoryRevoker) Revoke(claims *Claims) error {
+	if claims == nil || claims.ID == "" {
+		return ErrMissingClaims
+	}
+
+	// Tokens without an expiry are remembered for a day
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if claims.ExpiresAt > 0 {
+		expiresAt = time.Unix(claims.ExpiresAt, 0)
+	}
+	r.RevokeID(claims.ID, expiresAt)
+	return nil
+}
+
+// RevokeID revokes the token with the given jti. The entry is dropped after
+// expiresAt, which should be no earlier than the token's exp claim.
+func (r *MemoryRevoker) RevokeID(id string, expiresAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.purge()
+	r.ids[id] = expiresAt
+}
+
+// RevokeSubject revokes every token of subject issued up to now. ttl is how
+// long the entry is kept and should be at least the access token lifetime.
+//
+// The iat claim has a precision of one second, so every token issued in
+// the second of the revocation is revoked, including one issued right
+// after RevokeSubject: tokens re-issued to the subject, such as on the
+// next login, are accepted once they are minted in a later second.
+func (r *MemoryRevoker) RevokeSubject(subject string, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.purge()
+	now := time.Now()
+	r.subjects[subject] = subjectRevoked{
+		issuedUntil: now.Unix(),
+		expiresAt:   now.Add(ttl),
+	}
+}
+
+// IsRevoked implements Revoker.
+func (r *MemoryRevoker) IsRevoked(claims *Claims) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	if claims.ID != "" {
+		if expiresAt, ok := r.ids[claims.ID]; ok && now.Before(expiresAt) {
+			return true, nil
+		}
+	}
+	if claims.Subject != "" {
+		if entry, ok := r.subjects[claims.Subject]; ok && now.Before(entry.expiresAt) {
+			return claims.IssuedAt <= entry.issuedUntil, nil
+		}
+	}
+	return false, nil
+}
+
+// purge drops expired entries. The caller must hold the write lock.
+func (r *MemoryRevoker) purge() {
+	now := time.Now()
+	for id, expiresAt := range r.ids {
+		if !now.Before(expiresAt) {
+			delete(r.ids, id)
+		}
+	}
+	for subject, entry := range r.subjects {
+		if !now.Before(entry.expiresAt) {
+			delete(r.subjects, subject)
+		}
+	}
+}
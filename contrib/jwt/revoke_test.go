@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryRevokerSubject(t *testing.T) {
+	revoker := NewMemoryRevoker()
+	revoker.RevokeSubject("user123", time.Hour)
+	now := revoker.subjects["user123"].issuedUntil
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   bool
+	}{
+		{"issued before", Claims{Subject: "user123", IssuedAt: now - 1}, true},
+		{"issued in the same second", Claims{Subject: "user123", IssuedAt: now}, true},
+		{"issued after", Claims{Subject: "user123", IssuedAt: now + 1}, false},
+		{"other subject", Claims{Subject: "user456", IssuedAt: now - 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := revoker.IsRevoked(&tt.claims)
+			if err != nil || revoked != tt.want {
+				t.Errorf("IsRevoked = %v, %v; want %v", revoked, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRevokerID(t *testing.T) {
+	revoker := NewMemoryRevoker()
+	if err := revoker.Revoke(&Claims{}); err != ErrMissingClaims {
+		t.Errorf("Revoke without jti: expected ErrMissingClaims, got %v", err)
+	}
+	if err := revoker.Revoke(&Claims{ID: "t1", ExpiresAt: time.Now().Add(time.Hour).Unix()}); err != nil {
+		t.Fatalf("Revoke: unexpected error: %v", err)
+	}
+	revoker.RevokeID("t2", time.Now().Add(-time.Second))
+
+	tests := map[string]bool{"t1": true, "t2": false, "t3": false}
+	for id, want := range tests {
+		if revoked, _ := revoker.IsRevoked(&Claims{ID: id}); revoked != want {
+			t.Errorf("IsRevoked(%s) = %v, want %v", id, revoked, want)
+		}
+	}
+}
+
+func TestParseRevoked(t *testing.T) {
+	revoker := NewMemoryRevoker()
+	j := New(Config{Secret: testSecret, ExpiresIn: time.Hour, Revoker: revoker})
+
+	token, err := j.Generate(Claims{Subject: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := j.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if parsed.Claims.ID == "" {
+		t.Fatal("expected a jti assigned for revocation")
+	}
+
+	revoker.Revoke(&parsed.Claims)
+	if _, err := j.Parse(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestParseRevokedSubject(t *testing.T) {
+	revoker := NewMemoryRevoker()
+	j := New(Config{Secret: testSecret, ExpiresIn: time.Hour, Revoker: revoker})
+
+	before, err := j.Generate(Claims{Subject: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoker.RevokeSubject("user1", time.Hour)
+	sameSecond, err := j.Generate(Claims{Subject: "user1", IssuedAt: revoker.subjects["user1"].issuedUntil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	later, err := j.Generate(Claims{Subject: "user1", IssuedAt: revoker.subjects["user1"].issuedUntil + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"issued before": before, "issued in the same second": sameSecond} {
+		if _, err := j.Parse(token); !errors.Is(err, ErrTokenRevoked) {
+			t.Errorf("%s: expected ErrTokenRevoked, got %v", name, err)
+		}
+	}
+	if _, err := j.Parse(later); err != nil {
+		t.Errorf("expected a token minted in a later second to be accepted, got %v", err)
+	}
+}
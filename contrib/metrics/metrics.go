@@ -0,0 +1,400 @@
+// Package metrics provides Prometheus-compatible metrics for the Quark
+// framework without external dependencies: counters, gauges and histograms
+// with labels, collected in a Registry and exposed in the Prometheus text
+// exposition format.
+//
+// Basic usage:
+//
+//	registry := metrics.NewRegistry()
+//	orders := registry.Counter("orders_total", "Orders placed.", "payment")
+//	queue := registry.Gauge("jobs_queued", "Jobs waiting in the queue.")
+//	duration := registry.Histogram("job_duration_seconds", "Job duration.", metrics.DefaultBuckets)
+//
+//	orders.Inc("card")
+//	queue.Set(float64(len(jobs)))
+//	duration.Observe(time.Since(start).Seconds())
+//
+//	app.GET("/metrics", registry.Handler())
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// DefaultBuckets are histogram buckets suited to request latencies in
+// seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// kind is the type of a metric family.
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Registry holds metric families and writes them in the text exposition
+// format. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// DefaultRegistry is a process-wide registry for metrics shared between
+// packages.
+var DefaultRegistry = NewRegistry()
+
+// Counter registers a counter, a value that only goes up, such as the
+// number of requests served. Registering an existing name with the same
+// type and labels returns the existing counter; a conflicting registration
+// panics.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(name, help, kindCounter, labels, nil)}
+}
+
+// Gauge registers a gauge, a value that goes up and down, such as the
+// number of requests in flight.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(name, help, kindGauge, labels, nil)}
+}
+
+// GaugeFunc registers an unlabeled gauge whose value is read from fn when
+// the metrics are written, such as the size of a connection pool.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	f := r.register(name, help, kindGauge, nil, nil)
+	f.mu.Lock()
+	f.fn = fn
+	f.mu.Unlock()
+}
+
+// Histogram registers a histogram, counting observations such as request
+// durations in buckets with the given upper bounds (default:
+// DefaultBuckets).
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{r.register(name, help, kindHistogram, labels, buckets)}
+}
+
+// register returns the family of name, creating it if needed.
+func (r *Registry) register(name, help string, k kind, labels []string, buckets []float64) *family {
+	if !namePattern.MatchString(name) {
+		panic("metrics: invalid metric name " + strconv.Quote(name))
+	}
+	for _, label := range labels {
+		if !namePattern.MatchString(label) || strings.Contains(label, ":") || label == "le" {
+			panic("metrics: invalid label name " + strconv.Quote(label))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("metrics: %s already registered as a %s with labels %v", name, f.kind, f.labels))
+		}
+		return f
+	}
+
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    k,
+		labels:  append([]string(nil), labels...),
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.families[name] = f
+	return f
+}
+
+// Handler returns a handler exposing the metrics for Prometheus to scrape.
+//
+// Example:
+//
+//	app.GET("/metrics", registry.Handler())
+func (r *Registry) Handler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		c.SetHeader("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Writer.WriteHeader(200)
+		return r.Write(c.Writer)
+	}
+}
+
+// Write writes the metrics in the text exposition format, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Counter is a counter metric. Label values are passed to its methods in
+// the order of the registered label names.
+type Counter struct {
+	f *family
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter. Negative values panic, since counters never
+// go down.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.f.get(labelValues).add(v)
+}
+
+// Gauge is a gauge metric. Label values are passed to its methods in the
+// order of the registered label names.
+type Gauge struct {
+	f *family
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.get(labelValues).value.Store(math.Float64bits(v))
+}
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Add adds v, which may be negative, to the gauge.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.get(labelValues).add(v)
+}
+
+// Histogram is a histogram metric. Label values are passed to its methods
+// in the order of the registered label names.
+type Histogram struct {
+	f *family
+}
+
+// Observe records an observation.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	s := h.f.get(labelValues)
+	i := sort.SearchFloat64s(h.f.buckets, v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// family is a metric with its labeled series.
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	labels  []string
+	buckets []float64
+
+	mu     sync.RWMutex
+	series map[string]*series
+	fn     func() float64
+}
+
+// series is the value of a metric for one set of label values.
+type series struct {
+	labelValues []string
+
+	// Counters and gauges
+	value atomic.Uint64 // float64 bits
+
+	// Histograms
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// add adds v to a counter or gauge value.
+func (s *series) add(v float64) {
+	for {
+		old := s.value.Load()
+		if s.value.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// get returns the series of the label values, creating it if needed.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s = &series{labelValues: append([]string(nil), labelValues...)}
+	if f.kind == kindHistogram {
+		s.counts = make([]uint64, len(f.buckets))
+	}
+	f.series[key] = s
+	return s
+}
+
+// write writes the family in the text exposition format.
+func (f *family) write(w *bufio.Writer) {
+	f.mu.RLock()
+	fn := f.fn
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	all := make([]*series, len(keys))
+	for i, key := range keys {
+		all[i] = f.series[key]
+	}
+	f.mu.RUnlock()
+
+	if fn == nil && len(all) == 0 {
+		return
+	}
+
+	if f.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	if fn != nil {
+		fmt.Fprintf(w, "%s %s\n", f.name, formatValue(fn()))
+		return
+	}
+
+	for _, s := range all {
+		labels := formatLabels(f.labels, s.labelValues, "")
+		if f.kind != kindHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatValue(math.Float64frombits(s.value.Load())))
+			continue
+		}
+
+		s.mu.Lock()
+		counts := append([]uint64(nil), s.counts...)
+		count, sum := s.count, s.sum
+		s.mu.Unlock()
+
+		var cumulative uint64
+		for i, bound := range f.buckets {
+			cumulative += counts[i]
+			le := formatLabels(f.labels, s.labelValues, formatValue(bound))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, le, cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatValue(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, count)
+	}
+}
+
+// formatLabels formats label pairs, followed by an le label for histogram
+// buckets when le is not empty.
+func formatLabels(names, values []string, le string) string {
+	if len(names) == 0 && le == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, escapeLabel(values[i]))
+	}
+	if le != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `le="%s"`, le)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// formatValue formats a sample value.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes a help text.
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// escapeLabel escapes a label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// exposition returns the text exposition of r.
+func exposition(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write: unexpected error: %v", err)
+	}
+	return b.String()
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		record func(r *Registry)
+		want   string
+	}{
+		{
+			name: "counter",
+			record: func(r *Registry) {
+				c := r.Counter("orders_total", "Orders placed.", "payment", "country")
+				c.Inc("card", "FR")
+				c.Add(2.5, "card", "FR")
+				c.Inc("cash", "DE")
+			},
+			want: "# HELP orders_total Orders placed.\n" +
+				"# TYPE orders_total counter\n" +
+				"orders_total{payment=\"card\",country=\"FR\"} 3.5\n" +
+				"orders_total{payment=\"cash\",country=\"DE\"} 1\n",
+		},
+		{
+			name: "gauge",
+			record: func(r *Registry) {
+				g := r.Gauge("jobs_queued", "")
+				g.Set(10)
+				g.Inc()
+				g.Dec()
+				g.Add(-4)
+			},
+			want: "# TYPE jobs_queued gauge\njobs_queued 6\n",
+		},
+		{
+			name: "gauge func",
+			record: func(r *Registry) {
+				r.GaugeFunc("pool_open", "Open connections.", func() float64 { return 4 })
+			},
+			want: "# HELP pool_open Open connections.\n# TYPE pool_open gauge\npool_open 4\n",
+		},
+		{
+			name: "histogram",
+			record: func(r *Registry) {
+				h := r.Histogram("job_seconds", "Job duration.", []float64{1, 0.1}, "queue")
+				h.Observe(0.05, "mail")
+				h.Observe(0.1, "mail") // Bounds are inclusive
+				h.Observe(0.5, "mail")
+				h.Observe(3, "mail")
+			},
+			want: "# HELP job_seconds Job duration.\n" +
+				"# TYPE job_seconds histogram\n" +
+				"job_seconds_bucket{queue=\"mail\",le=\"0.1\"} 2\n" +
+				"job_seconds_bucket{queue=\"mail\",le=\"1\"} 3\n" +
+				"job_seconds_bucket{queue=\"mail\",le=\"+Inf\"} 4\n" +
+				"job_seconds_sum{queue=\"mail\"} 3.65\n" +
+				"job_seconds_count{queue=\"mail\"} 4\n",
+		},
+		{
+			name: "unlabeled histogram",
+			record: func(r *Registry) {
+				r.Histogram("size_bytes", "", []float64{100}).Observe(10)
+			},
+			want: "# TYPE size_bytes histogram\n" +
+				"size_bytes_bucket{le=\"100\"} 1\n" +
+				"size_bytes_bucket{le=\"+Inf\"} 1\n" +
+				"size_bytes_sum 10\n" +
+				"size_bytes_count 1\n",
+		},
+		{
+			name: "escaping",
+			record: func(r *Registry) {
+				r.Counter("errors_total", "Errors\nby \\ message.", "message").Inc("say \"hi\"\n\\")
+			},
+			want: "# HELP errors_total Errors\\nby \\\\ message.\n" +
+				"# TYPE errors_total counter\n" +
+				"errors_total{message=\"say \\\"hi\\\"\\n\\\\\"} 1\n",
+		},
+		{
+			name: "special values",
+			record: func(r *Registry) {
+				g := r.Gauge("value", "", "kind")
+				g.Set(math.Inf(1), "inf")
+				g.Set(math.Inf(-1), "minf")
+				g.Set(math.NaN(), "nan")
+				g.Set(1e21, "large")
+			},
+			want: "# TYPE value gauge\n" +
+				"value{kind=\"inf\"} +Inf\n" +
+				"value{kind=\"large\"} 1e+21\n" +
+				"value{kind=\"minf\"} -Inf\n" +
+				"value{kind=\"nan\"} NaN\n",
+		},
+		{
+			name: "sorted by name, empty families omitted",
+			record: func(r *Registry) {
+				r.Counter("b_total", "").Inc()
+				r.Counter("unused_total", "Never incremented.")
+				r.Gauge("a", "").Set(1)
+			},
+			want: "# TYPE a gauge\na 1\n# TYPE b_total counter\nb_total 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.record(r)
+			if got := exposition(t, r); got != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegisterExisting(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("requests_total", "", "method").Inc("GET")
+	r.Counter("requests_total", "", "method").Inc("GET")
+
+	want := "# TYPE requests_total counter\nrequests_total{method=\"GET\"} 2\n"
+	if got := exposition(t, r); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Registry)
+	}{
+		{"invalid name", func(r *Registry) { r.Counter("requests-total", "") }},
+		{"invalid label", func(r *Registry) { r.Counter("requests_total", "", "http:method") }},
+		{"reserved label", func(r *Registry) { r.Histogram("duration", "", nil, "le") }},
+		{"conflicting type", func(r *Registry) {
+			r.Counter("requests_total", "")
+			r.Gauge("requests_total", "")
+		}},
+		{"conflicting labels", func(r *Registry) {
+			r.Counter("requests_total", "", "method")
+			r.Counter("requests_total", "", "status")
+		}},
+		{"wrong label count", func(r *Registry) { r.Counter("requests_total", "", "method").Inc() }},
+		{"negative counter", func(r *Registry) { r.Counter("requests_total", "").Add(-1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.register(NewRegistry())
+		})
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	r := NewRegistry()
+	counter := r.Counter("hits_total", "", "path")
+	histogram := r.Histogram("latency", "", []float64{1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				counter.Inc("/")
+				histogram.Observe(0.5)
+			}
+			exposition(t, r)
+		}()
+	}
+	wg.Wait()
+
+	got := exposition(t, r)
+	for _, want := range []string{"hits_total{path=\"/\"} 5000\n", "latency_count 5000\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("hits_total", "Hits.").Inc()
+
+	app := quark.New()
+	app.GET("/metrics", r.Handler())
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("expected the exposition content type, got %q", ct)
+	}
+	if want := "# HELP hits_total Hits.\n# TYPE hits_total counter\nhits_total 1\n"; rec.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, rec.Body.String())
+	}
+}
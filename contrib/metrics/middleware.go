@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Middleware returns middleware recording HTTP metrics in registry
+// (default: DefaultRegistry):
+//
+//	http_requests_total            counter   by method, route and status class
+//	http_requests_in_flight        gauge
+//	http_request_duration_seconds  histogram by method, route and status class
+//
+// Requests are labeled by route pattern ("/users/{id}") rather than raw
+// path to keep the number of series bounded; requests matching no route
+// are labeled "unmatched". Status classes are "2xx", "4xx" and so on.
+//
+// Example:
+//
+//	registry := metrics.NewRegistry()
+//	app.Use(metrics.Middleware(registry))
+//	app.GET("/metrics", registry.Handler())
+func Middleware(registry *Registry) quark.MiddlewareFunc {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	requests := registry.Counter("http_requests_total",
+		"Total number of HTTP requests.", "method", "route", "status")
+	inFlight := registry.Gauge("http_requests_in_flight",
+		"Number of HTTP requests being served.")
+	duration := registry.Histogram("http_request_duration_seconds",
+		"HTTP request latency in seconds.", DefaultBuckets, "method", "route", "status")
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			start := time.Now()
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			sw := &statusWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = sw
+
+			err := next(c)
+
+			status := sw.status
+			if err != nil {
+				var httpErr *quark.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+
+			route := c.Route()
+			if route == "" {
+				route = "unmatched"
+			}
+			class := strconv.Itoa(status/100) + "xx"
+
+			requests.Inc(c.Method(), route, class)
+			duration.Observe(time.Since(start).Seconds(), c.Method(), route, class)
+			return err
+		}
+	}
+}
+
+// statusWriter wraps http.ResponseWriter to capture the status code.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,433 @@
+// Package oauth provides OAuth2 and OpenID Connect client helpers for the
+// Quark framework using only the standard library. It implements the
+// authorization code flow with PKCE, state and nonce handling, token
+// exchange, and ID token verification through the jwt package.
+//
+// Basic usage:
+//
+//	client := oauth.New(oauth.Config{
+//	    Provider:     oauth.Google,
+//	    ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+//	    ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+//	    RedirectURL:  "https://example.com/auth/google/callback",
+//	})
+//
+//	app.GET("/auth/google", client.LoginHandler())
+//	app.GET("/auth/google/callback", client.CallbackHandler(
+//	    func(c *quark.Context, token *oauth.Token) error {
+//	        // token.IDClaims holds the verified ID token claims (OIDC providers)
+//	        email := token.IDClaims.GetString("email")
+//	        // Find or create the user, start a session...
+//	        return c.Redirect(302, "/")
+//	    },
+//	))
+//
+// Providers without ID tokens, such as GitHub, expose the user's profile
+// through UserInfo:
+//
+//	profile, err := client.UserInfo(c.Context(), token)
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+	"github.com/AchrafSoltani/quark/contrib/jwt"
+)
+
+// Common errors
+var (
+	ErrInvalidState = errors.New("invalid oauth state")
+	ErrInvalidNonce = errors.New("invalid id token nonce")
+	ErrNoIDToken    = errors.New("provider returned no id token")
+	ErrNotOIDC      = errors.New("provider does not support id tokens")
+)
+
+// Error is an error response from the authorization server (RFC 6749).
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	URI         string `json:"error_uri,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+	}
+	return "oauth: " + e.Code
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+
+	// Expiry is when the access token expires, zero if unknown.
+	Expiry time.Time `json:"-"`
+
+	// IDClaims are the verified ID token claims. They are set by the
+	// callback handler for OpenID Connect providers.
+	IDClaims *jwt.Claims `json:"-"`
+}
+
+// Config holds the OAuth2 client configuration.
+type Config struct {
+	// Provider is the authorization server (e.g., oauth.Google).
+	Provider Provider
+
+	// ClientID is the application's client ID.
+	ClientID string
+
+	// ClientSecret is the application's client secret.
+	ClientSecret string
+
+	// RedirectURL is the callback URL registered with the provider.
+	RedirectURL string
+
+	// Scopes are the requested scopes (default: Provider.Scopes).
+	Scopes []string
+
+	// HTTPClient is used for token, user info and key requests
+	// (default: a client with a 10 second timeout).
+	HTTPClient *http.Client
+
+	// StateCookie is the name of the cookie holding the state, PKCE
+	// verifier and nonce between login and callback (default: "oauth_state").
+	StateCookie string
+
+	// CookieInsecure allows the state cookie over plain HTTP, for local
+	// development only.
+	CookieInsecure bool
+}
+
+// Client performs the authorization code flow against one provider.
+type Client struct {
+	config Config
+
+	mu       sync.Mutex
+	verifier *jwt.JWT // ID token verifier, created on first use
+}
+
+// New creates an OAuth2 client.
+func New(config Config) *Client {
+	if len(config.Scopes) == 0 {
+		config.Scopes = config.Provider.Scopes
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.StateCookie == "" {
+		config.StateCookie = "oauth_state"
+	}
+	return &Client{config: config}
+}
+
+// GenerateState returns a random value for the state parameter.
+func GenerateState() (string, error) {
+	return randomString(32)
+}
+
+// GeneratePKCE returns a random PKCE code verifier and its S256 code
+// challenge (RFC 7636).
+func GeneratePKCE() (verifier, challenge string, err error) {
+	verifier, err = randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// AuthCodeURL returns the URL of the provider's consent page. codeChallenge
+// and nonce are omitted when empty.
+func (cl *Client) AuthCodeURL(state, codeChallenge, nonce string) string {
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {cl.config.ClientID},
+		"state":         {state},
+	}
+	if cl.config.RedirectURL != "" {
+		params.Set("redirect_uri", cl.config.RedirectURL)
+	}
+	if len(cl.config.Scopes) > 0 {
+		params.Set("scope", strings.Join(cl.config.Scopes, " "))
+	}
+	if codeChallenge != "" {
+		params.Set("code_challenge", codeChallenge)
+		params.Set("code_challenge_method", "S256")
+	}
+	if nonce != "" {
+		params.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(cl.config.Provider.AuthURL, "?") {
+		sep = "&"
+	}
+	return cl.config.Provider.AuthURL + sep + params.Encode()
+}
+
+// Exchange trades an authorization code for tokens. codeVerifier is the
+// PKCE verifier, or empty if PKCE was not used.
+func (cl *Client) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	params := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if cl.config.RedirectURL != "" {
+		params.Set("redirect_uri", cl.config.RedirectURL)
+	}
+	if codeVerifier != "" {
+		params.Set("code_verifier", codeVerifier)
+	}
+	return cl.requestToken(ctx, params)
+}
+
+// Refresh obtains a new access token with a refresh token.
+func (cl *Client) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return cl.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// requestToken posts a token request and decodes the response.
+func (cl *Client) requestToken(ctx context.Context, params url.Values) (*Token, error) {
+	params.Set("client_id", cl.config.ClientID)
+	if cl.config.ClientSecret != "" {
+		params.Set("client_secret", cl.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cl.config.Provider.TokenURL,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := cl.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var oauthErr Error
+	if err := json.Unmarshal(body, &oauthErr); err == nil && oauthErr.Code != "" {
+		return nil, &oauthErr
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("token request failed: unexpected status %d", status)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response has no access token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
+
+// UserInfo fetches the profile of the token's user from the provider's
+// user info endpoint.
+func (cl *Client) UserInfo(ctx context.Context, token *Token) (map[string]interface{}, error) {
+	if cl.config.Provider.UserInfoURL == "" {
+		return nil, errors.New("provider has no user info endpoint")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cl.config.Provider.UserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user info request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := cl.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("user info request failed: unexpected status %d", status)
+	}
+
+	var info map[string]interface{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	return info, nil
+}
+
+// VerifyIDToken verifies an ID token's signature against the provider's
+// published keys and checks its issuer, audience (the client ID) and
+// expiry.
+func (cl *Client) VerifyIDToken(rawIDToken string) (*jwt.Claims, error) {
+	verifier, err := cl.idTokenVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := verifier.Parse(rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	return &token.Claims, nil
+}
+
+// idTokenVerifier returns the ID token verifier, downloading the
+// provider's keys on first use.
+func (cl *Client) idTokenVerifier() (*jwt.JWT, error) {
+	if !cl.config.Provider.OIDC() {
+		return nil, ErrNotOIDC
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.verifier == nil {
+		verifier, err := jwt.NewFromJWKS(cl.config.Provider.JWKSURL, jwt.JWKSOptions{
+			HTTPClient: cl.config.HTTPClient,
+			Issuers:    cl.config.Provider.Issuers,
+			Audience:   cl.config.ClientID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cl.verifier = verifier
+	}
+	return cl.verifier, nil
+}
+
+// LoginHandler returns a handler that starts the flow: it stores a fresh
+// state, PKCE verifier and (for OIDC providers) nonce in a short-lived
+// cookie and redirects to the provider.
+func (cl *Client) LoginHandler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		state, err := GenerateState()
+		if err != nil {
+			return err
+		}
+		verifier, challenge, err := GeneratePKCE()
+		if err != nil {
+			return err
+		}
+		var nonce string
+		if cl.config.Provider.OIDC() {
+			if nonce, err = randomString(16); err != nil {
+				return err
+			}
+		}
+
+		cl.setStateCookie(c, strings.Join([]string{state, verifier, nonce}, "."), 600)
+		return c.Redirect(http.StatusFound, cl.AuthCodeURL(state, challenge, nonce))
+	}
+}
+
+// CallbackHandler returns a handler for the redirect URL. It checks the
+// state, exchanges the code, verifies the ID token of OIDC providers and
+// passes the token to onSuccess, which typically signs the user in.
+func (cl *Client) CallbackHandler(onSuccess func(c *quark.Context, token *Token) error) quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		if code := c.Query("error"); code != "" {
+			return quark.WrapError(http.StatusUnauthorized, "authorization denied",
+				&Error{Code: code, Description: c.Query("error_description")})
+		}
+
+		cookie, err := c.Request.Cookie(cl.config.StateCookie)
+		if err != nil {
+			return quark.WrapError(http.StatusBadRequest, ErrInvalidState.Error(), err)
+		}
+		cl.setStateCookie(c, "", -1)
+
+		parts := strings.Split(cookie.Value, ".")
+		state := c.Query("state")
+		if len(parts) != 3 || state == "" || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(state)) != 1 {
+			return quark.WrapError(http.StatusBadRequest, ErrInvalidState.Error(), ErrInvalidState)
+		}
+		verifier, nonce := parts[1], parts[2]
+
+		code := c.Query("code")
+		if code == "" {
+			return quark.ErrBadRequest("missing authorization code")
+		}
+
+		token, err := cl.Exchange(c.Context(), code, verifier)
+		if err != nil {
+			return quark.WrapError(http.StatusBadGateway, "token exchange failed", err)
+		}
+
+		if cl.config.Provider.OIDC() {
+			if token.IDToken == "" {
+				return quark.WrapError(http.StatusBadGateway, "token exchange failed", ErrNoIDToken)
+			}
+			claims, err := cl.VerifyIDToken(token.IDToken)
+			if err != nil {
+				return quark.WrapError(http.StatusUnauthorized, "invalid id token", err)
+			}
+			if subtle.ConstantTimeCompare([]byte(claims.GetString("nonce")), []byte(nonce)) != 1 {
+				return quark.WrapError(http.StatusUnauthorized, "invalid id token", ErrInvalidNonce)
+			}
+			token.IDClaims = claims
+		}
+
+		return onSuccess(c, token)
+	}
+}
+
+// setStateCookie writes or (with a negative maxAge) deletes the state cookie.
+func (cl *Client) setStateCookie(c *quark.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cl.config.StateCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   !cl.config.CookieInsecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// do sends a request and reads the (size-limited) response body.
+func (cl *Client) do(req *http.Request) ([]byte, int, error) {
+	resp, err := cl.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("oauth request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read oauth response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// randomString returns n random bytes, base64url encoded without padding.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
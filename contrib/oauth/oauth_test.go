@@ -0,0 +1,202 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+	"github.com/AchrafSoltani/quark/contrib/jwt"
+)
+
+// fakeProvider is an authorization server issuing tokens for the code
+// "good-code", with ID tokens whose nonce is set by the test.
+type fakeProvider struct {
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+	signer *jwt.JWT
+	nonce  string
+	form   url.Values // Last token request
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keyring := jwt.NewKeyring()
+	if err := keyring.Rotate(jwt.Key{ID: "k1", Algorithm: jwt.AlgorithmES256, PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeProvider{key: key, signer: jwt.New(jwt.Config{Keyring: keyring})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwt.JWKSet{Keys: []jwt.JWK{{
+			KeyType: "EC",
+			KeyID:   "k1",
+			Curve:   "P-256",
+			X:       base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:       base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		p.form = r.PostForm
+		if r.PostForm.Get("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: "invalid_grant"})
+			return
+		}
+		claims := jwt.NewClaims("user1", time.Hour).WithCustom("nonce", p.nonce)
+		claims.Issuer = p.server.URL
+		claims.Audience = "client"
+		idToken, _ := p.signer.Sign(claims)
+		json.NewEncoder(w).Encode(Token{AccessToken: "access", TokenType: "Bearer", IDToken: idToken})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) provider(oidc bool) Provider {
+	provider := Provider{Name: "fake", AuthURL: p.server.URL + "/auth", TokenURL: p.server.URL + "/token"}
+	if oidc {
+		provider.Issuers = []string{p.server.URL}
+		provider.JWKSURL = p.server.URL + "/jwks"
+	}
+	return provider
+}
+
+// login runs the login handler and returns the state cookie and the
+// parameters of the redirect to the provider.
+func login(t *testing.T, app *quark.App) (*http.Cookie, url.Values) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login: expected a redirect, got %d", rec.Code)
+	}
+	location, _ := url.Parse(rec.Header().Get("Location"))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("login: expected a secure state cookie, got %v", cookies)
+	}
+	return cookies[0], location.Query()
+}
+
+func TestCallbackHandler(t *testing.T) {
+	p := newFakeProvider(t)
+	client := New(Config{Provider: p.provider(false), ClientID: "client", RedirectURL: "https://app.example.com/callback"})
+
+	var got *Token
+	app := quark.New()
+	app.GET("/login", client.LoginHandler())
+	app.GET("/callback", client.CallbackHandler(func(c *quark.Context, token *Token) error {
+		got = token
+		return c.NoContent()
+	}))
+
+	cookie, params := login(t, app)
+	state := params.Get("state")
+	if state == "" || params.Get("code_challenge_method") != "S256" || params.Get("client_id") != "client" {
+		t.Fatalf("unexpected authorization parameters %v", params)
+	}
+	otherCookie := &http.Cookie{Name: cookie.Name, Value: "forged.verifier."}
+
+	tests := []struct {
+		name       string
+		query      string
+		cookie     *http.Cookie
+		wantStatus int
+	}{
+		{"valid", "state=" + state + "&code=good-code", cookie, http.StatusNoContent},
+		{"state mismatch", "state=forged&code=good-code", cookie, http.StatusBadRequest},
+		{"missing state", "code=good-code", cookie, http.StatusBadRequest},
+		{"state of another login", "state=" + state + "&code=good-code", otherCookie, http.StatusBadRequest},
+		{"missing cookie", "state=" + state + "&code=good-code", nil, http.StatusBadRequest},
+		{"missing code", "state=" + state, cookie, http.StatusBadRequest},
+		{"denied", "error=access_denied&state=" + state, cookie, http.StatusUnauthorized},
+		{"rejected code", "state=" + state + "&code=bad-code", cookie, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/callback?"+tt.query, nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if (got != nil) != (tt.wantStatus == http.StatusNoContent) {
+				t.Errorf("expected onSuccess called only for a valid callback, got %v", got)
+			}
+		})
+	}
+
+	// The verifier sent to the provider matches the challenge of the login
+	sum := sha256.Sum256([]byte(p.form.Get("code_verifier")))
+	if challenge := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != params.Get("code_challenge") {
+		t.Errorf("expected the PKCE verifier of the login, got challenge %s", challenge)
+	}
+}
+
+func TestCallbackHandlerNonce(t *testing.T) {
+	p := newFakeProvider(t)
+	client := New(Config{Provider: p.provider(true), ClientID: "client"})
+
+	var got *Token
+	app := quark.New()
+	app.GET("/login", client.LoginHandler())
+	app.GET("/callback", client.CallbackHandler(func(c *quark.Context, token *Token) error {
+		got = token
+		return c.NoContent()
+	}))
+
+	tests := []struct {
+		name       string
+		nonce      func(login string) string
+		wantStatus int
+	}{
+		{"matching nonce", func(login string) string { return login }, http.StatusNoContent},
+		{"nonce mismatch", func(login string) string { return "replayed" }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			cookie, params := login(t, app)
+			p.nonce = tt.nonce(params.Get("nonce"))
+
+			req := httptest.NewRequest(http.MethodGet, "/callback?code=good-code&state="+params.Get("state"), nil)
+			req.AddCookie(cookie)
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusNoContent && (got == nil || got.IDClaims.Subject != "user1") {
+				t.Errorf("expected the verified ID token claims, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestExchangeError(t *testing.T) {
+	p := newFakeProvider(t)
+	client := New(Config{Provider: p.provider(false), ClientID: "client"})
+
+	_, err := client.Exchange(t.Context(), "bad-code", "")
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_grant" {
+		t.Errorf("expected an invalid_grant error, got %v", err)
+	}
+}
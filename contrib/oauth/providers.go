@@ -0,0 +1,53 @@
+package oauth
+
+// Provider describes the endpoints of an OAuth2 authorization server.
+// Issuer and JWKSURL are only set for OpenID Connect providers, whose ID
+// tokens can then be verified.
+type Provider struct {
+	// Name identifies the provider (e.g., "google").
+	Name string
+
+	// AuthURL is the authorization endpoint users are redirected to.
+	AuthURL string
+
+	// TokenURL is the endpoint that exchanges codes for tokens.
+	TokenURL string
+
+	// UserInfoURL returns the profile of the token's user.
+	UserInfoURL string
+
+	// Issuers are the accepted iss claims of ID tokens.
+	Issuers []string
+
+	// JWKSURL publishes the keys that sign ID tokens.
+	JWKSURL string
+
+	// Scopes are requested when Config.Scopes is empty.
+	Scopes []string
+}
+
+// OIDC reports whether the provider issues verifiable ID tokens.
+func (p Provider) OIDC() bool {
+	return p.JWKSURL != "" && len(p.Issuers) > 0
+}
+
+// Google is the Google OpenID Connect provider.
+var Google = Provider{
+	Name:        "google",
+	AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+	TokenURL:    "https://oauth2.googleapis.com/token",
+	UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+	Issuers:     []string{"https://accounts.google.com", "accounts.google.com"},
+	JWKSURL:     "https://www.googleapis.com/oauth2/v3/certs",
+	Scopes:      []string{"openid", "email", "profile"},
+}
+
+// GitHub is the GitHub OAuth2 provider. GitHub does not issue ID tokens;
+// use UserInfo to fetch the user's profile.
+var GitHub = Provider{
+	Name:        "github",
+	AuthURL:     "https://github.com/login/oauth/authorize",
+	TokenURL:    "https://github.com/login/oauth/access_token",
+	UserInfoURL: "https://api.github.com/user",
+	Scopes:      []string{"read:user", "user:email"},
+}
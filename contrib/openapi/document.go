@@ -0,0 +1,333 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Document is a parsed OpenAPI 3 document, holding the parts used to
+// validate requests: paths, operations, parameters, request bodies and
+// component schemas.
+type Document struct {
+	routes     []*route
+	components components
+}
+
+// spec is the JSON structure of an OpenAPI document.
+type spec struct {
+	OpenAPI    string               `json:"openapi"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components components           `json:"components"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `json:"schemas"`
+	Parameters    map[string]*parameter   `json:"parameters"`
+	RequestBodies map[string]*requestBody `json:"requestBodies"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `json:"parameters"`
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Options    *operation   `json:"options"`
+	Head       *operation   `json:"head"`
+	Patch      *operation   `json:"patch"`
+}
+
+type operation struct {
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *requestBody `json:"requestBody"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Ref      string                `json:"$ref"`
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// schema is the subset of JSON Schema validated by the middleware.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     json.RawMessage    `json:"exclusiveMinimum"`
+	ExclusiveMaximum     json.RawMessage    `json:"exclusiveMaximum"`
+	AllOf                []*schema          `json:"allOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	OneOf                []*schema          `json:"oneOf"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaType is a JSON Schema type: one name in OpenAPI 3.0, or a list of
+// names in OpenAPI 3.1, where "null" replaces nullable.
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = schemaType{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("invalid schema type %s", data)
+	}
+	*t = names
+	return nil
+}
+
+// additional is additionalProperties: false, true or a schema.
+type additional struct {
+	allowed bool
+	schema  *schema
+}
+
+func (a *additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(data, &a.schema)
+}
+
+// route is an operation with its path matcher.
+type route struct {
+	method      string
+	path        string
+	regex       *regexp.Regexp
+	paramNames  []string
+	parameters  []*parameter
+	requestBody *requestBody
+}
+
+// pathParam matches the parameters of OpenAPI path templates.
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Load parses an OpenAPI 3 document in JSON.
+func Load(data []byte) (*Document, error) {
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("openapi: parsing document: %w", err)
+	}
+	if !strings.HasPrefix(s.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version %q, expected 3.x", s.OpenAPI)
+	}
+
+	doc := &Document{components: s.Components}
+
+	// Literal paths win over templated ones, as OpenAPI requires
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ti, tj := strings.Count(paths[i], "{"), strings.Count(paths[j], "{")
+		if ti != tj {
+			return ti < tj
+		}
+		return paths[i] < paths[j]
+	})
+
+	for _, path := range paths {
+		item := s.Paths[path]
+		regex, names := compilePath(path)
+		for method, op := range map[string]*operation{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+			"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			r := &route{
+				method:      method,
+				path:        path,
+				regex:       regex,
+				paramNames:  names,
+				parameters:  doc.mergeParameters(item.Parameters, op.Parameters),
+				requestBody: doc.resolveBody(op.RequestBody),
+			}
+			doc.routes = append(doc.routes, r)
+		}
+	}
+
+	if err := doc.compilePatterns(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadFile parses an OpenAPI 3 document from a JSON file.
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return Load(data)
+}
+
+// compilePath converts a path template such as /users/{id} to a regex.
+func compilePath(path string) (*regexp.Regexp, []string) {
+	var names []string
+	pattern := "^"
+	last := 0
+	for _, m := range pathParam.FindAllStringSubmatchIndex(path, -1) {
+		pattern += regexp.QuoteMeta(path[last:m[0]]) + "([^/]+)"
+		names = append(names, path[m[2]:m[3]])
+		last = m[1]
+	}
+	pattern += regexp.QuoteMeta(path[last:]) + "$"
+	return regexp.MustCompile(pattern), names
+}
+
+// find returns the route of a request and its path parameters, and
+// whether the path exists for another method.
+func (d *Document) find(method, path string) (*route, map[string]string, bool) {
+	var pathMatched bool
+	for _, r := range d.routes {
+		m := r.regex.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+		pathMatched = true
+		if r.method != method {
+			continue
+		}
+		params := make(map[string]string, len(r.paramNames))
+		for i, name := range r.paramNames {
+			params[name] = m[i+1]
+		}
+		return r, params, true
+	}
+	return nil, nil, pathMatched
+}
+
+// mergeParameters resolves the path item and operation parameters, the
+// operation overriding parameters with the same name and location.
+func (d *Document) mergeParameters(shared, own []*parameter) []*parameter {
+	var merged []*parameter
+	index := make(map[string]int)
+	for _, p := range append(append([]*parameter{}, shared...), own...) {
+		p = d.resolveParameter(p)
+		if p == nil {
+			continue
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			merged[i] = p
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// resolveParameter follows a parameter reference.
+func (d *Document) resolveParameter(p *parameter) *parameter {
+	for i := 0; p != nil && p.Ref != "" && i < 32; i++ {
+		p = d.components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	}
+	return p
+}
+
+// resolveBody follows a request body reference.
+func (d *Document) resolveBody(b *requestBody) *requestBody {
+	for i := 0; b != nil && b.Ref != "" && i < 32; i++ {
+		b = d.components.RequestBodies[strings.TrimPrefix(b.Ref, "#/components/requestBodies/")]
+	}
+	return b
+}
+
+// resolve follows a schema reference.
+func (d *Document) resolve(s *schema) *schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		s = d.components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// compilePatterns compiles the pattern of every schema.
+func (d *Document) compilePatterns() error {
+	seen := make(map[*schema]bool)
+	var walk func(s *schema) error
+	walk = func(s *schema) error {
+		if s == nil || seen[s] {
+			return nil
+		}
+		seen[s] = true
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return fmt.Errorf("openapi: invalid pattern %q: %w", s.Pattern, err)
+			}
+			s.pattern = re
+		}
+		children := append(append(append([]*schema{s.Items}, s.AllOf...), s.AnyOf...), s.OneOf...)
+		for _, p := range s.Properties {
+			children = append(children, p)
+		}
+		if s.AdditionalProperties != nil {
+			children = append(children, s.AdditionalProperties.schema)
+		}
+		for _, child := range children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, s := range d.components.Schemas {
+		if err := walk(s); err != nil {
+			return err
+		}
+	}
+	for _, r := range d.routes {
+		for _, p := range r.parameters {
+			if err := walk(p.Schema); err != nil {
+				return err
+			}
+		}
+		if r.requestBody != nil {
+			for _, media := range r.requestBody.Content {
+				if err := walk(media.Schema); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
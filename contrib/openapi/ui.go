@@ -0,0 +1,104 @@
+// Package openapi provides OpenAPI tooling for the Quark framework:
+// request validation against an OpenAPI 3 document, so the implementation
+// cannot drift from its contract, and a bundled documentation UI served
+// from the binary without external assets.
+//
+// Basic usage:
+//
+//	doc, err := openapi.Load(specJSON)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.Use(openapi.Validator(doc))
+//
+//	app.GET("/openapi.json", func(c *quark.Context) error {
+//	    return c.Blob(200, "application/json", specJSON)
+//	})
+//	openapi.MountUI(app, "/docs", "/openapi.json")
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+//go:embed ui/index.html
+var uiFS embed.FS
+
+// uiTemplate renders the documentation page.
+var uiTemplate = template.Must(template.ParseFS(uiFS, "ui/index.html"))
+
+// UIConfig defines the configuration of the documentation UI.
+type UIConfig struct {
+	// SpecURL is the URL of the OpenAPI document, in JSON.
+	SpecURL string
+
+	// Title is the page title (default: "API Documentation").
+	Title string
+}
+
+// DefaultUIConfig is the default documentation UI configuration.
+var DefaultUIConfig = UIConfig{
+	SpecURL: "/openapi.json",
+	Title:   "API Documentation",
+}
+
+// UIHandler returns a handler serving a documentation UI for the OpenAPI
+// document at specURL. The page lists the operations by tag with their
+// parameters, request bodies and responses, with examples built from the
+// schemas.
+//
+// Example:
+//
+//	app.GET("/docs", openapi.UIHandler("/openapi.json"), middleware.BasicAuth(checkAdmin))
+func UIHandler(specURL string) quark.HandlerFunc {
+	config := DefaultUIConfig
+	config.SpecURL = specURL
+	return UIHandlerWithConfig(config)
+}
+
+// UIHandlerWithConfig returns a documentation UI handler with custom
+// configuration.
+func UIHandlerWithConfig(config UIConfig) quark.HandlerFunc {
+	if config.SpecURL == "" {
+		panic("openapi UI requires a SpecURL")
+	}
+	if config.Title == "" {
+		config.Title = DefaultUIConfig.Title
+	}
+
+	var buf bytes.Buffer
+	if err := uiTemplate.Execute(&buf, config); err != nil {
+		panic("openapi UI: " + err.Error())
+	}
+	page := buf.Bytes()
+
+	return func(c *quark.Context) error {
+		c.SetHeader("Cache-Control", "no-cache")
+		return c.Blob(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
+
+// MountUI mounts the documentation UI at path. The middleware, such as an
+// authentication check, applies to the page. In production the UI is only
+// mounted when middleware is given, since the document describes every
+// endpoint of the API.
+//
+// Example:
+//
+//	openapi.MountUI(app, "/docs", "/openapi.json")
+//
+//	// In production, behind authentication
+//	openapi.MountUI(app, "/docs", "/openapi.json", middleware.BasicAuth(checkAdmin))
+func MountUI(app *quark.App, path, specURL string, mw ...quark.MiddlewareFunc) {
+	if len(mw) == 0 && app.Config() != nil && app.Config().IsProduction() {
+		app.Log(slog.LevelWarn, "openapi documentation UI not mounted in production without middleware", "path", path)
+		return
+	}
+	app.GET(path, UIHandler(specURL), mw...)
+}
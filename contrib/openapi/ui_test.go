@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// printfLogger records log lines.
+type printfLogger struct {
+	lines []string
+}
+
+func (l *printfLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestMountUI(t *testing.T) {
+	app := quark.New()
+	MountUI(app, "/docs", "/openapi.json")
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "/openapi.json") {
+		t.Error("expected the page to reference the spec URL")
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("expected Cache-Control no-cache, got %q", got)
+	}
+}
+
+func TestMountUIProduction(t *testing.T) {
+	cfg := quark.DefaultConfig()
+	cfg.Environment = "production"
+	logger := &printfLogger{}
+	app := quark.New(quark.WithConfig(cfg), quark.WithLogger(logger))
+	MountUI(app, "/docs", "/openapi.json")
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "not mounted") {
+		t.Errorf("expected a warning, got %v", logger.lines)
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected the UI disabled in production, got %d", rec.Code)
+	}
+
+	denied := func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			return quark.ErrUnauthorized("")
+		}
+	}
+	MountUI(app, "/admin/docs", "/openapi.json", denied)
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/docs", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected the UI behind middleware, got %d", rec.Code)
+	}
+}
+
+func TestMountUIProductionWarning(t *testing.T) {
+	cfg := quark.DefaultConfig()
+	cfg.Environment = "production"
+	var buf bytes.Buffer
+	app := quark.New(quark.WithConfig(cfg), quark.WithSlog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	MountUI(app, "/docs", "/openapi.json")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q", buf.String())
+	}
+	if record["level"] != "WARN" || !strings.Contains(record["msg"].(string), "not mounted") || record["path"] != "/docs" {
+		t.Errorf("expected a warning, got %v", record)
+	}
+}
@@ -0,0 +1,496 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// ValidatorConfig defines the configuration of the request validator.
+type ValidatorConfig struct {
+	// Document is the OpenAPI document requests must conform to.
+	Document *Document
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// RejectUnknown rejects requests for operations missing from the
+	// document with 404 or 405 errors. By default they are passed on, for
+	// routes left out of the document such as health checks.
+	RejectUnknown bool
+
+	// MaxBodySize is the largest request body validated, in bytes; larger
+	// bodies are rejected with 413 (default: 10MB).
+	MaxBodySize int64
+}
+
+// Validator returns a middleware validating requests against an OpenAPI
+// document: path, query, header and cookie parameters, the content type
+// and the JSON or form body. Requests that do not conform are rejected
+// with a 400 *quark.HTTPError whose Details map each invalid location to
+// a message, or with 415 for unsupported content types:
+//
+//	{"error":{"code":400,"message":"request does not match the API specification",
+//	 "details":{"query.limit":"must be at most 100","body.email":"is required"}}}
+//
+// Example:
+//
+//	doc, err := openapi.LoadFile("openapi.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.Use(openapi.Validator(doc))
+func Validator(doc *Document) quark.MiddlewareFunc {
+	return ValidatorWithConfig(ValidatorConfig{Document: doc})
+}
+
+// ValidatorWithConfig returns a request validator with custom
+// configuration.
+func ValidatorWithConfig(config ValidatorConfig) quark.MiddlewareFunc {
+	if config.Document == nil {
+		panic("openapi validator requires a Document")
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 10 << 20
+	}
+	doc := config.Document
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			r, pathParams, pathMatched := doc.find(c.Method(), c.Path())
+			if r == nil {
+				if !config.RejectUnknown {
+					return next(c)
+				}
+				if pathMatched {
+					return quark.ErrMethodNotAllowed("")
+				}
+				return quark.ErrNotFound("")
+			}
+
+			errs := make(map[string]string)
+			doc.validateParameters(c, r, pathParams, errs)
+			if err := doc.validateBody(c, r, config.MaxBodySize, errs); err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				err := quark.NewHTTPError(http.StatusBadRequest, "request does not match the API specification")
+				err.Details = errs
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// validateParameters validates the parameters of an operation.
+func (d *Document) validateParameters(c *quark.Context, r *route, pathParams map[string]string, errs map[string]string) {
+	query := c.Request.URL.Query()
+	for _, p := range r.parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = c.Request.Header.Values(p.Name)
+		case "cookie":
+			if cookie, err := c.Request.Cookie(p.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+
+		key := p.In + "." + p.Name
+		if len(values) == 0 {
+			if p.Required || p.In == "path" {
+				errs[key] = "is required"
+			}
+			continue
+		}
+		if p.Schema == nil {
+			continue
+		}
+		if v, ok := d.coerceParameter(p.Schema, values, key, errs); ok {
+			d.validateValue(p.Schema, v, key, errs)
+		}
+	}
+}
+
+// coerceParameter converts the string values of a parameter to the type
+// of its schema.
+func (d *Document) coerceParameter(s *schema, values []string, key string, errs map[string]string) (interface{}, bool) {
+	s = d.resolve(s)
+	if s.Type.has("array") {
+		// Repeated (?id=1&id=2) or comma-separated (?id=1,2) values
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			item, ok := d.coerce(s.Items, value)
+			if !ok {
+				errs[fmt.Sprintf("%s[%d]", key, i)] = "must be " + d.typeName(s.Items)
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	}
+
+	v, ok := d.coerce(s, values[0])
+	if !ok {
+		errs[key] = "must be " + d.typeName(s)
+	}
+	return v, ok
+}
+
+// coerce converts a string to the type of a schema.
+func (d *Document) coerce(s *schema, value string) (interface{}, bool) {
+	s = d.resolve(s)
+	switch {
+	case s == nil:
+		return value, true
+	case s.Type.has("integer"):
+		n, err := strconv.ParseInt(value, 10, 64)
+		return float64(n), err == nil
+	case s.Type.has("number"):
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	case s.Type.has("boolean"):
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	}
+	return value, true
+}
+
+// validateBody validates the content type and body of a request. It
+// returns an error for responses other than a 400.
+func (d *Document) validateBody(c *quark.Context, r *route, maxSize int64, errs map[string]string) error {
+	rb := r.requestBody
+	if rb == nil || c.Request.Body == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSize+1))
+	if err != nil {
+		return quark.ErrBadRequest("reading request body failed")
+	}
+	if int64(len(data)) > maxSize {
+		return quark.NewHTTPError(http.StatusRequestEntityTooLarge, "request body too large")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(data) == 0 {
+		if rb.Required {
+			errs["body"] = "is required"
+		}
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.Header("Content-Type"))
+	media, ok := matchMediaType(rb.Content, contentType)
+	if !ok {
+		types := make([]string, 0, len(rb.Content))
+		for t := range rb.Content {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		err := quark.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content type")
+		err.Details = map[string]string{"content_type": "must be one of " + strings.Join(types, ", ")}
+		return err
+	}
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+
+	switch {
+	case contentType == "application/json" || strings.HasSuffix(contentType, "+json"):
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			errs["body"] = "must be valid JSON"
+			return nil
+		}
+		d.validateValue(media.Schema, v, "body", errs)
+
+	case contentType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			errs["body"] = "must be a valid form"
+			return nil
+		}
+		s := d.resolve(media.Schema)
+		obj := make(map[string]interface{}, len(form))
+		for name, values := range form {
+			key := "body." + name
+			if prop, ok := s.Properties[name]; ok {
+				if v, ok := d.coerceParameter(prop, values, key, errs); ok {
+					obj[name] = v
+				}
+				continue
+			}
+			obj[name] = values[0]
+		}
+		d.validateValue(s, obj, "body", errs)
+	}
+	return nil
+}
+
+// matchMediaType returns the media type of a body content map matching a
+// content type, trying exact, then "type/*", then "*/*" entries.
+func matchMediaType(content map[string]*mediaType, contentType string) (*mediaType, bool) {
+	if len(content) == 0 {
+		return nil, true
+	}
+	if media, ok := content[contentType]; ok {
+		return media, true
+	}
+	if i := strings.IndexByte(contentType, '/'); i != -1 {
+		if media, ok := content[contentType[:i]+"/*"]; ok {
+			return media, true
+		}
+	}
+	media, ok := content["*/*"]
+	return media, ok
+}
+
+// validateValue validates a decoded JSON value against a schema, adding
+// a message per invalid location to errs.
+func (d *Document) validateValue(s *schema, v interface{}, path string, errs map[string]string) {
+	s = d.resolve(s)
+	if s == nil {
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		d.validateValue(sub, v, path, errs)
+	}
+	if len(s.AnyOf) > 0 && d.countMatches(s.AnyOf, v) == 0 {
+		errs[path] = "must match at least one of the allowed schemas"
+		return
+	}
+	if len(s.OneOf) > 0 && d.countMatches(s.OneOf, v) != 1 {
+		errs[path] = "must match exactly one of the allowed schemas"
+		return
+	}
+
+	if v == nil {
+		if len(s.Type) > 0 && !s.Nullable && !s.Type.has("null") {
+			errs[path] = "must not be null"
+		}
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		errs[path] = "must be " + d.typeName(s)
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(allowed, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(s.Enum))
+			for i, allowed := range s.Enum {
+				names[i] = fmt.Sprint(allowed)
+			}
+			errs[path] = "must be one of " + strings.Join(names, ", ")
+			return
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		validateString(s, v, path, errs)
+	case float64:
+		validateNumber(s, v, path, errs)
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			errs[path] = fmt.Sprintf("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			errs[path] = fmt.Sprintf("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				d.validateValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				errs[path+"."+name] = "is required"
+			}
+		}
+		for name, value := range v {
+			if prop, ok := s.Properties[name]; ok {
+				d.validateValue(prop, value, path+"."+name, errs)
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.allowed {
+				errs[path+"."+name] = "is not allowed"
+			} else if s.AdditionalProperties.schema != nil {
+				d.validateValue(s.AdditionalProperties.schema, value, path+"."+name, errs)
+			}
+		}
+	}
+}
+
+// countMatches returns how many schemas a value is valid against.
+func (d *Document) countMatches(schemas []*schema, v interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		subErrs := make(map[string]string)
+		d.validateValue(sub, v, "", subErrs)
+		if len(subErrs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// formats are the string formats checked by the validator.
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool { _, err := time.Parse(time.RFC3339, s); return err == nil },
+	"date":      func(s string) bool { _, err := time.Parse("2006-01-02", s); return err == nil },
+	"email":     func(s string) bool { a, err := mail.ParseAddress(s); return err == nil && a.Address == s },
+	"uuid":      regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+// validateString validates the string constraints of a schema.
+func validateString(s *schema, v, path string, errs map[string]string) {
+	length := utf8.RuneCountInString(v)
+	switch {
+	case s.MinLength != nil && length < *s.MinLength:
+		errs[path] = fmt.Sprintf("must be at least %d characters", *s.MinLength)
+	case s.MaxLength != nil && length > *s.MaxLength:
+		errs[path] = fmt.Sprintf("must be at most %d characters", *s.MaxLength)
+	case s.pattern != nil && !s.pattern.MatchString(v):
+		errs[path] = "must match the pattern " + s.Pattern
+	case formats[s.Format] != nil && !formats[s.Format](v):
+		errs[path] = "must be a valid " + s.Format
+	}
+}
+
+// validateNumber validates the numeric constraints of a schema, with
+// exclusive bounds as booleans (OpenAPI 3.0) or numbers (OpenAPI 3.1).
+func validateNumber(s *schema, v float64, path string, errs map[string]string) {
+	if s.Minimum != nil {
+		if exclusive(s.ExclusiveMinimum) && v <= *s.Minimum {
+			errs[path] = fmt.Sprintf("must be greater than %v", *s.Minimum)
+		} else if v < *s.Minimum {
+			errs[path] = fmt.Sprintf("must be at least %v", *s.Minimum)
+		}
+	}
+	if s.Maximum != nil {
+		if exclusive(s.ExclusiveMaximum) && v >= *s.Maximum {
+			errs[path] = fmt.Sprintf("must be less than %v", *s.Maximum)
+		} else if v > *s.Maximum {
+			errs[path] = fmt.Sprintf("must be at most %v", *s.Maximum)
+		}
+	}
+	var bound float64
+	if json.Unmarshal(s.ExclusiveMinimum, &bound) == nil && v <= bound {
+		errs[path] = fmt.Sprintf("must be greater than %v", bound)
+	}
+	if json.Unmarshal(s.ExclusiveMaximum, &bound) == nil && v >= bound {
+		errs[path] = fmt.Sprintf("must be less than %v", bound)
+	}
+}
+
+// exclusive reports whether an OpenAPI 3.0 exclusive bound flag is set.
+func exclusive(raw json.RawMessage) bool {
+	var b bool
+	return json.Unmarshal(raw, &b) == nil && b
+}
+
+// has reports whether a schema type includes name.
+func (t schemaType) has(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a decoded JSON value has one of the types.
+func (t schemaType) matches(v interface{}) bool {
+	for _, name := range t {
+		switch v := v.(type) {
+		case string:
+			if name == "string" {
+				return true
+			}
+		case float64:
+			if name == "number" || name == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName describes the type of a schema for error messages.
+func (d *Document) typeName(s *schema) string {
+	s = d.resolve(s)
+	if s == nil || len(s.Type) == 0 {
+		return "valid"
+	}
+	names := make([]string, 0, len(s.Type))
+	for _, name := range s.Type {
+		if name == "integer" || name == "object" || name == "array" {
+			name = "an " + name
+		} else if name != "null" {
+			name = "a " + name
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " or ")
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+const testSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["active", "disabled"]}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+        },
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+      "get": {"responses": {"200": {"description": "OK"}}}
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {
+        "type": "object",
+        "required": ["email"],
+        "additionalProperties": false,
+        "properties": {
+          "email": {"type": "string", "format": "email"},
+          "age": {"type": "integer", "minimum": 0}
+        }
+      }
+    }
+  }
+}`
+
+// newValidatedApp returns an app validating requests against testSpec.
+func newValidatedApp(t *testing.T, config ValidatorConfig) *quark.App {
+	t.Helper()
+	doc, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	config.Document = doc
+
+	app := quark.New()
+	app.Use(ValidatorWithConfig(config))
+	ok := func(c *quark.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+	app.GET("/users", ok)
+	app.POST("/users", ok)
+	app.GET("/users/{id}", ok)
+	app.DELETE("/users/{id}", ok)
+	app.GET("/health", ok)
+	return app
+}
+
+func TestValidator(t *testing.T) {
+	app := newValidatedApp(t, ValidatorConfig{})
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantDetails map[string]string
+	}{
+		{name: "valid query", method: http.MethodGet, target: "/users?limit=10&status=active", wantStatus: http.StatusOK},
+		{name: "query above maximum", method: http.MethodGet, target: "/users?limit=500", wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"query.limit": "must be at most 100"}},
+		{name: "query not integer", method: http.MethodGet, target: "/users?limit=ten", wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"query.limit": "must be an integer"}},
+		{name: "query not in enum", method: http.MethodGet, target: "/users?status=deleted", wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"query.status": "must be one of active, disabled"}},
+		{name: "valid path", method: http.MethodGet, target: "/users/42", wantStatus: http.StatusOK},
+		{name: "path not integer", method: http.MethodGet, target: "/users/abc", wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"path.id": "must be an integer"}},
+		{name: "valid body", method: http.MethodPost, target: "/users", contentType: "application/json",
+			body: `{"email":"ada@example.com","age":36}`, wantStatus: http.StatusOK},
+		{name: "missing property", method: http.MethodPost, target: "/users", contentType: "application/json",
+			body: `{"age":36}`, wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"body.email": "is required"}},
+		{name: "unknown property", method: http.MethodPost, target: "/users", contentType: "application/json",
+			body: `{"email":"ada@example.com","admin":true}`, wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"body.admin": "is not allowed"}},
+		{name: "below minimum", method: http.MethodPost, target: "/users", contentType: "application/json",
+			body: `{"email":"ada@example.com","age":-1}`, wantStatus: http.StatusBadRequest,
+			wantDetails: map[string]string{"body.age": "must be at least 0"}},
+		{name: "unsupported content type", method: http.MethodPost, target: "/users", contentType: "text/plain",
+			body: "ada@example.com", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "undocumented route", method: http.MethodGet, target: "/health", wantStatus: http.StatusOK},
+		{name: "undocumented method", method: http.MethodDelete, target: "/users/42", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantDetails == nil {
+				return
+			}
+
+			var resp struct {
+				Error struct {
+					Details map[string]string `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unexpected body %q: %v", rec.Body.String(), err)
+			}
+			for key, want := range tt.wantDetails {
+				if got := resp.Error.Details[key]; got != want {
+					t.Errorf("expected %s %q, got %q (details %v)", key, want, got, resp.Error.Details)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatorRejectUnknown(t *testing.T) {
+	app := newValidatedApp(t, ValidatorConfig{RejectUnknown: true})
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{"documented", http.MethodGet, "/users/42", http.StatusOK},
+		{"unknown path", http.MethodGet, "/health", http.StatusNotFound},
+		{"unknown method", http.MethodDelete, "/users/42", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestValidatorMaxBodySize(t *testing.T) {
+	app := newValidatedApp(t, ValidatorConfig{MaxBodySize: 32})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"within limit", `{"email":"ada@example.com"}`, http.StatusOK},
+		{"too large", `{"email":"ada@example.com","age":36,"padding":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":    `openapi: 3.0.3`,
+		"bad pattern": `{"openapi":"3.0.3","paths":{"/a":{"get":{"parameters":[{"name":"q","in":"query","schema":{"type":"string","pattern":"("}}]}}}}`,
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load([]byte(spec)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package profiling mounts the net/http/pprof profiles and the expvar
+// variables on a Quark application, so CPU and heap profiles can be
+// captured from a running service.
+//
+// It is a separate package because net/http/pprof and expvar register
+// their handlers on http.DefaultServeMux when imported: only programs
+// importing this package get /debug/pprof/ and /debug/vars there.
+//
+// Basic usage:
+//
+//	profiling.Enable(app, "/debug")
+//	// go tool pprof http://localhost:8080/debug/pprof/heap
+package profiling
+
+import (
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Enable mounts the profiles and variables under prefix:
+//
+//	{prefix}/pprof/          profile index
+//	{prefix}/pprof/profile   CPU profile
+//	{prefix}/pprof/heap      heap profile (and the other runtime profiles)
+//	{prefix}/pprof/trace     execution trace
+//	{prefix}/vars            expvar variables as JSON
+//
+// The middleware, such as an authentication check, applies to all of
+// them. In production the endpoints are only mounted when middleware is
+// given, since profiles expose internals and are costly to capture.
+//
+// Example:
+//
+//	profiling.Enable(app, "/debug")
+//
+//	// In production, behind authentication
+//	profiling.Enable(app, "/debug", middleware.BasicAuth(checkAdmin))
+func Enable(app *quark.App, prefix string, mw ...quark.MiddlewareFunc) {
+	if len(mw) == 0 && app.Config() != nil && app.Config().IsProduction() {
+		app.Log(slog.LevelWarn, "profiling endpoints not mounted in production without middleware", "prefix", prefix)
+		return
+	}
+
+	g := app.Group(prefix, mw...)
+	index := strings.TrimSuffix(prefix, "/") + "/pprof/"
+
+	g.GET("/pprof", func(c *quark.Context) error {
+		// The index links to profiles relative to its URL
+		if !strings.HasSuffix(c.Path(), "/") {
+			http.Redirect(c.Writer, c.Request, index, http.StatusMovedPermanently)
+			return nil
+		}
+		pprof.Index(c.Writer, c.Request)
+		return nil
+	})
+	g.GET("/pprof/cmdline", wrap(pprof.Cmdline))
+	g.GET("/pprof/profile", wrap(pprof.Profile))
+	g.GET("/pprof/symbol", wrap(pprof.Symbol))
+	g.POST("/pprof/symbol", wrap(pprof.Symbol))
+	g.GET("/pprof/trace", wrap(pprof.Trace))
+	g.GET("/pprof/{name}", func(c *quark.Context) error {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		return nil
+	})
+	g.GET("/vars", wrap(expvar.Handler().ServeHTTP))
+}
+
+// wrap adapts a net/http handler function to a quark.HandlerFunc.
+func wrap(h http.HandlerFunc) quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		h(c.Writer, c.Request)
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package profiling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+type printfLogger struct {
+	lines []string
+}
+
+func (l *printfLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestEnable(t *testing.T) {
+	app := quark.New()
+	Enable(app, "/debug")
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/debug/pprof", http.StatusMovedPermanently},
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/pprof/heap", http.StatusOK},
+		{"/debug/pprof/cmdline", http.StatusOK},
+		{"/debug/vars", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s: expected %d, got %d", tt.path, tt.want, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if !strings.Contains(rec.Body.String(), `"memstats"`) {
+		t.Error("expected expvar variables")
+	}
+}
+
+func TestEnableProduction(t *testing.T) {
+	cfg := quark.DefaultConfig()
+	cfg.Environment = "production"
+	logger := &printfLogger{}
+	app := quark.New(quark.WithConfig(cfg), quark.WithLogger(logger))
+	Enable(app, "/debug")
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "not mounted") {
+		t.Errorf("expected a warning, got %v", logger.lines)
+	}
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/heap", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected profiling disabled in production, got %d", rec.Code)
+	}
+
+	denied := func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			return quark.ErrUnauthorized("")
+		}
+	}
+	Enable(app, "/admin/debug", denied)
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/debug/pprof/heap", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected profiling behind middleware, got %d", rec.Code)
+	}
+}
+
+func TestEnableProductionWarning(t *testing.T) {
+	cfg := quark.DefaultConfig()
+	cfg.Environment = "production"
+	var buf bytes.Buffer
+	app := quark.New(quark.WithConfig(cfg), quark.WithSlog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	Enable(app, "/debug")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q", buf.String())
+	}
+	if record["level"] != "WARN" || !strings.Contains(record["msg"].(string), "not mounted") || record["prefix"] != "/debug" {
+		t.Errorf("expected a warning, got %v", record)
+	}
+}
@@ -0,0 +1,238 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+	"github.com/AchrafSoltani/quark/contrib/jwt"
+)
+
+// QuotaStore counts requests per key over sliding windows. Implementations
+// must be safe for concurrent use.
+type QuotaStore interface {
+	// Hit adds one to the counter of key for the window starting at start
+	// and returns the counts of that window and of the previous one.
+	// Counters must outlive their window by another window length.
+	Hit(ctx context.Context, key string, start time.Time, window time.Duration) (current, previous int64, err error)
+}
+
+// Quota is a request budget over a rolling window.
+type Quota struct {
+	// Limit is the number of requests allowed in any Window.
+	Limit int64
+
+	// Window is the length of the rolling window.
+	Window time.Duration
+}
+
+// validate checks that the quota has a positive Limit and Window.
+func (q Quota) validate() error {
+	if q.Limit <= 0 || q.Window <= 0 {
+		return fmt.Errorf("invalid quota: limit %d per %s, both must be positive", q.Limit, q.Window)
+	}
+	return nil
+}
+
+// QuotaConfig holds quota middleware configuration.
+type QuotaConfig struct {
+	// Quotas are the budgets each key must stay within, such as 100 per
+	// minute and 10000 per day.
+	Quotas []Quota
+
+	// QuotaFunc returns the quotas of a key, overriding Quotas, e.g. to
+	// give each plan its own budget. No quotas lets the request through;
+	// a quota without a positive Limit and Window fails the request with
+	// an error.
+	QuotaFunc func(c *quark.Context, key string) []Quota
+
+	// Store holds the counters (default: a new MemoryStore).
+	Store QuotaStore
+
+	// KeyFunc returns the key requests are counted under. Requests with an
+	// empty key are not limited (default: AuthenticatedKey).
+	KeyFunc func(*quark.Context) string
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// ErrorHandler is called when a quota is exceeded
+	// (default: a 429 Too Many Requests error).
+	ErrorHandler func(*quark.Context, error) error
+}
+
+// Quotas returns a middleware that enforces per-key request budgets over
+// rolling windows, for API keys or users rather than client IPs. Place it
+// after the authentication middleware setting the key.
+//
+// Each window's count is estimated from the current and previous fixed
+// windows, weighted by their overlap with the rolling window. The
+// X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset headers describe the
+// quota closest to exhaustion, with Retry-After when one is exceeded. Store
+// errors let the request through.
+//
+// Example:
+//
+//	api := app.Group("/api", middleware.APIKey(lookupKey))
+//	api.Use(ratelimit.Quotas(ratelimit.QuotaConfig{
+//	    Quotas: []ratelimit.Quota{
+//	        {Limit: 100, Window: time.Minute},
+//	        {Limit: 10000, Window: 24 * time.Hour},
+//	    },
+//	}))
+func Quotas(config QuotaConfig) quark.MiddlewareFunc {
+	if len(config.Quotas) == 0 && config.QuotaFunc == nil {
+		panic("quota middleware requires Quotas or a QuotaFunc")
+	}
+	for _, q := range config.Quotas {
+		if err := q.validate(); err != nil {
+			panic("quota middleware requires a positive Limit and Window")
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = AuthenticatedKey
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+			quotas := config.Quotas
+			if config.QuotaFunc != nil {
+				quotas = config.QuotaFunc(c, key)
+				for _, q := range quotas {
+					if err := q.validate(); err != nil {
+						return err
+					}
+				}
+			}
+
+			now := time.Now()
+			var tightest *quotaUsage
+			for _, q := range quotas {
+				usage, err := hitQuota(c.Context(), config.Store, key, q, now)
+				if err != nil {
+					continue
+				}
+				if tightest == nil || usage.exceeded() && !tightest.exceeded() ||
+					usage.exceeded() == tightest.exceeded() && usage.remaining() < tightest.remaining() {
+					tightest = usage
+				}
+			}
+			if tightest == nil {
+				return next(c)
+			}
+
+			c.SetHeader("X-Quota-Limit", strconv.FormatInt(tightest.quota.Limit, 10))
+			c.SetHeader("X-Quota-Remaining", strconv.FormatInt(tightest.remaining(), 10))
+			c.SetHeader("X-Quota-Reset", strconv.FormatInt(tightest.start.Add(tightest.quota.Window).Unix(), 10))
+
+			if tightest.exceeded() {
+				retryAfter := int64(tightest.retryAfter(now).Seconds() + 0.999)
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				c.SetHeader("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+				err := quark.NewHTTPError(http.StatusTooManyRequests, "quota exceeded")
+				if config.ErrorHandler != nil {
+					return config.ErrorHandler(c, err)
+				}
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// quotaUsage is the estimated usage of a quota over the rolling window
+// ending now.
+type quotaUsage struct {
+	quota    Quota
+	start    time.Time
+	current  int64
+	previous int64
+	weight   float64 // Share of the previous window still in the rolling window
+}
+
+// hitQuota counts a request against a quota.
+func hitQuota(ctx context.Context, store QuotaStore, key string, q Quota, now time.Time) (*quotaUsage, error) {
+	start := now.Truncate(q.Window)
+	current, previous, err := store.Hit(ctx, key+":"+q.Window.String(), start, q.Window)
+	if err != nil {
+		return nil, err
+	}
+	return &quotaUsage{
+		quota:    q,
+		start:    start,
+		current:  current,
+		previous: previous,
+		weight:   1 - float64(now.Sub(start))/float64(q.Window),
+	}, nil
+}
+
+// count returns the estimated number of requests in the rolling window,
+// rounding the weighted previous count up but not its rounding errors.
+func (u *quotaUsage) count() int64 {
+	return u.current + int64(math.Ceil(float64(u.previous)*u.weight-1e-9))
+}
+
+func (u *quotaUsage) exceeded() bool {
+	return u.count() > u.quota.Limit
+}
+
+func (u *quotaUsage) remaining() int64 {
+	if remaining := u.quota.Limit - u.count(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// retryAfter returns when enough of the previous window slides out of the
+// rolling window for a request to fit, or the end of the current window
+// when the current window alone is over the limit.
+func (u *quotaUsage) retryAfter(now time.Time) time.Duration {
+	end := u.start.Add(u.quota.Window)
+	if u.current >= u.quota.Limit || u.previous == 0 {
+		return end.Sub(now)
+	}
+	share := float64(u.quota.Limit-u.current) / float64(u.previous)
+	return u.start.Add(time.Duration((1 - share) * float64(u.quota.Window))).Sub(now)
+}
+
+// AuthenticatedKey returns the identity set by the authentication
+// middleware: the API key user of middleware.APIKey, the user of
+// middleware.Auth and middleware.BasicAuth, or the subject of the JWT
+// claims. Values are used when they are strings, integers or
+// fmt.Stringers. It returns "" for anonymous requests.
+func AuthenticatedKey(c *quark.Context) string {
+	for _, name := range []string{"api_key_user", "user", "claims"} {
+		switch v := c.Get(name).(type) {
+		case nil:
+			continue
+		case string:
+			return v
+		case *jwt.Claims:
+			return v.Subject
+		case fmt.Stringer:
+			return v.String()
+		case int, int64, uint, uint64:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
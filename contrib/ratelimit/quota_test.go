@@ -0,0 +1,266 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// stubStore counts hits per key and window with a fixed previous count.
+type stubStore struct {
+	mu       sync.Mutex
+	counts   map[string]int64
+	previous int64
+	err      error
+	keys     []string
+}
+
+func newStubStore() *stubStore {
+	return &stubStore{counts: make(map[string]int64)}
+}
+
+func (s *stubStore) Hit(ctx context.Context, key string, start time.Time, window time.Duration) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, 0, s.err
+	}
+	s.keys = append(s.keys, key)
+	s.counts[key]++
+	return s.counts[key], s.previous, nil
+}
+
+// newQuotaApp returns an app limiting /api per X-Key header.
+func newQuotaApp(config QuotaConfig) *quark.App {
+	config.KeyFunc = func(c *quark.Context) string { return c.Header("X-Key") }
+	app := quark.New()
+	app.Use(Quotas(config))
+	app.GET("/api", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+	return app
+}
+
+// quotaRequest sends a request with key.
+func quotaRequest(app *quark.App, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	if key != "" {
+		req.Header.Set("X-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestQuotaUsage(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	quota := Quota{Limit: 10, Window: time.Minute}
+
+	tests := []struct {
+		name          string
+		elapsed       time.Duration
+		current       int64
+		previous      int64
+		wantCount     int64
+		wantExceeded  bool
+		wantRemaining int64
+		wantRetry     time.Duration
+	}{
+		{"window start counts all of previous", 0, 1, 9, 10, false, 0, 0},
+		{"previous weighted by overlap", 15 * time.Second, 4, 8, 10, false, 0, 0},
+		{"weighted count rounded up", 30 * time.Second, 2, 5, 5, false, 5, 0},
+		{"over through previous", 15 * time.Second, 5, 8, 11, true, 0, 7500 * time.Millisecond},
+		{"over in current window", 15 * time.Second, 11, 0, 11, true, 0, 45 * time.Second},
+		{"current at limit", 15 * time.Second, 10, 3, 13, true, 0, 45 * time.Second},
+		{"end of window", 59 * time.Second, 3, 60, 4, false, 6, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start.Add(tt.elapsed)
+			u := &quotaUsage{
+				quota:    quota,
+				start:    start,
+				current:  tt.current,
+				previous: tt.previous,
+				weight:   1 - float64(now.Sub(start))/float64(quota.Window),
+			}
+			if got := u.count(); got != tt.wantCount {
+				t.Errorf("expected count %d, got %d", tt.wantCount, got)
+			}
+			if got := u.exceeded(); got != tt.wantExceeded {
+				t.Errorf("expected exceeded %v, got %v", tt.wantExceeded, got)
+			}
+			if got := u.remaining(); got != tt.wantRemaining {
+				t.Errorf("expected remaining %d, got %d", tt.wantRemaining, got)
+			}
+			if tt.wantExceeded {
+				if got := u.retryAfter(now); got != tt.wantRetry {
+					t.Errorf("expected retry after %v, got %v", tt.wantRetry, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHitQuota(t *testing.T) {
+	store := newStubStore()
+	store.previous = 8
+	now := time.Date(2026, 10, 16, 9, 30, 45, 0, time.UTC)
+
+	u, err := hitQuota(context.Background(), store, "key1", Quota{Limit: 10, Window: time.Minute}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !u.start.Equal(time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected the window to start on the minute, got %v", u.start)
+	}
+	if u.weight != 0.25 || u.count() != 3 {
+		t.Errorf("expected weight 0.25 and count 3, got %v and %d", u.weight, u.count())
+	}
+	if store.keys[0] != "key1:1m0s" {
+		t.Errorf("expected counters per key and window, got %q", store.keys[0])
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	app := newQuotaApp(QuotaConfig{Store: newStubStore(), Quotas: []Quota{{Limit: 2, Window: time.Hour}}})
+
+	for i, want := range []string{"1", "0"} {
+		rec := quotaRequest(app, "key1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("X-Quota-Remaining"); got != want {
+			t.Errorf("request %d: expected %s remaining, got %s", i+1, want, got)
+		}
+	}
+
+	rec := quotaRequest(app, "key1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", rec.Code)
+	}
+	reset := time.Now().Truncate(time.Hour).Add(time.Hour)
+	if got := rec.Header().Get("X-Quota-Reset"); got != strconv.FormatInt(reset.Unix(), 10) {
+		t.Errorf("expected reset at %d, got %s", reset.Unix(), got)
+	}
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 3600 {
+		t.Errorf("expected Retry-After within the window, got %q", rec.Header().Get("Retry-After"))
+	}
+	if rec.Header().Get("X-Quota-Limit") != "2" || rec.Header().Get("X-Quota-Remaining") != "0" {
+		t.Errorf("unexpected quota headers %v", rec.Header())
+	}
+
+	if rec := quotaRequest(app, "key2"); rec.Code != http.StatusOK {
+		t.Errorf("expected other keys to have their own budget, got %d", rec.Code)
+	}
+	if rec := quotaRequest(app, ""); rec.Code != http.StatusOK || rec.Header().Get("X-Quota-Limit") != "" {
+		t.Errorf("expected anonymous requests not to be limited, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestQuotasTightest(t *testing.T) {
+	tests := []struct {
+		name       string
+		quotas     []Quota
+		requests   int
+		wantCode   int
+		wantLimit  string
+		wantRemain string
+	}{
+		{"lowest remaining", []Quota{{Limit: 100, Window: 24 * time.Hour}, {Limit: 3, Window: time.Hour}}, 1, http.StatusOK, "3", "2"},
+		{"lowest remaining first", []Quota{{Limit: 3, Window: time.Hour}, {Limit: 100, Window: 24 * time.Hour}}, 1, http.StatusOK, "3", "2"},
+		{"exceeded over exhausted", []Quota{{Limit: 2, Window: time.Hour}, {Limit: 1, Window: 24 * time.Hour}}, 2, http.StatusTooManyRequests, "1", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newQuotaApp(QuotaConfig{Store: newStubStore(), Quotas: tt.quotas})
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < tt.requests; i++ {
+				rec = quotaRequest(app, "key1")
+			}
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+			if rec.Header().Get("X-Quota-Limit") != tt.wantLimit || rec.Header().Get("X-Quota-Remaining") != tt.wantRemain {
+				t.Errorf("expected limit %s and %s remaining, got %v", tt.wantLimit, tt.wantRemain, rec.Header())
+			}
+		})
+	}
+}
+
+func TestQuotaFunc(t *testing.T) {
+	plans := map[string][]Quota{
+		"free":   {{Limit: 1, Window: time.Hour}},
+		"pro":    {{Limit: 10, Window: time.Hour}},
+		"broken": {{Limit: 5}},
+	}
+	app := newQuotaApp(QuotaConfig{
+		Store:     newStubStore(),
+		QuotaFunc: func(c *quark.Context, key string) []Quota { return plans[key] },
+	})
+
+	quotaRequest(app, "free")
+	if rec := quotaRequest(app, "free"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("free: expected status 429, got %d", rec.Code)
+	}
+	quotaRequest(app, "pro")
+	if rec := quotaRequest(app, "pro"); rec.Code != http.StatusOK || rec.Header().Get("X-Quota-Remaining") != "8" {
+		t.Errorf("pro: expected 8 remaining, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := quotaRequest(app, "unknown"); rec.Code != http.StatusOK || rec.Header().Get("X-Quota-Limit") != "" {
+		t.Errorf("unknown: expected no quota, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := quotaRequest(app, "broken"); rec.Code != http.StatusInternalServerError || rec.Header().Get("X-Quota-Remaining") != "" {
+		t.Errorf("broken: expected an invalid quota to fail, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestQuotasStoreError(t *testing.T) {
+	store := newStubStore()
+	store.err = errors.New("redis down")
+	app := newQuotaApp(QuotaConfig{Store: store, Quotas: []Quota{{Limit: 1, Window: time.Hour}}})
+
+	for i := 0; i < 3; i++ {
+		if rec := quotaRequest(app, "key1"); rec.Code != http.StatusOK {
+			t.Errorf("expected store errors to let requests through, got %d", rec.Code)
+		}
+	}
+}
+
+func TestQuotasErrorHandler(t *testing.T) {
+	app := newQuotaApp(QuotaConfig{
+		Store:  newStubStore(),
+		Quotas: []Quota{{Limit: 1, Window: time.Hour}},
+		ErrorHandler: func(c *quark.Context, err error) error {
+			return c.JSON(http.StatusTooManyRequests, quark.M{"error": err.Error(), "upgrade": "/pricing"})
+		},
+	})
+	quotaRequest(app, "key1")
+	rec := quotaRequest(app, "key1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected the custom 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestQuotasInvalidConfig(t *testing.T) {
+	for name, config := range map[string]QuotaConfig{
+		"no quotas":   {},
+		"zero limit":  {Quotas: []Quota{{Window: time.Minute}}},
+		"zero window": {Quotas: []Quota{{Limit: 10}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			Quotas(config)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package ratelimit provides fixed-window rate limiting for the Quark
+// framework, and per-key quotas over rolling windows with Quotas. Counters
+// live in a pluggable Store, so limits can be shared between instances by
+// using a networked store such as Redis.
+//
+// Basic usage:
+//
+//	// 100 requests per minute per client IP
+//	app.Use(ratelimit.Middleware(ratelimit.Config{
+//	    Limit:  100,
+//	    Window: time.Minute,
+//	}))
+//
+//	// Shared between instances
+//	app.Use(ratelimit.Middleware(ratelimit.Config{
+//	    Limit:  100,
+//	    Window: time.Minute,
+//	    Store:  redis.NewRateLimitStore(client, "ratelimit:"),
+//	}))
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Store counts requests per key and window. Implementations must be safe
+// for concurrent use.
+type Store interface {
+	// Increment adds one to the counter of key and returns the new count
+	// and when the counter resets. A counter that does not exist or has
+	// expired starts at one and resets after window.
+	Increment(ctx context.Context, key string, window time.Duration) (count int64, resetAt time.Time, err error)
+}
+
+// Config holds rate limit middleware configuration.
+type Config struct {
+	// Limit is the number of requests allowed per window.
+	Limit int64
+
+	// Window is the length of a rate limit window (default: 1 minute).
+	Window time.Duration
+
+	// Store holds the counters (default: a new MemoryStore).
+	Store Store
+
+	// KeyFunc returns the key requests are counted under
+	// (default: the client IP).
+	KeyFunc func(*quark.Context) string
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// ErrorHandler is called when the limit is exceeded
+	// (default: a 429 Too Many Requests error).
+	ErrorHandler func(*quark.Context, error) error
+}
+
+// Middleware returns a middleware that limits requests per key, setting the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers
+// and Retry-After when the limit is exceeded. Store errors let the request
+// through rather than taking the application down with the store.
+func Middleware(config Config) quark.MiddlewareFunc {
+	if config.Limit <= 0 {
+		panic("ratelimit middleware requires a positive Limit")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *quark.Context) string { return c.RealIP() }
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			count, resetAt, err := config.Store.Increment(c.Context(), config.KeyFunc(c), config.Window)
+			if err != nil {
+				return next(c)
+			}
+
+			remaining := config.Limit - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.SetHeader("X-RateLimit-Limit", strconv.FormatInt(config.Limit, 10))
+			c.SetHeader("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+			c.SetHeader("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+			if count > config.Limit {
+				retryAfter := int64(time.Until(resetAt).Seconds() + 0.999)
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				c.SetHeader("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+				err := quark.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+				if config.ErrorHandler != nil {
+					return config.ErrorHandler(c, err)
+				}
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// MemoryStore is an in-memory Store and QuotaStore for single-instance
+// deployments.
+// Expired counters are purged periodically as new windows start.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastPurge time.Time
+}
+
+// counter is the request count of a key in the current window.
+type counter struct {
+	count   int64
+	resetAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory counter store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter)}
+}
+
+// Increment implements Store.
+func (s *MemoryStore) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.purge(now, window)
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.resetAt) {
+		c = &counter{resetAt: now.Add(window)}
+		s.counters[key] = c
+	}
+	c.count++
+	return c.count, c.resetAt, nil
+}
+
+// purge deletes expired counters, at most once per window.
+func (s *MemoryStore) purge(now time.Time, window time.Duration) {
+	if now.Sub(s.lastPurge) <= window {
+		return
+	}
+	for k, c := range s.counters {
+		if !now.Before(c.resetAt) {
+			delete(s.counters, k)
+		}
+	}
+	s.lastPurge = now
+}
+
+// Hit implements QuotaStore.
+func (s *MemoryStore) Hit(ctx context.Context, key string, start time.Time, window time.Duration) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.purge(now, window)
+
+	currentKey := key + "@" + strconv.FormatInt(start.UnixNano(), 10)
+	c, ok := s.counters[currentKey]
+	if !ok {
+		c = &counter{resetAt: start.Add(2 * window)}
+		s.counters[currentKey] = c
+	}
+	c.count++
+
+	var previous int64
+	if p, ok := s.counters[key+"@"+strconv.FormatInt(start.Add(-window).UnixNano(), 10)]; ok && now.Before(p.resetAt) {
+		previous = p.count
+	}
+	return c.count, previous, nil
+}
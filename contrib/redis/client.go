@@ -0,0 +1,289 @@
+// Package redis provides Redis-backed stores for the cache, session and
+// ratelimit packages, so state can be shared between instances of a
+// horizontally scaled application.
+//
+// The stores talk to Redis through the Doer interface. Client is a minimal
+// RESP client implementing it with only the standard library; applications
+// that already use a full-featured Redis library can wrap it in a Doer
+// instead.
+//
+// Basic usage:
+//
+//	client, err := redis.Dial("localhost:6379", redis.Options{Password: secret})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	sessions := redis.NewSessionStore(client, "session:")
+//	limits := redis.NewRateLimitStore(client, "ratelimit:")
+//	cacheStore := redis.NewCacheStore(client, "cache:")
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned by Do for nil replies, e.g. GET on a missing key.
+var ErrNil = errors.New("redis: nil reply")
+
+// ErrClosed is returned when using a closed client.
+var ErrClosed = errors.New("redis: client is closed")
+
+// Error is an error reply from the server.
+type Error string
+
+// Error implements the error interface.
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Doer executes Redis commands. Replies are decoded as string (simple and
+// bulk strings), int64 (integers), []interface{} (arrays) or ErrNil for nil
+// replies; error replies are returned as errors. Within arrays, nil replies
+// are nil elements and error replies are Error elements.
+type Doer interface {
+	Do(ctx context.Context, args ...string) (interface{}, error)
+}
+
+// Options configures a Client.
+type Options struct {
+	// Password authenticates connections when set.
+	Password string
+
+	// Username is used with Password for Redis 6 ACL authentication.
+	Username string
+
+	// DB is the database selected on each connection.
+	DB int
+
+	// DialTimeout limits connection establishment (default: 5 seconds).
+	DialTimeout time.Duration
+
+	// PoolSize is the maximum number of idle connections kept for reuse
+	// (default: 10).
+	PoolSize int
+}
+
+// Client is a minimal, concurrency-safe Redis client with a connection
+// pool. It supports plain commands only; pub/sub and pipelining are out of
+// scope.
+type Client struct {
+	addr    string
+	options Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// conn is a pooled connection.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// Dial creates a client for the server at addr and checks connectivity.
+func Dial(addr string, opts Options) (*Client, error) {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+
+	c := &Client{addr: addr, options: opts}
+	if _, err := c.Do(context.Background(), "PING"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Do implements Doer.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, args)
+	if err != nil {
+		var redisErr Error
+		if errors.As(err, &redisErr) || errors.Is(err, ErrNil) {
+			// The connection is still in a clean state
+			c.put(cn)
+		} else {
+			cn.Close()
+		}
+		return nil, err
+	}
+
+	c.put(cn)
+	return reply, nil
+}
+
+// Close closes all idle connections. Connections in use are closed when
+// they are returned.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// get takes an idle connection or dials a new one.
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: c.options.DialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", c.addr, err)
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	if c.options.Password != "" {
+		args := []string{"AUTH", c.options.Password}
+		if c.options.Username != "" {
+			args = []string{"AUTH", c.options.Username, c.options.Password}
+		}
+		if _, err := cn.do(ctx, args); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.options.DB != 0 {
+		if _, err := cn.do(ctx, []string{"SELECT", strconv.Itoa(c.options.DB)}); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full.
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || len(c.idle) >= c.options.PoolSize {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+// do writes a command and reads its reply, honoring the context deadline.
+func (cn *conn) do(ctx context.Context, args []string) (interface{}, error) {
+	deadline, _ := ctx.Deadline()
+	cn.SetDeadline(deadline)
+
+	// Commands are arrays of bulk strings
+	fmt.Fprintf(cn.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(cn.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: write: %w", err)
+	}
+
+	return readReply(cn.r)
+}
+
+// readReply decodes one RESP reply.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply: %w", err)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if size < 0 {
+			return nil, ErrNil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: read: %w", err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length: %w", err)
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		// Every element is read, so the connection stays usable after
+		// error elements, e.g. in EXEC replies
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readReply(r)
+			var redisErr Error
+			switch {
+			case errors.As(err, &redisErr):
+				items[i] = redisErr
+			case err != nil && !errors.Is(err, ErrNil):
+				return nil, err
+			default:
+				items[i] = item
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
+
+// readLine reads a CRLF-terminated line without the terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("redis: read: %w", err)
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: malformed reply")
+	}
+	return line[:len(line)-2], nil
+}
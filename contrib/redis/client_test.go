@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr error
+	}{
+		{"simple string", "+OK\r\n", "OK", nil},
+		{"integer", ":42\r\n", int64(42), nil},
+		{"bulk string", "$5\r\nhello\r\n", "hello", nil},
+		{"nil bulk string", "$-1\r\n", nil, ErrNil},
+		{"error", "-ERR unknown command\r\n", nil, Error("ERR unknown command")},
+		{"array", "*2\r\n$1\r\na\r\n:1\r\n", []interface{}{"a", int64(1)}, nil},
+		{"nil array", "*-1\r\n", nil, ErrNil},
+		{"array with nil", "*2\r\n$-1\r\n$1\r\nb\r\n", []interface{}{nil, "b"}, nil},
+		{"array with errors", "*3\r\n-ERR first\r\n$1\r\nb\r\n-ERR third\r\n",
+			[]interface{}{Error("ERR first"), "b", Error("ERR third")}, nil},
+		{"nested array", "*2\r\n*1\r\n-ERR inner\r\n:2\r\n",
+			[]interface{}{[]interface{}{Error("ERR inner")}, int64(2)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input + "+NEXT\r\n"))
+			got, err := readReply(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+			// The whole reply is consumed
+			if next, err := readReply(r); err != nil || next != "NEXT" {
+				t.Errorf("expected the next reply, got %v %v", next, err)
+			}
+		})
+	}
+}
+
+func TestReadReplyMalformed(t *testing.T) {
+	for _, input := range []string{"", "\r\n", "!1\r\n", ":x\r\n", "$3\r\nab", "*2\r\n:1\r\n"} {
+		if _, err := readReply(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+// fakeServer is a RESP server answering commands with canned replies.
+type fakeServer struct {
+	listener net.Listener
+	replies  map[string]string
+	conns    atomic.Int32
+}
+
+func newFakeServer(t *testing.T, replies map[string]string) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: l, replies: replies}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) serve() {
+	for {
+		cn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.conns.Add(1)
+		go s.handle(cn)
+	}
+}
+
+func (s *fakeServer) handle(cn net.Conn) {
+	defer cn.Close()
+	r := bufio.NewReader(cn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		reply, ok := s.replies[strings.ToUpper(args[0])]
+		if !ok {
+			reply = fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+		}
+		if _, err := cn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(line, "*"))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		arg, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg.(string)
+	}
+	return args, nil
+}
+
+func TestClientArrayWithErrors(t *testing.T) {
+	server := newFakeServer(t, map[string]string{
+		"PING": "+PONG\r\n",
+		"EXEC": "*3\r\n+OK\r\n-WRONGTYPE Operation against a key holding the wrong kind of value\r\n:1\r\n",
+		"GET":  "$5\r\nvalue\r\n",
+	})
+	client, err := Dial(server.listener.Addr().String(), Options{})
+	if err != nil {
+		t.Fatalf("Dial: unexpected error: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	reply, err := client.Do(ctx, "EXEC")
+	if err != nil {
+		t.Fatalf("EXEC: unexpected error: %v", err)
+	}
+	items := reply.([]interface{})
+	var redisErr Error
+	if len(items) != 3 || items[0] != "OK" || !errors.As(items[1].(error), &redisErr) || items[2] != int64(1) {
+		t.Errorf("EXEC: unexpected reply %#v", reply)
+	}
+
+	// The pooled connection is in sync with the server
+	if reply, err := client.Do(ctx, "GET", "key"); err != nil || reply != "value" {
+		t.Errorf("GET: expected value, got %#v %v", reply, err)
+	}
+	if n := server.conns.Load(); n != 1 {
+		t.Errorf("expected the connection to be reused, got %d connections", n)
+	}
+
+	if _, err := client.Do(ctx, "NOPE"); !errors.As(err, &redisErr) {
+		t.Errorf("expected an error reply, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AchrafSoltani/quark/contrib/cache"
+	"github.com/AchrafSoltani/quark/contrib/ratelimit"
+	"github.com/AchrafSoltani/quark/contrib/session"
+)
+
+// Compile-time interface checks
+var (
+	_ cache.Store          = (*CacheStore)(nil)
+	_ session.Store        = (*SessionStore)(nil)
+	_ ratelimit.Store      = (*RateLimitStore)(nil)
+	_ ratelimit.QuotaStore = (*RateLimitStore)(nil)
+)
+
+// kvStore implements get/set/delete of byte values under prefixed keys,
+// shared by the cache and session stores.
+type kvStore struct {
+	doer   Doer
+	prefix string
+}
+
+// get returns the value of key, or notFound for missing keys.
+func (s kvStore) get(ctx context.Context, key string, notFound error) ([]byte, error) {
+	reply, err := s.doer.Do(ctx, "GET", s.prefix+key)
+	if errors.Is(err, ErrNil) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	str, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return []byte(str), nil
+}
+
+// set stores value under key, expiring after ttl when ttl is positive.
+func (s kvStore) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", s.prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttlMillis(ttl), 10))
+	}
+	_, err := s.doer.Do(ctx, args...)
+	return err
+}
+
+// del removes key.
+func (s kvStore) del(ctx context.Context, key string) error {
+	_, err := s.doer.Do(ctx, "DEL", s.prefix+key)
+	return err
+}
+
+// CacheStore is a cache.Store backed by Redis.
+type CacheStore struct {
+	kv kvStore
+}
+
+// NewCacheStore creates a cache store whose keys are prefixed with prefix.
+func NewCacheStore(doer Doer, prefix string) *CacheStore {
+	return &CacheStore{kv: kvStore{doer: doer, prefix: prefix}}
+}
+
+// Get implements cache.Store.
+func (s *CacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.kv.get(ctx, key, cache.ErrNotFound)
+}
+
+// Set implements cache.Store.
+func (s *CacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.kv.set(ctx, key, value, ttl)
+}
+
+// Delete implements cache.Store.
+func (s *CacheStore) Delete(ctx context.Context, key string) error {
+	return s.kv.del(ctx, key)
+}
+
+// SessionStore is a session.Store backed by Redis.
+type SessionStore struct {
+	kv kvStore
+}
+
+// NewSessionStore creates a session store whose keys are prefixed with
+// prefix.
+func NewSessionStore(doer Doer, prefix string) *SessionStore {
+	return &SessionStore{kv: kvStore{doer: doer, prefix: prefix}}
+}
+
+// Get implements session.Store.
+func (s *SessionStore) Get(ctx context.Context, id string) ([]byte, error) {
+	return s.kv.get(ctx, id, session.ErrNotFound)
+}
+
+// Set implements session.Store.
+func (s *SessionStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return s.kv.set(ctx, id, data, ttl)
+}
+
+// Delete implements session.Store.
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	return s.kv.del(ctx, id)
+}
+
+// incrementScript atomically increments a counter, starts its window on the
+// first increment, and returns the count with the window's remaining time.
+const incrementScript = `local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+  ttl = tonumber(ARGV[1])
+end
+return {count, ttl}`
+
+// RateLimitStore is a ratelimit.Store and ratelimit.QuotaStore backed by
+// Redis. Counters are shared by every instance using the same server and
+// prefix.
+type RateLimitStore struct {
+	doer   Doer
+	prefix string
+}
+
+// NewRateLimitStore creates a rate limit store whose keys are prefixed with
+// prefix.
+func NewRateLimitStore(doer Doer, prefix string) *RateLimitStore {
+	return &RateLimitStore{doer: doer, prefix: prefix}
+}
+
+// Increment implements ratelimit.Store.
+func (s *RateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	reply, err := s.doer.Do(ctx, "EVAL", incrementScript, "1", s.prefix+key,
+		strconv.FormatInt(ttlMillis(window), 10))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return 0, time.Time{}, fmt.Errorf("redis: unexpected EVAL reply %T", reply)
+	}
+	count, ok1 := items[0].(int64)
+	ttl, ok2 := items[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, time.Time{}, errors.New("redis: unexpected EVAL reply values")
+	}
+	return count, time.Now().Add(time.Duration(ttl) * time.Millisecond), nil
+}
+
+// hitScript atomically increments the counter of the current window,
+// expiring it after two windows, and returns it with the counter of the
+// previous window.
+const hitScript = `local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+return {count, previous}`
+
+// Hit implements ratelimit.QuotaStore.
+func (s *RateLimitStore) Hit(ctx context.Context, key string, start time.Time, window time.Duration) (int64, int64, error) {
+	reply, err := s.doer.Do(ctx, "EVAL", hitScript, "2",
+		s.prefix+key+"@"+strconv.FormatInt(start.UnixNano(), 10),
+		s.prefix+key+"@"+strconv.FormatInt(start.Add(-window).UnixNano(), 10),
+		strconv.FormatInt(ttlMillis(2*window), 10))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return 0, 0, fmt.Errorf("redis: unexpected EVAL reply %T", reply)
+	}
+	current, ok1 := items[0].(int64)
+	previous, ok2 := items[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, 0, errors.New("redis: unexpected EVAL reply values")
+	}
+	return current, previous, nil
+}
+
+// ttlMillis converts a duration to milliseconds, rounding sub-millisecond
+// durations up so they do not disable expiry.
+func ttlMillis(d time.Duration) int64 {
+	ms := d.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return ms
+}
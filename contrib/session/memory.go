@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for development, tests and
+// single-instance deployments. Expired sessions are purged as new ones are
+// saved.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+}
+
+// memorySession is a stored session.
+type memorySession struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memorySession)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || time.Now().After(session.expiresAt) {
+		delete(s.sessions, id)
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), session.data...), nil
+}
+
+// Set implements Store.
+func (s *MemoryStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, session := range s.sessions {
+		if now.After(session.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+
+	s.sessions[id] = memorySession{
+		data:      append([]byte(nil), data...),
+		expiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
@@ -0,0 +1,355 @@
+// Package session provides server-side sessions for the Quark framework.
+// Session data lives in a pluggable Store and the browser only holds a
+// random session ID in an HttpOnly cookie.
+//
+// Basic usage:
+//
+//	app.Use(session.Middleware(session.Config{
+//	    Store: session.NewMemoryStore(), // or a shared store such as Redis
+//	    TTL:   24 * time.Hour,
+//	}))
+//
+//	app.POST("/login", func(c *quark.Context) error {
+//	    // Authenticate the user...
+//	    s := session.Get(c)
+//	    s.Regenerate() // Prevent session fixation
+//	    s.Set("user_id", user.ID)
+//	    return c.NoContent()
+//	})
+//
+//	app.POST("/logout", func(c *quark.Context) error {
+//	    session.Get(c).Destroy()
+//	    return c.NoContent()
+//	})
+//
+// Values are stored as JSON, so numbers read back from a stored session are
+// float64; use GetInt64 and the other typed getters to convert them.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// ErrNotFound is returned by stores for unknown or expired sessions.
+var ErrNotFound = errors.New("session: not found")
+
+// Store persists encoded session data. Implementations must be safe for
+// concurrent use and return ErrNotFound for unknown or expired IDs.
+type Store interface {
+	// Get returns the data of a session.
+	Get(ctx context.Context, id string) ([]byte, error)
+
+	// Set stores the data of a session for ttl.
+	Set(ctx context.Context, id string, data []byte, ttl time.Duration) error
+
+	// Delete removes a session. Deleting an unknown session is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// contextKey is the context store key of the current session.
+const contextKey = "session"
+
+// Config holds session middleware configuration.
+type Config struct {
+	// Store persists sessions (default: a new MemoryStore).
+	Store Store
+
+	// TTL is the idle lifetime of a session (default: 24 hours). The
+	// session and its cookie are extended whenever the session is modified.
+	TTL time.Duration
+
+	// CookieName is the session cookie name (default: "session_id").
+	CookieName string
+
+	// CookiePath is the cookie path (default: "/").
+	CookiePath string
+
+	// CookieDomain is the cookie domain (default: the request host).
+	CookieDomain string
+
+	// SameSite is the cookie SameSite policy (default: http.SameSiteLaxMode).
+	SameSite http.SameSite
+
+	// Insecure allows the cookie over plain HTTP, for local development only.
+	Insecure bool
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+}
+
+// Session is the session of the current request.
+type Session struct {
+	id       string
+	values   map[string]interface{}
+	mu       sync.RWMutex
+	modified bool
+	isNew    bool
+	cookie   bool // Whether the cookie was written by this request
+
+	// Set by Regenerate and Destroy, applied when the session is saved
+	oldID     string
+	destroyed bool
+
+	c      *quark.Context
+	config *Config
+}
+
+// Middleware returns a middleware that loads the session before the handler
+// and saves it afterwards if it was modified.
+func Middleware(config Config) quark.MiddlewareFunc {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.CookieName == "" {
+		config.CookieName = "session_id"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			s, err := load(c, &config)
+			if err != nil {
+				return quark.WrapError(http.StatusInternalServerError, "failed to load session", err)
+			}
+			c.Set(contextKey, s)
+
+			handlerErr := next(c)
+
+			if err := s.save(c.Context()); err != nil && handlerErr == nil {
+				return quark.WrapError(http.StatusInternalServerError, "failed to save session", err)
+			}
+			return handlerErr
+		}
+	}
+}
+
+// Get returns the session of the current request, or nil if the session
+// middleware is not installed.
+func Get(c *quark.Context) *Session {
+	s, _ := c.Get(contextKey).(*Session)
+	return s
+}
+
+// load reads the session named by the request cookie, or starts a new one.
+func load(c *quark.Context, config *Config) (*Session, error) {
+	s := &Session{values: make(map[string]interface{}), c: c, config: config}
+
+	if cookie, err := c.Request.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+		data, err := config.Store.Get(c.Context(), cookie.Value)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &s.values); err != nil {
+				return nil, fmt.Errorf("failed to decode session: %w", err)
+			}
+			s.id = cookie.Value
+			return s, nil
+		case !errors.Is(err, ErrNotFound):
+			return nil, err
+		}
+	}
+
+	// Unknown or missing session: the ID is issued on first write
+	s.isNew = true
+	return s, nil
+}
+
+// ID returns the session ID, or an empty string for a new session that
+// has not been written to yet.
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// IsNew reports whether the session was started by this request.
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isNew
+}
+
+// Get returns a session value, or nil if it is not set.
+func (s *Session) Get(key string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// GetString returns a session value as a string.
+func (s *Session) GetString(key string) string {
+	v, _ := s.Get(key).(string)
+	return v
+}
+
+// GetInt64 returns a numeric session value as an int64.
+func (s *Session) GetInt64(key string) int64 {
+	switch v := s.Get(key).(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// GetBool returns a session value as a bool.
+func (s *Session) GetBool(key string) bool {
+	v, _ := s.Get(key).(bool)
+	return v
+}
+
+// Set sets a session value. Values must be JSON-encodable.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.touch()
+}
+
+// Delete removes a session value.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.touch()
+	}
+}
+
+// Clear removes all session values but keeps the session.
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]interface{})
+	s.touch()
+}
+
+// Regenerate moves the session to a new ID, keeping its values. Call it
+// when the privilege level changes (e.g., at login) to prevent session
+// fixation.
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.id != "" && s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = ""
+	s.touch()
+}
+
+// Destroy deletes the session and expires its cookie. Setting values
+// afterwards starts a new session.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.id != "" && s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = ""
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+	s.modified = false
+	s.setCookie("", -1)
+	s.cookie = false
+}
+
+// touch marks the session as modified, issues an ID if it has none, and
+// writes the cookie once per request. The cookie is written immediately
+// because the response headers may already be sent by the time the session
+// is saved. The caller must hold the write lock.
+func (s *Session) touch() {
+	s.modified = true
+	s.destroyed = false
+
+	if s.id == "" {
+		id, err := newID()
+		if err != nil {
+			// Leave the session without an ID; save reports the failure
+			return
+		}
+		s.id = id
+		s.cookie = false
+	}
+	if !s.cookie {
+		s.setCookie(s.id, int(s.config.TTL.Seconds()))
+		s.cookie = true
+	}
+}
+
+// setCookie writes the session cookie.
+func (s *Session) setCookie(value string, maxAge int) {
+	http.SetCookie(s.c.Writer, &http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    value,
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   !s.config.Insecure,
+		SameSite: s.config.SameSite,
+	})
+}
+
+// save persists a modified session and deletes replaced or destroyed ones.
+func (s *Session) save(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.oldID != "" {
+		if err := s.config.Store.Delete(ctx, s.oldID); err != nil {
+			return err
+		}
+		s.oldID = ""
+	}
+	if s.destroyed || !s.modified {
+		return nil
+	}
+	if s.id == "" {
+		return errors.New("failed to generate session id")
+	}
+
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	return s.config.Store.Set(ctx, s.id, data, s.config.TTL)
+}
+
+// newID returns a random session ID.
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
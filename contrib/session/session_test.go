@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// newTestApp returns an app with session routes and its store.
+func newTestApp() (*quark.App, *MemoryStore) {
+	store := NewMemoryStore()
+	app := quark.New()
+	app.Use(Middleware(Config{Store: store, TTL: time.Hour}))
+	app.POST("/login", func(c *quark.Context) error {
+		s := Get(c)
+		s.Regenerate()
+		s.Set("user_id", 42)
+		return c.NoContent()
+	})
+	app.GET("/me", func(c *quark.Context) error {
+		return c.JSON(http.StatusOK, quark.M{"user_id": Get(c).GetInt64("user_id")})
+	})
+	app.POST("/logout", func(c *quark.Context) error {
+		Get(c).Destroy()
+		return c.NoContent()
+	})
+	return app, store
+}
+
+// send sends a request with an optional session cookie.
+func send(app *quark.App, method, path, sessionID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if sessionID != "" {
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: sessionID})
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+// sessionCookie returns the session cookie set by a response, or nil.
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "session_id" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	app, store := newTestApp()
+
+	// Reading does not start a session
+	if rec := send(app, http.MethodGet, "/me", ""); sessionCookie(rec) != nil {
+		t.Error("expected no cookie for an unmodified session")
+	}
+
+	rec := send(app, http.MethodPost, "/login", "")
+	cookie := sessionCookie(rec)
+	if cookie == nil || !cookie.HttpOnly || !cookie.Secure || cookie.MaxAge != 3600 {
+		t.Fatalf("expected a secure session cookie, got %+v", cookie)
+	}
+	if rec := send(app, http.MethodGet, "/me", cookie.Value); rec.Body.String() != "{\"user_id\":42}\n" {
+		t.Errorf("expected the stored value, got %s", rec.Body.String())
+	}
+
+	rec = send(app, http.MethodPost, "/logout", cookie.Value)
+	if expired := sessionCookie(rec); expired == nil || expired.MaxAge >= 0 {
+		t.Errorf("expected the cookie expired, got %+v", expired)
+	}
+	if _, err := store.Get(context.Background(), cookie.Value); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the session deleted, got %v", err)
+	}
+}
+
+func TestMiddlewareSessionFixation(t *testing.T) {
+	app, store := newTestApp()
+	store.Set(context.Background(), "attacker-known", []byte(`{}`), time.Hour)
+
+	tests := []struct {
+		name      string
+		sessionID string
+	}{
+		{"known session", "attacker-known"},
+		{"unknown session", "attacker-chosen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookie := sessionCookie(send(app, http.MethodPost, "/login", tt.sessionID))
+			if cookie == nil || cookie.Value == tt.sessionID {
+				t.Fatalf("expected a new session ID at login, got %+v", cookie)
+			}
+			if _, err := store.Get(context.Background(), tt.sessionID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected the previous session deleted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.Set(ctx, "short", []byte("1"), time.Millisecond)
+	store.Set(ctx, "long", []byte("2"), time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr error
+	}{
+		{"short", "", ErrNotFound},
+		{"long", "2", nil},
+		{"missing", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		data, err := store.Get(ctx, tt.id)
+		if !errors.Is(err, tt.wantErr) || string(data) != tt.want {
+			t.Errorf("Get(%s) = %q, %v; want %q, %v", tt.id, data, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// tempPrefix marks files being written by Put, which List skips.
+const tempPrefix = ".upload-"
+
+// LocalConfig holds local-disk store configuration.
+type LocalConfig struct {
+	// Dir is the directory objects are stored in (required). It is
+	// created if missing.
+	Dir string
+
+	// BaseURL is the URL prefix of signed URLs, where Mount serves them
+	// (default: "/files").
+	BaseURL string
+
+	// Secret signs URLs (required for SignedURL). Use at least 32 random
+	// bytes, shared by every instance serving the URLs.
+	Secret []byte
+}
+
+// Local stores objects as files below a directory.
+type Local struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocal returns a local-disk store.
+func NewLocal(config LocalConfig) (*Local, error) {
+	if config.Dir == "" {
+		panic("storage: local store requires a Dir")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = "/files"
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{
+		dir:     config.Dir,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		secret:  config.Secret,
+	}, nil
+}
+
+// path returns the file path of key.
+func (s *Local) path(key string) (string, error) {
+	if !ValidKey(key) || strings.HasPrefix(path.Base(key), tempPrefix) {
+		return "", ErrInvalidKey
+	}
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Put implements Store. Content is written to a temporary file renamed
+// into place once complete, so readers never see partial objects.
+func (s *Local) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (Object, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return Object{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), tempPrefix+"*")
+	if err != nil {
+		return Object{}, err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	_, err = io.Copy(tmp, contextReader{ctx, r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return Object{}, err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return Object{}, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return Object{}, err
+	}
+	return s.object(key, info), nil
+}
+
+// Get implements Store.
+func (s *Local) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	f, info, err := s.open(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	return f, s.object(key, info), nil
+}
+
+// Delete implements Store.
+func (s *Local) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List implements Store.
+func (s *Local) List(ctx context.Context, prefix string) ([]Object, error) {
+	// Walk the deepest directory holding every key with the prefix
+	root := "."
+	if i := strings.LastIndexByte(prefix, '/'); i > 0 {
+		root = prefix[:i]
+		if !ValidKey(root) {
+			return nil, ErrInvalidKey
+		}
+	}
+
+	var objects []Object
+	err := fs.WalkDir(os.DirFS(s.dir), root, func(key string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(key, prefix) || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, s.object(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// SignedURL implements Store. The URL is served by Handler, which checks
+// the signature and expiry.
+func (s *Local) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if len(s.secret) == 0 {
+		return "", errors.New("storage: signed URLs require a Secret")
+	}
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	exp := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{"expires": {exp}, "signature": {s.sign(key, exp)}}
+	return s.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Handler returns a handler serving objects by signed URL, with support for
+// range and conditional requests. It expects the key in the "filepath"
+// path parameter, as registered by Mount. Missing, expired and invalid
+// signatures are all answered with 404 Not Found, revealing nothing about
+// which objects exist.
+func (s *Local) Handler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		key := c.Param("filepath")
+		exp := c.Query("expires")
+		unix, err := strconv.ParseInt(exp, 10, 64)
+		if err != nil || time.Now().Unix() > unix || len(s.secret) == 0 ||
+			!hmac.Equal([]byte(c.Query("signature")), []byte(s.sign(key, exp))) {
+			return quark.ErrNotFound("")
+		}
+
+		f, info, err := s.open(key)
+		if err != nil {
+			return quark.ErrNotFound("")
+		}
+		defer f.Close()
+
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			c.SetHeader("Content-Type", contentType)
+		}
+		c.SetHeader("Cache-Control", "private, max-age="+strconv.FormatInt(max(unix-time.Now().Unix(), 0), 10))
+		c.SetHeader("X-Content-Type-Options", "nosniff")
+		http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+		return nil
+	}
+}
+
+// Mount registers Handler on the app under the path of BaseURL.
+func (s *Local) Mount(app *quark.App) {
+	prefix := s.baseURL
+	if u, err := url.Parse(prefix); err == nil {
+		prefix = u.Path
+	}
+	app.GET(prefix+"/{filepath:.*}", s.Handler())
+}
+
+// open opens the regular file of key.
+func (s *Local) open(key string) (*os.File, fs.FileInfo, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, info, nil
+}
+
+// object describes the file of key.
+func (s *Local) object(key string, info fs.FileInfo) Object {
+	return Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     info.ModTime(),
+	}
+}
+
+// sign returns the signature of a key and expiry.
+func (s *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escapeKey escapes each segment of a key for use in a URL path.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// contextReader stops reading when its context is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,193 @@
+// Package storage provides file storage for the Quark framework behind a
+// Store interface, with a local-disk implementation. Objects are addressed
+// by slash-separated keys and streamed in and out, so adapters for
+// S3-compatible object stores can implement the same interface.
+//
+// Basic usage:
+//
+//	store, err := storage.NewLocal(storage.LocalConfig{
+//	    Dir:     "uploads",
+//	    BaseURL: "/files",
+//	    Secret:  []byte(os.Getenv("STORAGE_SECRET")),
+//	})
+//	store.Mount(app) // Serves signed URLs under /files
+//
+//	app.POST("/avatars", func(c *quark.Context) error {
+//	    obj, err := storage.Upload(c, store, "avatar", "avatars/") // Random file name
+//	    if err != nil {
+//	        return err
+//	    }
+//	    url, err := store.SignedURL(c.Context(), obj.Key, time.Hour)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(201, quark.M{"key": obj.Key, "url": url})
+//	})
+//
+// Handlers should depend on Store rather than a concrete store, so the
+// disk can be swapped for object storage without touching them.
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for empty keys and keys escaping the store,
+// such as "../secret" or "/etc/passwd".
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Object describes a stored object.
+type Object struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// PutOptions holds options for storing an object.
+type PutOptions struct {
+	// ContentType is the media type of the object. Stores that keep no
+	// metadata, such as Local, derive it from the key's extension.
+	ContentType string
+
+	// Size is the length of the content, or -1 when unknown. Object stores
+	// can use it to upload in a single request instead of in parts.
+	Size int64
+}
+
+// Store is the storage backend interface. Implementations must be safe for
+// concurrent use and return ErrNotFound for missing objects.
+type Store interface {
+	// Put stores the content read from r under key, replacing any object
+	// with the same key. Readers see either the old or the new object.
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (Object, error)
+
+	// Get opens the object stored under key. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+
+	// Delete removes the object stored under key. Deleting a missing
+	// object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL granting read access to the object stored
+	// under key until it expires.
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+
+	// List returns the objects whose keys start with prefix, in key order.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// ValidKey reports whether key is a clean, relative, slash-separated path,
+// the keys every Store accepts.
+func ValidKey(key string) bool {
+	return key != "" && key != "." && !strings.HasPrefix(key, "/") &&
+		path.Clean(key) == key && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+// UniqueKey returns a key made of prefix, a random name and the extension
+// of filename, for storing uploads without trusting client file names.
+//
+// Example:
+//
+//	storage.UniqueKey("avatars/", "me.png") // "avatars/3f9a0c6e1b2d4a5f8e7c9b1a2d3e4f50.png"
+func UniqueKey(prefix, filename string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("storage: " + err.Error())
+	}
+	return prefix + hex.EncodeToString(b) + strings.ToLower(path.Ext(filename))
+}
+
+// Upload streams an uploaded file into store under key without buffering
+// it in memory or on disk. For multipart requests the file is the part
+// named field; other requests upload their body, typed by the
+// Content-Type header, and forms already parsed, such as by the Uploads
+// middleware, are read from Request.MultipartForm. A key ending in "/" is
+// a prefix completed with UniqueKey and the uploaded file name.
+//
+// The content type is the one sent by the client, or sniffed from the
+// content when missing or generic. Limit the request size with a body
+// limit middleware, as Upload reads until the end of the file.
+func Upload(c *quark.Context, store Store, field, key string) (Object, error) {
+	var body io.Reader
+	var filename, contentType string
+	size := int64(-1)
+
+	switch {
+	case c.Request.MultipartForm != nil:
+		// Already parsed, e.g. by the Uploads middleware
+		file, header, err := c.Request.FormFile(field)
+		if err != nil {
+			return Object{}, quark.ErrBadRequest("missing file " + field)
+		}
+		defer file.Close()
+		body, filename, size = file, header.Filename, header.Size
+		contentType = header.Header.Get("Content-Type")
+
+	case c.ContentType() == "multipart/form-data":
+		reader, err := c.Request.MultipartReader()
+		if err != nil {
+			return Object{}, quark.WrapError(http.StatusBadRequest, "invalid multipart body", err)
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return Object{}, quark.ErrBadRequest("missing file " + field)
+			}
+			if err != nil {
+				return Object{}, quark.WrapError(http.StatusBadRequest, "invalid multipart body", err)
+			}
+			if part.FormName() == field && part.FileName() != "" {
+				defer part.Close()
+				body, filename = part, part.FileName()
+				contentType = part.Header.Get("Content-Type")
+				break
+			}
+			part.Close()
+		}
+
+	default:
+		if c.Request.Body == nil || c.Request.ContentLength == 0 {
+			return Object{}, quark.ErrBadRequest("empty request body")
+		}
+		body, size = c.Request.Body, c.Request.ContentLength
+		contentType = c.Header("Content-Type")
+	}
+
+	if strings.HasSuffix(key, "/") {
+		key = UniqueKey(key, filename)
+	}
+	if !ValidKey(key) {
+		return Object{}, ErrInvalidKey
+	}
+
+	buffered := bufio.NewReaderSize(body, 512)
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, _ := buffered.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	obj, err := store.Put(c.Context(), key, buffered, PutOptions{ContentType: contentType, Size: size})
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return Object{}, quark.WrapError(http.StatusRequestEntityTooLarge, "upload too large", err)
+		}
+		return Object{}, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestStore returns a local store in a temporary directory, next to a
+// file outside of it that keys must not reach.
+func newTestStore(t *testing.T) *Local {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewLocal(LocalConfig{Dir: filepath.Join(root, "store"), Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestValidKey(t *testing.T) {
+	tests := map[string]bool{
+		"avatars/me.png":   true,
+		"a.txt":            true,
+		"":                 false,
+		".":                false,
+		"..":               false,
+		"../secret.txt":    false,
+		"a/../../secret":   false,
+		"/etc/passwd":      false,
+		"avatars//me.png":  false,
+		"avatars/./me.png": false,
+		"avatars/":         false,
+	}
+	for key, want := range tests {
+		if got := ValidKey(key); got != want {
+			t.Errorf("ValidKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLocalKeyTraversal(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"../secret.txt", "a/../../secret.txt", "/etc/passwd", ".upload-123"} {
+		t.Run(key, func(t *testing.T) {
+			if _, err := store.Put(ctx, key, strings.NewReader("x"), PutOptions{}); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Put: expected ErrInvalidKey, got %v", err)
+			}
+			if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Get: expected ErrInvalidKey, got %v", err)
+			}
+			if err := store.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Delete: expected ErrInvalidKey, got %v", err)
+			}
+			if _, err := store.SignedURL(ctx, key, time.Minute); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("SignedURL: expected ErrInvalidKey, got %v", err)
+			}
+		})
+	}
+	if _, err := store.List(ctx, "../"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("List: expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestLocalPutGetList(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"docs/b.txt", "docs/a.txt", "images/c.png"} {
+		if _, err := store.Put(ctx, key, strings.NewReader(key), PutOptions{Size: -1}); err != nil {
+			t.Fatalf("Put(%s): unexpected error: %v", key, err)
+		}
+	}
+
+	r, obj, err := store.Get(ctx, "docs/a.txt")
+	if err != nil {
+		t.Fatalf("Get: unexpected error: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "docs/a.txt" || obj.Size != 10 || !strings.HasPrefix(obj.ContentType, "text/plain") {
+		t.Errorf("Get: unexpected object %q %+v", data, obj)
+	}
+
+	objects, err := store.List(ctx, "docs/")
+	if err != nil || len(objects) != 2 || objects[0].Key != "docs/a.txt" || objects[1].Key != "docs/b.txt" {
+		t.Errorf("List: unexpected objects %+v %v", objects, err)
+	}
+
+	if err := store.Delete(ctx, "docs/a.txt"); err != nil {
+		t.Fatalf("Delete: unexpected error: %v", err)
+	}
+	if _, _, err := store.Get(ctx, "docs/a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get: expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if _, err := store.Put(ctx, "reports/q1.txt", strings.NewReader("quarterly report"), PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	app := quark.New()
+	store.Mount(app)
+
+	signed, err := store.SignedURL(ctx, "reports/q1.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: unexpected error: %v", err)
+	}
+	u, _ := url.Parse(signed)
+	query := u.Query()
+
+	// resign sets the expiry of the URL and signs it for key
+	resign := func(key string, expires time.Time) string {
+		exp := strconv.FormatInt(expires.Unix(), 10)
+		return "/files/" + key + "?expires=" + exp + "&signature=" + store.sign(key, exp)
+	}
+	with := func(name, value string) string {
+		q := u.Query()
+		q.Set(name, value)
+		return u.Path + "?" + q.Encode()
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"valid", signed, http.StatusOK},
+		{"expired", resign("reports/q1.txt", time.Now().Add(-time.Second)), http.StatusNotFound},
+		{"extended expiry", with("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)), http.StatusNotFound},
+		{"bad signature", with("signature", strings.Repeat("0", 64)), http.StatusNotFound},
+		{"missing signature", u.Path + "?expires=" + query.Get("expires"), http.StatusNotFound},
+		{"other key", "/files/reports/q2.txt?" + query.Encode(), http.StatusNotFound},
+		{"missing object", resign("reports/q2.txt", time.Now().Add(time.Minute)), http.StatusNotFound},
+		{"traversal", resign("../secret.txt", time.Now().Add(time.Minute)), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != "quarterly report" {
+				t.Errorf("unexpected body %q", rec.Body.String())
+			}
+		})
+	}
+
+	store.secret = nil
+	if _, err := store.SignedURL(ctx, "reports/q1.txt", time.Minute); err == nil {
+		t.Error("SignedURL: expected an error without a Secret")
+	}
+}
+
+func TestUpload(t *testing.T) {
+	store := newTestStore(t)
+	app := quark.New()
+	app.POST("/upload", func(c *quark.Context) error {
+		obj, err := Upload(c, store, "file", "avatars/")
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, obj)
+	})
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("file", "../../me.PNG")
+	part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	w.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"multipart", w.FormDataContentType(), body.String(), http.StatusCreated},
+		{"raw body", "image/png", "\x89PNG\r\n\x1a\n", http.StatusCreated},
+		{"missing field", w.FormDataContentType(), "--" + w.Boundary() + "--\r\n", http.StatusBadRequest},
+		{"empty body", "image/png", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	objects, _ := store.List(context.Background(), "avatars/")
+	if len(objects) != 2 {
+		t.Fatalf("expected two uploads, got %+v", objects)
+	}
+	for _, obj := range objects {
+		if !ValidKey(obj.Key) || strings.Contains(obj.Key, "..") {
+			t.Errorf("expected a key without the client file name, got %s", obj.Key)
+		}
+	}
+}
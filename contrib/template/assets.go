@@ -0,0 +1,263 @@
+package template
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// AssetsConfig holds static asset configuration.
+type AssetsConfig struct {
+	// Dir is the directory containing static files (default: "static").
+	// Ignored when FS is set.
+	Dir string
+
+	// FS is the filesystem containing static files, e.g. an embed.FS.
+	FS fs.FS
+
+	// Prefix is the URL path the assets are served under
+	// (default: "/static").
+	Prefix string
+
+	// Manifest is the path within the filesystem of a JSON manifest mapping
+	// asset names to fingerprinted file names, as written by frontend
+	// build tools (e.g., {"app.css": "app.3f2a1b9c.css"}). Without a
+	// manifest, files are fingerprinted with a hash of their content on
+	// first use.
+	Manifest string
+
+	// MaxAge is the Cache-Control max-age of fingerprinted files
+	// (default: one year).
+	MaxAge time.Duration
+}
+
+// Assets generates fingerprinted asset URLs for cache busting and serves
+// the assets, with far-future cache headers for fingerprinted URLs. Since
+// the URL changes whenever the content does, browsers can cache assets
+// indefinitely.
+//
+// Example:
+//
+//	assets, err := template.NewAssets(template.AssetsConfig{Dir: "static"})
+//	assets.Mount(app) // Serves /static/...
+//
+//	config := template.DefaultConfig()
+//	config.Assets = assets
+//	engine, err := template.New(config)
+//
+//	// In templates: <link rel="stylesheet" href="{{asset "css/app.css"}}">
+//	// Renders:      <link rel="stylesheet" href="/static/css/app.3f2a1b9c.css">
+type Assets struct {
+	fsys     fs.FS
+	prefix   string
+	maxAge   time.Duration
+	manifest map[string]string   // Asset name to fingerprinted name
+	built    map[string]struct{} // Fingerprinted names from the manifest
+
+	mu     sync.Mutex
+	hashes map[string]assetHash // Content hashes by asset name
+}
+
+// assetHash is the content hash of a file as of its size and mtime.
+type assetHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+// NewAssets creates an asset helper, loading the manifest if configured.
+func NewAssets(config AssetsConfig) (*Assets, error) {
+	if config.FS == nil {
+		if config.Dir == "" {
+			config.Dir = "static"
+		}
+		config.FS = os.DirFS(config.Dir)
+	}
+	if config.Prefix == "" {
+		config.Prefix = "/static"
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 365 * 24 * time.Hour
+	}
+
+	a := &Assets{
+		fsys:   config.FS,
+		prefix: strings.TrimSuffix(config.Prefix, "/"),
+		maxAge: config.MaxAge,
+		hashes: make(map[string]assetHash),
+	}
+
+	if config.Manifest != "" {
+		data, err := fs.ReadFile(config.FS, config.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset manifest: %w", err)
+		}
+		if err := json.Unmarshal(data, &a.manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse asset manifest: %w", err)
+		}
+		a.built = make(map[string]struct{}, len(a.manifest))
+		for _, name := range a.manifest {
+			a.built[strings.TrimPrefix(name, "/")] = struct{}{}
+		}
+	}
+
+	return a, nil
+}
+
+// URL returns the fingerprinted URL of an asset. It is available in
+// templates as the asset function when the engine is configured with
+// Config.Assets.
+func (a *Assets) URL(name string) (string, error) {
+	name = cleanAssetPath(name)
+
+	if a.manifest != nil {
+		built, ok := a.manifest[name]
+		if !ok {
+			return "", fmt.Errorf("asset not in manifest: %s", name)
+		}
+		return a.prefix + "/" + strings.TrimPrefix(built, "/"), nil
+	}
+
+	hash, err := a.hash(name)
+	if err != nil {
+		return "", err
+	}
+	return a.prefix + "/" + fingerprint(name, hash), nil
+}
+
+// Handler returns a handler serving the assets from the "filepath" route
+// parameter. Fingerprinted files are served with far-future cache headers;
+// other files are served without them.
+func (a *Assets) Handler() quark.HandlerFunc {
+	return func(c *quark.Context) error {
+		name, immutable := a.resolve(cleanAssetPath(c.Param("filepath")))
+		content, info, err := a.open(name)
+		if err != nil {
+			return quark.ErrNotFound("asset not found")
+		}
+
+		if immutable {
+			c.SetHeader("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int64(a.maxAge.Seconds())))
+		}
+		http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), content)
+		return nil
+	}
+}
+
+// Mount registers the asset handler on the app under the configured
+// prefix, in place of app.Static.
+func (a *Assets) Mount(app *quark.App) {
+	app.GET(a.prefix+"/{filepath:.*}", a.Handler())
+}
+
+// resolve maps a requested path to the file to serve, reporting whether it
+// is a fingerprinted file.
+func (a *Assets) resolve(requested string) (string, bool) {
+	if _, ok := a.built[requested]; ok {
+		return requested, true
+	}
+
+	// Hashed on demand: strip the fingerprint and check it is current.
+	// Anything else, including stale fingerprints, is served as is.
+	if name, hash, ok := splitFingerprint(requested); ok && a.manifest == nil {
+		if current, err := a.hash(name); err == nil && current == hash {
+			return name, true
+		}
+	}
+	return requested, false
+}
+
+// open returns the content and info of a regular file.
+func (a *Assets) open(name string) (io.ReadSeeker, fs.FileInfo, error) {
+	f, err := a.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil, fs.ErrNotExist
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewReader(data), info, nil
+}
+
+// hash returns the content hash of an asset, recomputing it when the file
+// size or modification time changed.
+func (a *Assets) hash(name string) (string, error) {
+	info, err := fs.Stat(a.fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("asset not found: %s", name)
+	}
+
+	a.mu.Lock()
+	cached, ok := a.hashes[name]
+	a.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, nil
+	}
+
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("asset not found: %s", name)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:4])
+
+	a.mu.Lock()
+	a.hashes[name] = assetHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	a.mu.Unlock()
+	return hash, nil
+}
+
+// fingerprint inserts hash before the extension of name
+// ("css/app.css" becomes "css/app.3f2a1b9c.css").
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// splitFingerprint reverses fingerprint, reporting whether name contains a
+// fingerprint.
+func splitFingerprint(name string) (string, string, bool) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	dot := strings.LastIndexByte(base, '.')
+	if dot < 0 || strings.Contains(base[dot:], "/") {
+		return "", "", false
+	}
+
+	hash := base[dot+1:]
+	if len(hash) != 8 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", "", false
+	}
+	return base[:dot] + ext, hash, true
+}
+
+// cleanAssetPath normalizes an asset path relative to the asset root.
+func cleanAssetPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
@@ -0,0 +1,182 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+const appCSS = "body { color: red; }"
+
+// appCSSHash is the fingerprint of appCSS.
+var appCSSHash = func() string {
+	sum := sha256.Sum256([]byte(appCSS))
+	return hex.EncodeToString(sum[:4])
+}()
+
+func newTestAssets(t *testing.T, config AssetsConfig) *Assets {
+	t.Helper()
+	if config.FS == nil {
+		config.FS = fstest.MapFS{
+			"css/app.css":        {Data: []byte(appCSS)},
+			"js/app.1a2b3c4d.js": {Data: []byte("built")},
+			"manifest.json":      {Data: []byte(`{"js/app.js": "/js/app.1a2b3c4d.js"}`)},
+			"img/logo.png":       {Data: []byte("png")},
+			"fonts":              {Mode: fs.ModeDir | 0o755},
+			"fonts/inter.woff2":  {Data: []byte("font")},
+		}
+	}
+	assets, err := NewAssets(config)
+	if err != nil {
+		t.Fatalf("NewAssets: unexpected error: %v", err)
+	}
+	return assets
+}
+
+func TestAssetsURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AssetsConfig
+		asset   string
+		want    string
+		wantErr bool
+	}{
+		{"content hash", AssetsConfig{}, "css/app.css", "/static/css/app." + appCSSHash + ".css", false},
+		{"leading slash", AssetsConfig{}, "/css/app.css", "/static/css/app." + appCSSHash + ".css", false},
+		{"prefix", AssetsConfig{Prefix: "/assets/"}, "css/app.css", "/assets/css/app." + appCSSHash + ".css", false},
+		{"missing", AssetsConfig{}, "css/missing.css", "", true},
+		{"manifest", AssetsConfig{Manifest: "manifest.json"}, "js/app.js", "/static/js/app.1a2b3c4d.js", false},
+		{"not in manifest", AssetsConfig{Manifest: "manifest.json"}, "css/app.css", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestAssets(t, tt.config).URL(tt.asset)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expected %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestAssetsURLChangesWithContent(t *testing.T) {
+	fsys := fstest.MapFS{"app.css": {Data: []byte(appCSS), ModTime: time.Unix(1, 0)}}
+	assets := newTestAssets(t, AssetsConfig{FS: fsys})
+
+	before, _ := assets.URL("app.css")
+	fsys["app.css"] = &fstest.MapFile{Data: []byte("body { color: blue; }"), ModTime: time.Unix(2, 0)}
+	after, _ := assets.URL("app.css")
+	if before == after {
+		t.Errorf("expected a new fingerprint after a change, got %q twice", after)
+	}
+}
+
+func TestNewAssetsManifestErrors(t *testing.T) {
+	fsys := fstest.MapFS{"broken.json": {Data: []byte("{")}}
+	for _, manifest := range []string{"missing.json", "broken.json"} {
+		if _, err := NewAssets(AssetsConfig{FS: fsys, Manifest: manifest}); err == nil {
+			t.Errorf("%s: expected an error", manifest)
+		}
+	}
+}
+
+func TestAssetsHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        AssetsConfig
+		path          string
+		wantStatus    int
+		wantBody      string
+		wantImmutable bool
+	}{
+		{"fingerprinted", AssetsConfig{}, "/static/css/app." + appCSSHash + ".css", http.StatusOK, appCSS, true},
+		{"plain", AssetsConfig{}, "/static/css/app.css", http.StatusOK, appCSS, false},
+		{"stale fingerprint", AssetsConfig{}, "/static/css/app.00000000.css", http.StatusNotFound, "", false},
+		{"missing", AssetsConfig{}, "/static/css/missing.css", http.StatusNotFound, "", false},
+		{"directory", AssetsConfig{}, "/static/fonts", http.StatusNotFound, "", false},
+		{"traversal", AssetsConfig{}, "/static/../css/app.css", http.StatusOK, appCSS, false},
+		{"manifest file", AssetsConfig{Manifest: "manifest.json"}, "/static/js/app.1a2b3c4d.js", http.StatusOK, "built", true},
+		{"manifest unlisted", AssetsConfig{Manifest: "manifest.json"}, "/static/img/logo.png", http.StatusOK, "png", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := quark.New()
+			newTestAssets(t, tt.config).Mount(app)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.Path = tt.path
+			app.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+			cacheControl := rec.Header().Get("Cache-Control")
+			if tt.wantImmutable && cacheControl != "public, max-age=31536000, immutable" {
+				t.Errorf("expected far-future cache headers, got %q", cacheControl)
+			}
+			if !tt.wantImmutable && cacheControl != "" {
+				t.Errorf("expected no cache headers, got %q", cacheControl)
+			}
+		})
+	}
+}
+
+func TestSplitFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantHash string
+		wantOK   bool
+	}{
+		{"css/app.3f2a1b9c.css", "css/app.css", "3f2a1b9c", true},
+		{"css/app.css", "", "", false},
+		{"css/app.min.css", "", "", false},
+		{"css/app.3f2a1b9z.css", "", "", false},
+		{"v1.3f2a1b9c/app.css", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, hash, ok := splitFingerprint(tt.name)
+			if name != tt.wantName || hash != tt.wantHash || ok != tt.wantOK {
+				t.Errorf("expected (%q, %q, %v), got (%q, %q, %v)", tt.wantName, tt.wantHash, tt.wantOK, name, hash, ok)
+			}
+			if ok && fingerprint(name, hash) != tt.name {
+				t.Errorf("expected fingerprint to reverse it, got %q", fingerprint(name, hash))
+			}
+		})
+	}
+}
+
+func TestAssetFunc(t *testing.T) {
+	config := DefaultConfig()
+	config.Assets = newTestAssets(t, AssetsConfig{})
+	engine := newTestEngine(t, config, map[string]string{
+		"home.html": `<link href="{{asset "css/app.css"}}">`,
+		"bad.html":  `{{asset "missing.css"}}`,
+	})
+
+	got, err := engine.RenderString("home", nil)
+	want := `<link href="/static/css/app.` + appCSSHash + `.css">`
+	if err != nil || got != want {
+		t.Errorf("expected %q, got %q (%v)", want, got, err)
+	}
+	if _, err := engine.RenderString("bad", nil); err == nil {
+		t.Error("expected missing assets to fail the render")
+	}
+}
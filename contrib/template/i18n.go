@@ -0,0 +1,124 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Catalog provides translated messages to the t and tn template functions.
+//
+// Example templates:
+//
+//	<h1>{{t "welcome" .User.Name}}</h1>
+//	<p>{{tn "inbox.count" .Unread}}</p>
+type Catalog interface {
+	// Translate returns the message for key in locale, formatted with args.
+	Translate(locale, key string, args ...interface{}) string
+
+	// TranslatePlural returns the message form for count in locale,
+	// formatted with count followed by args.
+	TranslatePlural(locale, key string, count int, args ...interface{}) string
+}
+
+// Messages is an in-memory Catalog mapping locales to message keys and
+// fmt format strings. Plural forms are stored under key+".one" and
+// key+".other". Lookups fall back from a regional locale to its base
+// language (e.g., "pt-BR" to "pt"), and missing messages render as the key.
+//
+// Example:
+//
+//	catalog := template.Messages{
+//	    "en": {
+//	        "welcome":           "Welcome, %s!",
+//	        "inbox.count.one":   "%d new message",
+//	        "inbox.count.other": "%d new messages",
+//	    },
+//	    "fr": {
+//	        "welcome":           "Bienvenue, %s !",
+//	        "inbox.count.one":   "%d nouveau message",
+//	        "inbox.count.other": "%d nouveaux messages",
+//	    },
+//	}
+type Messages map[string]map[string]string
+
+// Translate implements Catalog.
+func (m Messages) Translate(locale, key string, args ...interface{}) string {
+	msg, ok := m.lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return format(msg, args)
+}
+
+// TranslatePlural implements Catalog.
+func (m Messages) TranslatePlural(locale, key string, count int, args ...interface{}) string {
+	form := key + ".other"
+	if count == 1 {
+		form = key + ".one"
+	}
+
+	msg, ok := m.lookup(locale, form)
+	if !ok {
+		if msg, ok = m.lookup(locale, key); !ok {
+			return key
+		}
+	}
+	return format(msg, append([]interface{}{count}, args...))
+}
+
+// lookup finds the message for key in locale or its base language.
+func (m Messages) lookup(locale, key string) (string, bool) {
+	if msg, ok := m[locale][key]; ok {
+		return msg, true
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		msg, ok := m[locale[:i]][key]
+		return msg, ok
+	}
+	return "", false
+}
+
+// format applies args to msg when it contains verbs.
+func format(msg string, args []interface{}) string {
+	if len(args) == 0 || !strings.Contains(msg, "%") {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// translate implements the t template function.
+func (e *Engine) translate(locale, key string, args []interface{}) string {
+	if e.catalog == nil {
+		return key
+	}
+	return e.catalog.Translate(locale, key, args...)
+}
+
+// translatePlural implements the tn template function.
+func (e *Engine) translatePlural(locale, key string, count int, args []interface{}) string {
+	if e.catalog == nil {
+		return key
+	}
+	return e.catalog.TranslatePlural(locale, key, count, args...)
+}
+
+// localeOf returns the locale templates are rendered in for a request.
+// Without a catalog every request uses the default locale, so no
+// per-locale template sets are built.
+func (e *Engine) localeOf(c *quark.Context) string {
+	if e.catalog == nil || c == nil {
+		return e.locale
+	}
+	if locale := e.localeFunc(c); locale != "" {
+		return locale
+	}
+	return e.locale
+}
+
+// contextLocale returns the locale context value, as stored by
+// quark.Context.Locale.
+func contextLocale(c *quark.Context) string {
+	return c.GetString(quark.LocaleKey)
+}
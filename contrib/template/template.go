@@ -8,7 +8,7 @@
 //	// Create template engine
 //	config := template.DefaultConfig()
 //	config.Dir = "views"
-//	config.Reload = true  // Enable reload in development
+//	config.Watch = true   // Reload changed templates in development
 //	engine, err := template.New(config)
 //
 //	// Render in handler
@@ -20,6 +20,45 @@
 //	    return engine.HTML(c, 200, "home", data)
 //	})
 //
+// With layouts:
+//
+//	config.Layout = "layouts/main" // Default layout for engine.HTML
+//	engine, err := template.New(config)
+//
+//	// layouts/main.html: <body>{{block "content" .}}{{end}}</body>
+//	// home.html:         {{define "content"}}<h1>{{.title}}</h1>{{end}}
+//	return engine.HTML(c, 200, "home", data)
+//
+//	// Or per call
+//	return engine.HTMLWithLayout(c, 200, "layouts/admin", "admin/stats", data)
+//
+// Registered on the app, so handlers need no engine reference:
+//
+//	engine.Register(app)
+//
+//	app.GET("/users/{id}", func(c *quark.Context) error {
+//	    return c.Render(200, "users/show", data)
+//	})
+//
+// Fragments for htmx or ajax requests, rendered without the layout:
+//
+//	return engine.HTMLPartial(c, 200, "partials/user_row", user)
+//
+//	// Or from inside a template, with a computed name
+//	{{partial (printf "cards/%s" .Kind) .}}
+//
+// With per-request data available to every template:
+//
+//	engine.Global(func(c *quark.Context) quark.M {
+//	    return quark.M{"user": c.Get("user"), "csrf": c.GetString("csrf")}
+//	})
+//
+// With custom delimiters, for pages that also contain Vue or Angular markup:
+//
+//	config.Delims = [2]string{"[[", "]]"}
+//
+//	// <p>[[.title]]</p> <div id="app">{{ message }}</div>
+//
 // With embedded templates:
 //
 //	//go:embed templates/*
@@ -39,6 +78,9 @@
 //   - plural: Pluralization helper
 //   - truncate: Text truncation
 //   - dict, list: Data structure helpers
+//   - partial: Render another template by name
+//   - t, tn: Translate messages in the request locale (see Catalog)
+//   - asset: Fingerprinted asset URL (see Assets)
 package template
 
 import (
@@ -49,9 +91,10 @@ import (
 	"io/fs"
 	"net/http"
 	"os"
-	"path/filepath"
+	"path"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/AchrafSoltani/quark"
 )
@@ -59,11 +102,38 @@ import (
 // Engine is a template engine that manages HTML templates.
 type Engine struct {
 	templates *template.Template
+	sources   []source                      // Template files in load order
+	pages     map[string]*template.Template // Per-page sets for layout rendering
+	locales   map[string]*template.Template // Full sets for non-default locales
 	funcMap   template.FuncMap
+	fsys      fs.FS
 	dir       string
 	ext       string
 	reload    bool
+	layout    string
+	stamp     string   // Fingerprint of the loaded template files
+	shared    []string // Patterns of templates included in every page set
+	delims    [2]string
 	mu        sync.RWMutex
+
+	// Localization
+	catalog    Catalog
+	locale     string
+	localeFunc func(*quark.Context) string
+
+	globals []func(*quark.Context) quark.M // Per-request view data hooks
+
+	// Watch mode state
+	watch         bool
+	watchInterval time.Duration
+	lastCheck     time.Time
+	watchMu       sync.Mutex
+}
+
+// source is a template file.
+type source struct {
+	name    string // Path relative to the root, without extension
+	content string
 }
 
 // Config holds template engine configuration.
@@ -77,11 +147,52 @@ type Config struct {
 	// Reload enables template reloading on each request (for development).
 	Reload bool
 
+	// Watch reloads templates only when template files change, polling
+	// their modification times at most once per WatchInterval. It keeps
+	// edits visible during development without parsing on every request.
+	// Reload takes precedence when both are set.
+	Watch bool
+
+	// WatchInterval is the minimum time between change checks in watch
+	// mode (default: 1 second).
+	WatchInterval time.Duration
+
 	// FuncMap is the template function map.
 	FuncMap template.FuncMap
 
-	// Layouts is a list of layout template paths relative to Dir.
+	// Layouts is a list of layout template paths relative to Dir. Entries
+	// are template names without extension and may be path.Match patterns.
+	// Matching templates (layouts and the partials they use) are available
+	// when rendering a page inside a layout (default: "layouts/*" and
+	// "partials/*").
 	Layouts []string
+
+	// Layout is the default layout used by HTML (e.g., "layouts/main").
+	// Empty renders templates standalone.
+	Layout string
+
+	// Delims sets the action delimiters (default: "{{" and "}}"), e.g.
+	// [2]string{"[[", "]]"} for templates that also contain Vue or
+	// Angular markup.
+	Delims [2]string
+
+	// Catalog provides the messages of the t and tn template functions.
+	// Without a catalog they return the message key.
+	Catalog Catalog
+
+	// DefaultLocale is the locale used when the request has none, and by
+	// renders without a request such as Render (default: "en").
+	DefaultLocale string
+
+	// LocaleFunc returns the locale of a request (default: the "locale"
+	// context value, as set by quark.Context.Locale). Templates
+	// are parsed once per locale, so it must only return supported locales,
+	// never raw client input.
+	LocaleFunc func(*quark.Context) string
+
+	// Assets provides the asset template function, which returns
+	// fingerprinted asset URLs (see Assets).
+	Assets *Assets
 }
 
 // DefaultConfig returns the default template configuration.
@@ -100,21 +211,60 @@ func New(config Config) (*Engine, error) {
 	if config.Dir == "" {
 		config.Dir = "templates"
 	}
+	return newEngine(os.DirFS(config.Dir), config)
+}
+
+// NewFromFS creates a template engine from an embedded filesystem.
+func NewFromFS(fsys fs.FS, config Config) (*Engine, error) {
+	// No reload for embedded FS
+	config.Reload = false
+	config.Watch = false
+	return newEngine(fsys, config)
+}
+
+// newEngine creates an engine over fsys and loads its templates.
+func newEngine(fsys fs.FS, config Config) (*Engine, error) {
 	if config.Extension == "" {
 		config.Extension = ".html"
 	}
 	if config.FuncMap == nil {
 		config.FuncMap = make(template.FuncMap)
 	}
+	if config.WatchInterval <= 0 {
+		config.WatchInterval = time.Second
+	}
+	if config.DefaultLocale == "" {
+		config.DefaultLocale = "en"
+	}
+	if config.LocaleFunc == nil {
+		config.LocaleFunc = contextLocale
+	}
 
 	// Add default functions
 	addDefaultFuncs(config.FuncMap)
+	if config.Assets != nil {
+		config.FuncMap["asset"] = config.Assets.URL
+	}
 
 	engine := &Engine{
 		funcMap: config.FuncMap,
+		fsys:    fsys,
 		dir:     config.Dir,
 		ext:     config.Extension,
 		reload:  config.Reload,
+		layout:  config.Layout,
+		shared:  config.Layouts,
+		delims:  config.Delims,
+
+		catalog:    config.Catalog,
+		locale:     config.DefaultLocale,
+		localeFunc: config.LocaleFunc,
+
+		watch:         config.Watch && !config.Reload,
+		watchInterval: config.WatchInterval,
+	}
+	if len(engine.shared) == 0 {
+		engine.shared = []string{"layouts/*", "partials/*"}
 	}
 
 	if err := engine.load(); err != nil {
@@ -124,91 +274,222 @@ func New(config Config) (*Engine, error) {
 	return engine, nil
 }
 
-// NewFromFS creates a template engine from an embedded filesystem.
-func NewFromFS(fsys fs.FS, config Config) (*Engine, error) {
-	if config.Extension == "" {
-		config.Extension = ".html"
-	}
-	if config.FuncMap == nil {
-		config.FuncMap = make(template.FuncMap)
+// load loads all templates from the filesystem.
+func (e *Engine) load() error {
+	// Taken before reading so that files changed meanwhile are seen as
+	// changed by the next check
+	stamp, err := e.fingerprint()
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
-	addDefaultFuncs(config.FuncMap)
+	var sources []source
 
-	tmpl := template.New("").Funcs(config.FuncMap)
-
-	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+	err = fs.WalkDir(e.fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
 			return nil
 		}
-		if !strings.HasSuffix(path, config.Extension) {
+		if !strings.HasSuffix(path, e.ext) {
 			return nil
 		}
 
-		content, err := fs.ReadFile(fsys, path)
+		content, err := fs.ReadFile(e.fsys, path)
 		if err != nil {
 			return err
 		}
 
-		name := strings.TrimSuffix(path, config.Extension)
-		_, err = tmpl.New(name).Parse(string(content))
-		return err
+		// Use relative path as template name (without extension)
+		sources = append(sources, source{
+			name:    strings.TrimSuffix(path, e.ext),
+			content: string(content),
+		})
+		return nil
 	})
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tmpl, err := e.parse(sources, e.funcsFor(e.locale))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load templates: %w", err)
+		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
-	return &Engine{
-		templates: tmpl,
-		funcMap:   config.FuncMap,
-		dir:       config.Dir,
-		ext:       config.Extension,
-		reload:    false, // No reload for embedded FS
-	}, nil
+	e.templates = tmpl
+	e.sources = sources
+	e.stamp = stamp
+	e.pages = make(map[string]*template.Template)
+	e.locales = make(map[string]*template.Template)
+	return nil
 }
 
-// load loads all templates from the directory.
-func (e *Engine) load() error {
+// parse parses sources into a single template set.
+func (e *Engine) parse(sources []source, funcs template.FuncMap) (*template.Template, error) {
+	tmpl := template.New("").Delims(e.delims[0], e.delims[1]).Funcs(funcs)
+	for _, src := range sources {
+		if _, err := tmpl.New(src.name).Parse(src.content); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// funcsFor returns the template functions for rendering in locale: the
+// engine functions plus partial, t and tn bound to the locale. Functions
+// from Config.FuncMap take precedence. The caller must hold the lock.
+func (e *Engine) funcsFor(locale string) template.FuncMap {
+	funcs := template.FuncMap{
+		"partial": func(name string, data interface{}) (template.HTML, error) {
+			return e.partial(locale, name, data)
+		},
+		"t": func(key string, args ...interface{}) string {
+			return e.translate(locale, key, args)
+		},
+		"tn": func(key string, count int, args ...interface{}) string {
+			return e.translatePlural(locale, key, count, args)
+		},
+	}
+	for name, fn := range e.funcMap {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// set returns the full template set for locale, parsing it on first use
+// for locales other than the default.
+func (e *Engine) set(locale string) (*template.Template, error) {
+	e.mu.RLock()
+	set, ok := e.locales[locale]
+	if locale == e.locale {
+		set, ok = e.templates, true
+	}
+	sources := e.sources
+	funcs := e.funcsFor(locale)
+	e.mu.RUnlock()
+	if ok {
+		return set, nil
+	}
+
+	set, err := e.parse(sources, funcs)
+	if err != nil {
+		return nil, err
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	e.locales[locale] = set
+	return set, nil
+}
 
-	tmpl := template.New("").Funcs(e.funcMap)
+// pageSet returns the template set used to render page inside layout in
+// locale: the shared templates (see Config.Layouts), the layout, and the
+// page, parsed last so that its {{define}} blocks override the layout's
+// {{block}} defaults. Other pages are excluded so their blocks cannot leak
+// in.
+func (e *Engine) pageSet(locale, layout, page string) (*template.Template, error) {
+	key := locale + "\x00" + layout + "\x00" + page
+
+	e.mu.RLock()
+	set, ok := e.pages[key]
+	sources := e.sources
+	funcs := e.funcsFor(locale)
+	e.mu.RUnlock()
+	if ok {
+		return set, nil
+	}
+
+	var selected []source
+	var pageSource *source
+	for i, src := range sources {
+		switch {
+		case src.name == page:
+			pageSource = &sources[i]
+		case src.name == layout || e.isShared(src.name):
+			selected = append(selected, src)
+		}
+	}
+	if pageSource == nil {
+		return nil, fmt.Errorf("template not found: %s", page)
+	}
+
+	set, err := e.parse(append(selected, *pageSource), funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pages[key] = set
+	return set, nil
+}
 
-	err := filepath.Walk(e.dir, func(path string, info os.FileInfo, err error) error {
+// isShared reports whether a template matches one of the shared patterns.
+func (e *Engine) isShared(name string) bool {
+	for _, pattern := range e.shared {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint returns the names, sizes and modification times of the
+// template files.
+func (e *Engine) fingerprint() (string, error) {
+	var b strings.Builder
+	err := fs.WalkDir(e.fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(path, e.ext) {
+		if d.IsDir() || !strings.HasSuffix(path, e.ext) {
 			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		info, err := d.Info()
 		if err != nil {
 			return err
 		}
+		fmt.Fprintf(&b, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String(), err
+}
 
-		// Use relative path as template name (without extension)
-		relPath, _ := filepath.Rel(e.dir, path)
-		name := strings.TrimSuffix(relPath, e.ext)
-		name = filepath.ToSlash(name) // Normalize to forward slashes
+// refresh reloads templates before rendering: always in reload mode, and
+// when files changed in watch mode.
+func (e *Engine) refresh() error {
+	if e.reload {
+		return e.load()
+	}
+	if !e.watch {
+		return nil
+	}
 
-		_, err = tmpl.New(name).Parse(string(content))
-		return err
-	})
+	e.watchMu.Lock()
+	defer e.watchMu.Unlock()
+
+	if time.Since(e.lastCheck) < e.watchInterval {
+		return nil
+	}
+	e.lastCheck = time.Now()
 
+	stamp, err := e.fingerprint()
 	if err != nil {
-		return fmt.Errorf("failed to load templates: %w", err)
+		return fmt.Errorf("failed to check templates: %w", err)
 	}
 
-	e.templates = tmpl
-	return nil
+	e.mu.RLock()
+	changed := stamp != e.stamp
+	e.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	return e.load()
 }
 
 // Reload reloads all templates.
@@ -218,23 +499,155 @@ func (e *Engine) Reload() error {
 
 // Render renders a template to a writer.
 func (e *Engine) Render(w io.Writer, name string, data interface{}) error {
-	if e.reload {
-		if err := e.load(); err != nil {
-			return err
-		}
+	if err := e.refresh(); err != nil {
+		return err
 	}
+	return e.execute(w, e.locale, name, data)
+}
 
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+// execute renders a template from the set of locale. No lock is held while
+// executing so that templates calling partial do not lock recursively.
+func (e *Engine) execute(w io.Writer, locale, name string, data interface{}) error {
+	set, err := e.set(locale)
+	if err != nil {
+		return err
+	}
 
-	tmpl := e.templates.Lookup(name)
+	tmpl := set.Lookup(name)
 	if tmpl == nil {
 		return fmt.Errorf("template not found: %s", name)
 	}
-
 	return tmpl.Execute(w, data)
 }
 
+// Partial renders a single template without a layout and returns it as
+// trusted HTML, e.g. to embed a fragment in a JSON response. Inside
+// templates it is available as the partial function, which unlike
+// {{template}} accepts a computed name:
+//
+//	{{range .Items}}{{partial (printf "cards/%s" .Kind) .}}{{end}}
+func (e *Engine) Partial(name string, data interface{}) (template.HTML, error) {
+	if err := e.refresh(); err != nil {
+		return "", err
+	}
+	return e.partial(e.locale, name, data)
+}
+
+// partial implements the partial template function. It never reloads, as
+// it runs while another template is being rendered.
+func (e *Engine) partial(locale, name string, data interface{}) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := e.execute(&buf, locale, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// RenderWithLayout renders page inside layout. The layout declares
+// placeholders with {{block "name" .}}default{{end}} and the page fills
+// them with {{define "name"}}...{{end}}; content outside the page's
+// defines is ignored. Besides the layout and the page, only the shared
+// templates listed in Config.Layouts are available. An empty layout renders
+// the page standalone.
+//
+// Example layout (layouts/main.html):
+//
+//	<html>
+//	<head><title>{{block "title" .}}My App{{end}}</title></head>
+//	<body>{{block "content" .}}{{end}}</body>
+//	</html>
+//
+// Example page (users/index.html):
+//
+//	{{define "title"}}Users{{end}}
+//	{{define "content"}}<ul>{{range .Users}}<li>{{.Name}}</li>{{end}}</ul>{{end}}
+func (e *Engine) RenderWithLayout(w io.Writer, layout, page string, data interface{}) error {
+	if err := e.refresh(); err != nil {
+		return err
+	}
+	return e.render(w, e.locale, layout, page, data)
+}
+
+// renderRequest renders page inside layout in the locale of the request,
+// with the global view data merged in.
+func (e *Engine) renderRequest(c *quark.Context, w io.Writer, layout, page string, data interface{}) error {
+	if err := e.refresh(); err != nil {
+		return err
+	}
+	return e.render(w, e.localeOf(c), layout, page, e.withGlobals(c, data))
+}
+
+// Global registers a hook returning view data for every request-bound
+// render (HTML, HTMLWithLayout, HTMLPartial and c.Render), such as the
+// current user, CSRF token or flash messages. The data is merged into
+// nil, quark.M and map[string]interface{} render data; keys set by the
+// handler win over global ones, and later hooks win over earlier ones.
+// Other data types are rendered unchanged.
+//
+// Example:
+//
+//	engine.Global(func(c *quark.Context) quark.M {
+//	    return quark.M{
+//	        "user":      c.Get("user"),
+//	        "requestID": c.GetString("request_id"),
+//	    }
+//	})
+func (e *Engine) Global(fn func(*quark.Context) quark.M) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.globals = append(e.globals, fn)
+}
+
+// withGlobals merges the global view data of the request into data.
+func (e *Engine) withGlobals(c *quark.Context, data interface{}) interface{} {
+	e.mu.RLock()
+	globals := e.globals
+	e.mu.RUnlock()
+	if len(globals) == 0 || c == nil {
+		return data
+	}
+
+	var local map[string]interface{}
+	switch d := data.(type) {
+	case nil:
+	case quark.M:
+		local = d
+	case map[string]interface{}:
+		local = d
+	default:
+		return data
+	}
+
+	merged := make(quark.M, len(local))
+	for _, fn := range globals {
+		for k, v := range fn(c) {
+			merged[k] = v
+		}
+	}
+	for k, v := range local {
+		merged[k] = v
+	}
+	return merged
+}
+
+// render renders page inside layout in locale, or standalone when layout
+// is empty.
+func (e *Engine) render(w io.Writer, locale, layout, page string, data interface{}) error {
+	if layout == "" {
+		return e.execute(w, locale, page, data)
+	}
+
+	set, err := e.pageSet(locale, layout, page)
+	if err != nil {
+		return err
+	}
+	if set.Lookup(layout) == nil {
+		return fmt.Errorf("layout not found: %s", layout)
+	}
+
+	return set.ExecuteTemplate(w, layout, data)
+}
+
 // RenderString renders a template to a string.
 func (e *Engine) RenderString(name string, data interface{}) (string, error) {
 	var buf bytes.Buffer
@@ -256,10 +669,17 @@ func (e *Engine) AddFunc(name string, fn interface{}) {
 	e.funcMap[name] = fn
 }
 
-// HTML renders a template and sends the result as an HTML response.
+// HTML renders a template and sends the result as an HTML response. When a
+// default layout is configured, the template is rendered inside it.
 func (e *Engine) HTML(c *quark.Context, code int, name string, data interface{}) error {
+	return e.HTMLWithLayout(c, code, e.layout, name, data)
+}
+
+// HTMLWithLayout renders page inside layout and sends the result as an HTML
+// response. An empty layout renders the page standalone.
+func (e *Engine) HTMLWithLayout(c *quark.Context, code int, layout, page string, data interface{}) error {
 	var buf bytes.Buffer
-	if err := e.Render(&buf, name, data); err != nil {
+	if err := e.renderRequest(c, &buf, layout, page, data); err != nil {
 		return quark.WrapError(http.StatusInternalServerError, "template rendering failed", err)
 	}
 
@@ -269,6 +689,43 @@ func (e *Engine) HTML(c *quark.Context, code int, name string, data interface{})
 	return err
 }
 
+// HTMLPartial renders a single template without a layout and sends it as an
+// HTML response. It suits fragment requests from htmx or other ajax
+// clients that swap part of a page already rendered in its layout.
+//
+// Example:
+//
+//	app.GET("/users/{id}/row", func(c *quark.Context) error {
+//	    return engine.HTMLPartial(c, 200, "partials/user_row", user)
+//	})
+func (e *Engine) HTMLPartial(c *quark.Context, code int, name string, data interface{}) error {
+	return e.HTMLWithLayout(c, code, "", name, data)
+}
+
+// Register makes the engine the application's renderer, so handlers can
+// call c.Render without holding an engine reference. Templates rendered
+// through c.Render use the default layout, like HTML.
+func (e *Engine) Register(app *quark.App) {
+	app.SetRenderer(appRenderer{engine: e})
+}
+
+// appRenderer adapts the engine to quark.Renderer, applying the default
+// layout.
+type appRenderer struct {
+	engine *Engine
+}
+
+// Render implements quark.Renderer.
+func (r appRenderer) Render(w io.Writer, name string, data interface{}) error {
+	return r.engine.RenderWithLayout(w, r.engine.layout, name, data)
+}
+
+// RenderContext implements quark.ContextRenderer, rendering in the locale
+// of the request.
+func (r appRenderer) RenderContext(c *quark.Context, w io.Writer, name string, data interface{}) error {
+	return r.engine.renderRequest(c, w, r.engine.layout, name, data)
+}
+
 // addDefaultFuncs adds default template functions.
 func addDefaultFuncs(fm template.FuncMap) {
 	// Safe HTML output
@@ -395,9 +852,11 @@ func addDefaultFuncs(fm template.FuncMap) {
 }
 
 // Renderer interface for Quark integration.
-type Renderer interface {
-	Render(io.Writer, string, interface{}) error
-}
+type Renderer = quark.Renderer
 
 // Ensure Engine implements Renderer
-var _ Renderer = (*Engine)(nil)
+var (
+	_ Renderer              = (*Engine)(nil)
+	_ quark.Renderer        = appRenderer{}
+	_ quark.ContextRenderer = appRenderer{}
+)
@@ -0,0 +1,343 @@
+package template
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// newTestEngine returns an engine over in-memory templates.
+func newTestEngine(t *testing.T, config Config, files map[string]string) *Engine {
+	t.Helper()
+	fsys := make(fstest.MapFS, len(files))
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	engine, err := NewFromFS(fsys, config)
+	if err != nil {
+		t.Fatalf("NewFromFS: unexpected error: %v", err)
+	}
+	return engine
+}
+
+// layoutFiles is a site with two layouts and pages overriding their blocks.
+var layoutFiles = map[string]string{
+	"layouts/main.html":    `<title>{{block "title" .}}My App{{end}}</title><main>{{block "content" .}}empty{{end}}</main>{{template "partials/footer" .}}`,
+	"layouts/admin.html":   `<admin>{{block "content" .}}{{end}}</admin>`,
+	"partials/footer.html": `<footer>{{.year}}</footer>`,
+	"home.html":            `{{define "title"}}Home{{end}}{{define "content"}}<h1>{{.name}}</h1>{{end}}ignored`,
+	"about.html":           `{{define "content"}}About{{end}}`,
+	"plain.html":           `<p>{{.name}}</p>`,
+}
+
+func TestRenderWithLayout(t *testing.T) {
+	engine := newTestEngine(t, DefaultConfig(), layoutFiles)
+	data := quark.M{"name": "Ada", "year": 2026}
+
+	tests := []struct {
+		name    string
+		layout  string
+		page    string
+		want    string
+		wantErr string
+	}{
+		{"blocks overridden", "layouts/main", "home", "<title>Home</title><main><h1>Ada</h1></main><footer>2026</footer>", ""},
+		{"block default", "layouts/main", "about", "<title>My App</title><main>About</main><footer>2026</footer>", ""},
+		{"other layout", "layouts/admin", "home", "<admin><h1>Ada</h1></admin>", ""},
+		{"standalone", "", "plain", "<p>Ada</p>", ""},
+		{"missing page", "layouts/main", "missing", "", "template not found: missing"},
+		{"missing layout", "layouts/missing", "home", "", "layout not found: layouts/missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := engine.RenderWithLayout(&b, tt.layout, tt.page, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || b.String() != tt.want {
+				t.Errorf("expected %q, got %q (%v)", tt.want, b.String(), err)
+			}
+		})
+	}
+}
+
+func TestRenderWithLayoutBlocksNotShared(t *testing.T) {
+	engine := newTestEngine(t, DefaultConfig(), layoutFiles)
+
+	// Rendering home first must not leak its title into about
+	var b strings.Builder
+	engine.RenderWithLayout(&b, "layouts/main", "home", nil)
+	b.Reset()
+	if err := engine.RenderWithLayout(&b, "layouts/main", "about", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "<title>My App</title>") {
+		t.Errorf("expected the default title, got %q", b.String())
+	}
+}
+
+func TestHTMLDefaultLayout(t *testing.T) {
+	config := DefaultConfig()
+	config.Layout = "layouts/main"
+	engine := newTestEngine(t, config, layoutFiles)
+
+	app := quark.New()
+	engine.Register(app)
+	app.GET("/html", func(c *quark.Context) error {
+		return engine.HTML(c, http.StatusOK, "home", quark.M{"name": "Ada"})
+	})
+	app.GET("/partial", func(c *quark.Context) error {
+		return engine.HTMLPartial(c, http.StatusOK, "plain", quark.M{"name": "Ada"})
+	})
+	app.GET("/render", func(c *quark.Context) error {
+		return c.Render(http.StatusOK, "about", nil)
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/html", "<title>Home</title><main><h1>Ada</h1></main>"},
+		{"/partial", "<p>Ada</p>"},
+		{"/render", "<title>My App</title><main>About</main>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), tt.want) {
+				t.Errorf("expected %q, got %d %q", tt.want, rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Errorf("expected an HTML content type, got %q", ct)
+			}
+		})
+	}
+}
+
+// writeTemplate writes a template file under dir.
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		reload   bool
+		watch    bool
+		interval time.Duration
+		want     string
+	}{
+		{"watch", false, true, time.Millisecond, "<main>v2!</main>"},
+		{"watch within interval", false, true, time.Hour, "<main>v1</main>"},
+		{"reload", true, false, 0, "<main>v2!</main>"},
+		{"disabled", false, false, 0, "<main>v1</main>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, "layouts/main.html", `<main>{{block "content" .}}{{end}}</main>`)
+			writeTemplate(t, dir, "home.html", `{{define "content"}}v1{{end}}`)
+
+			config := DefaultConfig()
+			config.Dir = dir
+			config.Reload = tt.reload
+			config.Watch = tt.watch
+			config.WatchInterval = tt.interval
+			engine, err := New(config)
+			if err != nil {
+				t.Fatalf("New: unexpected error: %v", err)
+			}
+
+			var b strings.Builder
+			if err := engine.RenderWithLayout(&b, "layouts/main", "home", nil); err != nil || b.String() != "<main>v1</main>" {
+				t.Fatalf("expected the first version, got %q (%v)", b.String(), err)
+			}
+
+			// A different size marks the file changed whatever the mtime
+			// resolution
+			writeTemplate(t, dir, "home.html", `{{define "content"}}v2!{{end}}`)
+			time.Sleep(5 * time.Millisecond)
+
+			b.Reset()
+			if err := engine.RenderWithLayout(&b, "layouts/main", "home", nil); err != nil || b.String() != tt.want {
+				t.Errorf("expected %q, got %q (%v)", tt.want, b.String(), err)
+			}
+		})
+	}
+}
+
+func TestWatchParseError(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "home.html", `v1`)
+
+	config := DefaultConfig()
+	config.Dir = dir
+	config.Watch = true
+	config.WatchInterval = time.Millisecond
+	engine, err := New(config)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+
+	writeTemplate(t, dir, "home.html", `{{if}}broken`)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := engine.RenderString("home", nil); err == nil {
+		t.Error("expected the parse error of the changed template")
+	}
+
+	// Fixing the file recovers
+	writeTemplate(t, dir, "home.html", `fixed`)
+	time.Sleep(5 * time.Millisecond)
+	if got, err := engine.RenderString("home", nil); err != nil || got != "fixed" {
+		t.Errorf("expected the fixed template, got %q (%v)", got, err)
+	}
+}
+
+func TestNewFromFSDisablesWatch(t *testing.T) {
+	config := DefaultConfig()
+	config.Watch = true
+	config.Reload = true
+	engine := newTestEngine(t, config, map[string]string{"home.html": "home"})
+	if engine.watch || engine.reload {
+		t.Error("expected embedded templates never to reload")
+	}
+}
+
+func TestDelims(t *testing.T) {
+	files := map[string]string{
+		"layouts/main.html":    `<main>[[block "content" .]][[end]]</main>[[template "partials/footer" .]]`,
+		"partials/footer.html": `<footer>[[.name]]</footer>`,
+		"home.html":            `[[define "content"]]<p>[[.name | upper]]</p><div id="app">{{ message }}</div>[[partial "card" .]][[end]]`,
+		"card.html":            `<card>[[.name]]</card>`,
+	}
+	config := DefaultConfig()
+	config.Delims = [2]string{"[[", "]]"}
+	engine := newTestEngine(t, config, files)
+
+	var b strings.Builder
+	if err := engine.RenderWithLayout(&b, "layouts/main", "home", quark.M{"name": "Ada"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `<main><p>ADA</p><div id="app">{{ message }}</div><card>Ada</card></main><footer>Ada</footer>`
+	if b.String() != want {
+		t.Errorf("expected %q, got %q", want, b.String())
+	}
+
+	// Locale sets are parsed with the same delimiters
+	b.Reset()
+	if err := engine.execute(&b, "fr", "card", quark.M{"name": "Ada"}); err != nil || b.String() != "<card>Ada</card>" {
+		t.Errorf("expected the card in the fr set, got %q (%v)", b.String(), err)
+	}
+}
+
+func TestDefaultDelims(t *testing.T) {
+	engine := newTestEngine(t, DefaultConfig(), map[string]string{"home.html": `{{.name}} [[.name]]`})
+	got, err := engine.RenderString("home", quark.M{"name": "Ada"})
+	if err != nil || got != "Ada [[.name]]" {
+		t.Errorf("expected the default delimiters, got %q (%v)", got, err)
+	}
+}
+
+// page is render data that is not a map.
+type page struct{ Title string }
+
+func TestGlobal(t *testing.T) {
+	files := map[string]string{
+		"layouts/main.html": `<nav>{{.user}}</nav>{{block "content" .}}{{end}}`,
+		"home.html":         `{{define "content"}}{{.title}}|{{.csrf}}{{end}}`,
+		"plain.html":        `{{.user}}|{{.title}}`,
+		"page.html":         `{{.Title}}`,
+	}
+	config := DefaultConfig()
+	config.Layout = "layouts/main"
+	engine := newTestEngine(t, config, files)
+	engine.Global(func(c *quark.Context) quark.M {
+		return quark.M{"user": c.Get("user"), "title": "Global", "csrf": "first"}
+	})
+	engine.Global(func(c *quark.Context) quark.M {
+		return quark.M{"csrf": "token"}
+	})
+
+	tests := []struct {
+		name   string
+		render func(c *quark.Context) error
+		want   string
+	}{
+		{"HTML", func(c *quark.Context) error {
+			return engine.HTML(c, http.StatusOK, "home", quark.M{"title": "Home"})
+		}, "<nav>Ada</nav>Home|token"},
+		{"nil data", func(c *quark.Context) error {
+			return engine.HTML(c, http.StatusOK, "home", nil)
+		}, "<nav>Ada</nav>Global|token"},
+		{"map data", func(c *quark.Context) error {
+			return engine.HTMLPartial(c, http.StatusOK, "plain", map[string]interface{}{"title": "Map"})
+		}, "Ada|Map"},
+		{"struct data", func(c *quark.Context) error {
+			return engine.HTMLPartial(c, http.StatusOK, "page", page{Title: "Struct"})
+		}, "Struct"},
+		{"c.Render", func(c *quark.Context) error {
+			return c.Render(http.StatusOK, "home", quark.M{"title": "Rendered"})
+		}, "<nav>Ada</nav>Rendered|token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := quark.New()
+			engine.Register(app)
+			app.GET("/", func(c *quark.Context) error {
+				c.Set("user", "Ada")
+				return tt.render(c)
+			})
+
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Body.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGlobalDataNotModified(t *testing.T) {
+	engine := newTestEngine(t, DefaultConfig(), map[string]string{"home.html": `{{.user}}`})
+	engine.Global(func(c *quark.Context) quark.M { return quark.M{"user": "Ada"} })
+
+	data := quark.M{"title": "Home"}
+	app := quark.New()
+	app.GET("/", func(c *quark.Context) error {
+		return engine.HTML(c, http.StatusOK, "home", data)
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if _, ok := data["user"]; ok || len(data) != 1 {
+		t.Errorf("expected the handler data left unchanged, got %v", data)
+	}
+}
+
+func TestGlobalNotUsedWithoutRequest(t *testing.T) {
+	engine := newTestEngine(t, DefaultConfig(), map[string]string{"home.html": `[{{.user}}]`})
+	engine.Global(func(c *quark.Context) quark.M { return quark.M{"user": c.Get("user")} })
+
+	got, err := engine.RenderString("home", quark.M{})
+	if err != nil || got != "[]" {
+		t.Errorf("expected no global data outside requests, got %q (%v)", got, err)
+	}
+}
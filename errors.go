@@ -7,9 +7,10 @@ import (
 
 // HTTPError represents an HTTP error with a status code and message.
 type HTTPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"` // Optional payload sent to the client (e.g. validation errors)
+	Err     error       `json:"-"`
 }
 
 // Error implements the error interface.
@@ -40,8 +40,8 @@ func main() {
 	app.Use(middleware.Logger())
 	app.Use(middleware.CORS(middleware.DefaultCORSConfig))
 
-	// Health check endpoint
-	app.GET("/health", healthHandler)
+	// Liveness and readiness endpoints
+	app.Health("/healthz", "/readyz")
 
 	// Public routes
 	app.POST("/auth/login", loginHandler)
@@ -67,14 +67,6 @@ func main() {
 	}
 }
 
-// healthHandler returns the server health status.
-func healthHandler(c *quark.Context) error {
-	return c.JSON(200, quark.M{
-		"status":  "ok",
-		"version": quark.Version,
-	})
-}
-
 // loginHandler handles user login and returns a JWT token.
 func loginHandler(c *quark.Context) error {
 	var input struct {
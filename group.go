@@ -70,7 +70,7 @@ func (g *RouteGroup) Group(prefix string, mw ...MiddlewareFunc) *RouteGroup {
 // handle registers a route with the combined prefix and middleware.
 // It merges the group's middleware with any route-specific middleware,
 // ensuring the group middleware runs first (outer layer).
-func (g *RouteGroup) handle(method, pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
+func (g *RouteGroup) handle(method, pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
 	// Combine group middleware with route middleware
 	// Group middleware is applied first (outer layer), then route middleware (inner layer)
 	allMiddleware := make([]MiddlewareFunc, len(g.middleware)+len(mw))
@@ -79,42 +79,42 @@ func (g *RouteGroup) handle(method, pattern string, h HandlerFunc, mw ...Middlew
 
 	// Concatenate group prefix with route pattern
 	fullPattern := g.prefix + pattern
-	g.router.Handle(method, fullPattern, h, allMiddleware...)
+	return g.router.Handle(method, fullPattern, h, allMiddleware...)
 }
 
 // GET registers a GET route.
-func (g *RouteGroup) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("GET", pattern, h, mw...)
+func (g *RouteGroup) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("GET", pattern, h, mw...)
 }
 
 // POST registers a POST route.
-func (g *RouteGroup) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("POST", pattern, h, mw...)
+func (g *RouteGroup) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("POST", pattern, h, mw...)
 }
 
 // PUT registers a PUT route.
-func (g *RouteGroup) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("PUT", pattern, h, mw...)
+func (g *RouteGroup) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("PUT", pattern, h, mw...)
 }
 
 // PATCH registers a PATCH route.
-func (g *RouteGroup) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("PATCH", pattern, h, mw...)
+func (g *RouteGroup) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("PATCH", pattern, h, mw...)
 }
 
 // DELETE registers a DELETE route.
-func (g *RouteGroup) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("DELETE", pattern, h, mw...)
+func (g *RouteGroup) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("DELETE", pattern, h, mw...)
 }
 
 // OPTIONS registers an OPTIONS route.
-func (g *RouteGroup) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("OPTIONS", pattern, h, mw...)
+func (g *RouteGroup) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("OPTIONS", pattern, h, mw...)
 }
 
 // HEAD registers a HEAD route.
-func (g *RouteGroup) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	g.handle("HEAD", pattern, h, mw...)
+func (g *RouteGroup) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return g.handle("HEAD", pattern, h, mw...)
 }
 
 // Any registers a route for all HTTP methods.
@@ -130,6 +130,11 @@ func (g *RouteGroup) Static(relativePath, root string) {
 	g.router.Static(g.prefix+relativePath, root)
 }
 
+// StaticWithConfig serves static files, see Router.StaticWithConfig.
+func (g *RouteGroup) StaticWithConfig(relativePath string, config StaticConfig) {
+	g.router.StaticWithConfig(g.prefix+relativePath, config)
+}
+
 // Prefix returns the group's prefix.
 func (g *RouteGroup) Prefix() string {
 	return g.prefix
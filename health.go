@@ -0,0 +1,240 @@
+package quark
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health statuses reported by health checks.
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// HealthCheckFunc checks a component, returning an error when it is
+// unhealthy. database.DB and database.Cluster provide one as HealthCheck.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthRegistry holds the named health checks of an application, served
+// as liveness and readiness endpoints by App.Health. It is safe for
+// concurrent use.
+type HealthRegistry struct {
+	mu       sync.RWMutex
+	checks   []healthCheck
+	liveness map[string]bool
+	timeout  time.Duration
+
+	// shuttingDown fails readiness while the app drains
+	shuttingDown atomic.Bool
+}
+
+// healthCheck is a named health check.
+type healthCheck struct {
+	name  string
+	check HealthCheckFunc
+}
+
+// HealthReport is the aggregated result of health checks.
+type HealthReport struct {
+	// Status is HealthUp when all checks passed.
+	Status string `json:"status"`
+
+	// Checks holds the result of each check by name.
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+// HealthCheckResult is the result of one health check.
+type HealthCheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// NewHealthRegistry creates an empty HealthRegistry whose checks time out
+// after 5 seconds.
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		liveness: make(map[string]bool),
+		timeout:  5 * time.Second,
+	}
+}
+
+// Register adds a readiness check, run by the readiness endpoint to tell
+// whether the application can serve traffic, such as a database ping.
+// Registering an existing name replaces its check.
+//
+// Example:
+//
+//	app.HealthChecks().Register("database", db.HealthCheck)
+//	app.HealthChecks().Register("cache", func(ctx context.Context) error {
+//	    return redisClient.Ping(ctx)
+//	})
+func (r *HealthRegistry) Register(name string, check HealthCheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, hc := range r.checks {
+		if hc.name == name {
+			r.checks[i].check = check
+			delete(r.liveness, name)
+			return
+		}
+	}
+	r.checks = append(r.checks, healthCheck{name: name, check: check})
+}
+
+// RegisterLiveness adds a liveness check, run by both endpoints. Liveness
+// checks should only fail when the process must be restarted, such as a
+// deadlocked worker, never because a dependency is down.
+func (r *HealthRegistry) RegisterLiveness(name string, check HealthCheckFunc) {
+	r.Register(name, check)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness[name] = true
+}
+
+// SetTimeout sets how long each check may run before it is reported down.
+func (r *HealthRegistry) SetTimeout(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = d
+}
+
+// Live runs the liveness checks.
+func (r *HealthRegistry) Live(ctx context.Context) HealthReport {
+	return r.run(ctx, true)
+}
+
+// Ready runs all checks. Once the app starts shutting down, readiness also
+// fails with a "shutdown" check.
+func (r *HealthRegistry) Ready(ctx context.Context) HealthReport {
+	return r.run(ctx, false)
+}
+
+// run runs the checks concurrently, each with the registry timeout.
+func (r *HealthRegistry) run(ctx context.Context, livenessOnly bool) HealthReport {
+	r.mu.RLock()
+	var checks []healthCheck
+	for _, hc := range r.checks {
+		if !livenessOnly || r.liveness[hc.name] {
+			checks = append(checks, hc)
+		}
+	}
+	timeout := r.timeout
+	r.mu.RUnlock()
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, hc := range checks {
+		wg.Add(1)
+		go func(i int, hc healthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, hc.check, timeout)
+		}(i, hc)
+	}
+	wg.Wait()
+
+	report := HealthReport{
+		Status: HealthUp,
+		Checks: make(map[string]HealthCheckResult, len(checks)),
+	}
+	for i, hc := range checks {
+		report.Checks[hc.name] = results[i]
+		if results[i].Status != HealthUp {
+			report.Status = HealthDown
+		}
+	}
+	if !livenessOnly && r.shuttingDown.Load() {
+		report.Status = HealthDown
+		report.Checks["shutdown"] = HealthCheckResult{Status: HealthDown, Error: "shutting down"}
+	}
+	return report
+}
+
+// runHealthCheck runs a check with a timeout, recovering from panics.
+func runHealthCheck(ctx context.Context, check HealthCheckFunc, timeout time.Duration) (result HealthCheckResult) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result = HealthCheckResult{
+		Status:    HealthUp,
+		LatencyMs: float64(time.Since(start).Nanoseconds()) / 1e6,
+	}
+	if err != nil {
+		result.Status = HealthDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// HealthChecks returns the application's health check registry, created
+// on first use.
+func (a *App) HealthChecks() *HealthRegistry {
+	a.healthOnce.Do(func() {
+		a.health = NewHealthRegistry()
+	})
+	return a.health
+}
+
+// Health mounts the liveness and readiness endpoints of the health check
+// registry. Both respond with a JSON HealthReport, with status 200 when
+// all their checks pass and 503 otherwise:
+//
+//	{"status":"down","checks":{"database":{"status":"down","latency_ms":5000,
+//	 "error":"context deadline exceeded"}}}
+//
+// An empty path skips the endpoint.
+//
+// Example:
+//
+//	app.HealthChecks().Register("database", db.HealthCheck)
+//	app.Health("/healthz", "/readyz")
+func (a *App) Health(livePath, readyPath string) {
+	registry := a.HealthChecks()
+	if livePath != "" {
+		a.GET(livePath, func(c *Context) error {
+			return writeHealthReport(c, registry.Live(c.Context()))
+		})
+	}
+	if readyPath != "" {
+		a.GET(readyPath, func(c *Context) error {
+			return writeHealthReport(c, registry.Ready(c.Context()))
+		})
+	}
+}
+
+// writeHealthReport writes a health report with its status code.
+func writeHealthReport(c *Context, report HealthReport) error {
+	c.SetHeader("Cache-Control", "no-store")
+	code := http.StatusOK
+	if report.Status != HealthUp {
+		code = http.StatusServiceUnavailable
+	}
+	return c.JSON(code, report)
+}
@@ -0,0 +1,77 @@
+package quark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthRegistry(t *testing.T) {
+	r := NewHealthRegistry()
+	r.SetTimeout(50 * time.Millisecond)
+
+	r.RegisterLiveness("worker", func(ctx context.Context) error { return nil })
+	r.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	r.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	r.Register("panics", func(ctx context.Context) error { panic("boom") })
+
+	live := r.Live(context.Background())
+	if live.Status != HealthUp || len(live.Checks) != 1 {
+		t.Errorf("Live: expected only the passing liveness check, got %+v", live)
+	}
+
+	ready := r.Ready(context.Background())
+	if ready.Status != HealthDown || len(ready.Checks) != 4 {
+		t.Fatalf("Ready: expected all checks down overall, got %+v", ready)
+	}
+	for name, want := range map[string]string{
+		"database": "connection refused",
+		"slow":     "context deadline exceeded",
+		"panics":   "panic: boom",
+	} {
+		if got := ready.Checks[name]; got.Status != HealthDown || got.Error != want {
+			t.Errorf("Ready: %s: expected down with %q, got %+v", name, want, got)
+		}
+	}
+
+	// Re-registering replaces the check
+	r.Register("database", func(ctx context.Context) error { return nil })
+	if got := r.Ready(context.Background()).Checks["database"]; got.Status != HealthUp {
+		t.Errorf("Register: expected replaced check up, got %+v", got)
+	}
+}
+
+func TestAppHealth(t *testing.T) {
+	app := New()
+	app.Health("/healthz", "/readyz")
+
+	failing := errors.New("unavailable")
+	app.HealthChecks().Register("cache", func(ctx context.Context) error { return failing })
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /healthz: expected 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz: expected 503, got %d", rec.Code)
+	}
+
+	var report HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("GET /readyz: invalid JSON: %v", err)
+	}
+	if report.Status != HealthDown || report.Checks["cache"].Error != "unavailable" {
+		t.Errorf("GET /readyz: unexpected report %+v", report)
+	}
+}
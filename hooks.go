@@ -0,0 +1,111 @@
+package quark
+
+import (
+	"net/http"
+	"time"
+)
+
+// OnRequest registers a hook called when a request arrives, before the
+// middleware chain runs.
+//
+// Example:
+//
+//	app.OnRequest(func(c *quark.Context) {
+//	    inFlight.Inc()
+//	})
+func (a *App) OnRequest(fn func(c *Context)) {
+	a.onRequest = append(a.onRequest, fn)
+}
+
+// OnResponse registers a hook called after a request is handled, with the
+// response status and the time spent handling it. Errors are already
+// written, so the status is the one sent to the client.
+//
+// Example:
+//
+//	app.OnResponse(func(c *quark.Context, status int, elapsed time.Duration) {
+//	    if c.Method() != http.MethodGet && status < 400 {
+//	        cache.Invalidate(c.Path())
+//	    }
+//	})
+func (a *App) OnResponse(fn func(c *Context, status int, elapsed time.Duration)) {
+	a.onResponse = append(a.onResponse, fn)
+}
+
+// OnError registers a hook called when the handler chain returns an
+// error, before it is written as a response. Hooks observe errors; they
+// do not change the response.
+//
+// Example:
+//
+//	app.OnError(func(c *quark.Context, err error) {
+//	    var httpErr *quark.HTTPError
+//	    if !errors.As(err, &httpErr) {
+//	        errorTracker.Capture(err, c.Route())
+//	    }
+//	})
+func (a *App) OnError(fn func(c *Context, err error)) {
+	a.onError = append(a.onError, fn)
+}
+
+// OnRouteRegistered registers a hook called for each route added to the
+// app, including routes added before the hook.
+//
+// Example:
+//
+//	app.OnRouteRegistered(func(method, pattern string) {
+//	    audit.Printf("route %s %s", method, pattern)
+//	})
+func (a *App) OnRouteRegistered(fn func(method, pattern string)) {
+	a.router.onRegister(func(route *Route) {
+		fn(route.method, route.pattern)
+	})
+}
+
+// serveWithHooks runs handle between the request and response hooks.
+func (a *App) serveWithHooks(c *Context, handle func(c *Context)) {
+	for _, fn := range a.onRequest {
+		fn(c)
+	}
+
+	if len(a.onResponse) == 0 {
+		handle(c)
+		return
+	}
+
+	start := time.Now()
+	w := &hookWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+	c.Writer = w
+	handle(c)
+	elapsed := time.Since(start)
+
+	for _, fn := range a.onResponse {
+		fn(c, w.status, elapsed)
+	}
+}
+
+// hookWriter wraps http.ResponseWriter to capture the status code for the
+// response hooks.
+type hookWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *hookWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *hookWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *hookWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
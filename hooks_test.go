@@ -0,0 +1,67 @@
+package quark
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppRequestHooks(t *testing.T) {
+	app := New()
+	app.GET("/ok", func(c *Context) error {
+		return c.JSON(http.StatusCreated, M{"ok": true})
+	})
+	app.GET("/fail", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	var events []string
+	var status int
+	var hookErr error
+	app.OnRequest(func(c *Context) {
+		events = append(events, "request "+c.Path())
+	})
+	app.OnError(func(c *Context, err error) {
+		events = append(events, "error")
+		hookErr = err
+		if c.IsWritten() {
+			t.Error("OnError: expected hook called before the error response")
+		}
+	})
+	app.OnResponse(func(c *Context, s int, elapsed time.Duration) {
+		events = append(events, "response")
+		status = s
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if len(events) != 2 || events[0] != "request /ok" || status != http.StatusCreated {
+		t.Errorf("expected request and response hooks with 201, got %v %d", events, status)
+	}
+
+	events = nil
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if len(events) != 3 || events[1] != "error" || hookErr == nil || hookErr.Error() != "boom" {
+		t.Errorf("expected error hook, got %v %v", events, hookErr)
+	}
+	if status != http.StatusInternalServerError {
+		t.Errorf("OnResponse: expected written error status 500, got %d", status)
+	}
+}
+
+func TestAppOnRouteRegistered(t *testing.T) {
+	app := New()
+	noop := func(c *Context) error { return nil }
+	app.GET("/before", noop)
+
+	var routes []string
+	app.OnRouteRegistered(func(method, pattern string) {
+		routes = append(routes, method+" "+pattern)
+	})
+	app.Group("/api").POST("/users", noop)
+
+	if len(routes) != 2 || routes[0] != "GET /before" || routes[1] != "POST /api/users" {
+		t.Errorf("OnRouteRegistered: unexpected routes %v", routes)
+	}
+}
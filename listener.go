@@ -0,0 +1,112 @@
+package quark
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+)
+
+// RunListener starts the HTTP server on an existing listener, such as one
+// passed by systemd socket activation or created by a test harness. The
+// listener is closed when the server stops.
+//
+// Example:
+//
+//	// systemd socket activation: the first passed socket is descriptor 3
+//	l, err := net.FileListener(os.NewFile(3, "socket"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.RunListener(l)
+func (a *App) RunListener(l net.Listener) error {
+	if err := a.prepare(l.Addr().String()); err != nil {
+		return err
+	}
+
+	a.logAt(slog.LevelInfo, "starting server", "addr", l.Addr().String())
+
+	return a.server.Serve(l)
+}
+
+// RunUnix starts the HTTP server on a unix socket at path with the given
+// permissions, for serving behind a reverse proxy such as nginx on the
+// same host. A stale socket left at path is removed first, and the socket
+// is removed when the server stops.
+//
+// Example:
+//
+//	app.RunUnix("/run/myapp/http.sock", 0660)
+//
+//	// nginx: proxy_pass http://unix:/run/myapp/http.sock;
+func (a *App) RunUnix(path string, perm os.FileMode) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		l.Close()
+		return err
+	}
+	return a.RunListener(l)
+}
+
+// listenerFDEnv holds the file descriptor of a listening socket passed to
+// a new process by an upgrade.
+const listenerFDEnv = "QUARK_LISTENER_FD"
+
+// listen returns the listening socket inherited from the process that
+// started this one, or listens on addr.
+func (a *App) listen(addr string) (net.Listener, error) {
+	fd := os.Getenv(listenerFDEnv)
+	if fd == "" {
+		return net.Listen("tcp", addr)
+	}
+	os.Unsetenv(listenerFDEnv)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", listenerFDEnv, fd)
+	}
+	f := os.NewFile(uintptr(n), "listener")
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("inherited listener: %w", err)
+	}
+	a.logAt(slog.LevelInfo, "inherited listener", "addr", l.Addr().String())
+	return l, nil
+}
+
+// readyFDEnv holds the file descriptor of the pipe a new process started
+// by an upgrade reports readiness on.
+const readyFDEnv = "QUARK_READY_FD"
+
+// notifyReady tells the process that started this one by an upgrade that
+// the server is prepared and listening, so it can shut down.
+func (a *App) notifyReady() {
+	fd := os.Getenv(readyFDEnv)
+	if fd == "" {
+		return
+	}
+	os.Unsetenv(readyFDEnv)
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		a.logAt(slog.LevelError, "invalid readiness descriptor", "fd", fd)
+		return
+	}
+	f := os.NewFile(uintptr(n), "ready")
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		a.logAt(slog.LevelError, "notifying readiness failed", "error", err)
+	}
+}
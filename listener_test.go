@@ -0,0 +1,177 @@
+//go:build unix
+
+package quark
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestListenInheritsListener(t *testing.T) {
+	parent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	f, err := parent.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// listen takes ownership of the descriptor
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(listenerFDEnv, strconv.Itoa(fd))
+	app := New(WithLogger(&printfLogger{}))
+
+	l, err := app.listen("127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("listen: unexpected error: %v", err)
+	}
+	defer l.Close()
+
+	if l.Addr().String() != parent.Addr().String() {
+		t.Errorf("listen: expected inherited address %s, got %s", parent.Addr(), l.Addr())
+	}
+	if _, ok := os.LookupEnv(listenerFDEnv); ok {
+		t.Errorf("listen: expected %s unset", listenerFDEnv)
+	}
+}
+
+func TestListenInvalidListenerFD(t *testing.T) {
+	t.Setenv(listenerFDEnv, "not-a-number")
+	if _, err := New().listen("127.0.0.1:0"); err == nil {
+		t.Error("listen: expected error for invalid file descriptor")
+	}
+}
+
+func TestRunUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http.sock")
+
+	// A stale socket is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	app := New(WithLogger(&printfLogger{}))
+	app.GET("/ping", func(c *Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	errs := make(chan error, 1)
+	go func() { errs <- app.RunUnix(path, 0600) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = client.Get("http://unix/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("expected pong, got %q", body)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected socket with mode 0600, got %v, %v", info, err)
+	}
+
+	app.Shutdown(context.Background())
+	if err := <-errs; err != http.ErrServerClosed {
+		t.Errorf("RunUnix: expected ErrServerClosed, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected socket removed after shutdown")
+	}
+}
+
+// TestUpgradeHelperProcess is the new process started by the upgrade
+// tests, behaving as set by QUARK_TEST_UPGRADE.
+func TestUpgradeHelperProcess(t *testing.T) {
+	switch os.Getenv("QUARK_TEST_UPGRADE") {
+	case "ready":
+		app := New(WithLogger(&printfLogger{}))
+		l, err := app.listen("")
+		if err != nil {
+			os.Exit(2)
+		}
+		defer l.Close()
+		app.notifyReady()
+		time.Sleep(time.Second)
+		os.Exit(0)
+	case "exit":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+func TestUpgradeProcess(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := upgradeCommand
+	upgradeCommand = func() (*exec.Cmd, error) {
+		return exec.Command(exe, "-test.run=^TestUpgradeHelperProcess$"), nil
+	}
+	defer func() { upgradeCommand = command }()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	tests := []struct {
+		mode    string
+		wantErr string
+	}{
+		{"ready", ""},
+		{"exit", "exited before it was ready"},
+		{"hang", "not ready after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv("QUARK_TEST_UPGRADE", tt.mode)
+			pid, err := upgradeProcess(l, 500*time.Millisecond)
+			if tt.wantErr == "" {
+				if err != nil || pid == 0 {
+					t.Errorf("expected the new process ready, got %d %v", pid, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package quark
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocaleKey is the context store key of the locale negotiated by
+// Context.Locale, read by the t and tn functions of the template package.
+const LocaleKey = "locale"
+
+// AcceptedLanguages returns the language tags of the Accept-Language
+// header, most preferred first. Tags refused with q=0 and the "*" wildcard
+// are omitted.
+//
+// Example:
+//
+//	// Accept-Language: fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5
+//	c.AcceptedLanguages() // ["fr-CH", "fr", "en"]
+func (c *Context) AcceptedLanguages() []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var langs []language
+	for _, part := range strings.Split(c.Header("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = f
+		}
+		if quality <= 0 {
+			continue
+		}
+		langs = append(langs, language{tag, quality})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].quality > langs[j].quality })
+	tags := make([]string, len(langs))
+	for i, lang := range langs {
+		tags[i] = lang.tag
+	}
+	return tags
+}
+
+// Locale negotiates the locale of the request among the supported ones and
+// stores it under LocaleKey for later middleware, handlers and templates.
+// Accepted languages are tried in order of preference, matching a
+// supported locale exactly, then by base language, so "pt-BR" selects "pt"
+// and "en" selects "en-US". Without a match the first supported locale is
+// used.
+//
+// Without supported locales, Locale returns the locale already stored,
+// such as one picked by middleware, or the preferred accepted language.
+//
+// Example:
+//
+//	locale := c.Locale("en", "fr", "pt-BR")
+func (c *Context) Locale(supported ...string) string {
+	if len(supported) == 0 {
+		if locale, ok := c.store[LocaleKey].(string); ok && locale != "" {
+			return locale
+		}
+	}
+
+	accepted := c.AcceptedLanguages()
+	locale := matchLocale(accepted, supported)
+	if locale == "" && len(accepted) > 0 && len(supported) == 0 {
+		locale = accepted[0]
+	}
+	c.Set(LocaleKey, locale)
+	return locale
+}
+
+// matchLocale returns the supported locale best matching the accepted
+// languages, the first supported locale when none match, or "" when none
+// are supported.
+func matchLocale(accepted, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	for _, tag := range accepted {
+		tag = normalizeLocale(tag)
+		for _, locale := range supported {
+			if normalizeLocale(locale) == tag {
+				return locale
+			}
+		}
+		base := baseLanguage(tag)
+		for _, locale := range supported {
+			if baseLanguage(normalizeLocale(locale)) == base {
+				return locale
+			}
+		}
+	}
+	return supported[0]
+}
+
+// normalizeLocale lower-cases a language tag and uses "-" as separator.
+func normalizeLocale(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+}
+
+// baseLanguage returns the language subtag of a normalized tag, e.g. "pt"
+// for "pt-br".
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return base
+}
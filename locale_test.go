@@ -0,0 +1,78 @@
+package quark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestContextAcceptedLanguages(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"en", []string{"en"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []string{"fr-CH", "fr", "en"}},
+		{"en;q=0.5, de, pt-BR;q=0.7", []string{"de", "pt-BR", "en"}},
+		{"en;q=0, fr;q=bad, es ; q=0.3", []string{"es"}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", tt.header)
+		c := newContext(httptest.NewRecorder(), req, nil)
+
+		if got := c.AcceptedLanguages(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AcceptedLanguages(%q): expected %v, got %v", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		supported []string
+		want      string
+	}{
+		{"exact", "fr, en;q=0.8", []string{"en", "fr"}, "fr"},
+		{"case and separator", "PT_br", []string{"en", "pt-BR"}, "pt-BR"},
+		{"regional to base", "pt-BR", []string{"en", "pt"}, "pt"},
+		{"base to regional", "en", []string{"fr", "en-US"}, "en-US"},
+		{"preference order", "de, fr;q=0.9", []string{"fr", "de-AT"}, "de-AT"},
+		{"fallback", "ja", []string{"en", "fr"}, "en"},
+		{"no header", "", []string{"en", "fr"}, "en"},
+		{"no supported locales", "es-MX, en;q=0.5", nil, "es-MX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", tt.header)
+			c := newContext(httptest.NewRecorder(), req, nil)
+
+			if got := c.Locale(tt.supported...); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if got := c.GetString(LocaleKey); got != tt.want {
+				t.Errorf("expected %q to be stored, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContextLocaleStored(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "fr")
+	c := newContext(httptest.NewRecorder(), req, nil)
+	c.Set(LocaleKey, "de")
+
+	if got := c.Locale(); got != "de" {
+		t.Errorf("expected the stored locale, got %q", got)
+	}
+	if got := c.Locale("en", "fr"); got != "fr" {
+		t.Errorf("expected negotiation with supported locales, got %q", got)
+	}
+}
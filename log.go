@@ -0,0 +1,104 @@
+package quark
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LeveledLogger is a Logger with leveled, structured methods taking a
+// message and alternating key/value attributes, like log/slog. The
+// framework logs through these methods when the app logger implements
+// them, and through Printf otherwise.
+type LeveledLogger interface {
+	Logger
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// SlogLogger adapts a *slog.Logger to LeveledLogger. It is the default app
+// logger, writing text records to stdout at the info level, or the debug
+// level in debug mode.
+type SlogLogger struct {
+	*slog.Logger
+}
+
+// NewSlogLogger creates a SlogLogger.
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	return &SlogLogger{Logger: l}
+}
+
+// Printf logs a formatted message at the info level.
+func (l *SlogLogger) Printf(format string, v ...interface{}) {
+	l.Logger.Info(fmt.Sprintf(format, v...))
+}
+
+// Ensure SlogLogger implements LeveledLogger
+var _ LeveledLogger = (*SlogLogger)(nil)
+
+// WithSlog sets a *slog.Logger as the application logger.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+//	app := quark.New(quark.WithSlog(logger))
+func WithSlog(l *slog.Logger) Option {
+	return func(a *App) {
+		a.logger = NewSlogLogger(l)
+	}
+}
+
+// debugLevel is the slog.Leveler of the default logger, following the
+// app's debug mode.
+type debugLevel struct {
+	app *App
+}
+
+// Level implements slog.Leveler.
+func (l debugLevel) Level() slog.Level {
+	if l.app.debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// Log logs a message with alternating key/value attributes at a level,
+// through the leveled methods of the app logger or, for loggers without
+// levels, its Printf. Debug messages are dropped outside debug mode.
+//
+// Example:
+//
+//	app.Log(slog.LevelWarn, "cache unavailable, serving from the database", "error", err)
+func (a *App) Log(level slog.Level, msg string, args ...interface{}) {
+	a.logAt(level, msg, args...)
+}
+
+// logAt logs a message with attributes at a level, falling back to Printf
+// for loggers without levels.
+func (a *App) logAt(level slog.Level, msg string, args ...interface{}) {
+	if l, ok := a.logger.(LeveledLogger); ok {
+		switch level {
+		case slog.LevelDebug:
+			l.Debug(msg, args...)
+		case slog.LevelWarn:
+			l.Warn(msg, args...)
+		case slog.LevelError:
+			l.Error(msg, args...)
+		default:
+			l.Info(msg, args...)
+		}
+		return
+	}
+
+	if level == slog.LevelDebug && !a.debug {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	a.logger.Printf("%s", b.String())
+}
@@ -0,0 +1,76 @@
+package quark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithSlog(t *testing.T) {
+	var buf bytes.Buffer
+	app := New(WithSlog(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	app.OnShutdown(func(*App) error {
+		return errors.New("flush failed")
+	})
+	app.Shutdown(context.Background())
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q", buf.String())
+	}
+	if record["level"] != "ERROR" || record["msg"] != "onShutdown callback failed" || record["error"] != "flush failed" {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	buf.Reset()
+	app.Logger().Printf("hello %s", "world")
+	if !strings.Contains(buf.String(), `"msg":"hello world"`) {
+		t.Errorf("expected Printf logged as info, got %q", buf.String())
+	}
+}
+
+type printfLogger struct {
+	lines []string
+}
+
+func (l *printfLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestLogAtPrintfLogger(t *testing.T) {
+	logger := &printfLogger{}
+	app := New(WithLogger(logger))
+
+	app.logAt(slog.LevelError, "closing services failed", "error", errors.New("boom"), "service", "db")
+	app.logAt(slog.LevelDebug, "hidden")
+
+	if len(logger.lines) != 1 || logger.lines[0] != "closing services failed error=boom service=db" {
+		t.Errorf("unexpected lines %q", logger.lines)
+	}
+
+	app.debug = true
+	app.logAt(slog.LevelDebug, "shown")
+	if len(logger.lines) != 2 {
+		t.Errorf("expected debug message in debug mode, got %q", logger.lines)
+	}
+}
+
+func TestDefaultLoggerLevel(t *testing.T) {
+	app := New()
+	l := app.Logger().(*SlogLogger)
+	if l.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected debug disabled by default")
+	}
+
+	app = New(WithDebug(true))
+	l = app.Logger().(*SlogLogger)
+	if !l.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected debug enabled in debug mode")
+	}
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// BodyDumpHandler receives the captured request and response bodies of a
+// request, after the handler has run.
+type BodyDumpHandler func(c *quark.Context, reqBody, resBody []byte)
+
+// BodyDumpConfig defines the configuration for BodyDump middleware.
+type BodyDumpConfig struct {
+	// Handler receives the captured bodies. Required.
+	Handler BodyDumpHandler
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// MaxSize is the maximum number of bytes captured from each body;
+	// longer bodies are truncated in the dump but sent in full.
+	MaxSize int
+
+	// ContentTypes lists the media types whose bodies are captured, as
+	// exact types ("application/json") or prefixes ending in "/"
+	// ("text/"). Other bodies are passed to the Handler as nil.
+	ContentTypes []string
+
+	// RedactFields lists JSON and form field names whose values are
+	// replaced with "[REDACTED]", matched case-insensitively.
+	RedactFields []string
+
+	// Redact is called with each captured body and its content type after
+	// RedactFields are applied, for custom redaction.
+	Redact func(contentType string, body []byte) []byte
+}
+
+// DefaultBodyDumpConfig is the default body dump configuration.
+var DefaultBodyDumpConfig = BodyDumpConfig{
+	MaxSize: 64 << 10, // 64 KB
+	ContentTypes: []string{
+		"application/json",
+		"application/xml",
+		"application/x-www-form-urlencoded",
+		"text/",
+	},
+	RedactFields: []string{"password", "token", "secret", "api_key", "access_token", "refresh_token"},
+}
+
+// BodyDump returns a BodyDump middleware with default configuration,
+// capturing request and response bodies for debugging and audit.
+//
+// Example:
+//
+//	app.Use(middleware.BodyDump(func(c *quark.Context, req, res []byte) {
+//	    log.Printf("%s %s\n> %s\n< %s", c.Method(), c.Path(), req, res)
+//	}))
+func BodyDump(handler BodyDumpHandler) quark.MiddlewareFunc {
+	config := DefaultBodyDumpConfig
+	config.Handler = handler
+	return BodyDumpWithConfig(config)
+}
+
+// BodyDumpWithConfig returns a BodyDump middleware with the given configuration.
+func BodyDumpWithConfig(config BodyDumpConfig) quark.MiddlewareFunc {
+	if config.Handler == nil {
+		panic("body dump middleware requires a handler")
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultBodyDumpConfig.MaxSize
+	}
+	if config.ContentTypes == nil {
+		config.ContentTypes = DefaultBodyDumpConfig.ContentTypes
+	}
+
+	// Compile field patterns once
+	var fieldPatterns []*regexp.Regexp
+	for _, field := range config.RedactFields {
+		fieldPatterns = append(fieldPatterns, regexp.MustCompile(
+			`(?i)("`+regexp.QuoteMeta(field)+`"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`))
+	}
+
+	dump := func(contentType string, body []byte) []byte {
+		if body == nil {
+			return nil
+		}
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		switch {
+		case mediaType == "application/x-www-form-urlencoded" && len(config.RedactFields) > 0:
+			body = redactForm(body, config.RedactFields)
+		case strings.HasSuffix(mediaType, "json"):
+			for _, pattern := range fieldPatterns {
+				body = pattern.ReplaceAll(body, []byte(`${1}"[REDACTED]"`))
+			}
+		}
+		if config.Redact != nil {
+			body = config.Redact(contentType, body)
+		}
+		return body
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			// Capture the start of the request body and put it back
+			reqType := c.Request.Header.Get("Content-Type")
+			var reqBody []byte
+			if c.Request.Body != nil && c.Request.Body != http.NoBody && matchContentType(reqType, config.ContentTypes) {
+				captured, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(config.MaxSize)))
+				if err != nil {
+					return err
+				}
+				reqBody = captured
+				c.Request.Body = &replayBody{
+					Reader: io.MultiReader(bytes.NewReader(captured), c.Request.Body),
+					Closer: c.Request.Body,
+				}
+			}
+
+			// Capture the start of the response body
+			dw := &bodyDumpWriter{ResponseWriter: c.Writer, max: config.MaxSize}
+			c.Writer = dw
+
+			err := next(c)
+
+			resType := dw.Header().Get("Content-Type")
+			var resBody []byte
+			if matchContentType(resType, config.ContentTypes) {
+				resBody = dw.buf.Bytes()
+			}
+
+			config.Handler(c, dump(reqType, reqBody), dump(resType, resBody))
+			return err
+		}
+	}
+}
+
+// matchContentType reports whether a Content-Type matches one of the
+// configured media types or prefixes.
+func matchContentType(contentType string, types []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactForm replaces the values of fields in a URL-encoded form body.
+func redactForm(body []byte, fields []string) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+	redacted := false
+	for key, vals := range values {
+		for _, field := range fields {
+			if strings.EqualFold(key, field) {
+				for i := range vals {
+					vals[i] = "[REDACTED]"
+				}
+				redacted = true
+			}
+		}
+	}
+	if !redacted {
+		return body
+	}
+	return []byte(values.Encode())
+}
+
+// replayBody replays captured request bytes before the rest of the body.
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyDumpWriter wraps http.ResponseWriter to capture the start of the
+// response body.
+type bodyDumpWriter struct {
+	http.ResponseWriter
+	buf bytes.Buffer
+	max int
+}
+
+func (w *bodyDumpWriter) Write(b []byte) (int, error) {
+	if room := w.max - w.buf.Len(); room > 0 {
+		if len(b) < room {
+			room = len(b)
+		}
+		w.buf.Write(b[:room])
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+func TestBodyDump(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      BodyDumpConfig
+		contentType string
+		body        string
+		wantReq     string
+		wantRes     string
+	}{
+		{
+			name:        "json redacted",
+			contentType: "application/json",
+			body:        `{"user":"ada","password":"hunter2","token":42}`,
+			wantReq:     `{"user":"ada","password":"[REDACTED]","token":"[REDACTED]"}`,
+			wantRes:     `echo`,
+		},
+		{
+			name:        "form redacted",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "user=ada&password=hunter2",
+			wantReq:     "password=%5BREDACTED%5D&user=ada",
+			wantRes:     `echo`,
+		},
+		{
+			name:        "truncated",
+			config:      BodyDumpConfig{MaxSize: 4},
+			contentType: "text/plain",
+			body:        "abcdefgh",
+			wantReq:     "abcd",
+			wantRes:     "echo",
+		},
+		{
+			name:        "type not captured",
+			contentType: "application/octet-stream",
+			body:        "\x00\x01",
+			wantReq:     "",
+			wantRes:     "echo",
+		},
+		{
+			name:        "custom redact",
+			config:      BodyDumpConfig{Redact: func(_ string, b []byte) []byte { return []byte(strings.ToUpper(string(b))) }},
+			contentType: "text/plain",
+			body:        "hello",
+			wantReq:     "HELLO",
+			wantRes:     "ECHO",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReq, gotRes []byte
+			var handlerBody string
+
+			config := DefaultBodyDumpConfig
+			if tt.config.MaxSize != 0 {
+				config.MaxSize = tt.config.MaxSize
+			}
+			config.Redact = tt.config.Redact
+			config.Handler = func(c *quark.Context, req, res []byte) {
+				gotReq, gotRes = req, res
+			}
+
+			app := quark.New()
+			app.Use(BodyDumpWithConfig(config))
+			app.POST("/echo", func(c *quark.Context) error {
+				b, _ := io.ReadAll(c.Request.Body)
+				handlerBody = string(b)
+				return c.String(http.StatusOK, "echo")
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			if handlerBody != tt.body {
+				t.Errorf("expected the handler to read %q, got %q", tt.body, handlerBody)
+			}
+			if string(gotReq) != tt.wantReq {
+				t.Errorf("expected request dump %q, got %q", tt.wantReq, gotReq)
+			}
+			if string(gotRes) != tt.wantRes {
+				t.Errorf("expected response dump %q, got %q", tt.wantRes, gotRes)
+			}
+			if rec.Body.String() != "echo" {
+				t.Errorf("expected the full response, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBodyDumpRequiresHandler(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic without a handler")
+		}
+	}()
+	BodyDumpWithConfig(BodyDumpConfig{})
+}
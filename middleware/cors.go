@@ -5,6 +5,8 @@
 //   - Logger: Request/response logging
 //   - Recovery: Panic recovery with stack traces
 //   - Auth: Token-based authentication
+//   - BodyDump: Request/response body capture for debugging and audit
+//   - Trace: W3C trace context propagation with span hooks
 //
 // Example usage:
 //
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// DecompressConfig defines the configuration for Decompress middleware.
+type DecompressConfig struct {
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// MaxSize is the maximum size of a decompressed body in bytes. Reading
+	// past it fails with an *http.MaxBytesError, which Bind reports as 413
+	// Request Entity Too Large, so small compressed bodies cannot expand
+	// into gigabytes.
+	MaxSize int64
+}
+
+// DefaultDecompressConfig is the default decompress configuration.
+var DefaultDecompressConfig = DecompressConfig{
+	MaxSize: 10 << 20, // 10 MB
+}
+
+// Decompress returns a Decompress middleware with default configuration.
+//
+// Example:
+//
+//	app.Use(middleware.Decompress())
+//
+//	// curl -H 'Content-Encoding: gzip' --data-binary @events.json.gz ...
+//	app.POST("/events", func(c *quark.Context) error {
+//	    var events []Event
+//	    if err := c.Bind(&events); err != nil { // Reads the decompressed JSON
+//	        return err
+//	    }
+//	    return c.NoContent()
+//	})
+func Decompress() quark.MiddlewareFunc {
+	return DecompressWithConfig(DefaultDecompressConfig)
+}
+
+// DecompressWithConfig returns a middleware that transparently decompresses
+// request bodies sent with Content-Encoding gzip or deflate, so handlers and
+// Bind read the original content. Bodies are decompressed as they are read,
+// up to MaxSize bytes. Other encodings are rejected with 415 Unsupported
+// Media Type, and corrupt compressed data with 400 Bad Request.
+func DecompressWithConfig(config DecompressConfig) quark.MiddlewareFunc {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultDecompressConfig.MaxSize
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			encoding := c.Request.Header.Get("Content-Encoding")
+			if encoding == "" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+				return next(c)
+			}
+
+			// Encodings are listed in the order they were applied
+			body := c.Request.Body
+			codings := strings.Split(encoding, ",")
+			for i := len(codings) - 1; i >= 0; i-- {
+				var err error
+				switch coding := strings.ToLower(strings.TrimSpace(codings[i])); coding {
+				case "identity", "":
+					continue
+				case "gzip", "x-gzip":
+					body, err = gzip.NewReader(body)
+				case "deflate":
+					body, err = deflateReader(body)
+				default:
+					c.SetHeader("Accept-Encoding", "gzip, deflate")
+					return quark.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content encoding: "+coding)
+				}
+				if err != nil {
+					return quark.WrapError(http.StatusBadRequest, "invalid compressed body", err)
+				}
+			}
+
+			c.Request.Body = http.MaxBytesReader(c.Writer, &decompressBody{ReadCloser: body, raw: c.Request.Body}, config.MaxSize)
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Del("Content-Length")
+			c.Request.ContentLength = -1
+			return next(c)
+		}
+	}
+}
+
+// deflateReader returns a reader for "deflate" content, which is zlib
+// data, also accepting the raw deflate data some clients send instead.
+func deflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header announces deflate compression and is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decompressBody closes the decompressor and the original body.
+type decompressBody struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (b *decompressBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+	return err
+}
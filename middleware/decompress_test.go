@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// compress returns data compressed by a writer from newWriter.
+func compress(t *testing.T, data string, newWriter func(io.Writer) io.WriteCloser) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := io.WriteString(w, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	const payload = `{"event":"signup","user":"ada"}`
+	gzipped := compress(t, payload, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	zlibbed := compress(t, payload, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+	deflated := compress(t, payload, func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	})
+	large := `{"padding":"` + strings.Repeat("x", 4096) + `"}`
+
+	app := quark.New()
+	app.Use(DecompressWithConfig(DecompressConfig{MaxSize: 1024}))
+	app.POST("/events", func(c *quark.Context) error {
+		var event map[string]interface{}
+		if err := c.Bind(&event); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, event)
+	})
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+	}{
+		{"uncompressed", "", []byte(payload), http.StatusOK},
+		{"gzip", "gzip", gzipped, http.StatusOK},
+		{"x-gzip", "x-gzip", gzipped, http.StatusOK},
+		{"deflate zlib", "deflate", zlibbed, http.StatusOK},
+		{"deflate raw", "deflate", deflated, http.StatusOK},
+		{"identity then gzip", "identity, gzip", gzipped, http.StatusOK},
+		{"unsupported encoding", "br", []byte(payload), http.StatusUnsupportedMediaType},
+		{"corrupt gzip", "gzip", []byte("not gzip data"), http.StatusBadRequest},
+		{"over MaxSize", "gzip", compress(t, large, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), `"user":"ada"`) {
+				t.Errorf("expected the decompressed body, got %s", rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnsupportedMediaType && rec.Header().Get("Accept-Encoding") != "gzip, deflate" {
+				t.Errorf("expected Accept-Encoding gzip, deflate, got %q", rec.Header().Get("Accept-Encoding"))
+			}
+		})
+	}
+}
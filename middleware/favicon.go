@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Favicon returns a middleware answering GET and HEAD /favicon.ico with
+// data from memory, before routing. Browsers request the icon on every
+// page, so registering Favicon before Logger also keeps it out of the
+// request log. The content type is detected from data, and the icon is
+// cached by clients for a day and revalidated with its ETag.
+//
+// Example:
+//
+//	//go:embed favicon.ico
+//	var favicon []byte
+//
+//	app.Use(middleware.Favicon(favicon))
+//	app.Use(middleware.Logger())
+func Favicon(data []byte) quark.MiddlewareFunc {
+	return serveFromMemory("/favicon.ico", http.DetectContentType(data), data)
+}
+
+// RobotsTxt returns a middleware answering GET and HEAD /robots.txt with
+// content from memory, before routing, like Favicon.
+//
+// Example:
+//
+//	app.Use(middleware.RobotsTxt("User-agent: *\nDisallow: /admin/\n"))
+func RobotsTxt(content string) quark.MiddlewareFunc {
+	return serveFromMemory("/robots.txt", "text/plain; charset=utf-8", []byte(content))
+}
+
+// serveFromMemory returns a middleware serving data at path.
+func serveFromMemory(path, contentType string, data []byte) quark.MiddlewareFunc {
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if c.Path() != path || c.Method() != http.MethodGet && c.Method() != http.MethodHead {
+				return next(c)
+			}
+
+			c.SetHeader("Content-Type", contentType)
+			c.SetHeader("Cache-Control", "public, max-age=86400")
+			c.SetHeader("ETag", etag)
+			http.ServeContent(c.Writer, c.Request, path, time.Time{}, bytes.NewReader(data))
+			return nil
+		}
+	}
+}
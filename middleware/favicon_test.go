@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// pngIcon is the start of a PNG file, enough for content type detection.
+var pngIcon = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestFavicon(t *testing.T) {
+	app := quark.New()
+	app.Use(Favicon(pngIcon))
+	app.Use(RobotsTxt("User-agent: *\nDisallow: /admin/\n"))
+	app.GET("/", func(c *quark.Context) error {
+		return c.String(http.StatusOK, "home")
+	})
+	app.POST("/favicon.ico", func(c *quark.Context) error {
+		return c.String(http.StatusOK, "route")
+	})
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		wantStatus      int
+		wantBody        string
+		wantContentType string
+	}{
+		{"favicon", http.MethodGet, "/favicon.ico", http.StatusOK, string(pngIcon), "image/png"},
+		{"favicon HEAD", http.MethodHead, "/favicon.ico", http.StatusOK, "", "image/png"},
+		{"robots", http.MethodGet, "/robots.txt", http.StatusOK, "User-agent: *\nDisallow: /admin/\n", "text/plain; charset=utf-8"},
+		{"other methods routed", http.MethodPost, "/favicon.ico", http.StatusOK, "route", "text/plain; charset=utf-8"},
+		{"other paths routed", http.MethodGet, "/", http.StatusOK, "home", "text/plain; charset=utf-8"},
+		{"nested path routed", http.MethodGet, "/static/favicon.ico", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.wantContentType {
+				t.Errorf("expected content type %q, got %q", tt.wantContentType, ct)
+			}
+		})
+	}
+}
+
+func TestFaviconRevalidation(t *testing.T) {
+	app := quark.New()
+	app.Use(Favicon(pngIcon))
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=86400" {
+		t.Errorf("expected a one day Cache-Control, got %q", cc)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 without a body, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A different icon has a different ETag
+	other := httptest.NewRecorder()
+	otherApp := quark.New()
+	otherApp.Use(Favicon([]byte("GIF89a")))
+	otherApp.ServeHTTP(other, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if other.Header().Get("ETag") == etag {
+		t.Error("expected the ETag to depend on the icon")
+	}
+}
+
+func TestFaviconBeforeLogger(t *testing.T) {
+	var out bytes.Buffer
+	app := quark.New()
+	app.Use(Favicon(pngIcon))
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &out, Format: "${path}"}))
+	app.GET("/", func(c *quark.Context) error {
+		return c.NoContent()
+	})
+
+	for _, path := range []string{"/favicon.ico", "/"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if out.String() != "/\n" {
+		t.Errorf("expected only the page logged, got %q", out.String())
+	}
+}
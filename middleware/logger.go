@@ -1,22 +1,38 @@
 package middleware
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AchrafSoltani/quark"
 )
 
 // LoggerConfig defines the configuration for Logger middleware.
+//
+// Logger middleware can be configured per route group. Instances writing
+// to os.Stdout, os.Stderr or the same SyncWriter serialize their writes,
+// so lines never interleave:
+//
+//	api := app.Group("/api", middleware.LoggerWithConfig(middleware.LoggerConfig{JSON: true}))
+//	admin := app.Group("/admin", middleware.LoggerWithConfig(middleware.LoggerConfig{
+//	    Format: "${time} | ${status} | ${method} ${path} | ${user_agent}",
+//	}))
+//	health := app.Group("/health") // not logged
 type LoggerConfig struct {
 	// Output is the writer where logs are written.
 	Output io.Writer
 
 	// Format is the log format template.
-	// Available fields: ${time}, ${method}, ${path}, ${status}, ${latency}, ${ip}, ${user_agent}
+	// Available fields: ${time}, ${method}, ${path}, ${status}, ${latency}, ${ip}, ${user_agent},
+	// ${trace_id}, ${span_id}
 	Format string
 
 	// TimeFormat is the time format (time.Layout).
@@ -27,6 +43,41 @@ type LoggerConfig struct {
 
 	// CustomTimeFormat allows custom time formatting.
 	CustomTimeFormat func(time.Time) string
+
+	// JSON writes one JSON object per request instead of the colored text
+	// Format, for log aggregation systems:
+	//
+	//	{"time":"2024-01-02T15:04:05.123Z","level":"info","method":"GET","path":"/users",
+	//	 "status":200,"latency_ms":1.25,"ip":"10.0.0.1","user_agent":"curl/8.0","request_id":"abc"}
+	//
+	// The level follows the status (error for 5xx, warn for 4xx, info
+	// otherwise). The time uses RFC 3339 unless CustomTimeFormat is set, and request_id
+	// comes from the "request_id" context value or the X-Request-ID header.
+	// trace_id and span_id, set by Trace middleware, follow request_id.
+	// These three are omitted when empty.
+	JSON bool
+
+	// Fields adds custom fields to request logs, such as the user ID from
+	// JWT claims, the tenant or the trace ID. In JSON mode they follow the
+	// standard fields in key order; in text mode they replace ${name} tags
+	// in Format, and unused ones are appended as name=value.
+	//
+	// Example:
+	//
+	//	Fields: map[string]func(*quark.Context) string{
+	//	    "user_id": func(c *quark.Context) string { return c.GetString("user_id") },
+	//	    "tenant":  func(c *quark.Context) string { return c.Header("X-Tenant") },
+	//	}
+	Fields map[string]func(*quark.Context) string
+
+	// SampleSuccess logs only 1 in SampleSuccess requests answered below
+	// 400, to cut the volume of high-traffic endpoints; errors (4xx and 5xx)
+	// are always logged. 0 or 1 logs every request.
+	SampleSuccess int
+
+	// SkipStatus skips logging responses whose status it returns true for,
+	// such as status < 400 for an errors-only log.
+	SkipStatus func(status int) bool
 }
 
 // DefaultLoggerConfig is the default logger configuration.
@@ -59,6 +110,17 @@ func LoggerWithConfig(config LoggerConfig) quark.MiddlewareFunc {
 	if config.TimeFormat == "" {
 		config.TimeFormat = DefaultLoggerConfig.TimeFormat
 	}
+	config.Output = loggerOutput(config.Output)
+
+	// Sort custom fields for a stable output
+	fieldNames := make([]string, 0, len(config.Fields))
+	for name := range config.Fields {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	// Count successful requests for sampling
+	var successes atomic.Uint64
 
 	// Build skip paths map
 	skipPaths := make(map[string]bool)
@@ -100,6 +162,38 @@ func LoggerWithConfig(config LoggerConfig) quark.MiddlewareFunc {
 				}
 			}
 
+			if config.SkipStatus != nil && config.SkipStatus(status) {
+				return err
+			}
+			if status < 400 && config.SampleSuccess > 1 && (successes.Add(1)-1)%uint64(config.SampleSuccess) != 0 {
+				return err
+			}
+
+			if config.JSON {
+				timeStr := start.UTC().Format(time.RFC3339Nano)
+				if config.CustomTimeFormat != nil {
+					timeStr = config.CustomTimeFormat(start)
+				}
+				fields := []logField{
+					{"time", timeStr},
+					{"level", statusLevel(status)},
+					{"method", c.Method()},
+					{"path", c.Path()},
+					{"status", status},
+					{"latency_ms", float64(latency.Nanoseconds()) / 1e6},
+					{"ip", c.RealIP()},
+					{"user_agent", c.Header("User-Agent")},
+					{"request_id", requestID(c)},
+					{"trace_id", c.TraceID()},
+					{"span_id", c.SpanID()},
+				}
+				for _, name := range fieldNames {
+					fields = append(fields, logField{name, config.Fields[name](c)})
+				}
+				config.Output.Write(jsonLogLine(fields))
+				return err
+			}
+
 			// Format time
 			var timeStr string
 			if config.CustomTimeFormat != nil {
@@ -120,6 +214,17 @@ func LoggerWithConfig(config LoggerConfig) quark.MiddlewareFunc {
 			log = replaceTag(log, "${latency}", latencyStr)
 			log = replaceTag(log, "${ip}", c.RealIP())
 			log = replaceTag(log, "${user_agent}", c.Header("User-Agent"))
+			log = replaceTag(log, "${trace_id}", c.TraceID())
+			log = replaceTag(log, "${span_id}", c.SpanID())
+			for _, name := range fieldNames {
+				value := config.Fields[name](c)
+				tag := "${" + name + "}"
+				if strings.Contains(log, tag) {
+					log = replaceTag(log, tag, value)
+				} else {
+					log += " " + name + "=" + value
+				}
+			}
 
 			// Add status color codes for terminal output
 			log = colorizeStatus(log, status)
@@ -131,6 +236,113 @@ func LoggerWithConfig(config LoggerConfig) quark.MiddlewareFunc {
 	}
 }
 
+// SyncWriter returns a writer serializing writes to w. Loggers writing to
+// os.Stdout or os.Stderr already share a lock; give loggers sharing another
+// output the same SyncWriter so their lines never interleave:
+//
+//	out := middleware.SyncWriter(logFile)
+//	api := app.Group("/api", middleware.LoggerWithConfig(middleware.LoggerConfig{Output: out, JSON: true}))
+//	admin := app.Group("/admin", middleware.LoggerWithConfig(middleware.LoggerConfig{Output: out}))
+func SyncWriter(w io.Writer) io.Writer {
+	if sw, ok := w.(*syncWriter); ok {
+		return sw
+	}
+	return &syncWriter{w: w}
+}
+
+// syncWriter serializes writes to a writer.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(b)
+}
+
+// Writers of the standard outputs, shared by every logger
+var (
+	stdoutWriter = &syncWriter{w: os.Stdout}
+	stderrWriter = &syncWriter{w: os.Stderr}
+)
+
+// loggerOutput returns the serialized writer of a logger output: the
+// shared one of os.Stdout and os.Stderr, w itself when it is a SyncWriter,
+// or a writer locked per logger otherwise.
+func loggerOutput(w io.Writer) io.Writer {
+	switch w {
+	case os.Stdout:
+		return stdoutWriter
+	case os.Stderr:
+		return stderrWriter
+	}
+	return SyncWriter(w)
+}
+
+// logField is a field of a JSON log line.
+type logField struct {
+	key   string
+	value interface{}
+}
+
+// jsonLogLine encodes fields as a newline-terminated JSON object, keeping
+// their order and omitting an empty request_id, trace_id or span_id.
+func jsonLogLine(fields []logField) []byte {
+	buf := []byte{'{'}
+	for _, f := range fields {
+		if s, ok := f.value.(string); ok && s == "" && optionalLogFields[f.key] {
+			continue
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(f.value))
+		}
+		if len(buf) > 1 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(f.key)
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}', '\n')
+}
+
+// optionalLogFields are the JSON log fields omitted when empty.
+var optionalLogFields = map[string]bool{
+	"request_id": true,
+	"trace_id":   true,
+	"span_id":    true,
+}
+
+// statusLevel returns the log level of a response status: error for 5xx,
+// warn for 4xx and info otherwise.
+func statusLevel(status int) string {
+	switch {
+	case status >= 500:
+		return "error"
+	case status >= 400:
+		return "warn"
+	default:
+		return "info"
+	}
+}
+
+// requestID returns the request ID set by request ID middleware, from the
+// "request_id" context value or the X-Request-ID request or response
+// header.
+func requestID(c *quark.Context) string {
+	if id := c.GetString("request_id"); id != "" {
+		return id
+	}
+	if id := c.Header("X-Request-ID"); id != "" {
+		return id
+	}
+	return c.Writer.Header().Get("X-Request-ID")
+}
+
 // statusWriter wraps http.ResponseWriter to capture the status code.
 type statusWriter struct {
 	http.ResponseWriter
@@ -0,0 +1,354 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// byteWriter writes one byte at a time, yielding in between, so that
+// unserialized concurrent writes interleave.
+type byteWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *byteWriter) Write(b []byte) (int, error) {
+	for _, ch := range b {
+		w.buf.WriteByte(ch)
+		runtime.Gosched()
+	}
+	return len(b), nil
+}
+
+func TestLoggerSharedOutput(t *testing.T) {
+	out := &byteWriter{}
+	shared := SyncWriter(out)
+	if SyncWriter(shared) != shared {
+		t.Error("expected SyncWriter of a SyncWriter to return it")
+	}
+
+	app := quark.New()
+	api := app.Group("/api", LoggerWithConfig(LoggerConfig{Output: shared, JSON: true}))
+	api.GET("/users", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+	admin := app.Group("/admin", LoggerWithConfig(LoggerConfig{Output: shared, Format: "text ${method} ${path} ${status}"}))
+	admin.GET("/stats", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+
+	const perLogger = 50
+	var wg sync.WaitGroup
+	for i := 0; i < perLogger; i++ {
+		for _, path := range []string{"/api/users", "/admin/stats"} {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+			}(path)
+		}
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.buf.String(), "\n"), "\n")
+	if len(lines) != 2*perLogger {
+		t.Fatalf("expected %d lines, got %d", 2*perLogger, len(lines))
+	}
+	var jsonLines, textLines int
+	for _, line := range lines {
+		var entry map[string]interface{}
+		switch {
+		case json.Unmarshal([]byte(line), &entry) == nil && entry["path"] == "/api/users":
+			jsonLines++
+		case line == "text GET /admin/stats "+green+"200"+reset:
+			textLines++
+		default:
+			t.Errorf("interleaved line %q", line)
+		}
+	}
+	if jsonLines != perLogger || textLines != perLogger {
+		t.Errorf("expected %d lines of each logger, got %d JSON and %d text", perLogger, jsonLines, textLines)
+	}
+}
+
+func TestLoggerOutput(t *testing.T) {
+	if loggerOutput(os.Stdout) != loggerOutput(os.Stdout) || loggerOutput(os.Stderr) != stderrWriter {
+		t.Error("expected loggers of the standard outputs to share their lock")
+	}
+	w := &byteWriter{}
+	if loggerOutput(w) == loggerOutput(w) {
+		t.Error("expected other outputs to be locked per logger")
+	}
+
+	// Outputs that are not comparable must not panic
+	var funcWriter writerFunc = func(b []byte) (int, error) { return len(b), nil }
+	fmt.Fprint(loggerOutput(funcWriter), "ok")
+}
+
+// writerFunc is an io.Writer of a function, a type that is not comparable.
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) { return f(b) }
+
+// decodeLogLines decodes newline-separated JSON log lines.
+func decodeLogLines(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLoggerJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   quark.HandlerFunc
+		header    map[string]string
+		wantLevel string
+		want      map[string]interface{}
+		absent    []string
+	}{
+		{
+			name:      "success",
+			handler:   func(c *quark.Context) error { return c.String(http.StatusOK, "ok") },
+			header:    map[string]string{"User-Agent": "curl/8.0", "X-Request-ID": "req-1"},
+			wantLevel: "info",
+			want:      map[string]interface{}{"status": float64(200), "user_agent": "curl/8.0", "request_id": "req-1"},
+			absent:    []string{"trace_id", "span_id"},
+		},
+		{
+			name:      "client error",
+			handler:   func(c *quark.Context) error { return quark.ErrNotFound("missing") },
+			wantLevel: "warn",
+			want:      map[string]interface{}{"status": float64(404)},
+			absent:    []string{"request_id"},
+		},
+		{
+			name:      "server error",
+			handler:   func(c *quark.Context) error { return fmt.Errorf("boom") },
+			wantLevel: "error",
+			want:      map[string]interface{}{"status": float64(500)},
+		},
+		{
+			name: "request ID context value",
+			handler: func(c *quark.Context) error {
+				c.Set("request_id", "ctx-1")
+				return c.String(http.StatusCreated, "ok")
+			},
+			wantLevel: "info",
+			want:      map[string]interface{}{"status": float64(201), "request_id": "ctx-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			app := quark.New()
+			app.Use(LoggerWithConfig(LoggerConfig{Output: &out, JSON: true}))
+			app.GET("/users", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			app.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := decodeLogLines(t, out.String())
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 log line, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry["level"] != tt.wantLevel || entry["method"] != "GET" || entry["path"] != "/users" || entry["ip"] != "10.0.0.1" {
+				t.Errorf("unexpected standard fields %v", entry)
+			}
+			for key, value := range tt.want {
+				if entry[key] != value {
+					t.Errorf("expected %s = %v, got %v", key, value, entry[key])
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := entry[key]; ok {
+					t.Errorf("expected no %s, got %v", key, entry[key])
+				}
+			}
+			if _, ok := entry["latency_ms"].(float64); !ok {
+				t.Errorf("expected a numeric latency_ms, got %v", entry["latency_ms"])
+			}
+			if _, ok := entry["time"].(string); !ok {
+				t.Errorf("expected a time, got %v", entry["time"])
+			}
+		})
+	}
+}
+
+func TestLoggerJSONFieldOrder(t *testing.T) {
+	line := jsonLogLine([]logField{
+		{"time", "2026-10-16T09:30:00Z"},
+		{"level", "info"},
+		{"status", 200},
+		{"request_id", ""},
+		{"trace_id", "abc"},
+		{"user", ""},
+		{"ratio", math.Inf(1)},
+	})
+	want := `{"time":"2026-10-16T09:30:00Z","level":"info","status":200,"trace_id":"abc","user":"","ratio":"+Inf"}` + "\n"
+	if string(line) != want {
+		t.Errorf("expected %q, got %q", want, line)
+	}
+}
+
+func TestLoggerJSONTrace(t *testing.T) {
+	var out bytes.Buffer
+	app := quark.New()
+	app.Use(Trace(), LoggerWithConfig(LoggerConfig{Output: &out, JSON: true, CustomTimeFormat: func(time.Time) string { return "now" }}))
+	app.GET("/", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entries := decodeLogLines(t, out.String())
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log line, got %d", len(entries))
+	}
+	if entries[0]["time"] != "now" {
+		t.Errorf("expected the custom time format, got %v", entries[0]["time"])
+	}
+	if id, _ := entries[0]["trace_id"].(string); len(id) != 32 {
+		t.Errorf("expected a trace_id, got %v", entries[0]["trace_id"])
+	}
+	if id, _ := entries[0]["span_id"].(string); len(id) != 16 {
+		t.Errorf("expected a span_id, got %v", entries[0]["span_id"])
+	}
+}
+
+func TestLoggerFields(t *testing.T) {
+	fields := map[string]func(*quark.Context) string{
+		"user_id": func(c *quark.Context) string { return c.GetString("user_id") },
+		"tenant":  func(c *quark.Context) string { return c.Header("X-Tenant") },
+	}
+	handler := func(c *quark.Context) error {
+		c.Set("user_id", "42")
+		return c.String(http.StatusOK, "ok")
+	}
+
+	tests := []struct {
+		name   string
+		config LoggerConfig
+		want   string
+	}{
+		{
+			name:   "JSON fields in key order",
+			config: LoggerConfig{JSON: true, CustomTimeFormat: func(time.Time) string { return "now" }},
+			want:   `{"time":"now","level":"info","method":"GET","path":"/","status":200,`,
+		},
+		{
+			name:   "text tags",
+			config: LoggerConfig{Format: "${method} ${path} user=${user_id} tenant=${tenant}"},
+			want:   "GET / user=42 tenant=acme\n",
+		},
+		{
+			name:   "text appended",
+			config: LoggerConfig{Format: "${method} ${path}"},
+			want:   "GET / tenant=acme user_id=42\n",
+		},
+		{
+			name:   "text partly used",
+			config: LoggerConfig{Format: "${method} [${tenant}]"},
+			want:   "GET [acme] user_id=42\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			config := tt.config
+			config.Output = &out
+			config.Fields = fields
+
+			app := quark.New()
+			app.Use(LoggerWithConfig(config))
+			app.GET("/", handler)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Tenant", "acme")
+			app.ServeHTTP(httptest.NewRecorder(), req)
+
+			got := out.String()
+			if !config.JSON {
+				if got != tt.want {
+					t.Errorf("expected %q, got %q", tt.want, got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.want) || !strings.HasSuffix(got, `,"tenant":"acme","user_id":"42"}`+"\n") {
+				t.Errorf("expected the custom fields after the standard ones, got %q", got)
+			}
+		})
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LoggerConfig
+		want   map[string]int // Lines per path
+	}{
+		{"every request", LoggerConfig{}, map[string]int{"/ok": 10, "/missing": 10, "/fail": 10}},
+		{"sample disabled by 1", LoggerConfig{SampleSuccess: 1}, map[string]int{"/ok": 10, "/missing": 10, "/fail": 10}},
+		{"1 in 5 successes", LoggerConfig{SampleSuccess: 5}, map[string]int{"/ok": 2, "/missing": 10, "/fail": 10}},
+		{"errors only", LoggerConfig{SkipStatus: func(status int) bool { return status < 400 }}, map[string]int{"/missing": 10, "/fail": 10}},
+		{"skip server errors", LoggerConfig{SkipStatus: func(status int) bool { return status >= 500 }}, map[string]int{"/ok": 10, "/missing": 10}},
+		{"skip paths", LoggerConfig{SkipPaths: []string{"/ok"}}, map[string]int{"/missing": 10, "/fail": 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			config := tt.config
+			config.Output = &out
+			config.JSON = true
+
+			app := quark.New()
+			app.Use(LoggerWithConfig(config))
+			app.GET("/ok", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+			app.GET("/missing", func(c *quark.Context) error { return quark.ErrNotFound("missing") })
+			app.GET("/fail", func(c *quark.Context) error { return fmt.Errorf("boom") })
+
+			for i := 0; i < 10; i++ {
+				for _, path := range []string{"/ok", "/missing", "/fail"} {
+					app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+				}
+			}
+
+			got := make(map[string]int)
+			for _, entry := range decodeLogLines(t, out.String()) {
+				got[entry["path"].(string)]++
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected lines %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoggerSamplingFirstLogged(t *testing.T) {
+	var out bytes.Buffer
+	app := quark.New()
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &out, SampleSuccess: 100, Format: "${path}"}))
+	app.GET("/ok", func(c *quark.Context) error { return c.String(http.StatusOK, "ok") })
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if out.String() != "/ok\n" {
+		t.Errorf("expected the first success to be logged, got %q", out.String())
+	}
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// Span describes the server span of a request. Hooks may read it and, in
+// StartSpan, adjust Name or Sampled.
+type Span struct {
+	// TraceID is the 32-character hex trace ID, shared by all spans of a trace.
+	TraceID string
+
+	// SpanID is the 16-character hex ID of this span.
+	SpanID string
+
+	// ParentSpanID is the span ID of the caller, empty for a new trace.
+	ParentSpanID string
+
+	// Sampled reports whether the trace is recorded.
+	Sampled bool
+
+	// TraceState is the vendor-specific tracestate header, propagated as is.
+	TraceState string
+
+	// Name is the span name, "GET /users/{id}" once the route is matched.
+	Name string
+
+	// Start and End bound the request; End is set before EndSpan.
+	Start time.Time
+	End   time.Time
+
+	// Status is the response status code, set before EndSpan.
+	Status int
+}
+
+// SpanHook receives the server span of each request, to export it to a
+// tracing system such as OpenTelemetry without Quark depending on it.
+type SpanHook interface {
+	// StartSpan is called before the request is handled.
+	StartSpan(c *quark.Context, span *Span)
+
+	// EndSpan is called after the request is handled, with the handler error.
+	EndSpan(c *quark.Context, span *Span, err error)
+}
+
+// TraceConfig defines the configuration for Trace middleware.
+type TraceConfig struct {
+	// Hooks receive the span of each request.
+	Hooks []SpanHook
+
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// IgnoreIncoming starts a new trace for every request instead of
+	// continuing the caller's, for services exposed to untrusted clients.
+	IgnoreIncoming bool
+
+	// Sampler decides whether a new trace is sampled (default: always).
+	// Continued traces keep the caller's decision.
+	Sampler func(*quark.Context) bool
+
+	// ResponseHeader sets the traceparent response header, so clients can
+	// report the trace ID of failed requests.
+	ResponseHeader bool
+}
+
+// DefaultTraceConfig is the default trace configuration.
+var DefaultTraceConfig = TraceConfig{}
+
+// spanKey is the context store key of the request span.
+const spanKey = "trace_span"
+
+// Trace returns a Trace middleware with default configuration.
+//
+// It continues the trace of the W3C traceparent request header, or starts
+// a new one, and makes the IDs available as c.TraceID() and c.SpanID()
+// and in Logger output.
+func Trace() quark.MiddlewareFunc {
+	return TraceWithConfig(DefaultTraceConfig)
+}
+
+// TraceWithConfig returns a Trace middleware with the given configuration.
+//
+// Example:
+//
+//	app.Use(middleware.TraceWithConfig(middleware.TraceConfig{
+//	    Hooks: []middleware.SpanHook{otelHook},
+//	}))
+//
+//	// Propagate the trace to a downstream service
+//	req.Header.Set("traceparent", middleware.TraceParent(c))
+func TraceWithConfig(config TraceConfig) quark.MiddlewareFunc {
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			span := &Span{
+				SpanID: newTraceID(8),
+				Name:   c.Method(),
+				Start:  time.Now(),
+			}
+			traceID, parentID, sampled, ok := parseTraceParent(c.Header("traceparent"))
+			if ok && !config.IgnoreIncoming {
+				span.TraceID = traceID
+				span.ParentSpanID = parentID
+				span.Sampled = sampled
+				span.TraceState = c.Header("tracestate")
+			} else {
+				span.TraceID = newTraceID(16)
+				span.Sampled = config.Sampler == nil || config.Sampler(c)
+			}
+
+			c.Set("trace_id", span.TraceID)
+			c.Set("span_id", span.SpanID)
+			c.Set(spanKey, span)
+			if config.ResponseHeader {
+				c.SetHeader("traceparent", formatTraceParent(span))
+			}
+
+			for _, hook := range config.Hooks {
+				hook.StartSpan(c, span)
+			}
+
+			sw := &statusWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = sw
+
+			err := next(c)
+
+			span.End = time.Now()
+			span.Status = sw.status
+			if err != nil {
+				var httpErr *quark.HTTPError
+				if errors.As(err, &httpErr) {
+					span.Status = httpErr.Code
+				} else {
+					span.Status = http.StatusInternalServerError
+				}
+			}
+			if route := c.Route(); route != "" {
+				span.Name = c.Method() + " " + route
+			}
+
+			for i := len(config.Hooks) - 1; i >= 0; i-- {
+				config.Hooks[i].EndSpan(c, span, err)
+			}
+			return err
+		}
+	}
+}
+
+// CurrentSpan returns the span of the request, or nil outside Trace
+// middleware.
+func CurrentSpan(c *quark.Context) *Span {
+	span, _ := c.Get(spanKey).(*Span)
+	return span
+}
+
+// TraceParent returns the traceparent header value identifying the
+// request's span as the parent of outgoing requests, or an empty string
+// outside Trace middleware.
+func TraceParent(c *quark.Context) string {
+	span := CurrentSpan(c)
+	if span == nil {
+		return ""
+	}
+	return formatTraceParent(span)
+}
+
+// formatTraceParent formats a version 00 traceparent header.
+func formatTraceParent(span *Span) string {
+	flags := "00"
+	if span.Sampled {
+		flags = "01"
+	}
+	return "00-" + span.TraceID + "-" + span.SpanID + "-" + flags
+}
+
+// parseTraceParent parses a traceparent header:
+// version-traceid-parentid-flags, such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceParent(header string) (traceID, parentID string, sampled, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return "", "", false, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Version 00 has exactly four fields; later versions may append more
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false, false
+	}
+	if !isLowerHex(traceID, 32) || traceID == strings.Repeat("0", 32) {
+		return "", "", false, false
+	}
+	if !isLowerHex(parentID, 16) || parentID == strings.Repeat("0", 16) {
+		return "", "", false, false
+	}
+	if !isLowerHex(flags, 2) {
+		return "", "", false, false
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return traceID, parentID, b[0]&0x01 == 1, true
+}
+
+// isLowerHex reports whether s is n lowercase hex digits.
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newTraceID returns n random bytes as lowercase hex.
+func newTraceID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// recordingHook records the spans it receives.
+type recordingHook struct {
+	started []*Span
+	ended   []*Span
+	errs    []error
+}
+
+func (h *recordingHook) StartSpan(c *quark.Context, span *Span) {
+	h.started = append(h.started, span)
+}
+
+func (h *recordingHook) EndSpan(c *quark.Context, span *Span, err error) {
+	h.ended = append(h.ended, span)
+	h.errs = append(h.errs, err)
+}
+
+func TestTrace(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name        string
+		config      TraceConfig
+		traceparent string
+		wantTraceID string
+		wantParent  string
+		wantSampled bool
+	}{
+		{name: "continued", traceparent: "00-" + traceID + "-" + parentID + "-01", wantTraceID: traceID, wantParent: parentID, wantSampled: true},
+		{name: "continued unsampled", traceparent: "00-" + traceID + "-" + parentID + "-00", wantTraceID: traceID, wantParent: parentID},
+		{name: "later version", traceparent: "01-" + traceID + "-" + parentID + "-01-extra", wantTraceID: traceID, wantParent: parentID, wantSampled: true},
+		{name: "new trace", wantSampled: true},
+		{name: "uppercase", traceparent: "00-" + strings.ToUpper(traceID) + "-" + parentID + "-01", wantSampled: true},
+		{name: "zero trace ID", traceparent: "00-" + strings.Repeat("0", 32) + "-" + parentID + "-01", wantSampled: true},
+		{name: "version 00 with extra field", traceparent: "00-" + traceID + "-" + parentID + "-01-extra", wantSampled: true},
+		{name: "version ff", traceparent: "ff-" + traceID + "-" + parentID + "-01", wantSampled: true},
+		{name: "ignore incoming", config: TraceConfig{IgnoreIncoming: true}, traceparent: "00-" + traceID + "-" + parentID + "-01", wantSampled: true},
+		{name: "sampler", config: TraceConfig{Sampler: func(*quark.Context) bool { return false }}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &recordingHook{}
+			config := tt.config
+			config.Hooks = []SpanHook{hook}
+			config.ResponseHeader = true
+
+			var outgoing, ctxTraceID, ctxSpanID string
+			app := quark.New()
+			app.Use(TraceWithConfig(config))
+			app.GET("/users/{id}", func(c *quark.Context) error {
+				outgoing = TraceParent(c)
+				ctxTraceID, ctxSpanID = c.TraceID(), c.SpanID()
+				return c.String(http.StatusCreated, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			if len(hook.started) != 1 || len(hook.ended) != 1 {
+				t.Fatalf("expected one span, got %d started and %d ended", len(hook.started), len(hook.ended))
+			}
+			span := hook.ended[0]
+			if tt.wantTraceID != "" && span.TraceID != tt.wantTraceID {
+				t.Errorf("expected trace ID %s, got %s", tt.wantTraceID, span.TraceID)
+			}
+			if tt.wantTraceID == "" && (span.TraceID == traceID || !isLowerHex(span.TraceID, 32)) {
+				t.Errorf("expected a new trace ID, got %s", span.TraceID)
+			}
+			if span.ParentSpanID != tt.wantParent {
+				t.Errorf("expected parent span ID %q, got %q", tt.wantParent, span.ParentSpanID)
+			}
+			if span.Sampled != tt.wantSampled {
+				t.Errorf("expected sampled %v, got %v", tt.wantSampled, span.Sampled)
+			}
+			if span.Name != "GET /users/{id}" {
+				t.Errorf("expected span name GET /users/{id}, got %q", span.Name)
+			}
+			if span.Status != http.StatusCreated {
+				t.Errorf("expected status 201, got %d", span.Status)
+			}
+
+			want := formatTraceParent(span)
+			if outgoing != want {
+				t.Errorf("expected outgoing traceparent %s, got %s", want, outgoing)
+			}
+			if got := rec.Header().Get("traceparent"); got != want {
+				t.Errorf("expected response traceparent %s, got %s", want, got)
+			}
+			if ctxTraceID != span.TraceID || ctxSpanID != span.SpanID {
+				t.Errorf("expected context IDs %s/%s, got %s/%s", span.TraceID, span.SpanID, ctxTraceID, ctxSpanID)
+			}
+		})
+	}
+}
+
+func TestTraceErrorStatus(t *testing.T) {
+	hook := &recordingHook{}
+	app := quark.New()
+	app.Use(TraceWithConfig(TraceConfig{Hooks: []SpanHook{hook}}))
+	app.GET("/missing", func(c *quark.Context) error {
+		return quark.ErrNotFound("user not found")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if len(hook.ended) != 1 {
+		t.Fatalf("expected one span, got %d", len(hook.ended))
+	}
+	if hook.ended[0].Status != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", hook.ended[0].Status)
+	}
+	if hook.errs[0] == nil {
+		t.Error("expected the handler error")
+	}
+}
+
+func TestTraceParentOutsideTrace(t *testing.T) {
+	app := quark.New()
+	var got string
+	app.GET("/", func(c *quark.Context) error {
+		got = TraceParent(c)
+		return c.String(http.StatusOK, "ok")
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got != "" {
+		t.Errorf("expected no traceparent, got %q", got)
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// UploadsConfig defines the configuration for Uploads middleware.
+type UploadsConfig struct {
+	// Skipper defines a function to skip this middleware.
+	Skipper func(*quark.Context) bool
+
+	// MaxFileSize is the maximum size of each file in bytes.
+	MaxFileSize int64
+
+	// MaxFiles is the maximum number of files per request.
+	MaxFiles int
+
+	// MaxRequestSize is the maximum size of the whole multipart body
+	// (default: MaxFiles*MaxFileSize plus 1MB for the other fields).
+	MaxRequestSize int64
+
+	// AllowedTypes lists the accepted media types, as exact types
+	// ("application/pdf") or prefixes ending in "/" ("image/"). Types are
+	// sniffed from the content of each file, ignoring the name and the
+	// type declared by the client. Empty accepts every type.
+	AllowedTypes []string
+
+	// MaxMemory is the part of the form kept in memory while parsing;
+	// larger files are buffered in temporary files (default: 32MB).
+	MaxMemory int64
+}
+
+// DefaultUploadsConfig is the default uploads configuration.
+var DefaultUploadsConfig = UploadsConfig{
+	MaxFileSize: 10 << 20, // 10 MB
+	MaxFiles:    10,
+	MaxMemory:   32 << 20, // 32 MB
+}
+
+// Uploads returns a middleware validating multipart uploads before the
+// handler runs. The form is parsed into Request.MultipartForm, and requests
+// with too many or too large files are rejected with 413 Request Entity
+// Too Large, and files of other types with 415 Unsupported Media Type.
+// Other requests pass through.
+//
+// Content types are detected from the first 512 bytes of each file with
+// http.DetectContentType, which recognizes common image, audio, video,
+// archive, PDF and text formats; other content is application/octet-stream.
+//
+// Example:
+//
+//	r.POST("/avatar", setAvatar, middleware.Uploads(middleware.UploadsConfig{
+//	    MaxFileSize:  2 << 20,
+//	    MaxFiles:     1,
+//	    AllowedTypes: []string{"image/png", "image/jpeg", "image/webp"},
+//	}))
+func Uploads(config UploadsConfig) quark.MiddlewareFunc {
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = DefaultUploadsConfig.MaxFileSize
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = DefaultUploadsConfig.MaxFiles
+	}
+	if config.MaxRequestSize <= 0 {
+		config.MaxRequestSize = int64(config.MaxFiles)*config.MaxFileSize + 1<<20
+	}
+	if config.MaxMemory <= 0 {
+		config.MaxMemory = DefaultUploadsConfig.MaxMemory
+	}
+
+	return func(next quark.HandlerFunc) quark.HandlerFunc {
+		return func(c *quark.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			if c.ContentType() != "multipart/form-data" {
+				return next(c)
+			}
+
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxRequestSize)
+			if err := c.Request.ParseMultipartForm(config.MaxMemory); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					return uploadError(http.StatusRequestEntityTooLarge, "request body too large", quark.M{
+						"max_request_size": config.MaxRequestSize,
+					})
+				}
+				return quark.WrapError(http.StatusBadRequest, "invalid multipart body", err)
+			}
+
+			files := 0
+			for field, headers := range c.Request.MultipartForm.File {
+				for _, fh := range headers {
+					files++
+					if files > config.MaxFiles {
+						return uploadError(http.StatusRequestEntityTooLarge, "too many files", quark.M{
+							"max_files": config.MaxFiles,
+						})
+					}
+					if fh.Size > config.MaxFileSize {
+						return uploadError(http.StatusRequestEntityTooLarge, "file too large", quark.M{
+							"field":         field,
+							"filename":      fh.Filename,
+							"size":          fh.Size,
+							"max_file_size": config.MaxFileSize,
+						})
+					}
+					if len(config.AllowedTypes) == 0 {
+						continue
+					}
+
+					contentType, err := sniffFile(fh)
+					if err != nil {
+						return err
+					}
+					if !matchContentType(contentType, config.AllowedTypes) {
+						return uploadError(http.StatusUnsupportedMediaType, "file type not allowed", quark.M{
+							"field":         field,
+							"filename":      fh.Filename,
+							"type":          contentType,
+							"allowed_types": config.AllowedTypes,
+						})
+					}
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// sniffFile detects the content type of an uploaded file.
+func sniffFile(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// uploadError returns a rejected upload error with its limits as details.
+func uploadError(code int, message string, details quark.M) *quark.HTTPError {
+	err := quark.NewHTTPError(code, message)
+	err.Details = details
+	return err
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// pngHeader is the signature http.DetectContentType reports as image/png.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// uploadFile is a file of a multipart test request.
+type uploadFile struct {
+	name    string
+	content []byte
+}
+
+// multipartRequest builds a multipart upload of files in the "file" field.
+func multipartRequest(t *testing.T, files ...uploadFile) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, f := range files {
+		part, err := w.CreateFormFile("file", f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(f.content)
+	}
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestUploads(t *testing.T) {
+	app := quark.New()
+	app.POST("/upload", func(c *quark.Context) error {
+		return c.String(http.StatusOK, "uploaded")
+	}, Uploads(UploadsConfig{
+		MaxFileSize:    1024,
+		MaxFiles:       2,
+		MaxRequestSize: 8 << 10,
+		AllowedTypes:   []string{"image/png", "text/"},
+	}))
+
+	png := uploadFile{"avatar.png", append(pngHeader, make([]byte, 64)...)}
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantError  string
+	}{
+		{"allowed type", multipartRequest(t, png), http.StatusOK, ""},
+		{"allowed prefix", multipartRequest(t, uploadFile{"notes.txt", []byte("plain text")}), http.StatusOK, ""},
+		{"sniffed type", multipartRequest(t, uploadFile{"avatar.png", []byte("%PDF-1.7\n")}), http.StatusUnsupportedMediaType, "file type not allowed"},
+		{"file too large", multipartRequest(t, uploadFile{"big.txt", bytes.Repeat([]byte("a"), 2048)}), http.StatusRequestEntityTooLarge, "file too large"},
+		{"too many files", multipartRequest(t, png, png, png), http.StatusRequestEntityTooLarge, "too many files"},
+		{"request too large", multipartRequest(t, uploadFile{"huge.txt", bytes.Repeat([]byte("a"), 16<<10)}), http.StatusRequestEntityTooLarge, "request body too large"},
+		{"not multipart", httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=ada")), http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantError != "" && !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Errorf("expected error %q, got %s", tt.wantError, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package quark
+
+import "fmt"
+
+// Module is a self-contained feature, such as billing or user accounts,
+// bundling its services, routes, middleware and lifecycle. Modules are
+// added with App.Register; embed BaseModule to implement only the methods
+// a module needs.
+//
+// Example:
+//
+//	type UsersModule struct {
+//	    quark.BaseModule
+//	}
+//
+//	func (UsersModule) Register(c *quark.Container) {
+//	    quark.ProvideType(c, func(c *quark.Container) (*UserService, error) {
+//	        return NewUserService(quark.MustResolveType[*sql.DB](c)), nil
+//	    })
+//	}
+//
+//	func (UsersModule) Routes(r *quark.RouteGroup) {
+//	    users := r.Group("/users")
+//	    users.GET("", listUsers)
+//	    users.POST("", createUser)
+//	}
+//
+//	app.Register(UsersModule{}, BillingModule{})
+type Module interface {
+	// Register registers the module's services in the app container.
+	Register(c *Container)
+
+	// Middleware returns middleware applied to the module's routes only.
+	Middleware() []MiddlewareFunc
+
+	// Routes registers the module's routes on a group at the app root.
+	Routes(r *RouteGroup)
+
+	// Boot runs when the app starts, after all modules are registered.
+	Boot(a *App) error
+
+	// Shutdown runs when the app shuts down.
+	Shutdown(a *App) error
+}
+
+// BaseModule implements Module with no-op methods, to be embedded in
+// modules.
+type BaseModule struct{}
+
+// Register implements Module.
+func (BaseModule) Register(c *Container) {}
+
+// Middleware implements Module.
+func (BaseModule) Middleware() []MiddlewareFunc { return nil }
+
+// Routes implements Module.
+func (BaseModule) Routes(r *RouteGroup) {}
+
+// Boot implements Module.
+func (BaseModule) Boot(a *App) error { return nil }
+
+// Shutdown implements Module.
+func (BaseModule) Shutdown(a *App) error { return nil }
+
+// Register adds modules to the app: their services are registered, then
+// their routes mounted with their middleware. Boot runs with the OnStart
+// hooks and Shutdown with the OnShutdown hooks, in registration order.
+func (a *App) Register(modules ...Module) {
+	for _, m := range modules {
+		m.Register(a.container)
+	}
+	for _, m := range modules {
+		m.Routes(a.Group("", m.Middleware()...))
+		a.OnStart(func(a *App) error {
+			if err := m.Boot(a); err != nil {
+				return fmt.Errorf("module %T boot failed: %w", m, err)
+			}
+			return nil
+		})
+		a.OnShutdown(func(a *App) error {
+			if err := m.Shutdown(a); err != nil {
+				return fmt.Errorf("module %T shutdown failed: %w", m, err)
+			}
+			return nil
+		})
+	}
+}
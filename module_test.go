@@ -0,0 +1,83 @@
+package quark
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testModule struct {
+	BaseModule
+	events *[]string
+}
+
+func (m testModule) Register(c *Container) {
+	ProvideValue(c, "greeting", "hello")
+}
+
+func (m testModule) Middleware() []MiddlewareFunc {
+	return []MiddlewareFunc{func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Module", "test")
+			return next(c)
+		}
+	}}
+}
+
+func (m testModule) Routes(r *RouteGroup) {
+	r.GET("/greet", func(c *Context) error {
+		return c.String(http.StatusOK, MustResolve[string](c.App().Container(), "greeting"))
+	})
+}
+
+func (m testModule) Boot(a *App) error {
+	*m.events = append(*m.events, "boot")
+	return nil
+}
+
+func (m testModule) Shutdown(a *App) error {
+	*m.events = append(*m.events, "shutdown")
+	return nil
+}
+
+type failingModule struct {
+	BaseModule
+}
+
+func (failingModule) Boot(a *App) error {
+	return errors.New("missing settings")
+}
+
+func TestAppRegisterModules(t *testing.T) {
+	var events []string
+	app := New(WithLogger(&printfLogger{}))
+	app.GET("/other", func(c *Context) error { return c.NoContent() })
+	app.Register(testModule{events: &events})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/greet", nil))
+	if rec.Body.String() != "hello" || rec.Header().Get("X-Module") != "test" {
+		t.Errorf("expected module route with its middleware, got %q %q", rec.Body.String(), rec.Header().Get("X-Module"))
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if rec.Header().Get("X-Module") != "" {
+		t.Error("expected module middleware scoped to module routes")
+	}
+
+	if err := app.prepare(":0"); err != nil {
+		t.Fatalf("prepare: unexpected error: %v", err)
+	}
+	app.runShutdownHooks()
+	if len(events) != 2 || events[0] != "boot" || events[1] != "shutdown" {
+		t.Errorf("expected boot then shutdown, got %v", events)
+	}
+
+	failing := New(WithLogger(&printfLogger{}))
+	failing.Register(failingModule{})
+	if err := failing.prepare(":0"); err == nil || !strings.Contains(err.Error(), "failingModule boot failed") {
+		t.Errorf("expected module boot error, got %v", err)
+	}
+}
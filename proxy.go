@@ -0,0 +1,119 @@
+package quark
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+)
+
+// defaultTrustedProxies are the loopback networks, for a reverse proxy on
+// the same host. Private networks are not trusted by default: any host on
+// them, not only the load balancer, could then spoof client IPs.
+var defaultTrustedProxies = []string{"127.0.0.0/8", "::1/128"}
+
+// defaultProxyNets are the parsed defaultTrustedProxies.
+var defaultProxyNets, _ = parseProxies(defaultTrustedProxies)
+
+// WithTrustedProxies sets the proxies whose forwarded headers
+// (X-Forwarded-For, X-Real-IP, X-Forwarded-Proto) are honored by
+// Context.RealIP and Context.Scheme, overriding Config.TrustedProxies.
+// Entries are CIDRs or single IPs. Without arguments, no proxy is trusted
+// and forwarded headers are ignored. Panics on an invalid entry.
+//
+// Example:
+//
+//	app := quark.New(quark.WithTrustedProxies("10.0.4.0/24", "203.0.113.7"))
+func WithTrustedProxies(proxies ...string) Option {
+	nets, err := parseProxies(proxies)
+	if err != nil {
+		panic("quark: invalid trusted proxy: " + err.Error())
+	}
+	return func(a *App) {
+		a.proxyOnce.Do(func() {})
+		a.proxyNets = nets
+	}
+}
+
+// trustedProxies returns the networks of trusted proxies, parsed from
+// Config.TrustedProxies on first use. Invalid entries are logged and
+// skipped.
+func (a *App) trustedProxies() []*net.IPNet {
+	a.proxyOnce.Do(func() {
+		for _, proxy := range a.config.TrustedProxies {
+			nets, err := parseProxies([]string{proxy})
+			if err != nil {
+				a.logAt(slog.LevelWarn, "invalid trusted proxy ignored", "proxy", proxy, "error", err)
+				continue
+			}
+			a.proxyNets = append(a.proxyNets, nets...)
+		}
+	})
+	return a.proxyNets
+}
+
+// parseProxies parses CIDRs and single IPs into networks.
+func parseProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: proxy}
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// isTrustedProxy reports whether ip belongs to one of the networks.
+func isTrustedProxy(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP of the connection, without the port.
+func (c *Context) remoteIP() string {
+	addr := c.Request.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// proxyNets returns the trusted proxies of the app, or the default ones
+// for contexts created outside an app.
+func (c *Context) proxyNets() []*net.IPNet {
+	if c.app == nil {
+		return defaultProxyNets
+	}
+	return c.app.trustedProxies()
+}
+
+// fromTrustedProxy reports whether the request comes from a trusted proxy,
+// so its forwarded headers can be honored.
+func (c *Context) fromTrustedProxy() bool {
+	return isTrustedProxy(c.remoteIP(), c.proxyNets())
+}
@@ -0,0 +1,100 @@
+package quark
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContextScheme(t *testing.T) {
+	tests := []struct {
+		name       string
+		proto      string
+		remoteAddr string
+		tls        bool
+		expected   string
+	}{
+		{"plain", "", "127.0.0.1:1234", false, "http"},
+		{"TLS", "", "203.0.113.9:1234", true, "https"},
+		{"forwarded by trusted proxy", "https", "127.0.0.1:1234", false, "https"},
+		{"forwarded by IPv6 loopback", "https", "[::1]:1234", false, "https"},
+		{"forwarded list", "HTTPS, http", "127.0.0.1:1234", false, "https"},
+		{"forwarded by untrusted client", "https", "203.0.113.9:1234", false, "http"},
+		{"forwarded from private network", "https", "10.0.0.1:1234", false, "http"},
+		{"invalid forwarded proto", "gopher", "127.0.0.1:1234", false, "http"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			c := &Context{Request: req}
+			if got := c.Scheme(); got != tt.expected {
+				t.Errorf("Scheme(): expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	var ip, scheme string
+	handler := func(c *Context) error {
+		ip, scheme = c.RealIP(), c.Scheme()
+		return c.NoContent()
+	}
+	request := func(app *App, remoteAddr string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "1.1.1.1")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	app := New(WithTrustedProxies("203.0.113.0/24", "198.51.100.7"))
+	app.GET("/", handler)
+
+	request(app, "198.51.100.7:1234")
+	if ip != "1.1.1.1" || scheme != "https" {
+		t.Errorf("WithTrustedProxies: expected forwarded headers honored, got %s %s", ip, scheme)
+	}
+	request(app, "127.0.0.1:1234")
+	if ip != "127.0.0.1" || scheme != "http" {
+		t.Errorf("WithTrustedProxies: expected defaults replaced, got %s %s", ip, scheme)
+	}
+
+	none := New(WithTrustedProxies())
+	none.GET("/", handler)
+	request(none, "127.0.0.1:1234")
+	if ip != "127.0.0.1" {
+		t.Errorf("WithTrustedProxies(): expected no proxy trusted, got %s", ip)
+	}
+
+	cfg := DefaultConfig()
+	cfg.TrustedProxies = []string{"not-an-ip", "203.0.113.0/24"}
+	logger := &printfLogger{}
+	fromConfig := New(WithConfig(cfg), WithLogger(logger))
+	fromConfig.GET("/", handler)
+	request(fromConfig, "203.0.113.5:1234")
+	if ip != "1.1.1.1" {
+		t.Errorf("Config.TrustedProxies: expected forwarded header honored, got %s", ip)
+	}
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "not-an-ip") {
+		t.Errorf("Config.TrustedProxies: expected warning for invalid entry, got %v", logger.lines)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WithTrustedProxies: expected panic for invalid entry")
+		}
+	}()
+	WithTrustedProxies("10.0.0.0/33")
+}
@@ -22,10 +22,14 @@ package quark
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -42,17 +46,59 @@ type App struct {
 	middleware  []MiddlewareFunc
 	onStart     []func(*App) error
 	onShutdown  []func(*App) error
+	onRequest   []func(*Context)
+	onResponse  []func(*Context, int, time.Duration)
+	onError     []func(*Context, error)
 	server      *http.Server
 	contextPool sync.Pool
 	debug       bool
 	logger      Logger
+	renderer    Renderer
+	codecs      map[string]Codec
+	health      *HealthRegistry
+	healthOnce  sync.Once
+	upgrades    bool
+	autoTLS     AutoTLSConfig
+
+	serverConfigurers []func(*http.Server)
+	proxyNets         []*net.IPNet
+	proxyOnce         sync.Once
 }
 
-// Logger interface for application logging.
+// Logger interface for application logging. Loggers also implementing
+// LeveledLogger receive leveled, structured records.
 type Logger interface {
 	Printf(format string, v ...interface{})
 }
 
+// Renderer renders named templates for Context.Render. The template
+// contrib package's Engine implements it; other engines (text/template,
+// Markdown, ...) can be plugged in with SetRenderer so handlers only
+// depend on Context.Render.
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+//
+// Example:
+//
+//	app.SetRenderer(quark.RendererFunc(func(w io.Writer, name string, data interface{}) error {
+//	    return textTemplates.ExecuteTemplate(w, name, data)
+//	}))
+type RendererFunc func(w io.Writer, name string, data interface{}) error
+
+// Render implements Renderer.
+func (f RendererFunc) Render(w io.Writer, name string, data interface{}) error {
+	return f(w, name, data)
+}
+
+// ContextRenderer is implemented by renderers that need the request, e.g.
+// to localize templates. Context.Render prefers it over Render.
+type ContextRenderer interface {
+	RenderContext(c *Context, w io.Writer, name string, data interface{}) error
+}
+
 // Option is a function that configures the App.
 type Option func(*App)
 
@@ -66,8 +112,10 @@ func New(opts ...Option) *App {
 		onStart:    make([]func(*App) error, 0),
 		onShutdown: make([]func(*App) error, 0),
 		debug:      false,
-		logger:     log.New(os.Stdout, "[quark] ", log.LstdFlags),
 	}
+	app.logger = NewSlogLogger(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: debugLevel{app},
+	})))
 
 	app.contextPool = sync.Pool{
 		New: func() interface{} {
@@ -83,6 +131,7 @@ func New(opts ...Option) *App {
 	for _, opt := range opts {
 		opt(app)
 	}
+	app.mountDebugRoutes()
 
 	return app
 }
@@ -101,6 +150,65 @@ func WithLogger(l Logger) Option {
 	}
 }
 
+// WithRenderer sets the renderer used by Context.Render.
+func WithRenderer(r Renderer) Option {
+	return func(a *App) {
+		a.renderer = r
+	}
+}
+
+// WithEagerServices creates the named container services, or all of them
+// when no name is given, when the server starts, so a misconfigured
+// database or template engine fails the start instead of a request.
+func WithEagerServices(names ...string) Option {
+	return func(a *App) {
+		a.onStart = append(a.onStart, func(a *App) error {
+			return a.container.Warmup(names...)
+		})
+	}
+}
+
+// WithServerConfigurer registers a function that tunes the http.Server
+// created by the Run methods, after the Config settings are applied, for
+// settings Config does not cover.
+//
+// Example:
+//
+//	app := quark.New(quark.WithServerConfigurer(func(s *http.Server) {
+//	    s.ErrorLog = log.New(io.Discard, "", 0)
+//	    s.ConnState = trackConnections
+//	}))
+func WithServerConfigurer(fn func(*http.Server)) Option {
+	return func(a *App) {
+		a.serverConfigurers = append(a.serverConfigurers, fn)
+	}
+}
+
+// upgradeTimeout is how long an upgrade waits for the new process to be
+// ready to serve.
+var upgradeTimeout = time.Minute
+
+// WithUpgrades enables zero-downtime restarts of RunWithGracefulShutdown:
+// on SIGUSR2 the app starts a new process of the current executable and
+// passes it the listening socket. Once the new process has run its
+// OnStart callbacks and is listening, the app drains and exits. If the new
+// process exits first, or is not ready within a minute, it is killed and
+// the app keeps serving. Deploys replace the binary and send SIGUSR2
+// without dropping connections. Upgrades are not supported on Windows.
+//
+// Example:
+//
+//	app := quark.New(quark.WithUpgrades())
+//	app.RunWithGracefulShutdown(":8080")
+//
+//	// Deploy
+//	//   cp myapp.new myapp && kill -USR2 $(pidof myapp)
+func WithUpgrades() Option {
+	return func(a *App) {
+		a.upgrades = true
+	}
+}
+
 // WithConfig sets the application configuration.
 func WithConfig(cfg *Config) Option {
 	return func(a *App) {
@@ -133,6 +241,17 @@ func (a *App) Logger() Logger {
 	return a.logger
 }
 
+// SetRenderer sets the renderer used by Context.Render.
+func (a *App) SetRenderer(r Renderer) {
+	a.renderer = r
+}
+
+// Renderer returns the renderer used by Context.Render, or nil if none is
+// set.
+func (a *App) Renderer() Renderer {
+	return a.renderer
+}
+
 // Use adds middleware to the global middleware stack.
 func (a *App) Use(mw ...MiddlewareFunc) {
 	a.middleware = append(a.middleware, mw...)
@@ -149,38 +268,38 @@ func (a *App) OnShutdown(fn func(*App) error) {
 }
 
 // GET registers a GET route.
-func (a *App) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.GET(pattern, h, mw...)
+func (a *App) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.GET(pattern, h, mw...)
 }
 
 // POST registers a POST route.
-func (a *App) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.POST(pattern, h, mw...)
+func (a *App) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.POST(pattern, h, mw...)
 }
 
 // PUT registers a PUT route.
-func (a *App) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.PUT(pattern, h, mw...)
+func (a *App) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.PUT(pattern, h, mw...)
 }
 
 // PATCH registers a PATCH route.
-func (a *App) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.PATCH(pattern, h, mw...)
+func (a *App) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.PATCH(pattern, h, mw...)
 }
 
 // DELETE registers a DELETE route.
-func (a *App) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.DELETE(pattern, h, mw...)
+func (a *App) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.DELETE(pattern, h, mw...)
 }
 
 // OPTIONS registers an OPTIONS route.
-func (a *App) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.OPTIONS(pattern, h, mw...)
+func (a *App) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.OPTIONS(pattern, h, mw...)
 }
 
 // HEAD registers a HEAD route.
-func (a *App) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	a.router.HEAD(pattern, h, mw...)
+func (a *App) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return a.router.HEAD(pattern, h, mw...)
 }
 
 // Any registers a route for all HTTP methods.
@@ -193,6 +312,11 @@ func (a *App) Static(prefix, root string) {
 	a.router.Static(prefix, root)
 }
 
+// StaticWithConfig serves static files, see Router.StaticWithConfig.
+func (a *App) StaticWithConfig(prefix string, config StaticConfig) {
+	a.router.StaticWithConfig(prefix, config)
+}
+
 // Group creates a new route group with the given prefix.
 func (a *App) Group(prefix string, mw ...MiddlewareFunc) *RouteGroup {
 	return NewRouteGroup(a.router, prefix, mw...)
@@ -218,14 +342,75 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute the handler
-	if err := handler(c); err != nil {
-		a.handleError(c, err)
+	a.serveWithHooks(c, func(c *Context) {
+		if err := handler(c); err != nil {
+			for _, fn := range a.onError {
+				fn(c, err)
+			}
+			a.handleError(c, err)
+		}
+	})
+
+	// Close request-scoped services
+	if c.scope != nil {
+		if err := c.scope.Close(context.Background()); err != nil {
+			a.logAt(slog.LevelError, "closing request services failed", "error", err)
+		}
 	}
 
 	// Return context to pool
 	a.contextPool.Put(c)
 }
 
+// Test runs a request through the middleware and router in-memory and
+// returns the response, for integration tests. The response body is a copy
+// the app no longer touches. The request times out after the optional
+// timeout (default: 1 second), cancelling its context so the handler can
+// stop; a timeout of zero or less waits indefinitely. Handler panics are
+// returned as errors.
+//
+// Example:
+//
+//	resp, err := app.Test(httptest.NewRequest("GET", "/users/1", nil))
+//	body, _ := io.ReadAll(resp.Body)
+func (a *App) Test(req *http.Request, timeout ...time.Duration) (*http.Response, error) {
+	wait := time.Second
+	if len(timeout) > 0 {
+		wait = timeout[0]
+	}
+
+	if wait > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), wait)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	rec := httptest.NewRecorder()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("quark: test request panicked: %v", p)
+			}
+		}()
+		a.ServeHTTP(rec, req)
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, err
+		}
+	case <-req.Context().Done():
+		return nil, fmt.Errorf("quark: test request %s %s timed out after %v", req.Method, req.URL.Path, wait)
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
 // handleError handles errors returned from handlers.
 func (a *App) handleError(c *Context, err error) {
 	if c.IsWritten() {
@@ -234,13 +419,17 @@ func (a *App) handleError(c *Context, err error) {
 
 	if httpErr, ok := err.(*HTTPError); ok {
 		if a.debug && httpErr.Err != nil {
-			c.JSON(httpErr.Code, M{
-				"error": M{
-					"code":    httpErr.Code,
-					"message": httpErr.Message,
-					"debug":   httpErr.Err.Error(),
-				},
-			})
+			body := M{
+				"code":    httpErr.Code,
+				"message": httpErr.Message,
+				"debug":   httpErr.Err.Error(),
+			}
+			if httpErr.Details != nil {
+				body["details"] = httpErr.Details
+			}
+			c.JSON(httpErr.Code, M{"error": body})
+		} else if httpErr.Details != nil {
+			c.ErrorWithDetails(httpErr.Code, httpErr.Message, httpErr.Details)
 		} else {
 			c.Error(httpErr.Code, httpErr.Message)
 		}
@@ -267,22 +456,11 @@ func (a *App) Run(addr string) error {
 		addr = fmt.Sprintf("%s:%s", a.config.Host, a.config.Port)
 	}
 
-	// Run onStart callbacks
-	for _, fn := range a.onStart {
-		if err := fn(a); err != nil {
-			return fmt.Errorf("onStart callback failed: %w", err)
-		}
-	}
-
-	a.server = &http.Server{
-		Addr:         addr,
-		Handler:      a,
-		ReadTimeout:  a.config.ReadTimeout,
-		WriteTimeout: a.config.WriteTimeout,
-		IdleTimeout:  a.config.IdleTimeout,
+	if err := a.prepare(addr); err != nil {
+		return err
 	}
 
-	a.logger.Printf("Starting server on %s", addr)
+	a.logAt(slog.LevelInfo, "starting server", "addr", addr)
 
 	return a.server.ListenAndServe()
 }
@@ -293,6 +471,18 @@ func (a *App) RunTLS(addr, certFile, keyFile string) error {
 		addr = fmt.Sprintf("%s:%s", a.config.Host, a.config.Port)
 	}
 
+	if err := a.prepare(addr); err != nil {
+		return err
+	}
+
+	a.logAt(slog.LevelInfo, "starting TLS server", "addr", addr)
+
+	return a.server.ListenAndServeTLS(certFile, keyFile)
+}
+
+// prepare runs the onStart callbacks, logs the configuration and creates
+// the server.
+func (a *App) prepare(addr string) error {
 	// Run onStart callbacks
 	for _, fn := range a.onStart {
 		if err := fn(a); err != nil {
@@ -300,97 +490,231 @@ func (a *App) RunTLS(addr, certFile, keyFile string) error {
 		}
 	}
 
-	a.server = &http.Server{
-		Addr:         addr,
-		Handler:      a,
-		ReadTimeout:  a.config.ReadTimeout,
-		WriteTimeout: a.config.WriteTimeout,
-		IdleTimeout:  a.config.IdleTimeout,
-	}
+	// Report the routes instead of serving for "quark routes", including
+	// those added by OnStart hooks and module Boot
+	a.dumpRoutes()
 
-	a.logger.Printf("Starting TLS server on %s", addr)
+	a.logConfig()
 
-	return a.server.ListenAndServeTLS(certFile, keyFile)
+	a.server = &http.Server{
+		Addr:              addr,
+		Handler:           a,
+		ReadTimeout:       a.config.ReadTimeout,
+		ReadHeaderTimeout: a.config.ReadHeaderTimeout,
+		WriteTimeout:      a.config.WriteTimeout,
+		IdleTimeout:       a.config.IdleTimeout,
+		MaxHeaderBytes:    a.config.MaxHeaderBytes,
+	}
+	if a.config.DisableKeepAlives {
+		a.server.SetKeepAlivesEnabled(false)
+	}
+	for _, fn := range a.serverConfigurers {
+		fn(a.server)
+	}
+	return nil
 }
 
-// RunWithGracefulShutdown starts the server with graceful shutdown on SIGINT/SIGTERM.
+// RunWithGracefulShutdown starts the server with graceful shutdown on SIGINT/SIGTERM,
+// and zero-downtime restarts on SIGUSR2 when enabled with WithUpgrades.
 func (a *App) RunWithGracefulShutdown(addr string) error {
 	if addr == "" {
 		addr = fmt.Sprintf("%s:%s", a.config.Host, a.config.Port)
 	}
 
-	// Run onStart callbacks
-	for _, fn := range a.onStart {
-		if err := fn(a); err != nil {
-			return fmt.Errorf("onStart callback failed: %w", err)
-		}
+	if err := a.prepare(addr); err != nil {
+		return err
 	}
 
-	a.server = &http.Server{
-		Addr:         addr,
-		Handler:      a,
-		ReadTimeout:  a.config.ReadTimeout,
-		WriteTimeout: a.config.WriteTimeout,
-		IdleTimeout:  a.config.IdleTimeout,
+	// Listen, or take over the socket of the process that started this one
+	l, err := a.listen(addr)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
 	}
+	a.notifyReady()
 
-	// Channel to listen for errors from ListenAndServe
+	// Channel to listen for errors from Serve
 	serverErrors := make(chan error, 1)
 
 	// Start the server
 	go func() {
-		a.logger.Printf("Starting server on %s", addr)
-		serverErrors <- a.server.ListenAndServe()
+		a.logAt(slog.LevelInfo, "starting server", "addr", l.Addr().String())
+		serverErrors <- a.server.Serve(l)
 	}()
 
 	// Channel to listen for OS signals
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(shutdown)
+
+	upgrade := make(chan os.Signal, 1)
+	if a.upgrades && len(upgradeSignals) > 0 {
+		signal.Notify(upgrade, upgradeSignals...)
+		defer signal.Stop(upgrade)
+	}
 
 	// Block until we receive a signal or error
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("server error: %w", err)
+
+		case <-upgrade:
+			pid, err := upgradeProcess(l, upgradeTimeout)
+			if err != nil {
+				a.logAt(slog.LevelError, "upgrade failed, still serving", "error", err)
+				continue
+			}
+			a.logAt(slog.LevelInfo, "upgraded, starting graceful shutdown", "pid", pid)
+			return a.gracefulShutdown()
+
+		case sig := <-shutdown:
+			a.logAt(slog.LevelInfo, "received signal, starting graceful shutdown", "signal", sig.String())
+			return a.gracefulShutdown()
+		}
+	}
+}
+
+// RunContext starts the HTTP server and shuts it down gracefully when ctx
+// is done, for embedding the app in larger programs, tests and
+// orchestration frameworks that own the process lifecycle. It returns nil
+// after a graceful shutdown.
+//
+// Example:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.Go(func() error { return app.RunContext(ctx, ":8080") })
+//	g.Go(func() error { return worker.Run(ctx) })
+//	return g.Wait()
+func (a *App) RunContext(ctx context.Context, addr string) error {
+	if addr == "" {
+		addr = fmt.Sprintf("%s:%s", a.config.Host, a.config.Port)
+	}
+
+	if err := a.prepare(addr); err != nil {
+		return err
+	}
+
+	l, err := a.listen(addr)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	a.notifyReady()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		a.logAt(slog.LevelInfo, "starting server", "addr", l.Addr().String())
+		serverErrors <- a.server.Serve(l)
+	}()
+
 	select {
 	case err := <-serverErrors:
 		return fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
+		a.logAt(slog.LevelInfo, "context done, starting graceful shutdown")
+		return a.gracefulShutdown()
+	}
+}
 
-	case sig := <-shutdown:
-		a.logger.Printf("Received signal %v, starting graceful shutdown...", sig)
+// gracefulShutdown drains and shuts down the server started by
+// RunWithGracefulShutdown or RunContext within Config.ShutdownTimeout.
+func (a *App) gracefulShutdown() error {
+	// Let load balancers stop routing requests before shutting down
+	a.drain(context.Background())
+
+	// Create a context with timeout for shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+	defer cancel()
+
+	// Gracefully shutdown the server, waiting for in-flight requests
+	var err error
+	graceful := a.server.Shutdown(ctx)
+	if graceful != nil {
+		a.logAt(slog.LevelError, "graceful shutdown failed", "error", graceful)
+		err = a.server.Close()
+	}
+	a.runShutdownHooks()
 
-		// Create a context with timeout for shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
-		defer cancel()
+	// Services get their own timeout, even when the server used up its own
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+	defer closeCancel()
+	a.closeContainer(closeCtx)
 
-		// Run onShutdown callbacks
-		for _, fn := range a.onShutdown {
-			if err := fn(a); err != nil {
-				a.logger.Printf("onShutdown callback failed: %v", err)
-			}
-		}
+	if graceful != nil {
+		return err
+	}
+	a.logAt(slog.LevelInfo, "server stopped gracefully")
+	return nil
+}
 
-		// Gracefully shutdown the server
-		if err := a.server.Shutdown(ctx); err != nil {
-			a.logger.Printf("Graceful shutdown failed: %v", err)
-			return a.server.Close()
-		}
+// logConfig logs the configuration, with secrets redacted, in debug mode.
+func (a *App) logConfig() {
+	if !a.debug {
+		return
+	}
+	var b strings.Builder
+	if err := DumpConfig(&b, a.config); err != nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		key, value, _ := strings.Cut(line, " = ")
+		a.logAt(slog.LevelInfo, "config", "key", key, "value", value)
+	}
+}
 
-		a.logger.Printf("Server stopped gracefully")
+// Shutdown gracefully shuts down the server: readiness checks start
+// failing, the server waits for the configured drain delay and in-flight
+// requests, then the OnShutdown callbacks run and the container is closed.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.drain(ctx)
+
+	var err error
+	if a.server != nil {
+		err = a.server.Shutdown(ctx)
 	}
 
-	return nil
+	a.runShutdownHooks()
+	a.closeContainer(ctx)
+	return err
 }
 
-// Shutdown gracefully shuts down the server.
-func (a *App) Shutdown(ctx context.Context) error {
-	// Run onShutdown callbacks
+// drain prepares the server for shutdown: readiness checks fail and
+// keep-alive connections are closed after their current request, then it
+// waits for Config.DrainDelay so load balancers stop sending requests.
+func (a *App) drain(ctx context.Context) {
+	a.HealthChecks().shuttingDown.Store(true)
+	if a.server == nil {
+		return
+	}
+	a.server.SetKeepAlivesEnabled(false)
+
+	if delay := a.config.DrainDelay; delay > 0 {
+		a.logAt(slog.LevelInfo, "draining connections", "delay", delay.String())
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// runShutdownHooks runs the onShutdown callbacks, logging failures.
+func (a *App) runShutdownHooks() {
 	for _, fn := range a.onShutdown {
 		if err := fn(a); err != nil {
-			a.logger.Printf("onShutdown callback failed: %v", err)
+			a.logAt(slog.LevelError, "onShutdown callback failed", "error", err)
 		}
 	}
+}
 
-	if a.server != nil {
-		return a.server.Shutdown(ctx)
+// closeContainer closes the services of the container.
+func (a *App) closeContainer(ctx context.Context) {
+	if err := a.container.Close(ctx); err != nil {
+		a.logAt(slog.LevelError, "closing services failed", "error", err)
 	}
-	return nil
 }
 
 // DefaultConfig returns the default configuration.
@@ -404,5 +728,9 @@ func DefaultConfig() *Config {
 		WriteTimeout:    30 * time.Second,
 		IdleTimeout:     120 * time.Second,
 		ShutdownTimeout: 30 * time.Second,
+
+		ReadHeaderTimeout: 10 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		TrustedProxies:    append([]string(nil), defaultTrustedProxies...),
 	}
 }
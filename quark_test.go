@@ -0,0 +1,234 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppShutdownDrainsBeforeHooks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DrainDelay = 20 * time.Millisecond
+	app := New(WithConfig(cfg))
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	app.GET("/slow", func(c *Context) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return c.NoContent()
+	})
+
+	var finishedBeforeHooks, readyDuringHooks bool
+	app.OnShutdown(func(a *App) error {
+		finishedBeforeHooks = finished.Load()
+		readyDuringHooks = a.HealthChecks().Ready(context.Background()).Status == HealthUp
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.server = &http.Server{Handler: app}
+	go app.server.Serve(l)
+
+	go http.Get("http://" + l.Addr().String() + "/slow")
+	<-started
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: unexpected error: %v", err)
+	}
+	if !finishedBeforeHooks {
+		t.Error("Shutdown: expected in-flight request completed before OnShutdown hooks")
+	}
+	if readyDuringHooks {
+		t.Error("Shutdown: expected readiness failing while shutting down")
+	}
+}
+
+func TestAppGracefulShutdownTimeoutClosesContainer(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ShutdownTimeout = 20 * time.Millisecond
+	app := New(WithConfig(cfg), WithLogger(&printfLogger{}))
+
+	worker := &ctxErrRecorder{}
+	app.Container().Register("worker", func(c *Container) (interface{}, error) {
+		return worker, nil
+	})
+	app.Container().Get("worker")
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	app.GET("/stuck", func(c *Context) error {
+		close(started)
+		<-release
+		return c.NoContent()
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.server = &http.Server{Handler: app}
+	go app.server.Serve(l)
+
+	go http.Get("http://" + l.Addr().String() + "/stuck")
+	<-started
+
+	app.gracefulShutdown()
+	if !worker.called {
+		t.Fatal("expected the container closed after a timed out shutdown")
+	}
+	if worker.err != nil {
+		t.Errorf("expected a live context for closing services, got %v", worker.err)
+	}
+}
+
+// ctxErrRecorder records the state of the context it is shut down with.
+type ctxErrRecorder struct {
+	called bool
+	err    error
+}
+
+func (r *ctxErrRecorder) Shutdown(ctx context.Context) error {
+	r.called, r.err = true, ctx.Err()
+	return nil
+}
+
+func TestAppRunContext(t *testing.T) {
+	app := New(WithLogger(&printfLogger{}))
+
+	var stopped bool
+	app.OnShutdown(func(a *App) error {
+		stopped = true
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- app.RunContext(ctx, "127.0.0.1:0") }()
+
+	cancel()
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Fatalf("RunContext: unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext: expected return after cancellation")
+	}
+	if !stopped {
+		t.Error("RunContext: expected OnShutdown hooks run")
+	}
+
+	if err := New(WithLogger(&printfLogger{})).RunContext(context.Background(), "invalid-address"); err == nil {
+		t.Error("RunContext: expected error for invalid address")
+	}
+}
+
+func TestAppPrepareServerConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReadHeaderTimeout = 3 * time.Second
+	cfg.MaxHeaderBytes = 4096
+
+	var configured *http.Server
+	app := New(WithConfig(cfg), WithLogger(&printfLogger{}), WithServerConfigurer(func(s *http.Server) {
+		configured = s
+		s.IdleTimeout = time.Second
+	}))
+	if err := app.prepare(":0"); err != nil {
+		t.Fatalf("prepare: unexpected error: %v", err)
+	}
+
+	if configured != app.server {
+		t.Error("WithServerConfigurer: expected configurer called with the server")
+	}
+	if app.server.ReadHeaderTimeout != 3*time.Second || app.server.MaxHeaderBytes != 4096 {
+		t.Errorf("prepare: unexpected server settings %v, %d", app.server.ReadHeaderTimeout, app.server.MaxHeaderBytes)
+	}
+	if app.server.IdleTimeout != time.Second {
+		t.Errorf("WithServerConfigurer: expected override of Config, got %v", app.server.IdleTimeout)
+	}
+}
+
+func TestAppTest(t *testing.T) {
+	app := New()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Middleware", "yes")
+			return next(c)
+		}
+	})
+	app.GET("/users/{id}", func(c *Context) error {
+		return c.JSON(http.StatusOK, M{"id": c.Param("id")})
+	})
+	app.GET("/slow", func(c *Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.NoContent()
+	})
+	app.GET("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	if err != nil {
+		t.Fatalf("Test: unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Middleware") != "yes" || !strings.Contains(string(body), `"id":"7"`) {
+		t.Errorf("Test: unexpected response %d %v %s", resp.StatusCode, resp.Header, body)
+	}
+
+	// Later requests reuse pooled contexts without touching earlier responses
+	app.Test(httptest.NewRequest(http.MethodGet, "/users/8", nil))
+	if !strings.Contains(string(body), `"id":"7"`) {
+		t.Errorf("Test: response changed by a later request: %s", body)
+	}
+
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), 10*time.Millisecond); err == nil {
+		t.Error("Test: expected timeout error")
+	}
+
+	// The handler sees the request cancelled on timeout
+	cancelled := make(chan error, 1)
+	app.GET("/blocked", func(c *Context) error {
+		<-c.Context().Done()
+		cancelled <- c.Context().Err()
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/blocked", nil), 10*time.Millisecond); err == nil {
+		t.Error("Test: expected timeout error")
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Test: expected the deadline exceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Test: expected the request context cancelled on timeout")
+	}
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), 0); err != nil {
+		t.Errorf("Test: unexpected error without timeout: %v", err)
+	}
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/panic", nil)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Test: expected panic error, got %v", err)
+	}
+}
+
+func TestImportLeavesDefaultServeMuxEmpty(t *testing.T) {
+	for _, path := range []string{"/debug/pprof/", "/debug/vars"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if _, pattern := http.DefaultServeMux.Handler(req); pattern != "" {
+			t.Errorf("expected no handler for %s on http.DefaultServeMux, got %q", path, pattern)
+		}
+	}
+}
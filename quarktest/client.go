@@ -0,0 +1,181 @@
+// Package quarktest provides a fluent client for testing Quark handlers.
+// Requests run in-memory through the full middleware and router pipeline,
+// and responses are checked with chained assertions that report failures
+// on the test, or against snapshot files with MatchSnapshot.
+//
+// Basic usage:
+//
+//	func TestUsers(t *testing.T) {
+//	    client := quarktest.New(app)
+//
+//	    client.GET("/users/1").
+//	        WithHeader("Authorization", "Bearer "+token).
+//	        Expect(t).
+//	        Status(200).
+//	        JSONPath("$.name", "John")
+//
+//	    client.POST("/users").
+//	        WithJSON(quark.M{"name": "Jane"}).
+//	        Expect(t).
+//	        Status(201).
+//	        JSONPath("$.id", 2)
+//	}
+package quarktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Client sends test requests to a handler, usually a *quark.App.
+type Client struct {
+	handler http.Handler
+	header  http.Header
+}
+
+// New creates a client sending requests to handler.
+func New(handler http.Handler) *Client {
+	return &Client{handler: handler, header: make(http.Header)}
+}
+
+// WithHeader sets a header sent with every request of the client.
+func (c *Client) WithHeader(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+// GET starts a GET request.
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// POST starts a POST request.
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT starts a PUT request.
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH starts a PATCH request.
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE starts a DELETE request.
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// Request starts a request with the given method.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: c.header.Clone(),
+		query:  make(url.Values),
+	}
+}
+
+// Request is a test request being built.
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    []byte
+	err     error
+}
+
+// WithHeader sets a request header.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie adds a cookie.
+func (r *Request) WithCookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+	return r
+}
+
+// WithJSON sets the body to v encoded as JSON.
+func (r *Request) WithJSON(v interface{}) *Request {
+	r.body, r.err = json.Marshal(v)
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// WithForm sets the body to URL-encoded form values.
+func (r *Request) WithForm(values url.Values) *Request {
+	r.body = []byte(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// WithBody sets a raw body with its content type.
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.body = body
+	r.header.Set("Content-Type", contentType)
+	return r
+}
+
+// Do sends the request and returns the recorded response.
+func (r *Request) Do() (*httptest.ResponseRecorder, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(rec, req)
+	return rec, nil
+}
+
+// Expect sends the request and returns its response for assertions,
+// failing the test if the request cannot be built.
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+	rec, err := r.Do()
+	if err != nil {
+		t.Fatalf("%s %s: %v", r.method, r.path, err)
+	}
+	return &Response{t: t, name: r.method + " " + r.path, Recorder: rec}
+}
@@ -0,0 +1,102 @@
+package quarktest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// recordingT records assertion failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+	t.fatal = true
+}
+
+// newEchoApp returns an app describing the requests it receives.
+func newEchoApp() *quark.App {
+	app := quark.New()
+	echo := func(c *quark.Context) error {
+		resp := quark.M{
+			"method": c.Method(),
+			"query":  c.Request.URL.RawQuery,
+			"auth":   c.Header("Authorization"),
+			"type":   c.Header("Content-Type"),
+		}
+		if cookie, err := c.Request.Cookie("session"); err == nil {
+			resp["session"] = cookie.Value
+		}
+		if c.Request.ContentLength > 0 {
+			if c.ContentType() == "application/x-www-form-urlencoded" {
+				resp["name"] = c.Request.FormValue("name")
+			} else {
+				var body quark.M
+				if err := c.Bind(&body); err != nil {
+					return err
+				}
+				resp["body"] = body
+			}
+		}
+		return c.JSON(http.StatusOK, resp)
+	}
+	app.Any("/echo", echo)
+	return app
+}
+
+func TestClientRequests(t *testing.T) {
+	client := New(newEchoApp()).WithHeader("Authorization", "Bearer token")
+
+	tests := []struct {
+		name    string
+		request *Request
+		path    string
+		want    interface{}
+	}{
+		{"GET", client.GET("/echo"), "$.method", "GET"},
+		{"POST", client.POST("/echo"), "$.method", "POST"},
+		{"PUT", client.PUT("/echo"), "$.method", "PUT"},
+		{"PATCH", client.PATCH("/echo"), "$.method", "PATCH"},
+		{"DELETE", client.DELETE("/echo"), "$.method", "DELETE"},
+		{"client header", client.GET("/echo"), "$.auth", "Bearer token"},
+		{"request header", client.GET("/echo").WithHeader("Authorization", "Basic abc"), "$.auth", "Basic abc"},
+		{"query", client.GET("/echo").WithQuery("page", "2"), "$.query", "page=2"},
+		{"query appended", client.GET("/echo?sort=name").WithQuery("page", "2"), "$.query", "sort=name&page=2"},
+		{"cookie", client.GET("/echo").WithCookie("session", "abc123"), "$.session", "abc123"},
+		{"JSON body", client.POST("/echo").WithJSON(quark.M{"name": "Jane"}), "$.body.name", "Jane"},
+		{"form body", client.POST("/echo").WithForm(url.Values{"name": {"Jane"}}), "$.name", "Jane"},
+		{"raw body", client.POST("/echo").WithBody("application/json", []byte(`{"id":7}`)), "$.body.id", 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.Expect(t).Status(http.StatusOK).JSONPath(tt.path, tt.want)
+		})
+	}
+}
+
+func TestClientHeaderNotShared(t *testing.T) {
+	client := New(newEchoApp())
+	client.GET("/echo").WithHeader("Authorization", "Bearer once")
+	client.GET("/echo").Expect(t).JSONPath("$.auth", "")
+}
+
+func TestExpectInvalidJSON(t *testing.T) {
+	rt := &recordingT{}
+	New(newEchoApp()).POST("/echo").WithJSON(make(chan int)).Expect(rt)
+	if !rt.fatal {
+		t.Error("expected the unencodable body to fail the test")
+	}
+}
@@ -0,0 +1,200 @@
+package quarktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response is a recorded response with chained assertions. Failed
+// assertions are reported with t.Errorf, so every assertion of a chain
+// runs.
+type Response struct {
+	// Recorder holds the raw response.
+	Recorder *httptest.ResponseRecorder
+
+	t    testing.TB
+	name string
+	json interface{} // decoded body, cached by decodeJSON
+}
+
+// Status asserts the response status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("%s: expected status %d, got %d: %s", r.name, code, r.Recorder.Code, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Header asserts a response header value.
+func (r *Response) Header(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get(key); got != want {
+		r.t.Errorf("%s: expected header %s %q, got %q", r.name, key, want, got)
+	}
+	return r
+}
+
+// Body asserts the whole response body.
+func (r *Response) Body(want string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Body.String(); got != want {
+		r.t.Errorf("%s: expected body %q, got %q", r.name, want, got)
+	}
+	return r
+}
+
+// BodyContains asserts that the response body contains substr.
+func (r *Response) BodyContains(substr string) *Response {
+	r.t.Helper()
+	if !strings.Contains(r.Recorder.Body.String(), substr) {
+		r.t.Errorf("%s: expected body containing %q, got %q", r.name, substr, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// JSON asserts that the body is JSON equal to want, which is encoded to
+// JSON for the comparison, so structs, maps and raw strings all work.
+func (r *Response) JSON(want interface{}) *Response {
+	r.t.Helper()
+	got, ok := r.decodeJSON()
+	if !ok {
+		return r
+	}
+	if !jsonEqual(got, want) {
+		r.t.Errorf("%s: expected JSON %s, got %s", r.name, jsonString(want), r.Recorder.Body.String())
+	}
+	return r
+}
+
+// JSONPath asserts the value at a path of the JSON body. Paths start at
+// the root "$" and select object fields with ".name" and array elements
+// with "[index]", e.g. "$.users[0].email".
+func (r *Response) JSONPath(path string, want interface{}) *Response {
+	r.t.Helper()
+	root, ok := r.decodeJSON()
+	if !ok {
+		return r
+	}
+	got, err := lookupPath(root, path)
+	if err != nil {
+		r.t.Errorf("%s: JSON path %s: %v in %s", r.name, path, err, r.Recorder.Body.String())
+		return r
+	}
+	if !jsonEqual(got, want) {
+		r.t.Errorf("%s: expected %s at %s, got %s", r.name, jsonString(want), path, jsonString(got))
+	}
+	return r
+}
+
+// Decode decodes the JSON body into v for custom assertions.
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), v); err != nil {
+		r.t.Errorf("%s: invalid JSON body: %v: %s", r.name, err, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// decodeJSON decodes the body once, reporting invalid JSON.
+func (r *Response) decodeJSON() (interface{}, bool) {
+	r.t.Helper()
+	if r.json != nil {
+		return r.json, true
+	}
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), &r.json); err != nil {
+		r.t.Errorf("%s: invalid JSON body: %v: %s", r.name, err, r.Recorder.Body.String())
+		return nil, false
+	}
+	return r.json, true
+}
+
+// lookupPath returns the value at a JSON path of a decoded document.
+func lookupPath(v interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			rest = rest[end+1:]
+
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not an object", key)
+			}
+			if v, ok = obj[key]; !ok {
+				return nil, fmt.Errorf("field %q not found", key)
+			}
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed [")
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", rest[1:end])
+			}
+			rest = rest[end+1:]
+
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("[%d] is not an array", index)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range (length %d)", index, len(arr))
+			}
+			v = arr[index]
+
+		default:
+			return nil, fmt.Errorf("unexpected %q", rest)
+		}
+	}
+	return v, nil
+}
+
+// jsonEqual compares a decoded JSON value with any value by their JSON
+// encodings. Strings holding JSON documents are compared as documents.
+func jsonEqual(got, want interface{}) bool {
+	var normalized interface{}
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	if reflect.DeepEqual(got, normalized) {
+		return true
+	}
+
+	// A raw JSON document, e.g. `{"id":1}`
+	if s, ok := want.(string); ok {
+		var doc interface{}
+		if json.Unmarshal([]byte(s), &doc) == nil {
+			return reflect.DeepEqual(got, doc)
+		}
+	}
+	return false
+}
+
+// jsonString formats a value as JSON for failure messages.
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
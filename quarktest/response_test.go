@@ -0,0 +1,99 @@
+package quarktest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AchrafSoltani/quark"
+)
+
+// newUsersApp returns an app serving a fixed list of users.
+func newUsersApp() *quark.App {
+	app := quark.New()
+	app.GET("/users", func(c *quark.Context) error {
+		c.SetHeader("X-Total-Count", "2")
+		return c.JSON(http.StatusOK, quark.M{
+			"users": []quark.M{
+				{"id": 1, "name": "John", "email": "john@example.com"},
+				{"id": 2, "name": "Jane", "email": "jane@example.com"},
+			},
+		})
+	})
+	app.GET("/text", func(c *quark.Context) error {
+		return c.String(http.StatusOK, "hello world")
+	})
+	return app
+}
+
+func TestResponseAssertions(t *testing.T) {
+	client := New(newUsersApp())
+
+	tests := []struct {
+		name      string
+		path      string
+		assert    func(*Response)
+		wantError string
+	}{
+		{"status", "/users", func(r *Response) { r.Status(http.StatusOK) }, ""},
+		{"wrong status", "/users", func(r *Response) { r.Status(http.StatusCreated) }, "expected status 201, got 200"},
+		{"header", "/users", func(r *Response) { r.Header("X-Total-Count", "2") }, ""},
+		{"wrong header", "/users", func(r *Response) { r.Header("X-Total-Count", "3") }, `expected header X-Total-Count "3", got "2"`},
+		{"body", "/text", func(r *Response) { r.Body("hello world") }, ""},
+		{"wrong body", "/text", func(r *Response) { r.Body("hello") }, `expected body "hello"`},
+		{"body contains", "/text", func(r *Response) { r.BodyContains("world") }, ""},
+		{"body missing", "/text", func(r *Response) { r.BodyContains("moon") }, `expected body containing "moon"`},
+		{"JSON path", "/users", func(r *Response) { r.JSONPath("$.users[1].name", "Jane") }, ""},
+		{"JSON path number", "/users", func(r *Response) { r.JSONPath("$.users[0].id", 1) }, ""},
+		{"JSON path object", "/users", func(r *Response) { r.JSONPath("$.users[0]", `{"id":1,"name":"John","email":"john@example.com"}`) }, ""},
+		{"JSON path mismatch", "/users", func(r *Response) { r.JSONPath("$.users[0].name", "Jane") }, `expected "Jane" at $.users[0].name, got "John"`},
+		{"JSON path missing field", "/users", func(r *Response) { r.JSONPath("$.users[0].age", 30) }, `field "age" not found`},
+		{"JSON path out of range", "/users", func(r *Response) { r.JSONPath("$.users[5]", nil) }, "index 5 out of range (length 2)"},
+		{"JSON path not array", "/users", func(r *Response) { r.JSONPath("$.users[0].name[0]", nil) }, "[0] is not an array"},
+		{"JSON path without root", "/users", func(r *Response) { r.JSONPath("users", nil) }, "path must start with $"},
+		{"JSON", "/users", func(r *Response) {
+			r.JSON(quark.M{"users": []quark.M{
+				{"id": 1, "name": "John", "email": "john@example.com"},
+				{"id": 2, "name": "Jane", "email": "jane@example.com"},
+			}})
+		}, ""},
+		{"JSON mismatch", "/users", func(r *Response) { r.JSON(quark.M{"users": []quark.M{}}) }, `expected JSON {"users":[]}`},
+		{"JSON of text", "/text", func(r *Response) { r.JSONPath("$.name", "John") }, "invalid JSON body"},
+		{"chain runs every assertion", "/users", func(r *Response) { r.Status(http.StatusCreated).JSONPath("$.users[0].name", "Jane") }, `expected "Jane"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{}
+			tt.assert(client.GET(tt.path).Expect(rt))
+
+			if tt.wantError == "" {
+				if len(rt.errors) > 0 {
+					t.Errorf("expected no failure, got %v", rt.errors)
+				}
+				return
+			}
+			found := false
+			for _, msg := range rt.errors {
+				if strings.Contains(msg, tt.wantError) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a failure containing %q, got %v", tt.wantError, rt.errors)
+			}
+		})
+	}
+}
+
+func TestResponseDecode(t *testing.T) {
+	var resp struct {
+		Users []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"users"`
+	}
+	New(newUsersApp()).GET("/users").Expect(t).Decode(&resp)
+	if len(resp.Users) != 2 || resp.Users[1].Name != "Jane" {
+		t.Errorf("unexpected users %+v", resp.Users)
+	}
+}
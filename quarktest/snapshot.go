@@ -0,0 +1,226 @@
+package quarktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// Tests may define their own -update flag; snapshots honor it too.
+	if flag.Lookup("update") == nil {
+		flag.Bool("update", false, "update quarktest snapshot files")
+	}
+}
+
+// updating reports whether the tests run with -update.
+func updating() bool {
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+// SnapshotOption normalizes a response body before it is compared with its
+// snapshot, so values changing on every run do not fail the comparison.
+type SnapshotOption func(*snapshotConfig)
+
+// snapshotConfig holds the normalizations of a snapshot comparison.
+type snapshotConfig struct {
+	fields     map[string]bool
+	replacers  []valueReplacer
+	normalizer []func(interface{}) interface{}
+}
+
+// valueReplacer replaces string values matching a pattern.
+type valueReplacer struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// IgnoreFields replaces the values of object fields with these names, at
+// any depth, by "<name>".
+func IgnoreFields(names ...string) SnapshotOption {
+	return func(cfg *snapshotConfig) {
+		for _, name := range names {
+			cfg.fields[name] = true
+		}
+	}
+}
+
+// ReplaceValues replaces the matches of pattern in string values by
+// placeholder.
+func ReplaceValues(pattern *regexp.Regexp, placeholder string) SnapshotOption {
+	return func(cfg *snapshotConfig) {
+		cfg.replacers = append(cfg.replacers, valueReplacer{pattern, placeholder})
+	}
+}
+
+// timestampPattern matches RFC 3339 timestamps.
+var timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// uuidPattern matches UUIDs.
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// ReplaceTimestamps replaces RFC 3339 timestamps by "<timestamp>".
+func ReplaceTimestamps() SnapshotOption {
+	return ReplaceValues(timestampPattern, "<timestamp>")
+}
+
+// ReplaceUUIDs replaces UUIDs by "<uuid>".
+func ReplaceUUIDs() SnapshotOption {
+	return ReplaceValues(uuidPattern, "<uuid>")
+}
+
+// Normalize applies fn to the decoded JSON body, for normalizations the
+// other options do not cover, such as sorting an unordered list.
+func Normalize(fn func(v interface{}) interface{}) SnapshotOption {
+	return func(cfg *snapshotConfig) {
+		cfg.normalizer = append(cfg.normalizer, fn)
+	}
+}
+
+// MatchSnapshot compares a response body with the snapshot file at path,
+// failing the test on differences. JSON bodies are normalized by the
+// options and indented; other bodies are compared as text. Running the
+// tests with -update writes the snapshots instead.
+//
+// Example:
+//
+//	resp, _ := app.Test(httptest.NewRequest("GET", "/users", nil))
+//	quarktest.MatchSnapshot(t, resp, "testdata/users_index.json",
+//	    quarktest.IgnoreFields("id"), quarktest.ReplaceTimestamps())
+//
+//	// go test ./... -update
+func MatchSnapshot(t testing.TB, resp *http.Response, path string, opts ...SnapshotOption) {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("snapshot %s: reading body: %v", path, err)
+	}
+	// Leave the body readable for further assertions
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	matchSnapshot(t, body, path, opts)
+}
+
+// MatchSnapshot compares the response body with the snapshot file at path,
+// like the MatchSnapshot function.
+func (r *Response) MatchSnapshot(path string, opts ...SnapshotOption) *Response {
+	r.t.Helper()
+	matchSnapshot(r.t, r.Recorder.Body.Bytes(), path, opts)
+	return r
+}
+
+// matchSnapshot compares a normalized body with its snapshot file.
+func matchSnapshot(t testing.TB, body []byte, path string, opts []SnapshotOption) {
+	t.Helper()
+	cfg := &snapshotConfig{fields: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	got, err := cfg.normalize(body)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", path, err)
+	}
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("snapshot %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("snapshot %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Errorf("snapshot %s does not exist, run the tests with -update to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("snapshot %s does not match (run the tests with -update to accept the changes):\n%s",
+			path, firstDifference(string(want), string(got)))
+	}
+}
+
+// normalize applies the options to a body. JSON bodies are indented, with
+// object keys sorted.
+func (cfg *snapshotConfig) normalize(body []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		text := string(body)
+		for _, r := range cfg.replacers {
+			text = r.pattern.ReplaceAllString(text, r.placeholder)
+		}
+		return []byte(text), nil
+	}
+
+	doc = cfg.normalizeValue(doc)
+	for _, fn := range cfg.normalizer {
+		doc = fn(doc)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeValue replaces ignored fields and matching string values.
+func (cfg *snapshotConfig) normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if cfg.fields[key] {
+				v[key] = "<" + key + ">"
+			} else {
+				v[key] = cfg.normalizeValue(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = cfg.normalizeValue(value)
+		}
+	case string:
+		for _, r := range cfg.replacers {
+			v = r.pattern.ReplaceAllString(v, r.placeholder)
+		}
+		return v
+	}
+	return v
+}
+
+// firstDifference describes the first differing line of two texts.
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package quarktest
+
+import (
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		opts []SnapshotOption
+		want string
+	}{
+		{
+			name: "indented with sorted keys",
+			body: `{"name":"John","id":1}`,
+			want: "{\n  \"id\": 1,\n  \"name\": \"John\"\n}\n",
+		},
+		{
+			name: "ignored fields at any depth",
+			body: `{"id":1,"owner":{"id":2,"name":"Jane"}}`,
+			opts: []SnapshotOption{IgnoreFields("id")},
+			want: "{\n  \"id\": \"<id>\",\n  \"owner\": {\n    \"id\": \"<id>\",\n    \"name\": \"Jane\"\n  }\n}\n",
+		},
+		{
+			name: "timestamps and UUIDs",
+			body: `{"created":"2026-10-16T09:30:00.123Z","ref":"order 123e4567-e89b-12d3-a456-426614174000"}`,
+			opts: []SnapshotOption{ReplaceTimestamps(), ReplaceUUIDs()},
+			want: "{\n  \"created\": \"<timestamp>\",\n  \"ref\": \"order <uuid>\"\n}\n",
+		},
+		{
+			name: "custom pattern in text",
+			body: "request took 42ms",
+			opts: []SnapshotOption{ReplaceValues(regexp.MustCompile(`\d+ms`), "<duration>")},
+			want: "request took <duration>",
+		},
+		{
+			name: "normalize",
+			body: `["b","c","a"]`,
+			opts: []SnapshotOption{Normalize(func(v interface{}) interface{} {
+				list := v.([]interface{})
+				sort.Slice(list, func(i, j int) bool { return list[i].(string) < list[j].(string) })
+				return list
+			})},
+			want: "[\n  \"a\",\n  \"b\",\n  \"c\"\n]\n",
+		},
+		{
+			name: "HTML not escaped",
+			body: `{"html":"<b>bold</b>"}`,
+			want: "{\n  \"html\": \"<b>bold</b>\"\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &snapshotConfig{fields: make(map[string]bool)}
+			for _, opt := range tt.opts {
+				opt(cfg)
+			}
+			got, err := cfg.normalize([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte("{\n  \"id\": \"<id>\",\n  \"name\": \"John\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		body      string
+		wantError string
+	}{
+		{"matching", path, `{"id":41,"name":"John"}`, ""},
+		{"different", path, `{"id":41,"name":"Jane"}`, "line 3:\n  want:   \"name\": \"John\"\n  got:    \"name\": \"Jane\""},
+		{"missing", filepath.Join(t.TempDir(), "missing.json"), `{}`, "run the tests with -update to create it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Content-Type", "application/json")
+			rec.WriteString(tt.body)
+
+			rt := &recordingT{}
+			MatchSnapshot(rt, rec.Result(), tt.path, IgnoreFields("id"))
+
+			if tt.wantError == "" {
+				if len(rt.errors) > 0 {
+					t.Errorf("expected no failure, got %v", rt.errors)
+				}
+				return
+			}
+			if len(rt.errors) != 1 || !strings.Contains(rt.errors[0], tt.wantError) {
+				t.Errorf("expected a failure containing %q, got %v", tt.wantError, rt.errors)
+			}
+		})
+	}
+}
+
+func TestMatchSnapshotKeepsBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text.txt")
+	os.WriteFile(path, []byte("hello"), 0o644)
+
+	rec := httptest.NewRecorder()
+	rec.WriteString("hello")
+	resp := rec.Result()
+	MatchSnapshot(t, resp, path)
+
+	rec2 := httptest.NewRecorder()
+	if _, err := rec2.Body.ReadFrom(resp.Body); err != nil || rec2.Body.String() != "hello" {
+		t.Errorf("expected the body to stay readable, got %q (%v)", rec2.Body.String(), err)
+	}
+}
+
+func TestMatchSnapshotUpdate(t *testing.T) {
+	if err := flag.Set("update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("update", "false")
+
+	path := filepath.Join(t.TempDir(), "nested", "created.json")
+	rec := httptest.NewRecorder()
+	rec.WriteString(`{"ok":true}`)
+	MatchSnapshot(t, rec.Result(), path)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the snapshot to be written: %v", err)
+	}
+	if string(got) != "{\n  \"ok\": true\n}\n" {
+		t.Errorf("unexpected snapshot %q", got)
+	}
+}
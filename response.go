@@ -1,39 +1,97 @@
 package quark
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
 )
 
 // M is a shorthand for map[string]interface{}.
 type M map[string]interface{}
 
-// JSON sends a JSON response with the given status code.
+// JSON sends a JSON response with the given status code, encoded with the
+// App's JSON codec.
 func (c *Context) JSON(code int, data interface{}) error {
-	c.SetHeader("Content-Type", "application/json; charset=utf-8")
-	c.Writer.WriteHeader(code)
-	c.markWritten()
+	return c.writeJSON(code, data, "")
+}
 
+// JSONPretty sends a formatted JSON response.
+func (c *Context) JSONPretty(code int, data interface{}, indent string) error {
+	return c.writeJSON(code, data, indent)
+}
+
+// writeJSON encodes data, indented when indent is set, and sends it. The
+// data is encoded before the status is written, so encoding errors can
+// still be reported.
+func (c *Context) writeJSON(code int, data interface{}, indent string) error {
+	c.SetHeader("Content-Type", "application/json; charset=utf-8")
 	if data == nil {
+		c.Writer.WriteHeader(code)
+		c.markWritten()
 		return nil
 	}
 
-	return json.NewEncoder(c.Writer).Encode(data)
-}
+	body, err := c.jsonCodec().Marshal(data)
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "failed to encode response", err)
+	}
+	if indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", indent); err != nil {
+			return WrapError(http.StatusInternalServerError, "failed to encode response", err)
+		}
+		body = buf.Bytes()
+	}
 
-// JSONPretty sends a formatted JSON response.
-func (c *Context) JSONPretty(code int, data interface{}, indent string) error {
-	c.SetHeader("Content-Type", "application/json; charset=utf-8")
 	c.Writer.WriteHeader(code)
 	c.markWritten()
+	_, err = c.Writer.Write(append(body, '\n'))
+	return err
+}
 
-	if data == nil {
-		return nil
+// jsonpCallback matches safe JSONP callback names: JavaScript identifiers,
+// optionally dotted, such as "handle" or "app.api.done".
+var jsonpCallback = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+
+// JSONP sends data as a JSONP script calling callback, for legacy clients
+// that cannot use CORS. Callbacks are usually taken from the query string,
+// so names that are not plain (dotted) JavaScript identifiers of at most
+// 128 characters are rejected with a 400 error. An empty callback sends
+// plain JSON.
+//
+// Example:
+//
+//	return c.JSONP(200, c.Query("callback"), data)
+//	// /**/ typeof cb === 'function' && cb({"id":1});
+func (c *Context) JSONP(code int, callback string, data interface{}) error {
+	if callback == "" {
+		return c.JSON(code, data)
+	}
+	if len(callback) > 128 || !jsonpCallback.MatchString(callback) {
+		return ErrBadRequest("invalid JSONP callback")
+	}
+
+	body, err := c.jsonCodec().Marshal(data)
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "failed to encode response", err)
 	}
 
-	enc := json.NewEncoder(c.Writer)
-	enc.SetIndent("", indent)
-	return enc.Encode(data)
+	c.SetHeader("Content-Type", "text/javascript; charset=utf-8")
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	c.Writer.WriteHeader(code)
+	c.markWritten()
+
+	// The leading comment defeats content sniffing attacks such as Rosetta
+	// Flash. U+2028 and U+2029, which encoding/json escapes, are also
+	// escaped for other codecs since they end JavaScript strings in older
+	// engines.
+	body = bytes.ReplaceAll(body, []byte("\u2028"), []byte(`\u2028`))
+	body = bytes.ReplaceAll(body, []byte("\u2029"), []byte(`\u2029`))
+	_, err = fmt.Fprintf(c.Writer, "/**/ typeof %s === 'function' && %s(%s);", callback, callback, body)
+	return err
 }
 
 // PaginatedResponse represents a paginated API response.
@@ -90,6 +148,44 @@ func (c *Context) HTML(code int, html string) error {
 	return err
 }
 
+// Render renders a template with the application's renderer and sends the
+// result as an HTML response, or with the Content-Type already set by the
+// handler, e.g. for plain text templates. The template is rendered before
+// anything is written, so a rendering error can still produce an error
+// response.
+//
+// Example:
+//
+//	app.SetRenderer(engine)
+//
+//	app.GET("/users/{id}", func(c *quark.Context) error {
+//	    return c.Render(200, "users/show", quark.M{"user": user})
+//	})
+func (c *Context) Render(code int, name string, data interface{}) error {
+	if c.app == nil || c.app.renderer == nil {
+		return ErrInternal("no renderer configured")
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if r, ok := c.app.renderer.(ContextRenderer); ok {
+		err = r.RenderContext(c, &buf, name, data)
+	} else {
+		err = c.app.renderer.Render(&buf, name, data)
+	}
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "template rendering failed", err)
+	}
+
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.SetHeader("Content-Type", "text/html; charset=utf-8")
+	}
+	c.Writer.WriteHeader(code)
+	c.markWritten()
+	_, err = c.Writer.Write(buf.Bytes())
+	return err
+}
+
 // Blob sends a binary response.
 func (c *Context) Blob(code int, contentType string, data []byte) error {
 	c.SetHeader("Content-Type", contentType)
@@ -117,6 +213,57 @@ func (c *Context) Redirect(code int, url string) error {
 	return nil
 }
 
+// RedirectPermanent redirects to url with 301 Moved Permanently.
+func (c *Context) RedirectPermanent(url string) error {
+	return c.Redirect(http.StatusMovedPermanently, url)
+}
+
+// RedirectTemporary redirects to url with 302 Found. Browsers follow it
+// with a GET, so it also suits redirects after a form POST.
+func (c *Context) RedirectTemporary(url string) error {
+	return c.Redirect(http.StatusFound, url)
+}
+
+// Back redirects to the page the request came from, given by the Referer
+// header, or to fallback when there is none. Referers on other hosts are
+// ignored, so Back cannot be used as an open redirect.
+//
+// Example:
+//
+//	app.POST("/cart/items", func(c *quark.Context) error {
+//	    // Add the item...
+//	    return c.Back("/cart")
+//	})
+func (c *Context) Back(fallback string) error {
+	if referer, err := url.Parse(c.Header("Referer")); err == nil && referer.Host == c.Request.Host &&
+		(referer.Scheme == "http" || referer.Scheme == "https") {
+		return c.RedirectTemporary(referer.String())
+	}
+	return c.RedirectTemporary(fallback)
+}
+
+// RedirectToRoute redirects with 302 Found to the route registered with
+// name, filling its path parameters from params, see Route.Path.
+//
+// Example:
+//
+//	app.GET("/users/{id}", showUser).Name("users.show")
+//
+//	app.POST("/users", func(c *quark.Context) error {
+//	    user := createUser(c)
+//	    return c.RedirectToRoute("users.show", quark.M{"id": user.ID})
+//	})
+func (c *Context) RedirectToRoute(name string, params M) error {
+	if c.app == nil {
+		return ErrInternal("redirect to route " + name + " without an App")
+	}
+	path, err := c.app.RoutePath(name, params)
+	if err != nil {
+		return WrapError(http.StatusInternalServerError, "failed to build redirect URL", err)
+	}
+	return c.RedirectTemporary(path)
+}
+
 // Created sends a 201 Created response with the given data.
 func (c *Context) Created(data interface{}) error {
 	return c.JSON(http.StatusCreated, data)
@@ -2,8 +2,12 @@ package quark
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -33,6 +37,46 @@ func TestContextJSON(t *testing.T) {
 	}
 }
 
+func TestContextJSONP(t *testing.T) {
+	t.Run("callback", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := &Context{Writer: rec}
+
+		if err := c.JSONP(http.StatusOK, "app.done", M{"text": "a\u2028b"}); err != nil {
+			t.Fatalf("JSONP: unexpected error: %v", err)
+		}
+		want := `/**/ typeof app.done === 'function' && app.done({"text":"a\u2028b"});`
+		if rec.Body.String() != want {
+			t.Errorf("JSONP: expected %s, got %s", want, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+			t.Errorf("JSONP: expected text/javascript, got %s", ct)
+		}
+	})
+
+	t.Run("no callback", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := &Context{Writer: rec}
+
+		if err := c.JSONP(http.StatusOK, "", M{"id": 1}); err != nil {
+			t.Fatalf("JSONP: unexpected error: %v", err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("JSONP: expected plain JSON, got %s", ct)
+		}
+	})
+
+	for _, callback := range []string{"alert(1)//", "a b", "1cb", "cb;", "a..b", "cb.", strings.Repeat("a", 129)} {
+		rec := httptest.NewRecorder()
+		c := &Context{Writer: rec}
+
+		var he *HTTPError
+		if err := c.JSONP(http.StatusOK, callback, M{}); !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+			t.Errorf("JSONP: expected 400 for callback %q, got %v", callback, err)
+		}
+	}
+}
+
 func TestContextJSONPaginated(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := &Context{Writer: rec}
@@ -166,6 +210,69 @@ func TestContextRedirectInvalidCode(t *testing.T) {
 	}
 }
 
+func TestContextRedirectHelpers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := &Context{Writer: rec}
+	c.RedirectPermanent("/moved")
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/moved" {
+		t.Errorf("RedirectPermanent: got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	c = &Context{Writer: rec}
+	c.RedirectTemporary("/later")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/later" {
+		t.Errorf("RedirectTemporary: got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestContextBack(t *testing.T) {
+	tests := []struct {
+		referer string
+		want    string
+	}{
+		{"http://example.com/cart?page=2", "http://example.com/cart?page=2"},
+		{"", "/home"},
+		{"https://evil.com/phish", "/home"},
+		{"javascript://example.com/%0aalert(1)", "/home"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/cart/items", nil)
+		req.Header.Set("Referer", tt.referer)
+		rec := httptest.NewRecorder()
+		c := &Context{Request: req, Writer: rec}
+
+		c.Back("/home")
+		if loc := rec.Header().Get("Location"); rec.Code != http.StatusFound || loc != tt.want {
+			t.Errorf("Back with referer %q: expected 302 to %s, got %d to %s", tt.referer, tt.want, rec.Code, loc)
+		}
+	}
+}
+
+func TestContextRedirectToRoute(t *testing.T) {
+	app := New()
+	app.GET("/users/{id:[0-9]+}", func(c *Context) error { return nil }).Name("users.show")
+	app.POST("/users", func(c *Context) error {
+		return c.RedirectToRoute("users.show", M{"id": 42, "tab": "posts"})
+	})
+	app.POST("/invalid", func(c *Context) error {
+		return c.RedirectToRoute("users.show", M{"id": "me"})
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if loc := rec.Header().Get("Location"); rec.Code != http.StatusFound || loc != "/users/42?tab=posts" {
+		t.Errorf("RedirectToRoute: expected 302 to /users/42?tab=posts, got %d to %s", rec.Code, loc)
+	}
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/invalid", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("RedirectToRoute: expected 500 for invalid parameters, got %d", rec.Code)
+	}
+}
+
 func TestContextErrorResponses(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -245,6 +352,99 @@ func TestContextBlob(t *testing.T) {
 	}
 }
 
+type stubRenderer struct{}
+
+func (stubRenderer) Render(w io.Writer, name string, data interface{}) error {
+	if name == "missing" {
+		return errors.New("template not found")
+	}
+	_, err := fmt.Fprintf(w, "<p>%s: %v</p>", name, data)
+	return err
+}
+
+func TestContextRender(t *testing.T) {
+	app := New()
+	app.SetRenderer(stubRenderer{})
+
+	rec := httptest.NewRecorder()
+	c := &Context{Writer: rec, app: app}
+
+	if err := c.Render(http.StatusCreated, "users/show", "alice"); err != nil {
+		t.Fatalf("Render: unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Render: expected status 201, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Render: expected content-type text/html, got %s", ct)
+	}
+	if body := rec.Body.String(); body != "<p>users/show: alice</p>" {
+		t.Errorf("Render: unexpected body %q", body)
+	}
+}
+
+type stubContextRenderer struct{ stubRenderer }
+
+func (stubContextRenderer) RenderContext(c *Context, w io.Writer, name string, data interface{}) error {
+	_, err := fmt.Fprintf(w, "<p>%s: %v</p>", name, c.Get("locale"))
+	return err
+}
+
+func TestContextRenderWithContextRenderer(t *testing.T) {
+	app := New()
+	app.SetRenderer(stubContextRenderer{})
+
+	rec := httptest.NewRecorder()
+	c := &Context{Writer: rec, app: app, store: map[string]interface{}{"locale": "fr"}}
+
+	if err := c.Render(http.StatusOK, "home", nil); err != nil {
+		t.Fatalf("Render: unexpected error: %v", err)
+	}
+	if body := rec.Body.String(); body != "<p>home: fr</p>" {
+		t.Errorf("Render: expected RenderContext to be used, got %q", body)
+	}
+}
+
+func TestContextRenderWithRendererFunc(t *testing.T) {
+	app := New(WithRenderer(RendererFunc(func(w io.Writer, name string, data interface{}) error {
+		_, err := fmt.Fprintf(w, "%s=%v", name, data)
+		return err
+	})))
+
+	rec := httptest.NewRecorder()
+	c := &Context{Writer: rec, app: app}
+	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
+
+	if err := c.Render(http.StatusOK, "greeting", "hi"); err != nil {
+		t.Fatalf("Render: unexpected error: %v", err)
+	}
+	if body := rec.Body.String(); body != "greeting=hi" {
+		t.Errorf("Render: unexpected body %q", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Render: expected preset content-type to be kept, got %s", ct)
+	}
+}
+
+func TestContextRenderErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := &Context{Writer: rec, app: New()}
+
+	err := c.Render(http.StatusOK, "home", nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusInternalServerError {
+		t.Errorf("Render: expected 500 without renderer, got %v", err)
+	}
+
+	c.app.SetRenderer(stubRenderer{})
+	if err := c.Render(http.StatusOK, "missing", nil); err == nil {
+		t.Error("Render: expected error for missing template")
+	}
+	if c.IsWritten() {
+		t.Error("Render: expected nothing written after a rendering error")
+	}
+}
+
 func TestContextIsWritten(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := &Context{Writer: rec}
@@ -18,6 +18,7 @@ type Route struct {
 	middleware []MiddlewareFunc
 	regex      *regexp.Regexp
 	paramNames []string
+	name       string
 }
 
 // Router is a regex-based HTTP router with path parameters.
@@ -25,6 +26,7 @@ type Router struct {
 	routes      []*Route
 	notFound    HandlerFunc
 	methodNotAllowed HandlerFunc
+	hooks       []func(*Route) // called for each registered route
 	mu          sync.RWMutex
 }
 
@@ -56,7 +58,9 @@ func (r *Router) SetMethodNotAllowed(h HandlerFunc) {
 //   - /users           - Exact match
 //   - /users/{id}      - Named parameter (matches anything except /)
 //   - /users/{id:[0-9]+} - Named parameter with regex constraint
-func (r *Router) Handle(method, pattern string, h HandlerFunc, middleware ...MiddlewareFunc) {
+//
+// The returned route can be named with Route.Name.
+func (r *Router) Handle(method, pattern string, h HandlerFunc, middleware ...MiddlewareFunc) *Route {
 	route := &Route{
 		method:     method,
 		pattern:    pattern,
@@ -69,7 +73,27 @@ func (r *Router) Handle(method, pattern string, h HandlerFunc, middleware ...Mid
 
 	r.mu.Lock()
 	r.routes = append(r.routes, route)
+	hooks := r.hooks
 	r.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(route)
+	}
+	return route
+}
+
+// onRegister adds a hook called for each registered route, replaying the
+// routes already registered.
+func (r *Router) onRegister(fn func(*Route)) {
+	r.mu.Lock()
+	r.hooks = append(r.hooks, fn)
+	routes := make([]*Route, len(r.routes))
+	copy(routes, r.routes)
+	r.mu.Unlock()
+
+	for _, route := range routes {
+		fn(route)
+	}
 }
 
 // parsePattern converts a route pattern to a regex and extracts param names.
@@ -178,6 +202,7 @@ func (r *Router) handleRequest(c *Context) error {
 	}
 
 	c.SetParams(params)
+	c.route = route.pattern
 
 	// Apply route-specific middleware
 	handler := route.handler
@@ -189,38 +214,38 @@ func (r *Router) handleRequest(c *Context) error {
 }
 
 // GET registers a GET route.
-func (r *Router) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodGet, pattern, h, mw...)
+func (r *Router) GET(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodGet, pattern, h, mw...)
 }
 
 // POST registers a POST route.
-func (r *Router) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodPost, pattern, h, mw...)
+func (r *Router) POST(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPost, pattern, h, mw...)
 }
 
 // PUT registers a PUT route.
-func (r *Router) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodPut, pattern, h, mw...)
+func (r *Router) PUT(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPut, pattern, h, mw...)
 }
 
 // PATCH registers a PATCH route.
-func (r *Router) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodPatch, pattern, h, mw...)
+func (r *Router) PATCH(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPatch, pattern, h, mw...)
 }
 
 // DELETE registers a DELETE route.
-func (r *Router) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodDelete, pattern, h, mw...)
+func (r *Router) DELETE(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodDelete, pattern, h, mw...)
 }
 
 // OPTIONS registers an OPTIONS route.
-func (r *Router) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodOptions, pattern, h, mw...)
+func (r *Router) OPTIONS(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodOptions, pattern, h, mw...)
 }
 
 // HEAD registers a HEAD route.
-func (r *Router) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
-	r.Handle(http.MethodHead, pattern, h, mw...)
+func (r *Router) HEAD(pattern string, h HandlerFunc, mw ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodHead, pattern, h, mw...)
 }
 
 // Any registers a route for all HTTP methods.
@@ -239,17 +264,6 @@ func (r *Router) Any(pattern string, h HandlerFunc, mw ...MiddlewareFunc) {
 	}
 }
 
-// Static serves static files from the given filesystem path.
-func (r *Router) Static(prefix, root string) {
-	fs := http.FileServer(http.Dir(root))
-	handler := http.StripPrefix(prefix, fs)
-
-	r.GET(prefix+"/{filepath:.*}", func(c *Context) error {
-		handler.ServeHTTP(c.Writer, c.Request)
-		return nil
-	})
-}
-
 // Routes returns all registered routes (for debugging).
 func (r *Router) Routes() []*Route {
 	r.mu.RLock()
@@ -264,3 +278,13 @@ func (r *Router) Routes() []*Route {
 func (route *Route) RouteInfo() (method, pattern string) {
 	return route.method, route.pattern
 }
+
+// Name names the route, so it can be looked up and reported by name.
+//
+// Example:
+//
+//	app.GET("/users/{id}", showUser).Name("users.show")
+func (route *Route) Name(name string) *Route {
+	route.name = name
+	return route
+}
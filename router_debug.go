@@ -0,0 +1,45 @@
+//go:build quarkdebug
+
+package quark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// mountDebugRoutes serves the route report. It is answered before the
+// router, so the endpoint stays out of the reported route table.
+func (a *App) mountDebugRoutes() {
+	a.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Method() == http.MethodGet && c.Path() == DebugRoutesPath {
+				return c.JSON(http.StatusOK, a.RouteReport())
+			}
+			return next(c)
+		}
+	})
+}
+
+// exit ends the process after dumpRoutes; tests replace it.
+var exit = os.Exit
+
+// dumpRoutes writes the route report to the file named by the
+// QUARK_ROUTES_OUT environment variable and exits, for "quark routes".
+func (a *App) dumpRoutes() {
+	path := os.Getenv(RoutesOutEnv)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(a.RouteReport())
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "quark: writing route report:", err)
+		exit(1)
+		return
+	}
+	exit(0)
+}
@@ -0,0 +1,90 @@
+//go:build quarkdebug
+
+package quark
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDebugRoutesEndpoint(t *testing.T) {
+	app := New()
+	h := func(c *Context) error { return nil }
+	app.GET("/users/{id}", h).Name("users.show")
+	app.GET("/users/me", h)
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugRoutesPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var report RouteReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Routes) != 2 {
+		t.Errorf("expected 2 routes, got %v", report.Routes)
+	}
+	if len(report.Issues) != 2 || report.Issues[0].Kind != RouteShadowed || report.Issues[1].Kind != RouteUnnamed {
+		t.Errorf("expected /users/me to be shadowed and unnamed, got %v", report.Issues)
+	}
+}
+
+// bootRoutesModule registers a route when it boots.
+type bootRoutesModule struct {
+	BaseModule
+}
+
+func (bootRoutesModule) Boot(a *App) error {
+	a.GET("/booted", func(c *Context) error { return nil }).Name("booted")
+	return nil
+}
+
+func TestDumpRoutesAfterBoot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "routes.json")
+	t.Setenv(RoutesOutEnv, file)
+	// Stop like os.Exit, so routes added later are missing from the report
+	type exited int
+	exit = func(code int) { panic(exited(code)) }
+	defer func() { exit = os.Exit }()
+
+	app := New(WithLogger(&printfLogger{}))
+	app.GET("/users", func(c *Context) error { return nil }).Name("users.index")
+	app.OnStart(func(a *App) error {
+		a.GET("/started", func(c *Context) error { return nil }).Name("started")
+		return nil
+	})
+	app.Register(bootRoutesModule{})
+
+	func() {
+		defer func() {
+			if code := recover(); code != exited(0) {
+				t.Fatalf("expected exit code 0, got %v", code)
+			}
+		}()
+		app.prepare(":0")
+	}()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report RouteReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]bool)
+	for _, r := range report.Routes {
+		paths[r.Pattern] = true
+	}
+	for _, path := range []string{"/users", "/started", "/booted"} {
+		if !paths[path] {
+			t.Errorf("expected %s in the route report, got %v", path, report.Routes)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package quark
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// Route issue kinds reported by LintRoutes.
+const (
+	RouteDuplicate = "duplicate" // Same method and pattern as an earlier route
+	RouteShadowed  = "shadowed"  // Every path is matched by an earlier route
+	RouteUnnamed   = "unnamed"   // No name given with Route.Name
+)
+
+// Route debugging in builds with the quarkdebug tag:
+//
+//	go run -tags quarkdebug .
+//	curl localhost:8080/_quark/routes
+//
+// The Run methods of such builds write the route report to the file named
+// by the QUARK_ROUTES_OUT environment variable and exit instead of serving,
+// which is how "quark routes" reads the route table of an application.
+const (
+	DebugRoutesPath = "/_quark/routes"
+	RoutesOutEnv    = "QUARK_ROUTES_OUT"
+)
+
+// RouteEntry describes a registered route.
+type RouteEntry struct {
+	Method  string   `json:"method"`
+	Pattern string   `json:"pattern"`
+	Name    string   `json:"name,omitempty"`
+	Params  []string `json:"params,omitempty"`
+}
+
+// RouteIssue is a problem found in the route table.
+type RouteIssue struct {
+	Kind    string `json:"kind"`
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	Name    string `json:"name,omitempty"`
+	Cause   string `json:"cause,omitempty"` // Pattern of the earlier route that wins
+}
+
+// String describes the issue.
+func (i RouteIssue) String() string {
+	switch i.Kind {
+	case RouteDuplicate:
+		return fmt.Sprintf("%s %s is registered twice, the first registration always wins", i.Method, i.Pattern)
+	case RouteShadowed:
+		return fmt.Sprintf("%s %s never matches, %s %s is registered earlier and matches all its paths", i.Method, i.Pattern, i.Method, i.Cause)
+	case RouteUnnamed:
+		return fmt.Sprintf("%s %s has no name", i.Method, i.Pattern)
+	}
+	return fmt.Sprintf("%s %s: %s", i.Method, i.Pattern, i.Kind)
+}
+
+// RouteReport is the route table with its issues, as served by the debug
+// endpoint and read by the "quark routes" command.
+type RouteReport struct {
+	Routes []RouteEntry `json:"routes"`
+	Issues []RouteIssue `json:"issues"`
+}
+
+// RouteTable returns the registered routes in matching order.
+func (a *App) RouteTable() []RouteEntry {
+	routes := a.router.Routes()
+	table := make([]RouteEntry, len(routes))
+	for i, route := range routes {
+		table[i] = RouteEntry{
+			Method:  route.method,
+			Pattern: route.pattern,
+			Name:    route.name,
+			Params:  route.paramNames,
+		}
+	}
+	return table
+}
+
+// LintRoutes reports routes that can never be reached and routes without a
+// name. Routes are matched in registration order, so a route is dead when
+// an earlier route with the same method has the same pattern, or a pattern
+// matching all its paths, such as /users/{id} registered before /users/me.
+//
+// Shadowing is detected by matching sample paths generated from the later
+// pattern against the earlier one, which covers the literal and constrained
+// parameter patterns routes use in practice.
+func (a *App) LintRoutes() []RouteIssue {
+	routes := a.router.Routes()
+	var issues []RouteIssue
+
+	for i, route := range routes {
+		issue := RouteIssue{Method: route.method, Pattern: route.pattern, Name: route.name}
+
+		samples := samplePaths(route)
+		for _, earlier := range routes[:i] {
+			if earlier.method != route.method {
+				continue
+			}
+			if earlier.regex.String() == route.regex.String() {
+				issue.Kind, issue.Cause = RouteDuplicate, earlier.pattern
+				break
+			}
+			if len(samples) > 0 && matchesAll(earlier, samples) {
+				issue.Kind, issue.Cause = RouteShadowed, earlier.pattern
+				break
+			}
+		}
+		if issue.Kind != "" {
+			issues = append(issues, issue)
+		}
+
+		if route.name == "" {
+			issue.Kind, issue.Cause = RouteUnnamed, ""
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// RouteReport returns the route table with its issues.
+func (a *App) RouteReport() RouteReport {
+	return RouteReport{Routes: a.RouteTable(), Issues: a.LintRoutes()}
+}
+
+// matchesAll reports whether route matches all paths.
+func matchesAll(route *Route, paths []string) bool {
+	for _, path := range paths {
+		if !route.regex.MatchString(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxSamples bounds the sample paths generated per route.
+const maxSamples = 256
+
+// samplePaths generates paths matched by the route.
+func samplePaths(route *Route) []string {
+	re, err := syntax.Parse(route.regex.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, path := range samples(re.Simplify()) {
+		if route.regex.MatchString(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// samples returns strings matched by re, covering each alternative,
+// repetition count and character range boundary.
+func samples(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCharClass:
+		return classSamples(re.Rune)
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return []string{"a", "0", "/", "-"}
+	case syntax.OpCapture:
+		return samples(re.Sub[0])
+	case syntax.OpStar:
+		return repeat(samples(re.Sub[0]), 0, 2)
+	case syntax.OpPlus:
+		return repeat(samples(re.Sub[0]), 1, 2)
+	case syntax.OpQuest:
+		return repeat(samples(re.Sub[0]), 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 || max > re.Min+1 {
+			max = re.Min + 1
+		}
+		return repeat(samples(re.Sub[0]), re.Min, max)
+	case syntax.OpConcat:
+		out := []string{""}
+		for _, sub := range re.Sub {
+			out = product(out, samples(sub))
+		}
+		return out
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			out = append(out, samples(sub)...)
+		}
+		return limit(out)
+	}
+	// Empty matches and anchors
+	return []string{""}
+}
+
+// classSamples returns characters of a class, given as rune ranges: common
+// path characters it contains and the bounds of each range.
+func classSamples(ranges []rune) []string {
+	seen := make(map[rune]bool)
+	var out []string
+	add := func(r rune) {
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, string(r))
+		}
+	}
+	for _, r := range "az09AZ-._~/" {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				add(r)
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		add(ranges[i])
+		add(ranges[i+1])
+	}
+	return limit(out)
+}
+
+// repeat returns the concatenations of min to max samples.
+func repeat(s []string, min, max int) []string {
+	var out []string
+	for n := min; n <= max; n++ {
+		seq := []string{""}
+		for i := 0; i < n; i++ {
+			seq = product(seq, s)
+		}
+		out = append(out, seq...)
+	}
+	return limit(out)
+}
+
+// product returns the concatenations of each a with each b.
+func product(a, b []string) []string {
+	out := make([]string, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			out = append(out, x+y)
+		}
+	}
+	return limit(out)
+}
+
+// limit removes duplicates and bounds the number of samples.
+func limit(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	out := s[:0]
+	for _, v := range s {
+		if !seen[v] && len(out) < maxSamples {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
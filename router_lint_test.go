@@ -0,0 +1,80 @@
+package quark
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintRoutes(t *testing.T) {
+	h := func(c *Context) error { return nil }
+
+	tests := []struct {
+		name    string
+		earlier string
+		later   string
+		method  string
+		kind    string
+	}{
+		{"duplicate", "/users", "/users", "GET", RouteDuplicate},
+		{"duplicate with trailing slash", "/users/", "/users", "GET", RouteDuplicate},
+		{"literal after param", "/users/{id}", "/users/me", "GET", RouteShadowed},
+		{"constrained after param", "/users/{id}", "/users/{id:[0-9]+}", "GET", RouteShadowed},
+		{"narrower constraint", "/files/{id:[0-9a-f]+}", "/files/{id:[0-9]+}", "GET", RouteShadowed},
+		{"catch-all", "/static/{path:.*}", "/static/app.js", "GET", RouteShadowed},
+		{"literal before param", "/users/me", "/users/{id}", "GET", ""},
+		{"disjoint constraints", "/users/{id:[0-9]+}", "/users/{name:[a-z]+}", "GET", ""},
+		{"wider constraint", "/files/{id:[0-9]+}", "/files/{id:[0-9a-f]+}", "GET", ""},
+		{"different method", "/users/{id}", "/users/me", "POST", ""},
+		{"different depth", "/users/{id}", "/users/{id}/posts", "GET", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New()
+			app.GET(tt.earlier, h).Name("earlier")
+			app.router.Handle(tt.method, tt.later, h).Name("later")
+
+			issues := app.LintRoutes()
+			if tt.kind == "" {
+				if len(issues) != 0 {
+					t.Fatalf("expected no issues, got %v", issues)
+				}
+				return
+			}
+			want := []RouteIssue{{Kind: tt.kind, Method: tt.method, Pattern: tt.later, Name: "later", Cause: tt.earlier}}
+			if !reflect.DeepEqual(issues, want) {
+				t.Errorf("expected %v, got %v", want, issues)
+			}
+		})
+	}
+}
+
+func TestLintRoutesUnnamed(t *testing.T) {
+	app := New()
+	h := func(c *Context) error { return nil }
+	app.GET("/users", h).Name("users.index")
+	app.Group("/api").POST("/users", h)
+
+	issues := app.LintRoutes()
+	if len(issues) != 1 || issues[0].Kind != RouteUnnamed || issues[0].Pattern != "/api/users" {
+		t.Fatalf("expected /api/users to be unnamed, got %v", issues)
+	}
+	if got := issues[0].String(); got != "POST /api/users has no name" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestRouteTable(t *testing.T) {
+	app := New()
+	h := func(c *Context) error { return nil }
+	app.GET("/users/{id:[0-9]+}", h).Name("users.show")
+	app.DELETE("/users/{id}", h)
+
+	want := []RouteEntry{
+		{Method: "GET", Pattern: "/users/{id:[0-9]+}", Name: "users.show", Params: []string{"id"}},
+		{Method: "DELETE", Pattern: "/users/{id}", Params: []string{"id"}},
+	}
+	if got := app.RouteTable(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
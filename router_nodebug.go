@@ -0,0 +1,9 @@
+//go:build !quarkdebug
+
+package quark
+
+// mountDebugRoutes does nothing without the quarkdebug build tag.
+func (a *App) mountDebugRoutes() {}
+
+// dumpRoutes does nothing without the quarkdebug build tag.
+func (a *App) dumpRoutes() {}
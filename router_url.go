@@ -0,0 +1,125 @@
+package quark
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Named returns the first route registered with the given name, or nil.
+func (r *Router) Named(name string) *Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range r.routes {
+		if route.name == name {
+			return route
+		}
+	}
+	return nil
+}
+
+// Path builds the path of the route, filling its parameters from params.
+// Values are formatted with fmt.Sprint and escaped, and must satisfy the
+// parameter constraints. Params that are not route parameters are added
+// as query parameters.
+//
+// Example:
+//
+//	route := app.GET("/users/{id:[0-9]+}/posts/{slug}", showPost)
+//	route.Path(quark.M{"id": 42, "slug": "hello world", "page": 2})
+//	// "/users/42/posts/hello%20world?page=2"
+func (route *Route) Path(params M) (string, error) {
+	var raw, escaped strings.Builder
+	used := make(map[string]bool, len(route.paramNames))
+
+	pattern := route.pattern
+	for i := 0; i < len(pattern); {
+		end := strings.IndexByte(pattern[i:], '}')
+		if pattern[i] != '{' || end == -1 {
+			raw.WriteByte(pattern[i])
+			escaped.WriteByte(pattern[i])
+			i++
+			continue
+		}
+
+		name, _, _ := strings.Cut(pattern[i+1:i+end], ":")
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("quark: route %s: missing parameter %q", route.pattern, name)
+		}
+		s := fmt.Sprint(value)
+		raw.WriteString(s)
+		escaped.WriteString(escapePath(s))
+		used[name] = true
+		i += end + 1
+	}
+
+	if !route.regex.MatchString(raw.String()) {
+		return "", fmt.Errorf("quark: route %s: parameters do not match the pattern: %s", route.pattern, raw.String())
+	}
+
+	path := escaped.String()
+	if query := queryValues(params, used); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// RoutePath builds the path of the named route, see Route.Path.
+//
+// Example:
+//
+//	app.GET("/users/{id}", showUser).Name("users.show")
+//
+//	path, err := app.RoutePath("users.show", quark.M{"id": 42}) // "/users/42"
+func (a *App) RoutePath(name string, params M) (string, error) {
+	route := a.router.Named(name)
+	if route == nil {
+		return "", fmt.Errorf("quark: no route named %q", name)
+	}
+	return route.Path(params)
+}
+
+// escapePath escapes each segment of a path, keeping its slashes.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// queryValues returns the params not in used as query values, formatting
+// slices as repeated keys.
+func queryValues(params M, used map[string]bool) url.Values {
+	query := make(url.Values)
+	for key, value := range params {
+		if !used[key] {
+			query[key] = queryStrings(value)
+		}
+	}
+	return query
+}
+
+// queryStrings formats a query value, expanding slices into one string per
+// element.
+func queryStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []string{fmt.Sprint(value)}
+	}
+	values := make([]string, rv.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return values
+}
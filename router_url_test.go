@@ -0,0 +1,38 @@
+package quark
+
+import "testing"
+
+func TestRoutePath(t *testing.T) {
+	h := func(c *Context) error { return nil }
+	app := New()
+	app.GET("/users/{id:[0-9]+}/posts/{slug}", h).Name("posts.show")
+	app.GET("/files/{path:.*}", h).Name("files")
+	app.Group("/api").GET("/status", h).Name("api.status")
+
+	tests := []struct {
+		name    string
+		params  M
+		want    string
+		wantErr bool
+	}{
+		{"posts.show", M{"id": 42, "slug": "hello world"}, "/users/42/posts/hello%20world", false},
+		{"posts.show", M{"id": 42, "slug": "a", "page": 2, "tag": []string{"x", "y"}}, "/users/42/posts/a?page=2&tag=x&tag=y", false},
+		{"posts.show", M{"id": "me", "slug": "a"}, "", true},
+		{"posts.show", M{"id": 42, "slug": "a/b"}, "", true},
+		{"posts.show", M{"id": 42}, "", true},
+		{"files", M{"path": "docs/read me.txt"}, "/files/docs/read%20me.txt", false},
+		{"api.status", nil, "/api/status", false},
+		{"missing", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := app.RoutePath(tt.name, tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RoutePath(%s, %v): unexpected error %v", tt.name, tt.params, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RoutePath(%s, %v) = %q, want %q", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
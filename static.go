@@ -0,0 +1,217 @@
+package quark
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig configures how static files are served.
+type StaticConfig struct {
+	// Root is the directory files are served from (required).
+	Root string
+
+	// Index is the file served for directory requests (default:
+	// "index.html").
+	Index string
+
+	// Browse lists the contents of directories without an index file.
+	// Directories are not listed by default.
+	Browse bool
+
+	// NotFound handles requests for missing files (default: a 404
+	// Not Found error, rendered by the App's error handler).
+	NotFound HandlerFunc
+
+	// MaxAge sets "Cache-Control: public, max-age=..." on files without a
+	// more specific policy. Zero sends no Cache-Control header.
+	MaxAge time.Duration
+
+	// Immutable reports whether a file's URL changes with its content,
+	// such as Fingerprinted names. Such files are cached for a year and
+	// marked immutable, so browsers never revalidate them.
+	Immutable func(name string) bool
+
+	// CacheControl maps file extensions, with their dot, to the
+	// Cache-Control header of matching files, overriding MaxAge, e.g.
+	// {".html": "no-cache"}.
+	CacheControl map[string]string
+
+	// ETag sends an ETag derived from the size and modification time of
+	// files, for If-None-Match revalidation in addition to
+	// If-Modified-Since.
+	ETag bool
+}
+
+// immutableCacheControl is the Cache-Control header of immutable files.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// fingerprintPattern matches file names with a content hash, such as
+// "app.3f2a1b9c.css" or "chunk-5d41402abc4b2a76.js".
+var fingerprintPattern = regexp.MustCompile(`[.-]([0-9a-fA-F]{8,})\.[^./]+$`)
+
+// Fingerprinted reports whether a file name contains a content hash of
+// at least eight hex digits before its extension, as produced by asset
+// bundlers, e.g. "app.3f2a1b9c.css" or "chunk-5d41402abc4b2a76.js". Use
+// it as StaticConfig.Immutable. Hashes made of decimal digits only are
+// not recognized, so dated names such as "report-20240115.pdf" are not
+// mistaken for immutable files.
+func Fingerprinted(name string) bool {
+	m := fingerprintPattern.FindStringSubmatch(name)
+	return m != nil && strings.ContainsAny(m[1], "abcdefABCDEF")
+}
+
+// Static serves static files from the given filesystem path, see
+// StaticWithConfig.
+func (r *Router) Static(prefix, root string) {
+	r.StaticWithConfig(prefix, StaticConfig{Root: root})
+}
+
+// StaticWithConfig serves static files below prefix. Each file is served
+// with http.ServeContent, so Range requests, If-Modified-Since and
+// If-Range conditionals work, and the Content-Type is detected from the
+// extension or, failing that, the content. Directory requests are
+// redirected to the path with a trailing slash and served their index
+// file. Cache headers are only sent as configured.
+//
+// Example:
+//
+//	app.StaticWithConfig("/docs", quark.StaticConfig{
+//	    Root:   "./public/docs",
+//	    Browse: true,
+//	    NotFound: func(c *quark.Context) error {
+//	        return c.HTML(404, "<h1>No such page</h1>")
+//	    },
+//	})
+//
+//	app.StaticWithConfig("/assets", quark.StaticConfig{
+//	    Root:         "./dist",
+//	    MaxAge:       time.Hour,
+//	    Immutable:    quark.Fingerprinted, // app.3f2a1b9c.js
+//	    CacheControl: map[string]string{".html": "no-cache"},
+//	    ETag:         true,
+//	})
+func (r *Router) StaticWithConfig(prefix string, config StaticConfig) {
+	if config.Root == "" {
+		panic("quark: static files require a Root")
+	}
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	if config.NotFound == nil {
+		config.NotFound = func(c *Context) error { return ErrNotFound("") }
+	}
+
+	h := staticHandler(http.Dir(config.Root), config)
+	r.GET(prefix+"/{filepath:.*}", h)
+	r.HEAD(prefix+"/{filepath:.*}", h)
+}
+
+// staticHandler returns the handler serving files from root.
+func staticHandler(root http.FileSystem, config StaticConfig) HandlerFunc {
+	return func(c *Context) error {
+		name := path.Clean("/" + c.Param("filepath"))
+
+		f, err := root.Open(name)
+		if err != nil {
+			return config.NotFound(c)
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return config.NotFound(c)
+		}
+
+		if info.IsDir() {
+			if !strings.HasSuffix(c.Request.URL.Path, "/") {
+				return c.Redirect(http.StatusMovedPermanently, path.Base(c.Request.URL.Path)+"/"+queryString(c))
+			}
+
+			index, err := root.Open(path.Join(name, config.Index))
+			if err == nil {
+				defer index.Close()
+				if indexInfo, err := index.Stat(); err == nil && indexInfo.Mode().IsRegular() {
+					serveStaticFile(c, config, path.Join(name, config.Index), indexInfo, index)
+					return nil
+				}
+			}
+			if !config.Browse {
+				return config.NotFound(c)
+			}
+			return listDirectory(c, f)
+		}
+
+		if !info.Mode().IsRegular() {
+			return config.NotFound(c)
+		}
+		serveStaticFile(c, config, name, info, f)
+		return nil
+	}
+}
+
+// serveStaticFile sends a file with the configured cache headers.
+func serveStaticFile(c *Context, config StaticConfig, name string, info fs.FileInfo, content io.ReadSeeker) {
+	if cacheControl := staticCacheControl(config, name); cacheControl != "" {
+		c.SetHeader("Cache-Control", cacheControl)
+	}
+	if config.ETag {
+		c.SetHeader("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	}
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), content)
+}
+
+// staticCacheControl returns the Cache-Control header of a file: immutable
+// for fingerprinted files, then the policy of its extension, then MaxAge.
+func staticCacheControl(config StaticConfig, name string) string {
+	if config.Immutable != nil && config.Immutable(name) {
+		return immutableCacheControl
+	}
+	if cacheControl, ok := config.CacheControl[strings.ToLower(path.Ext(name))]; ok {
+		return cacheControl
+	}
+	if config.MaxAge > 0 {
+		return "public, max-age=" + strconv.FormatInt(int64(config.MaxAge/time.Second), 10)
+	}
+	return ""
+}
+
+// listDirectory sends an HTML listing of a directory.
+func listDirectory(c *Context, dir http.File) error {
+	entries, err := dir.Readdir(-1)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return WrapError(http.StatusInternalServerError, "failed to read directory", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		link := url.URL{Path: name}
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", html.EscapeString(link.String()), html.EscapeString(name))
+	}
+	b.WriteString("</pre>\n")
+	return c.HTML(http.StatusOK, b.String())
+}
+
+// queryString returns the query of the request with its "?", if any.
+func queryString(c *Context) string {
+	if c.Request.URL.RawQuery == "" {
+		return ""
+	}
+	return "?" + c.Request.URL.RawQuery
+}
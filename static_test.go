@@ -0,0 +1,165 @@
+package quark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticRoot creates a directory of static files for tests.
+func staticRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"app.js":          "console.log('hello, world');",
+		"docs/index.html": "<h1>Docs</h1>",
+		"files/a.txt":     "a",
+		"files/b.txt":     "b",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// serveStatic sends a GET request with the given headers to app.
+func serveStatic(app *App, target string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestStatic(t *testing.T) {
+	app := New()
+	app.Static("/static", staticRoot(t))
+
+	w := serveStatic(app, "/static/app.js")
+	if w.Code != http.StatusOK || w.Body.String() != "console.log('hello, world');" {
+		t.Fatalf("expected the file, got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("expected a JavaScript content type, got %q", ct)
+	}
+
+	w = serveStatic(app, "/static/app.js", "Range", "bytes=0-6")
+	if w.Code != http.StatusPartialContent || w.Body.String() != "console" {
+		t.Errorf("expected a partial response, got %d %q", w.Code, w.Body.String())
+	}
+
+	modified := w.Header().Get("Last-Modified")
+	w = serveStatic(app, "/static/app.js", "If-Modified-Since", modified)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for an unmodified file, got %d", w.Code)
+	}
+	w = serveStatic(app, "/static/app.js", "If-Modified-Since", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a modified file, got %d", w.Code)
+	}
+
+	w = serveStatic(app, "/static/docs/")
+	if w.Code != http.StatusOK || w.Body.String() != "<h1>Docs</h1>" {
+		t.Errorf("expected the index file, got %d %q", w.Code, w.Body.String())
+	}
+	w = serveStatic(app, "/static/docs?v=1")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "docs/?v=1" {
+		t.Errorf("expected a redirect to the directory, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	for _, target := range []string{"/static/missing.js", "/static/files/", "/static/../static_test.go"} {
+		if w := serveStatic(app, target); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestStaticWithConfig(t *testing.T) {
+	app := New()
+	app.StaticWithConfig("/static", StaticConfig{
+		Root:   staticRoot(t),
+		Browse: true,
+		NotFound: func(c *Context) error {
+			return c.String(http.StatusNotFound, "custom not found")
+		},
+	})
+
+	w := serveStatic(app, "/static/files/")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<a href="a.txt">a.txt</a>`) {
+		t.Errorf("expected a directory listing, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = serveStatic(app, "/static/missing.js")
+	if w.Code != http.StatusNotFound || w.Body.String() != "custom not found" {
+		t.Errorf("expected the custom not found handler, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestStaticCacheControl(t *testing.T) {
+	root := staticRoot(t)
+	if err := os.WriteFile(filepath.Join(root, "app.3f2a1b9c.js"), []byte("1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.StaticWithConfig("/static", StaticConfig{
+		Root:         root,
+		MaxAge:       time.Hour,
+		Immutable:    Fingerprinted,
+		CacheControl: map[string]string{".html": "no-cache"},
+		ETag:         true,
+	})
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/static/app.js", "public, max-age=3600"},
+		{"/static/app.3f2a1b9c.js", "public, max-age=31536000, immutable"},
+		{"/static/docs/", "no-cache"},
+		{"/static/missing.js", ""},
+	}
+	for _, tt := range tests {
+		w := serveStatic(app, tt.target)
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s: expected Cache-Control %q, got %q", tt.target, tt.want, got)
+		}
+	}
+
+	w := serveStatic(app, "/static/app.js")
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+	if w := serveStatic(app, "/static/app.js", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+}
+
+func TestFingerprinted(t *testing.T) {
+	tests := map[string]bool{
+		"app.3f2a1b9c.css":             true,
+		"js/chunk-5d41402abc4b2a76.js": true,
+		"app.css":                      false,
+		"app.min.js":                   false,
+		"app.3f2a1b.css":               false,
+		"report-20240115.pdf":          false,
+	}
+	for name, want := range tests {
+		if got := Fingerprinted(name); got != want {
+			t.Errorf("Fingerprinted(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package quark
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MIMENDJSON is the content type of newline-delimited JSON.
+const MIMENDJSON = "application/x-ndjson"
+
+// streamFlushInterval is how often buffered stream output is flushed to
+// the client.
+const streamFlushInterval = 100 * time.Millisecond
+
+// NDJSON streams the items of source as newline-delimited JSON, one object
+// per line, without buffering the whole response. The source is a channel,
+// read until closed, an iter.Seq of items, or an iter.Seq2 of items and
+// errors, which ends the stream at the first non-nil error.
+//
+// Output is buffered and flushed periodically, so slow producers such as
+// log tails reach the client promptly. The stream stops when the client
+// disconnects. Errors after the status is sent are returned to the caller
+// but cannot change the response.
+//
+// Example:
+//
+//	app.GET("/export", func(c *quark.Context) error {
+//	    rows := make(chan Order)
+//	    go exportOrders(c.Context(), rows) // closes rows when done
+//	    return c.NDJSON(200, rows)
+//	})
+//
+//	app.GET("/users.ndjson", func(c *quark.Context) error {
+//	    return c.NDJSON(200, repo.All(c.Context())) // iter.Seq2[User, error]
+//	})
+func (c *Context) NDJSON(code int, source interface{}) error {
+	items, err := sourceItems(source)
+	if err != nil {
+		return err
+	}
+
+	c.SetHeader("Content-Type", MIMENDJSON)
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	codec := c.jsonCodec()
+	s := c.stream(code)
+	err = items(c, func(item interface{}) error {
+		line, err := codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		return s.locked(func() error {
+			_, err := s.Write(append(line, '\n'))
+			return err
+		})
+	})
+	if closeErr := s.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ArrayEncoder writes the elements of a JSON array streamed by
+// Context.JSONStream.
+type ArrayEncoder struct {
+	s     *streamWriter
+	codec Codec
+	n     int
+}
+
+// Encode writes v as the next element of the array.
+func (e *ArrayEncoder) Encode(v interface{}) error {
+	data, err := e.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return e.s.locked(func() error {
+		if e.n > 0 {
+			if _, err := e.s.Write([]byte{','}); err != nil {
+				return err
+			}
+		}
+		e.n++
+		_, err := e.s.Write(data)
+		return err
+	})
+}
+
+// JSONStream streams a JSON array whose elements are written by fn, so large
+// collections are sent without materializing them. Output is flushed
+// periodically as with NDJSON. When fn fails, the array is left
+// unterminated, so clients see an invalid document rather than a
+// truncated list.
+//
+// Example:
+//
+//	app.GET("/orders", func(c *quark.Context) error {
+//	    rows, err := db.QueryContext(c.Context(), "SELECT id, total FROM orders")
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer rows.Close()
+//
+//	    return c.JSONStream(200, func(enc *quark.ArrayEncoder) error {
+//	        for rows.Next() {
+//	            var o Order
+//	            if err := rows.Scan(&o.ID, &o.Total); err != nil {
+//	                return err
+//	            }
+//	            if err := enc.Encode(o); err != nil {
+//	                return err
+//	            }
+//	        }
+//	        return rows.Err()
+//	    })
+//	})
+func (c *Context) JSONStream(code int, fn func(enc *ArrayEncoder) error) error {
+	c.SetHeader("Content-Type", "application/json; charset=utf-8")
+	s := c.stream(code)
+	enc := &ArrayEncoder{s: s, codec: c.jsonCodec()}
+
+	err := s.locked(func() error {
+		_, err := s.Write([]byte{'['})
+		return err
+	})
+	if err == nil {
+		err = fn(enc)
+	}
+	if err == nil {
+		err = s.locked(func() error {
+			_, err := s.Write([]byte("]\n"))
+			return err
+		})
+	}
+	if closeErr := s.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// JSONArray streams the items of a channel or iterator as a JSON array,
+// accepting the same sources as NDJSON.
+//
+// Example:
+//
+//	return c.JSONArray(200, repo.All(c.Context())) // iter.Seq2[User, error]
+func (c *Context) JSONArray(code int, source interface{}) error {
+	items, err := sourceItems(source)
+	if err != nil {
+		return err
+	}
+	return c.JSONStream(code, func(enc *ArrayEncoder) error {
+		return items(c, enc.Encode)
+	})
+}
+
+// sourceItems returns a function calling fn for each item of a channel or
+// iterator, stopping at the first error or when the request ends.
+func sourceItems(source interface{}) (func(*Context, func(interface{}) error) error, error) {
+	v := reflect.ValueOf(source)
+	switch {
+	case v.Kind() == reflect.Chan && v.Type().ChanDir()&reflect.RecvDir != 0:
+		return func(c *Context, fn func(interface{}) error) error {
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.Context().Done())},
+				{Dir: reflect.SelectRecv, Chan: v},
+			}
+			for {
+				chosen, item, ok := reflect.Select(cases)
+				if chosen == 0 {
+					return c.Context().Err()
+				}
+				if !ok {
+					return nil
+				}
+				if err := fn(item.Interface()); err != nil {
+					return err
+				}
+			}
+		}, nil
+
+	case v.Kind() == reflect.Func && isIterator(v.Type()):
+		return func(c *Context, fn func(interface{}) error) error {
+			var err error
+			yield := reflect.MakeFunc(v.Type().In(0), func(args []reflect.Value) []reflect.Value {
+				if len(args) == 2 && !args[1].IsNil() {
+					err = args[1].Interface().(error)
+				} else if err = c.Context().Err(); err == nil {
+					err = fn(args[0].Interface())
+				}
+				return []reflect.Value{reflect.ValueOf(err == nil)}
+			})
+			v.Call([]reflect.Value{yield})
+			return err
+		}, nil
+	}
+	return nil, ErrInternal("stream source must be a channel, iter.Seq or iter.Seq2 with errors")
+}
+
+// isIterator reports whether t is iter.Seq[T] or iter.Seq2[T, error].
+func isIterator(t reflect.Type) bool {
+	if t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+		return false
+	}
+	return yield.NumIn() == 1 || yield.NumIn() == 2 && yield.In(1) == errorType
+}
+
+// streamWriter buffers a streamed response, flushing it to the client
+// periodically from a background goroutine.
+type streamWriter struct {
+	mu    sync.Mutex
+	buf   *bufio.Writer
+	rc    *http.ResponseController
+	dirty bool
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// stream sends the status and returns a writer for the response body.
+func (c *Context) stream(code int) *streamWriter {
+	c.Writer.WriteHeader(code)
+	c.markWritten()
+
+	s := &streamWriter{
+		buf:  bufio.NewWriterSize(c.Writer, 32<<10),
+		rc:   http.NewResponseController(c.Writer),
+		done: make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.flushLoop()
+	return s
+}
+
+// Write buffers p. Callers hold the lock through locked.
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.dirty = true
+	return s.buf.Write(p)
+}
+
+// locked runs fn with the writer locked against the flush loop.
+func (s *streamWriter) locked(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn()
+}
+
+// flush sends the buffered output to the client. Callers hold the lock.
+func (s *streamWriter) flush() error {
+	if !s.dirty {
+		return nil
+	}
+	s.dirty = false
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// flushLoop flushes the buffered output every streamFlushInterval.
+func (s *streamWriter) flushLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.locked(s.flush)
+		}
+	}
+}
+
+// close stops the flush loop and flushes the remaining output.
+func (s *streamWriter) close() error {
+	close(s.done)
+	s.wg.Wait()
+	return s.locked(s.flush)
+}
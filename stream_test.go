@@ -0,0 +1,196 @@
+package quark
+
+import (
+	"bufio"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+func TestContextNDJSON(t *testing.T) {
+	items := []streamItem{{1}, {2}, {3}}
+	want := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+
+	tests := []struct {
+		name   string
+		source func() interface{}
+	}{
+		{"channel", func() interface{} {
+			ch := make(chan streamItem, len(items))
+			for _, item := range items {
+				ch <- item
+			}
+			close(ch)
+			return (<-chan streamItem)(ch)
+		}},
+		{"iter.Seq", func() interface{} {
+			return slices.Values(items)
+		}},
+		{"iter.Seq2", func() interface{} {
+			return iter.Seq2[streamItem, error](func(yield func(streamItem, error) bool) {
+				for _, item := range items {
+					if !yield(item, nil) {
+						return
+					}
+				}
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+			if err := c.NDJSON(http.StatusOK, tt.source()); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Body.String() != want {
+				t.Errorf("expected %q, got %q", want, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != MIMENDJSON {
+				t.Errorf("expected %s, got %s", MIMENDJSON, ct)
+			}
+		})
+	}
+}
+
+func TestContextNDJSONErrors(t *testing.T) {
+	t.Run("iterator error", func(t *testing.T) {
+		failure := errors.New("query failed")
+		seq := func(yield func(streamItem, error) bool) {
+			if yield(streamItem{1}, nil) {
+				yield(streamItem{}, failure)
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+		if err := c.NDJSON(http.StatusOK, seq); !errors.Is(err, failure) {
+			t.Errorf("expected the iterator error, got %v", err)
+		}
+		if rec.Body.String() != "{\"id\":1}\n" {
+			t.Errorf("expected the items before the error, got %q", rec.Body.String())
+		}
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+		if err := c.NDJSON(http.StatusOK, []streamItem{{1}}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if c.IsWritten() {
+			t.Error("expected nothing to be written")
+		}
+	})
+}
+
+func TestContextNDJSONFlushes(t *testing.T) {
+	release := make(chan struct{})
+	app := New()
+	app.GET("/tail", func(c *Context) error {
+		ch := make(chan streamItem)
+		go func() {
+			defer close(ch)
+			ch <- streamItem{1}
+			<-release
+		}()
+		return c.NDJSON(http.StatusOK, ch)
+	})
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+	defer close(release)
+
+	resp, err := http.Get(srv.URL + "/tail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// The first line arrives while the producer is still blocked
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- s
+	}()
+	select {
+	case s := <-line:
+		if s != "{\"id\":1}\n" {
+			t.Errorf("unexpected line %q", s)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first item was not flushed")
+	}
+}
+
+func TestContextJSONStream(t *testing.T) {
+	t.Run("elements", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+		err := c.JSONStream(http.StatusOK, func(enc *ArrayEncoder) error {
+			for i := 1; i <= 3; i++ {
+				if err := enc.Encode(streamItem{i}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "[{\"id\":1},{\"id\":2},{\"id\":3}]\n"; rec.Body.String() != want {
+			t.Errorf("expected %q, got %q", want, rec.Body.String())
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+		if err := c.JSONStream(http.StatusOK, func(enc *ArrayEncoder) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Body.String() != "[]\n" {
+			t.Errorf("expected an empty array, got %q", rec.Body.String())
+		}
+	})
+
+	t.Run("error leaves the array open", func(t *testing.T) {
+		failure := errors.New("scan failed")
+		rec := httptest.NewRecorder()
+		c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+		err := c.JSONStream(http.StatusOK, func(enc *ArrayEncoder) error {
+			enc.Encode(streamItem{1})
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("expected the callback error, got %v", err)
+		}
+		if rec.Body.String() != "[{\"id\":1}" {
+			t.Errorf("expected an unterminated array, got %q", rec.Body.String())
+		}
+	})
+}
+
+func TestContextJSONArray(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newContext(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if err := c.JSONArray(http.StatusOK, slices.Values([]streamItem{{1}, {2}})); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[{\"id\":1},{\"id\":2}]\n"; rec.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, rec.Body.String())
+	}
+}
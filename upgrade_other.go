@@ -0,0 +1,19 @@
+//go:build !unix
+
+package quark
+
+import (
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// upgradeSignals are the signals starting an upgrade, none on this
+// platform.
+var upgradeSignals []os.Signal
+
+// upgradeProcess is not supported on this platform.
+func upgradeProcess(l net.Listener, timeout time.Duration) (int, error) {
+	return 0, errors.New("upgrades are not supported on this platform")
+}
@@ -0,0 +1,101 @@
+//go:build unix
+
+package quark
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// upgradeSignals are the signals starting an upgrade.
+var upgradeSignals = []os.Signal{syscall.SIGUSR2}
+
+// upgradeCommand returns the command starting the new process: the
+// current executable with the same arguments.
+var upgradeCommand = func() (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("executable: %w", err)
+	}
+	return exec.Command(exe, os.Args[1:]...), nil
+}
+
+// upgradeProcess starts a new process of the current executable with the
+// same arguments, passing it the listening socket, and returns its PID
+// once the new process reports it is ready to serve. A process exiting
+// first or not ready within timeout is killed, and an error returned so
+// this process keeps serving.
+func upgradeProcess(l net.Listener, timeout time.Duration) (int, error) {
+	fl, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return 0, fmt.Errorf("listener %T cannot be passed to a new process", l)
+	}
+	f, err := fl.File()
+	if err != nil {
+		return 0, fmt.Errorf("listener file: %w", err)
+	}
+	defer f.Close()
+
+	cmd, err := upgradeCommand()
+	if err != nil {
+		return 0, err
+	}
+
+	ready, notify, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("readiness pipe: %w", err)
+	}
+	defer ready.Close()
+
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, listenerFDEnv+"=") && !strings.HasPrefix(kv, readyFDEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+
+	// ExtraFiles start at file descriptor 3
+	cmd.Env = append(env, listenerFDEnv+"=3", readyFDEnv+"=4")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{f, notify}
+	err = cmd.Start()
+	// The new process holds the only write end, so reads fail if it exits
+	notify.Close()
+	if err != nil {
+		return 0, fmt.Errorf("starting new process: %w", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		if _, err := ready.Read(make([]byte, 1)); err != nil {
+			result <- errors.New("new process exited before it was ready")
+			return
+		}
+		result <- nil
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-result:
+	case <-timer.C:
+		err = fmt.Errorf("new process not ready after %s", timeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, err
+	}
+
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,159 @@
+package quark
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLBuilder builds URLs with escaped path parameters and query values.
+// Builders are immutable: each method returns a new builder, so a base
+// builder can be shared, e.g. between the links of a paginated response.
+// Errors, such as an invalid base or a missing path argument, are kept
+// until Build reports them.
+type URLBuilder struct {
+	base     url.URL
+	path     string // Escaped path
+	query    url.Values
+	fragment string
+	err      error
+}
+
+// URL returns a builder for URLs below base, an absolute URL or a path.
+// The path and query of base are kept.
+//
+// Example:
+//
+//	quark.URL("https://api.example.com/v1").
+//	    Path("/users/{id}/posts", user.ID).
+//	    Query("page", 2).
+//	    String() // "https://api.example.com/v1/users/42/posts?page=2"
+func URL(base string) *URLBuilder {
+	u, err := url.Parse(base)
+	if err != nil {
+		return &URLBuilder{err: fmt.Errorf("quark: invalid base URL: %w", err)}
+	}
+	b := &URLBuilder{base: *u, path: u.EscapedPath(), query: u.Query(), fragment: u.Fragment}
+	b.base.RawQuery, b.base.Fragment = "", ""
+	return b
+}
+
+// clone returns a copy of the builder whose query can be modified.
+func (b *URLBuilder) clone() *URLBuilder {
+	c := *b
+	c.query = make(url.Values, len(b.query))
+	for key, values := range b.query {
+		c.query[key] = append([]string(nil), values...)
+	}
+	return &c
+}
+
+// Path appends a path to the URL. Each {placeholder} of pattern, written
+// as in route patterns, is replaced with the next arg, formatted with
+// fmt.Sprint and escaped, so values cannot alter the path structure.
+func (b *URLBuilder) Path(pattern string, args ...interface{}) *URLBuilder {
+	c := b.clone()
+	if c.err != nil {
+		return c
+	}
+
+	var path strings.Builder
+	n := 0
+	for i := 0; i < len(pattern); {
+		end := strings.IndexByte(pattern[i:], '}')
+		if pattern[i] != '{' || end == -1 {
+			path.WriteByte(pattern[i])
+			i++
+			continue
+		}
+		if n >= len(args) {
+			c.err = fmt.Errorf("quark: missing argument for %s in path %s", pattern[i:i+end+1], pattern)
+			return c
+		}
+		path.WriteString(url.PathEscape(fmt.Sprint(args[n])))
+		n++
+		i += end + 1
+	}
+	if n < len(args) {
+		c.err = fmt.Errorf("quark: %d arguments for %d placeholders in path %s", len(args), n, pattern)
+		return c
+	}
+
+	if p := path.String(); p != "" {
+		c.path = strings.TrimSuffix(c.path, "/") + "/" + strings.TrimPrefix(p, "/")
+	}
+	return c
+}
+
+// Query sets a query parameter, replacing its current values. Slice values
+// are repeated, and no values removes the parameter.
+func (b *URLBuilder) Query(key string, values ...interface{}) *URLBuilder {
+	c := b.clone()
+	c.query.Del(key)
+	for _, value := range values {
+		c.query[key] = append(c.query[key], queryStrings(value)...)
+	}
+	return c
+}
+
+// Fragment sets the fragment of the URL, without its "#".
+func (b *URLBuilder) Fragment(fragment string) *URLBuilder {
+	c := b.clone()
+	c.fragment = fragment
+	return c
+}
+
+// Build returns the URL, or the first error met while building it.
+func (b *URLBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	u := b.base
+	path, err := url.PathUnescape(b.path)
+	if err != nil {
+		return "", fmt.Errorf("quark: invalid path %s: %w", b.path, err)
+	}
+	u.Path, u.RawPath = path, b.path
+	u.RawQuery = b.query.Encode()
+	u.Fragment = b.fragment
+	return u.String(), nil
+}
+
+// String returns the URL, or "" when it could not be built. Use Build to
+// get the error.
+func (b *URLBuilder) String() string {
+	s, _ := b.Build()
+	return s
+}
+
+// URLFor returns a builder for the absolute URL of the route registered
+// with name, filling its path parameters from params, see Route.Path. The
+// scheme and host are those of the request.
+//
+// Example:
+//
+//	app.GET("/users/{id}", showUser).Name("users.show")
+//
+//	c.URLFor("users.show", quark.M{"id": 42}).String() // "https://example.com/users/42"
+func (c *Context) URLFor(name string, params M) *URLBuilder {
+	if c.app == nil {
+		return &URLBuilder{err: fmt.Errorf("quark: no App to look up route %q", name)}
+	}
+	path, err := c.app.RoutePath(name, params)
+	if err != nil {
+		return &URLBuilder{err: err}
+	}
+	return URL(c.Scheme() + "://" + c.Request.Host + path)
+}
+
+// RequestURL returns a builder for the absolute URL of the request, with
+// its query, such as for the links of a paginated response.
+//
+// Example:
+//
+//	next := c.RequestURL().Query("page", page+1).String()
+//	c.SetHeader("Link", "<"+next+`>; rel="next"`)
+func (c *Context) RequestURL() *URLBuilder {
+	return URL(c.Scheme() + "://" + c.Request.Host + c.Request.URL.RequestURI())
+}
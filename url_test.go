@@ -0,0 +1,75 @@
+package quark
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLBuilder(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *URLBuilder
+		want string
+	}{
+		{"path and query", URL("https://api.example.com/v1").Path("/users/{id}/posts", 42).Query("page", 2), "https://api.example.com/v1/users/42/posts?page=2"},
+		{"escaped values", URL("/").Path("/search/{q}", "a b/c?d").Query("tag", "x&y"), "/search/a%20b%2Fc%3Fd?tag=x%26y"},
+		{"base query kept", URL("https://example.com/items?sort=name&page=1").Query("page", 3), "https://example.com/items?page=3&sort=name"},
+		{"repeated values", URL("/items").Query("id", []int{1, 2}, 3), "/items?id=1&id=2&id=3"},
+		{"removed query", URL("/items?page=2").Query("page"), "/items"},
+		{"constrained placeholder", URL("").Path("/users/{id:[0-9]+}", 7), "/users/7"},
+		{"fragment", URL("/docs").Path("intro").Fragment("setup"), "/docs/intro#setup"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.b.Build()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestURLBuilderErrors(t *testing.T) {
+	for name, b := range map[string]*URLBuilder{
+		"invalid base":       URL("http://[::1"),
+		"missing argument":   URL("/").Path("/users/{id}"),
+		"too many arguments": URL("/").Path("/users", 1),
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if s := b.String(); s != "" {
+			t.Errorf("%s: expected an empty string, got %q", name, s)
+		}
+	}
+}
+
+func TestURLBuilderImmutable(t *testing.T) {
+	base := URL("/items").Query("sort", "name")
+	first := base.Query("page", 1)
+	second := base.Query("page", 2)
+
+	if base.String() != "/items?sort=name" || first.String() != "/items?page=1&sort=name" || second.String() != "/items?page=2&sort=name" {
+		t.Errorf("builders share state: %s, %s, %s", base, first, second)
+	}
+}
+
+func TestContextURLFor(t *testing.T) {
+	app := New()
+	app.GET("/users/{id}", func(c *Context) error {
+		return c.String(http.StatusOK, c.URLFor("users.show", M{"id": 7}).Query("tab", "posts").String()+" "+
+			c.RequestURL().Query("page", 2).String())
+	}).Name("users.show")
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/users/42?page=1&q=go", nil))
+
+	want := "http://example.com/users/7?tab=posts http://example.com/users/42?page=2&q=go"
+	if rec.Body.String() != want {
+		t.Errorf("got %q, want %q", rec.Body.String(), want)
+	}
+}
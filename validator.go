@@ -1,12 +1,17 @@
 // Package quark provides struct validation through field tags.
 //
 // The validation system supports a wide range of validators including:
-// - Presence validation (required)
+// - Presence validation (required, required_if, required_with, required_without)
 // - Length and size validation (min, max, len)
 // - Format validation (email, url, uuid, pattern)
+// - Network validation (ip, ipv4, ipv6, cidr, mac, port, hostname)
+// - Encoding validation (json, base64, base64url, hex, jwt)
 // - Character set validation (alpha, alphanum, numeric)
 // - Comparison validation (gt, gte, lt, lte)
+// - Date/time validation (datetime, before, after)
+// - Cross-field validation (eqfield, nefield, gtfield, gtefield, ltfield, ltefield)
 // - Enumeration validation (oneof)
+// - Collection element validation (dive)
 //
 // Validation is performed recursively on nested structs,
 // allowing for complex validation scenarios.
@@ -26,12 +31,20 @@
 package quark
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/mail"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 )
 
@@ -101,6 +114,10 @@ func (e ValidationErrors) HasErrors() bool {
 //
 // Supported validation tags:
 //   - required:       field must not be empty/zero
+//   - required_if:F v:     required when sibling field F equals v
+//     (multiple "F v" pairs must all match)
+//   - required_with:F G:   required when any of sibling fields F, G is present
+//   - required_without:F G: required when any of sibling fields F, G is missing
 //   - min:n:          minimum length (strings/slices/maps) or value (numbers)
 //   - max:n:          maximum length (strings/slices/maps) or value (numbers)
 //   - len:n:          exact length (strings/slices/maps)
@@ -116,9 +133,44 @@ func (e ValidationErrors) HasErrors() bool {
 //   - uuid:           must be a valid UUID (v4 format)
 //   - oneof:a b c:    must be one of the space-separated values
 //   - pattern:regex:  must match the regex pattern
+//   - ip, ipv4, ipv6: must be a valid IP address (of the given version)
+//   - cidr:           must be a valid CIDR notation (e.g., 10.0.0.0/8)
+//   - mac:            must be a valid MAC address
+//   - hostname:       must be a valid RFC 1123 hostname
+//   - port:           must be a port number between 1 and 65535 (strings or integers)
+//   - json:           must be well-formed JSON
+//   - base64:         must be standard base64 (padded)
+//   - base64url:      must be URL-safe base64 (padding optional)
+//   - hex:            must be hexadecimal (optional 0x prefix)
+//   - jwt:            must be a structurally valid JWT (signature not verified)
+//   - datetime:layout: string must parse with the Go time layout (e.g., datetime:2006-01-02)
+//   - before:X:       time/date string must be before X ("now", a sibling field or a date)
+//   - after:X:        time/date string must be after X ("now", a sibling field or a date)
+//   - eqfield:F:      must equal sibling field F (e.g., eqfield:Password)
+//   - nefield:F:      must not equal sibling field F
+//   - gtfield:F:      must be greater than sibling field F (numbers, strings, time.Time)
+//   - gtefield:F:     must be greater than or equal to sibling field F
+//   - ltfield:F:      must be less than sibling field F
+//   - ltefield:F:     must be less than or equal to sibling field F
+//   - omitempty:      skip the remaining validators when the value is zero or nil
+//   - dive:           apply the following validators to each element of a
+//     slice, array or map (e.g., validate:"min:1,dive,email")
 //
 // Tags can be combined with commas, e.g., validate:"required,min:2,max:50"
 //
+// A trailing "message:text" entry replaces the generated error messages for
+// the field; see SetValidationMessage to override messages per validator.
+//
+// Pointer fields are dereferenced before validation; a nil pointer fails
+// required and is skipped by every other validator. Nested struct pointers
+// are validated when non-nil.
+//
+// Structs held in slices, arrays and maps are validated recursively as well,
+// with element errors reported as "items[0].name" or "labels[key]".
+//
+// Types implementing Validatable or StructValidator have their struct-level
+// hook called after the field rules, including when nested.
+//
 // Example:
 //
 //	type Address struct {
@@ -140,6 +192,12 @@ func (e ValidationErrors) HasErrors() bool {
 //	    return c.ErrorWithDetails(400, "Validation failed", errs.ToMap())
 //	}
 func Validate(v interface{}) ValidationErrors {
+	return newValidationRun(context.Background(), false).validate(v)
+}
+
+// validate validates a struct value within a validation run. It holds the
+// implementation of Validate and ValidateCtx.
+func (run *validationRun) validate(v interface{}) ValidationErrors {
 	val := reflect.ValueOf(v)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -154,75 +212,304 @@ func Validate(v interface{}) ValidationErrors {
 	}
 
 	var errors ValidationErrors
-	typ := val.Type()
-
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-		fieldVal := val.Field(i)
+	for _, field := range structRulesFor(val.Type()) {
+		fieldVal := val.Field(field.index)
 
-		// Skip unexported fields
-		if !fieldVal.CanInterface() {
-			continue
+		// Apply validators if tag exists and is not "-"
+		if field.rules != nil {
+			errors = append(errors, run.validateRules(field.name, fieldVal, val, field.rules)...)
 		}
 
-		// Get field name (use json tag if available)
-		fieldName := field.Name
-		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
-			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" && parts[0] != "-" {
-				fieldName = parts[0]
+		// Recursively validate nested structs (always, regardless of whether
+		// the parent field has a validate tag). This ensures complete validation
+		// of complex nested structures.
+		switch fieldVal.Kind() {
+		case reflect.Struct:
+			errors = append(errors, run.validateNested(field.name, fieldVal)...)
+		case reflect.Ptr:
+			// Nested struct pointers are validated when non-nil
+			if fieldVal.Type().Elem().Kind() == reflect.Struct {
+				errors = append(errors, run.validateNested(field.name, fieldVal)...)
+			}
+		case reflect.Slice, reflect.Array, reflect.Map:
+			// Structs held in collections are validated element by element,
+			// e.g. "items[0].name".
+			if !field.walkElements {
+				break
 			}
+			eachElement(field.name, fieldVal, func(elemName string, elem reflect.Value) {
+				errors = append(errors, run.validateNested(elemName, elem)...)
+			})
 		}
+	}
 
-		// Get validate tag
-		tag := field.Tag.Get("validate")
+	// Struct-level invariants run after all field rules
+	errors = append(errors, validateStructHooks(val)...)
 
-		// Apply validators if tag exists and is not "-"
-		if tag != "" && tag != "-" {
-			validators := strings.Split(tag, ",")
-			for _, validator := range validators {
-				validator = strings.TrimSpace(validator)
-				if validator == "" {
-					continue
-				}
-
-				// Parse validator and parameter
-				var name, param string
-				if idx := strings.Index(validator, ":"); idx != -1 {
-					name = validator[:idx]
-					param = validator[idx+1:]
-				} else {
-					name = validator
-				}
-
-				// Apply validator
-				if err := applyValidator(fieldName, fieldVal, name, param); err != nil {
-					errors = append(errors, *err)
-				}
+	return errors
+}
+
+// Validatable is implemented by types that enforce invariants spanning
+// multiple fields (e.g., "either email or phone must be set"). Validate
+// calls it after the field tags have been checked.
+//
+// A returned ValidationErrors or ValidationError is merged into the result
+// as-is; any other error is reported as a struct-level error with an empty
+// field name (or the parent field name when the struct is nested).
+//
+// Implementations must not call quark.Validate on their own receiver, as
+// that would recurse indefinitely.
+type Validatable interface {
+	Validate() error
+}
+
+// StructValidator is implemented by types that report struct-level
+// validation errors directly. Field names in the returned errors are
+// relative to the struct and are prefixed like any other nested error.
+//
+// Example:
+//
+//	func (c Contact) ValidateStruct() quark.ValidationErrors {
+//	    if c.Email == "" && c.Phone == "" {
+//	        return quark.ValidationErrors{{
+//	            Field:   "email",
+//	            Tag:     "required_without",
+//	            Message: "either email or phone must be set",
+//	        }}
+//	    }
+//	    return nil
+//	}
+type StructValidator interface {
+	ValidateStruct() ValidationErrors
+}
+
+// validateStructHooks runs the Validatable and StructValidator hooks of a
+// struct value. Pointer receivers are supported when the value is
+// addressable.
+func validateStructHooks(val reflect.Value) ValidationErrors {
+	target := val.Interface()
+	if val.CanAddr() {
+		target = val.Addr().Interface()
+	}
+
+	var errors ValidationErrors
+
+	if sv, ok := target.(StructValidator); ok {
+		errors = append(errors, sv.ValidateStruct()...)
+	}
+
+	if v, ok := target.(Validatable); ok {
+		switch err := v.Validate().(type) {
+		case nil:
+		case ValidationErrors:
+			errors = append(errors, err...)
+		case ValidationError:
+			errors = append(errors, err)
+		case *ValidationError:
+			errors = append(errors, *err)
+		default:
+			errors = append(errors, ValidationError{
+				Field:   "",
+				Tag:     "struct",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateRules applies compiled validation rules to a value. Rules that
+// follow a "dive" entry are applied to each element of a slice, array or map
+// instead of the value itself, and a custom message replaces the message of
+// every error the rules produce.
+//
+// Pointers are dereferenced before the value is checked, except by the
+// presence validators (required and its conditional variants), which treat a
+// nil pointer as missing. Other validators skip nil pointers. An "omitempty"
+// entry stops validation when the value is zero or nil.
+//
+// parent is the struct that holds the field; it is used by cross-field
+// validators and is the zero Value when validating a standalone variable.
+func (run *validationRun) validateRules(fieldName string, fieldVal, parent reflect.Value, rules *tagRules) ValidationErrors {
+	var errors ValidationErrors
+
+	elem, hasValue := indirect(fieldVal)
+
+	for _, rule := range rules.validators {
+		if rule.name == "omitempty" {
+			if isEmpty(fieldVal) {
+				return nil
 			}
+			continue
 		}
 
-		// Recursively validate nested structs (always, regardless of whether
-		// the parent field has a validate tag). This ensures complete validation
-		// of complex nested structures.
-		if fieldVal.Kind() == reflect.Struct {
-			nestedErrors := Validate(fieldVal.Interface())
-			// Prefix nested field names with parent field name for clarity
-			for _, err := range nestedErrors {
-				err.Field = fieldName + "." + err.Field
-				errors = append(errors, err)
+		target := elem
+		if checksPresence(rule.name) {
+			target = fieldVal
+		} else if !hasValue {
+			continue
+		}
+
+		if err := run.applyValidator(fieldName, target, parent, rule.name, rule.param); err != nil {
+			if tmpl, ok := validationMessage(err.Tag); ok {
+				err.Message = formatValidationMessage(tmpl, err)
 			}
+			errors = append(errors, *err)
+		}
+	}
+
+	if rules.dive != nil && hasValue {
+		eachElement(fieldName, elem, func(elemName string, e reflect.Value) {
+			errors = append(errors, run.validateRules(elemName, e, parent, rules.dive)...)
+		})
+	}
+
+	if rules.message != "" {
+		for i := range errors {
+			errors[i].Message = formatValidationMessage(rules.message, &errors[i])
 		}
 	}
 
 	return errors
 }
 
+// checksPresence reports whether a validator inspects the raw field value
+// (including nil pointers) rather than the value it points to.
+func checksPresence(name string) bool {
+	switch name {
+	case "required", "required_if", "required_with", "required_without":
+		return true
+	default:
+		return false
+	}
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete
+// value. It reports false if a nil pointer or interface is encountered.
+func indirect(val reflect.Value) (reflect.Value, bool) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return reflect.Value{}, false
+		}
+		val = val.Elem()
+	}
+	return val, val.IsValid()
+}
+
+// validationMessages holds message templates registered with
+// SetValidationMessage, keyed by validator tag.
+var (
+	validationMessages   = make(map[string]string)
+	validationMessagesMu sync.RWMutex
+)
+
+// SetValidationMessage overrides the error message generated by a validator
+// for every field, allowing APIs to return product-specific copy. The
+// template may reference the placeholders {field} (the field name) and
+// {param} (the validator parameter, e.g. "2" for min:2). Passing an empty
+// template restores the built-in message.
+//
+// Per-field overrides are also available through a trailing "message:"
+// entry in the validate tag, which takes precedence over the registry.
+//
+// Example:
+//
+//	quark.SetValidationMessage("email", "Please enter a valid email for {field}")
+//	quark.SetValidationMessage("min", "{field} needs at least {param} characters")
+//
+//	type Signup struct {
+//	    Handle string `json:"handle" validate:"required,alphanum,message:Pick a handle using letters and digits"`
+//	}
+func SetValidationMessage(tag, tmpl string) {
+	validationMessagesMu.Lock()
+	defer validationMessagesMu.Unlock()
+
+	if tmpl == "" {
+		delete(validationMessages, tag)
+		return
+	}
+	validationMessages[tag] = tmpl
+}
+
+// validationMessage returns the registered message template for a tag.
+func validationMessage(tag string) (string, bool) {
+	validationMessagesMu.RLock()
+	defer validationMessagesMu.RUnlock()
+	tmpl, ok := validationMessages[tag]
+	return tmpl, ok
+}
+
+// formatValidationMessage expands the {field} and {param} placeholders of a
+// message template for the given error.
+func formatValidationMessage(tmpl string, err *ValidationError) string {
+	return strings.NewReplacer("{field}", err.Field, "{param}", err.Value).Replace(tmpl)
+}
+
+// eachElement calls fn for every element of a slice, array or map, passing
+// an indexed field name such as "emails[0]" or "labels[env]". Map keys are
+// visited in sorted order so that errors are reported deterministically.
+// Values of any other kind are ignored.
+func eachElement(fieldName string, val reflect.Value, fn func(string, reflect.Value)) {
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			fn(fmt.Sprintf("%s[%d]", fieldName, i), val.Index(i))
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			fn(fmt.Sprintf("%s[%v]", fieldName, key.Interface()), val.MapIndex(key))
+		}
+	}
+}
+
+// mayHoldStruct reports whether values of type t can be, point to, or
+// (through an interface) contain a struct.
+func mayHoldStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Interface
+}
+
+// validateNested validates a struct value (or a non-nil pointer/interface
+// holding one) and prefixes the resulting field names with fieldName.
+// Values that do not hold a struct produce no errors.
+func (run *validationRun) validateNested(fieldName string, val reflect.Value) ValidationErrors {
+	val, ok := indirect(val)
+	if !ok || val.Kind() != reflect.Struct || !val.CanInterface() {
+		return nil
+	}
+
+	// Keep addressability so pointer-receiver hooks are honoured
+	target := val.Interface()
+	if val.CanAddr() {
+		target = val.Addr().Interface()
+	}
+
+	var errors ValidationErrors
+	// Prefix nested field names with parent field name for clarity
+	for _, err := range run.validate(target) {
+		if err.Field == "" {
+			err.Field = fieldName
+		} else {
+			err.Field = fieldName + "." + err.Field
+		}
+		errors = append(errors, err)
+	}
+	return errors
+}
+
 // applyValidator applies a single named validator to a field value.
-// It dispatches to the appropriate validation function based on the validator name.
+// It dispatches to the appropriate validation function based on the validator name,
+// falling back to validators registered with RegisterContextValidator.
 // Returns nil if validation passes or if the validator is unknown.
 // Unknown validators are silently skipped to allow for future extensibility.
-func applyValidator(fieldName string, fieldVal reflect.Value, name, param string) *ValidationError {
+func (run *validationRun) applyValidator(fieldName string, fieldVal, parent reflect.Value, name, param string) *ValidationError {
 	switch name {
 	case "required":
 		return validateRequired(fieldName, fieldVal)
@@ -256,7 +543,27 @@ func applyValidator(fieldName string, fieldVal reflect.Value, name, param string
 		return validateLt(fieldName, fieldVal, param)
 	case "lte":
 		return validateLte(fieldName, fieldVal, param)
+	case "required_if":
+		return validateRequiredIf(fieldName, fieldVal, parent, param)
+	case "required_with":
+		return validateRequiredWith(fieldName, fieldVal, parent, param)
+	case "required_without":
+		return validateRequiredWithout(fieldName, fieldVal, parent, param)
+	case "ip", "ipv4", "ipv6", "cidr", "mac", "hostname",
+		"json", "base64", "base64url", "hex", "jwt":
+		return validateFormat(fieldName, fieldVal, name)
+	case "port":
+		return validatePort(fieldName, fieldVal)
+	case "datetime":
+		return validateDatetime(fieldName, fieldVal, param)
+	case "before", "after":
+		return validateBeforeAfter(fieldName, fieldVal, parent, name, param)
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		return validateFieldComparison(fieldName, fieldVal, parent, name, param)
 	default:
+		if fn, ok := contextValidator(name); ok {
+			return run.applyContextValidator(fieldName, fieldVal, name, param, fn)
+		}
 		return nil // Unknown validator, skip
 	}
 }
@@ -273,6 +580,76 @@ func validateRequired(fieldName string, val reflect.Value) *ValidationError {
 	return nil
 }
 
+// validateRequiredIf makes a field required when one or more sibling fields
+// hold specific values. The parameter is a space-separated list of
+// field/value pairs, all of which must match (e.g., "Type card" or
+// "Type card Country US"). Values are compared using their fmt.Sprint form.
+func validateRequiredIf(fieldName string, val, parent reflect.Value, param string) *ValidationError {
+	parts := strings.Fields(param)
+	if len(parts) == 0 || len(parts)%2 != 0 {
+		return nil
+	}
+
+	var conditions []string
+	for i := 0; i < len(parts); i += 2 {
+		other, ok := lookupField(parent, parts[i])
+		if !ok || fmt.Sprint(other.Interface()) != parts[i+1] {
+			return nil
+		}
+		conditions = append(conditions, parts[i]+" is "+parts[i+1])
+	}
+
+	if isEmpty(val) {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     "required_if",
+			Value:   param,
+			Message: fmt.Sprintf("%s is required when %s", fieldName, strings.Join(conditions, " and ")),
+		}
+	}
+	return nil
+}
+
+// validateRequiredWith makes a field required when any of the space-separated
+// sibling fields in param is present (non-empty).
+func validateRequiredWith(fieldName string, val, parent reflect.Value, param string) *ValidationError {
+	if !isEmpty(val) {
+		return nil
+	}
+
+	for _, name := range strings.Fields(param) {
+		if other, ok := lookupField(parent, name); ok && !isEmpty(other) {
+			return &ValidationError{
+				Field:   fieldName,
+				Tag:     "required_with",
+				Value:   param,
+				Message: fmt.Sprintf("%s is required when %s is present", fieldName, name),
+			}
+		}
+	}
+	return nil
+}
+
+// validateRequiredWithout makes a field required when any of the
+// space-separated sibling fields in param is missing (empty).
+func validateRequiredWithout(fieldName string, val, parent reflect.Value, param string) *ValidationError {
+	if !isEmpty(val) {
+		return nil
+	}
+
+	for _, name := range strings.Fields(param) {
+		if other, ok := lookupField(parent, name); ok && isEmpty(other) {
+			return &ValidationError{
+				Field:   fieldName,
+				Tag:     "required_without",
+				Value:   param,
+				Message: fmt.Sprintf("%s is required when %s is missing", fieldName, name),
+			}
+		}
+	}
+	return nil
+}
+
 // validateMin checks minimum length/value.
 func validateMin(fieldName string, val reflect.Value, param string) *ValidationError {
 	min, err := strconv.ParseInt(param, 10, 64)
@@ -405,6 +782,9 @@ func validateEmail(fieldName string, val reflect.Value) *ValidationError {
 	return nil
 }
 
+// urlPattern is the simple URL format accepted by the url validator.
+var urlPattern = regexp.MustCompile(`^(https?|ftp)://[^\s/$.?#].[^\s]*$`)
+
 // validateURL checks if the value is a valid URL.
 func validateURL(fieldName string, val reflect.Value) *ValidationError {
 	if val.Kind() != reflect.String {
@@ -417,9 +797,7 @@ func validateURL(fieldName string, val reflect.Value) *ValidationError {
 	}
 
 	// Simple URL validation
-	urlPattern := `^(https?|ftp)://[^\s/$.?#].[^\s]*$`
-	matched, _ := regexp.MatchString(urlPattern, url)
-	if !matched {
+	if !urlPattern.MatchString(url) {
 		return &ValidationError{
 			Field:   fieldName,
 			Tag:     "url",
@@ -498,6 +876,9 @@ func validateNumeric(fieldName string, val reflect.Value) *ValidationError {
 	return nil
 }
 
+// uuidPattern is the canonical 8-4-4-4-12 hexadecimal UUID format.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 // validateUUID checks if the value is a valid UUID.
 func validateUUID(fieldName string, val reflect.Value) *ValidationError {
 	if val.Kind() != reflect.String {
@@ -509,9 +890,7 @@ func validateUUID(fieldName string, val reflect.Value) *ValidationError {
 		return nil
 	}
 
-	uuidPattern := `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
-	matched, _ := regexp.MatchString(uuidPattern, uuid)
-	if !matched {
+	if !uuidPattern.MatchString(uuid) {
 		return &ValidationError{
 			Field:   fieldName,
 			Tag:     "uuid",
@@ -558,8 +937,8 @@ func validatePattern(fieldName string, val reflect.Value, param string) *Validat
 		return nil
 	}
 
-	matched, err := regexp.MatchString(param, s)
-	if err != nil || !matched {
+	re, err := compilePattern(param)
+	if err != nil || !re.MatchString(s) {
 		return &ValidationError{
 			Field:   fieldName,
 			Tag:     "pattern",
@@ -690,6 +1069,371 @@ func validateLte(fieldName string, val reflect.Value, param string) *ValidationE
 	return nil
 }
 
+// stringFormats maps the network and encoding validator tags to a check
+// function and the description used in error messages.
+var stringFormats = map[string]struct {
+	check       func(string) bool
+	description string
+}{
+	"ip": {func(s string) bool {
+		return net.ParseIP(s) != nil
+	}, "a valid IP address"},
+	"ipv4": {func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	}, "a valid IPv4 address"},
+	"ipv6": {func(s string) bool {
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}, "a valid IPv6 address"},
+	"cidr": {func(s string) bool {
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}, "a valid CIDR notation"},
+	"mac": {func(s string) bool {
+		_, err := net.ParseMAC(s)
+		return err == nil
+	}, "a valid MAC address"},
+	"hostname": {isHostname, "a valid hostname"},
+	"json": {func(s string) bool {
+		return json.Valid([]byte(s))
+	}, "valid JSON"},
+	"base64": {func(s string) bool {
+		_, err := base64.StdEncoding.DecodeString(s)
+		return err == nil
+	}, "valid base64"},
+	"base64url": {func(s string) bool {
+		_, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		return err == nil
+	}, "valid base64url"},
+	"hex": {func(s string) bool {
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+		_, err := hex.DecodeString(s)
+		return s != "" && err == nil
+	}, "valid hexadecimal"},
+	"jwt": {isJWT, "a well-formed JWT"},
+}
+
+// isJWT reports whether s is structurally a JSON Web Token: three
+// base64url segments whose header (with an "alg" member) and payload decode
+// to JSON objects. The signature is not verified.
+func isJWT(s string) bool {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	var header map[string]interface{}
+	for i, part := range parts[:2] {
+		data, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return false
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return false
+		}
+		if i == 0 {
+			header = obj
+		}
+	}
+	if _, ok := header["alg"].(string); !ok {
+		return false
+	}
+
+	_, err := base64.RawURLEncoding.DecodeString(parts[2])
+	return err == nil
+}
+
+// hostnamePattern matches a single RFC 1123 hostname label.
+var hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// isHostname reports whether s is a valid RFC 1123 hostname. A single
+// trailing dot (fully qualified form) is accepted.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !hostnamePattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateFormat checks a string field against one of the network formats
+// (ip, ipv4, ipv6, cidr, mac, hostname), backed by the net package, or one
+// of the encoding formats (json, base64, base64url, hex, jwt).
+func validateFormat(fieldName string, val reflect.Value, name string) *ValidationError {
+	if val.Kind() != reflect.String {
+		return nil
+	}
+
+	s := val.String()
+	if s == "" {
+		return nil
+	}
+
+	format := stringFormats[name]
+	if !format.check(s) {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     name,
+			Message: fmt.Sprintf("%s must be %s", fieldName, format.description),
+		}
+	}
+	return nil
+}
+
+// validatePort checks that a string or integer field is a valid TCP/UDP
+// port number (1-65535).
+func validatePort(fieldName string, val reflect.Value) *ValidationError {
+	var port int64
+	switch val.Kind() {
+	case reflect.String:
+		if val.String() == "" {
+			return nil
+		}
+		p, err := strconv.ParseInt(val.String(), 10, 64)
+		if err != nil {
+			port = -1
+		} else {
+			port = p
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		port = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		port = int64(val.Uint())
+	default:
+		return nil
+	}
+
+	if port < 1 || port > 65535 {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     "port",
+			Message: fmt.Sprintf("%s must be a valid port number", fieldName),
+		}
+	}
+	return nil
+}
+
+// validateDatetime checks that a string field can be parsed using the Go
+// time layout in param (e.g., "datetime:2006-01-02").
+func validateDatetime(fieldName string, val reflect.Value, param string) *ValidationError {
+	if val.Kind() != reflect.String || param == "" {
+		return nil
+	}
+
+	s := val.String()
+	if s == "" {
+		return nil
+	}
+
+	if _, err := time.Parse(param, s); err != nil {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     "datetime",
+			Value:   param,
+			Message: fmt.Sprintf("%s must be a valid date matching %s", fieldName, param),
+		}
+	}
+	return nil
+}
+
+// validateBeforeAfter checks that a time.Time or date string lies strictly
+// before or after a reference point. The parameter is "now", the Go name of
+// a sibling field, or a literal date (e.g., "before:now",
+// "after:StartDate", "after:2000-01-01"). Strings are parsed with
+// parseTimeValue; zero times and empty strings are left to required.
+func validateBeforeAfter(fieldName string, val, parent reflect.Value, name, param string) *ValidationError {
+	t, ok := parseTimeValue(val)
+	if !ok {
+		return nil
+	}
+
+	var ref time.Time
+	if param == "now" {
+		ref = time.Now()
+	} else if other, found := lookupField(parent, param); found {
+		if other, ok = indirect(other); !ok {
+			return nil
+		}
+		if ref, ok = parseTimeValue(other); !ok {
+			return nil
+		}
+	} else if ref, ok = parseTimeValue(reflect.ValueOf(param)); !ok {
+		return nil
+	}
+
+	valid := t.Before(ref)
+	if name == "after" {
+		valid = t.After(ref)
+	}
+
+	if !valid {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     name,
+			Value:   param,
+			Message: fmt.Sprintf("%s must be %s %s", fieldName, name, param),
+		}
+	}
+	return nil
+}
+
+// timeLayouts are the layouts tried, in order, when a date/time validator
+// needs to interpret a string value.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.DateTime,
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+}
+
+// parseTimeValue extracts a time from a time.Time value or a string in one
+// of timeLayouts. It reports false for zero times, empty strings and values
+// that cannot be interpreted as a time.
+func parseTimeValue(val reflect.Value) (time.Time, bool) {
+	if val.Type() == timeType {
+		t := val.Interface().(time.Time)
+		return t, !t.IsZero()
+	}
+
+	if val.Kind() != reflect.String || val.String() == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, val.String()); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// validateFieldComparison compares a field against a sibling field of the
+// same struct. The parameter is the Go field name of the sibling, optionally
+// dotted to reach into nested structs (e.g., "eqfield:Password" or
+// "gtfield:Period.Start").
+//
+// Numbers, strings and time.Time values are supported for ordering
+// comparisons; eqfield and nefield also work on any comparable value.
+// Validation is skipped when the sibling field cannot be found, such as
+// when validating a standalone variable with ValidateVar.
+func validateFieldComparison(fieldName string, val, parent reflect.Value, name, param string) *ValidationError {
+	other, ok := lookupField(parent, param)
+	if !ok {
+		return nil
+	}
+	if other, ok = indirect(other); !ok {
+		return nil
+	}
+
+	cmp, comparable := compareValues(val, other)
+	var valid bool
+	var message string
+
+	switch name {
+	case "eqfield":
+		valid = (comparable && cmp == 0) || (!comparable && reflect.DeepEqual(val.Interface(), other.Interface()))
+		message = fmt.Sprintf("%s must match %s", fieldName, param)
+	case "nefield":
+		valid = (comparable && cmp != 0) || (!comparable && !reflect.DeepEqual(val.Interface(), other.Interface()))
+		message = fmt.Sprintf("%s must not equal %s", fieldName, param)
+	case "gtfield":
+		valid = !comparable || cmp > 0
+		message = fmt.Sprintf("%s must be greater than %s", fieldName, param)
+	case "gtefield":
+		valid = !comparable || cmp >= 0
+		message = fmt.Sprintf("%s must be greater than or equal to %s", fieldName, param)
+	case "ltfield":
+		valid = !comparable || cmp < 0
+		message = fmt.Sprintf("%s must be less than %s", fieldName, param)
+	case "ltefield":
+		valid = !comparable || cmp <= 0
+		message = fmt.Sprintf("%s must be less than or equal to %s", fieldName, param)
+	}
+
+	if !valid {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     name,
+			Value:   param,
+			Message: message,
+		}
+	}
+	return nil
+}
+
+// lookupField resolves a (possibly dotted) Go field name against a struct
+// value, dereferencing pointers along the way. It reports false if any
+// segment of the path cannot be resolved.
+func lookupField(parent reflect.Value, path string) (reflect.Value, bool) {
+	val := parent
+	for _, name := range strings.Split(path, ".") {
+		var ok bool
+		if val, ok = indirect(val); !ok || val.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		val = val.FieldByName(name)
+		if !val.IsValid() || !val.CanInterface() {
+			return reflect.Value{}, false
+		}
+	}
+	return val, true
+}
+
+// timeType is the reflect.Type of time.Time, used for date comparisons.
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareValues orders two values of compatible kinds, returning -1, 0 or 1
+// and true. Signed, unsigned and floating point numbers are compared
+// numerically, strings lexically and time.Time values chronologically.
+// It returns false when the values cannot be ordered.
+func compareValues(a, b reflect.Value) (int, bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	x, ok := toFloat(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := toFloat(b)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// toFloat converts any numeric reflect.Value to a float64.
+func toFloat(val reflect.Value) (float64, bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), true
+	default:
+		return 0, false
+	}
+}
+
 // isEmpty checks if a reflected value is considered "empty" for validation purposes.
 // The definition of empty varies by type:
 //   - String: empty string ""
@@ -716,6 +1460,8 @@ func isEmpty(val reflect.Value) bool {
 		return val.Float() == 0
 	case reflect.Interface, reflect.Ptr:
 		return val.IsNil()
+	case reflect.Invalid:
+		return true
 	default:
 		return reflect.DeepEqual(val.Interface(), reflect.Zero(val.Type()).Interface())
 	}
@@ -739,28 +1485,6 @@ func isEmpty(val reflect.Value) bool {
 //	    return c.BadRequest(errs.Error())
 //	}
 func ValidateVar(value interface{}, tag string) ValidationErrors {
-	val := reflect.ValueOf(value)
-	validators := strings.Split(tag, ",")
-
-	var errors ValidationErrors
-	for _, validator := range validators {
-		validator = strings.TrimSpace(validator)
-		if validator == "" {
-			continue
-		}
-
-		var name, param string
-		if idx := strings.Index(validator, ":"); idx != -1 {
-			name = validator[:idx]
-			param = validator[idx+1:]
-		} else {
-			name = validator
-		}
-
-		if err := applyValidator("value", val, name, param); err != nil {
-			errors = append(errors, *err)
-		}
-	}
-
-	return errors
+	run := newValidationRun(context.Background(), false)
+	return run.validateRules("value", reflect.ValueOf(value), reflect.Value{}, compileTagCached(tag))
 }
@@ -0,0 +1,158 @@
+package quark
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Compiled validation metadata.
+//
+// Parsing validate tags and walking struct types with reflection is the same
+// work for every value of a given type, so Validate compiles each struct type
+// once and caches the result. Tag strings used with ValidateVar and regular
+// expressions used by the pattern validator are cached the same way.
+var (
+	structRulesCache sync.Map // reflect.Type -> []fieldRules
+	tagRulesCache    sync.Map // string -> *tagRules
+	patternCache     sync.Map // string -> *regexp.Regexp
+)
+
+// fieldRules is the compiled validation metadata for a single struct field.
+type fieldRules struct {
+	index        int       // Field index within the struct
+	name         string    // Name reported in errors (json tag or Go name)
+	rules        *tagRules // Compiled validate tag, nil if absent or "-"
+	walkElements bool      // Whether collection elements may hold structs
+}
+
+// tagRules is a compiled validate tag.
+type tagRules struct {
+	validators []validatorRule // Validators applied to the value itself
+	dive       *tagRules       // Rules applied to each element, nil without dive
+	message    string          // Custom message from a trailing "message:" entry
+}
+
+// validatorRule is a single validator name with its parameter.
+type validatorRule struct {
+	name  string
+	param string
+}
+
+// structRulesFor returns the compiled field rules for a struct type,
+// compiling and caching them on first use. Unexported fields are omitted.
+func structRulesFor(typ reflect.Type) []fieldRules {
+	if cached, ok := structRulesCache.Load(typ); ok {
+		return cached.([]fieldRules)
+	}
+
+	fields := make([]fieldRules, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		// Skip unexported fields
+		if !field.IsExported() {
+			continue
+		}
+
+		// Get field name (use json tag if available)
+		name := field.Name
+		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+			parts := strings.Split(jsonTag, ",")
+			if parts[0] != "" && parts[0] != "-" {
+				name = parts[0]
+			}
+		}
+
+		var rules *tagRules
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			rules = compileTag(tag)
+		}
+
+		var walk bool
+		switch field.Type.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			walk = mayHoldStruct(field.Type.Elem())
+		}
+
+		fields = append(fields, fieldRules{
+			index:        i,
+			name:         name,
+			rules:        rules,
+			walkElements: walk,
+		})
+	}
+
+	actual, _ := structRulesCache.LoadOrStore(typ, fields)
+	return actual.([]fieldRules)
+}
+
+// compileTagCached compiles a validate tag, reusing previous compilations
+// of the same tag string.
+func compileTagCached(tag string) *tagRules {
+	if cached, ok := tagRulesCache.Load(tag); ok {
+		return cached.(*tagRules)
+	}
+	actual, _ := tagRulesCache.LoadOrStore(tag, compileTag(tag))
+	return actual.(*tagRules)
+}
+
+// compileTag parses a comma-separated validate tag.
+//
+// A "dive" entry makes the remaining validators apply to collection elements
+// (dives can be nested, e.g. "dive,dive,email" for [][]string). A
+// "message:" entry consumes the rest of the tag, so it must come last and
+// may itself contain commas.
+func compileTag(tag string) *tagRules {
+	rules := &tagRules{}
+
+	// Split off a custom message before looking at the validators
+	if idx := strings.Index(tag, "message:"); idx != -1 && (idx == 0 || tag[idx-1] == ',') {
+		rules.message = tag[idx+len("message:"):]
+		tag = tag[:idx]
+	}
+
+	validators := strings.Split(tag, ",")
+	for i, validator := range validators {
+		validator = strings.TrimSpace(validator)
+		if validator == "" {
+			continue
+		}
+
+		// Everything after "dive" targets the collection elements
+		if validator == "dive" {
+			rules.dive = compileTag(strings.Join(validators[i+1:], ","))
+			break
+		}
+
+		name, param := parseValidator(validator)
+		rules.validators = append(rules.validators, validatorRule{name: name, param: param})
+	}
+
+	return rules
+}
+
+// parseValidator splits a validator entry such as "min:2" into its name
+// and parameter. The parameter is empty for validators without one.
+func parseValidator(validator string) (name, param string) {
+	if idx := strings.Index(validator, ":"); idx != -1 {
+		return validator[:idx], validator[idx+1:]
+	}
+	return validator, ""
+}
+
+// compilePattern compiles a regular expression used by the pattern
+// validator, caching successful compilations.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := patternCache.LoadOrStore(pattern, re)
+	return actual.(*regexp.Regexp), nil
+}
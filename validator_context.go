@@ -0,0 +1,153 @@
+package quark
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ContextValidatorFunc is a validator that receives the request context,
+// for rules that need I/O such as a database lookup (e.g., "unique:users.email").
+//
+// It receives the field value (pointers already dereferenced) and the tag
+// parameter, and reports whether the value is valid. A non-nil error means
+// the rule could not be evaluated (database failure, cancelled context) and
+// is distinct from the value being invalid.
+type ContextValidatorFunc func(ctx context.Context, value interface{}, param string) (bool, error)
+
+// contextValidators holds validators registered with RegisterContextValidator.
+var (
+	contextValidators   = make(map[string]ContextValidatorFunc)
+	contextValidatorsMu sync.RWMutex
+)
+
+// RegisterContextValidator registers a context-aware validator under the
+// given tag name. Built-in validator names take precedence and cannot be
+// overridden. Registering a nil function removes the validator.
+//
+// Failed values produce the message "<field> is invalid", which can be
+// customized with SetValidationMessage or a "message:" tag entry.
+//
+// Context validators should only run through ValidateCtx or
+// Context.BindAndValidate, which report lookup failures as errors. Validate
+// and ValidateVar have no error return: they run the validator with a
+// background context and report a failure as the validation error
+// "<field> could not be validated", without the underlying error, which
+// may reveal internals to clients.
+//
+// Example:
+//
+//	quark.RegisterContextValidator("unique", func(ctx context.Context, value interface{}, param string) (bool, error) {
+//	    table, column, _ := strings.Cut(param, ".")
+//	    var exists bool
+//	    err := db.QueryRowContext(ctx,
+//	        "SELECT EXISTS(SELECT 1 FROM "+table+" WHERE "+column+" = $1)", value,
+//	    ).Scan(&exists)
+//	    return !exists, err
+//	})
+//	quark.SetValidationMessage("unique", "{field} is already taken")
+//
+//	type Signup struct {
+//	    Email string `json:"email" validate:"required,email,unique:users.email"`
+//	}
+func RegisterContextValidator(name string, fn ContextValidatorFunc) {
+	contextValidatorsMu.Lock()
+	defer contextValidatorsMu.Unlock()
+
+	if fn == nil {
+		delete(contextValidators, name)
+		return
+	}
+	contextValidators[name] = fn
+}
+
+// contextValidator returns the context-aware validator registered for name.
+func contextValidator(name string) (ContextValidatorFunc, bool) {
+	contextValidatorsMu.RLock()
+	defer contextValidatorsMu.RUnlock()
+	fn, ok := contextValidators[name]
+	return fn, ok
+}
+
+// ValidateCtx validates a struct like Validate, passing ctx to validators
+// registered with RegisterContextValidator.
+//
+// The returned error is non-nil when a context-aware validator could not
+// evaluate its rule or ctx was cancelled; it should be treated as a server
+// error rather than invalid input. Once an error occurs, the remaining
+// context-aware validators are skipped, while the other rules still run.
+//
+// Example:
+//
+//	errs, err := quark.ValidateCtx(c.Context(), input)
+//	if err != nil {
+//	    return err
+//	}
+//	if errs.HasErrors() {
+//	    return c.ErrorWithDetails(422, "Validation failed", errs.ToMap())
+//	}
+func ValidateCtx(ctx context.Context, v interface{}) (ValidationErrors, error) {
+	run := newValidationRun(ctx, true)
+	errs := run.validate(v)
+	return errs, run.err
+}
+
+// validationRun carries the state of a single Validate, ValidateVar or
+// ValidateCtx call through the recursive validation functions.
+type validationRun struct {
+	ctx context.Context
+
+	// collectErrors makes validator failures surface through err instead of
+	// being reported as validation errors (ValidateCtx).
+	collectErrors bool
+
+	// err is the first error returned by a context-aware validator.
+	err error
+}
+
+// newValidationRun creates the state for a validation call.
+func newValidationRun(ctx context.Context, collectErrors bool) *validationRun {
+	return &validationRun{ctx: ctx, collectErrors: collectErrors}
+}
+
+// applyContextValidator runs a context-aware validator against a value.
+//
+// When the validator cannot evaluate its rule, ValidateCtx records the error
+// on the run and skips further context-aware validators, while Validate and
+// ValidateVar (which have no error return) report it as a generic
+// validation error, leaving the error out of the message.
+func (run *validationRun) applyContextValidator(fieldName string, val reflect.Value, name, param string, fn ContextValidatorFunc) *ValidationError {
+	if run.err != nil || !val.IsValid() || !val.CanInterface() {
+		return nil
+	}
+
+	err := run.ctx.Err()
+	valid := false
+	if err == nil {
+		valid, err = fn(run.ctx, val.Interface(), param)
+	}
+
+	if err != nil {
+		if run.collectErrors {
+			run.err = fmt.Errorf("validator %s failed for %s: %w", name, fieldName, err)
+			return nil
+		}
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     name,
+			Value:   param,
+			Message: fmt.Sprintf("%s could not be validated", fieldName),
+		}
+	}
+
+	if !valid {
+		return &ValidationError{
+			Field:   fieldName,
+			Tag:     name,
+			Value:   param,
+			Message: fmt.Sprintf("%s is invalid", fieldName),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package quark
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateCtx(t *testing.T) {
+	taken := map[string]bool{"taken@example.com": true}
+
+	RegisterContextValidator("unique", func(ctx context.Context, value interface{}, param string) (bool, error) {
+		if param != "users.email" {
+			t.Errorf("unexpected param: %s", param)
+		}
+		return !taken[value.(string)], nil
+	})
+	defer RegisterContextValidator("unique", nil)
+
+	type Signup struct {
+		Email string `json:"email" validate:"required,email,unique:users.email"`
+	}
+
+	errs, err := ValidateCtx(context.Background(), Signup{Email: "new@example.com"})
+	if err != nil || errs.HasErrors() {
+		t.Errorf("unexpected result: %v, %v", errs, err)
+	}
+
+	errs, err = ValidateCtx(context.Background(), Signup{Email: "taken@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Tag != "unique" || errs[0].Message != "email is invalid" {
+		t.Errorf("expected unique error, got %v", errs)
+	}
+
+	// Plain Validate uses a background context
+	if errs := Validate(Signup{Email: "taken@example.com"}); len(errs) != 1 {
+		t.Errorf("expected unique error from Validate, got %v", errs)
+	}
+}
+
+func TestValidateCtxErrors(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	calls := 0
+
+	RegisterContextValidator("exists", func(ctx context.Context, value interface{}, param string) (bool, error) {
+		calls++
+		return false, dbErr
+	})
+	defer RegisterContextValidator("exists", nil)
+
+	type Input struct {
+		A    string `validate:"exists"`
+		B    string `validate:"exists"`
+		Name string `validate:"required"`
+	}
+
+	errs, err := ValidateCtx(context.Background(), Input{A: "a", B: "b"})
+	if !errors.Is(err, dbErr) {
+		t.Errorf("expected wrapped db error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected remaining context validators to be skipped, got %d calls", calls)
+	}
+	if len(errs) != 1 || errs[0].Field != "Name" {
+		t.Errorf("expected other rules to still run, got %v", errs)
+	}
+
+	// Without an error return, Validate reports the failure as a validation error
+	errs = Validate(Input{Name: "x"})
+	if len(errs) != 2 || errs[0].Tag != "exists" {
+		t.Fatalf("expected exists errors, got %v", errs)
+	}
+	if errs[0].Message != "A could not be validated" {
+		t.Errorf("expected a generic message without the db error, got %q", errs[0].Message)
+	}
+}
+
+func TestValidateCtxCancelled(t *testing.T) {
+	called := false
+	RegisterContextValidator("slow", func(ctx context.Context, value interface{}, param string) (bool, error) {
+		called = true
+		return true, nil
+	})
+	defer RegisterContextValidator("slow", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type Input struct {
+		Value string `validate:"slow"`
+	}
+
+	_, err := ValidateCtx(ctx, Input{Value: "x"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if called {
+		t.Error("validator should not run with a cancelled context")
+	}
+}
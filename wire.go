@@ -0,0 +1,143 @@
+package quark
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	containerType = reflect.TypeOf((*Container)(nil))
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Wire registers a constructor whose parameters are resolved by type, so
+// large service graphs need no string-key plumbing. The constructor
+// returns the service, optionally followed by an error; a *Container
+// parameter receives the container. The service is registered under the
+// name of its type (see TypeName) and can be resolved with ResolveType or
+// wired into other constructors.
+//
+// Example:
+//
+//	quark.ProvideValue(c, "db", db)          // *sql.DB
+//	quark.ProvideValue(c, "logger", logger)  // *slog.Logger
+//
+//	func NewUserRepository(db *sql.DB) *UserRepository { ... }
+//	func NewUserService(repo *UserRepository, logger *slog.Logger) (*UserService, error) { ... }
+//
+//	c.Wire(NewUserRepository)
+//	c.Wire(NewUserService)
+//	svc, err := quark.ResolveType[*UserService](c)
+func (c *Container) Wire(constructor interface{}) error {
+	fn := reflect.ValueOf(constructor)
+	ft := fn.Type()
+	if ft.Kind() != reflect.Func {
+		return fmt.Errorf("wire: %s is not a function", ft)
+	}
+	if ft.IsVariadic() || ft.NumOut() < 1 || ft.NumOut() > 2 || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		return fmt.Errorf("wire: constructor %s must return a service and optionally an error", ft)
+	}
+
+	out := ft.Out(0)
+	name := TypeName(out)
+	c.Register(name, func(cont *Container) (interface{}, error) {
+		args := make([]reflect.Value, ft.NumIn())
+		for i := range args {
+			param := ft.In(i)
+			if param == containerType {
+				args[i] = reflect.ValueOf(cont)
+				continue
+			}
+
+			dep, err := cont.GetByType(param)
+			if err != nil {
+				return nil, err
+			}
+			if dep == nil {
+				args[i] = reflect.Zero(param)
+			} else {
+				args[i] = reflect.ValueOf(dep)
+			}
+		}
+
+		results := fn.Call(args)
+		if len(results) == 2 && !results[1].IsNil() {
+			return nil, results[1].Interface().(error)
+		}
+		return results[0].Interface(), nil
+	})
+	c.declare(name, out)
+	return nil
+}
+
+// MustWire registers a constructor like Wire or panics.
+func (c *Container) MustWire(constructor interface{}) {
+	if err := c.Wire(constructor); err != nil {
+		panic(err)
+	}
+}
+
+// GetByType retrieves the service of type t. Services registered with a
+// type (Provide, ProvideValue, RegisterInstance and Wire) are candidates:
+// a service of exactly type t is preferred, otherwise the single service
+// assignable to t, such as the implementation of an interface. Several
+// candidates fail as ambiguous.
+func (c *Container) GetByType(t reflect.Type) (interface{}, error) {
+	types := c.declaredTypes()
+
+	var exact, assignable []string
+	for name, declared := range types {
+		switch {
+		case declared == t:
+			exact = append(exact, name)
+		case declared.AssignableTo(t):
+			assignable = append(assignable, name)
+		}
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = assignable
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("service not found for type %s", t)
+	case 1:
+		return c.Get(candidates[0])
+	default:
+		sort.Strings(candidates)
+		return nil, fmt.Errorf("ambiguous services for type %s: %s", t, strings.Join(candidates, ", "))
+	}
+}
+
+// declaredTypes returns the declared types of the services of the
+// container and, for a scope, its ancestors.
+func (c *Container) declaredTypes() map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	if c.parent != nil {
+		types = c.parent.declaredTypes()
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, t := range c.types {
+		types[name] = t
+	}
+	return types
+}
+
+// TypeName returns the service name of a type registered with Wire or
+// ProvideType: the package path and name of the type, such as
+// "*github.com/acme/shop/users.Service".
+func TypeName(t reflect.Type) string {
+	switch {
+	case t.Name() != "" && t.PkgPath() != "":
+		return t.PkgPath() + "." + t.Name()
+	case t.Kind() == reflect.Ptr:
+		return "*" + TypeName(t.Elem())
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,140 @@
+package quark
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type wireGreeter interface {
+	Greet() string
+}
+
+type wireConfig struct {
+	Greeting string
+}
+
+type wireRepo struct {
+	config *wireConfig
+}
+
+func (r *wireRepo) Greet() string {
+	return r.config.Greeting
+}
+
+type wireService struct {
+	greeter   wireGreeter
+	container *Container
+}
+
+func newWireRepo(config *wireConfig) *wireRepo {
+	return &wireRepo{config: config}
+}
+
+func newWireService(greeter wireGreeter, c *Container) (*wireService, error) {
+	return &wireService{greeter: greeter, container: c}, nil
+}
+
+func TestContainerWire(t *testing.T) {
+	c := NewContainer()
+
+	ProvideValue(c, "config", &wireConfig{Greeting: "hello"})
+	if err := c.Wire(newWireRepo); err != nil {
+		t.Fatalf("Wire: unexpected error: %v", err)
+	}
+	if err := c.Wire(newWireService); err != nil {
+		t.Fatalf("Wire: unexpected error: %v", err)
+	}
+
+	instance, err := c.GetByType(reflect.TypeOf((*wireService)(nil)))
+	if err != nil {
+		t.Fatalf("GetByType: unexpected error: %v", err)
+	}
+	svc := instance.(*wireService)
+	if svc.greeter.Greet() != "hello" {
+		t.Errorf("expected greeter wired by interface, got %q", svc.greeter.Greet())
+	}
+	if svc.container == nil {
+		t.Error("expected container parameter")
+	}
+
+	name := TypeName(reflect.TypeOf((*wireService)(nil)))
+	if name != "*github.com/AchrafSoltani/quark.wireService" {
+		t.Errorf("TypeName: unexpected name %q", name)
+	}
+	if byName, _ := c.Get(name); byName != instance {
+		t.Error("expected wired service registered under its type name")
+	}
+}
+
+func TestContainerWireErrors(t *testing.T) {
+	c := NewContainer()
+
+	for _, constructor := range []interface{}{
+		"not a function",
+		func() {},
+		func() (int, int) { return 0, 0 },
+		func(...int) int { return 0 },
+	} {
+		if err := c.Wire(constructor); err == nil {
+			t.Errorf("Wire(%T): expected error", constructor)
+		}
+	}
+
+	c.MustWire(newWireRepo)
+	if _, err := c.GetByType(reflect.TypeOf((*wireRepo)(nil))); err == nil ||
+		!strings.Contains(err.Error(), "service not found for type *quark.wireConfig") {
+		t.Errorf("GetByType: expected missing dependency error, got %v", err)
+	}
+
+	failing := errors.New("constructor failed")
+	c.MustWire(func() (*wireConfig, error) { return nil, failing })
+	if _, err := c.GetByType(reflect.TypeOf((*wireRepo)(nil))); !errors.Is(err, failing) {
+		t.Errorf("GetByType: expected constructor error, got %v", err)
+	}
+}
+
+func TestContainerGetByTypeAmbiguous(t *testing.T) {
+	c := NewContainer()
+
+	ProvideValue(c, "a", &wireRepo{config: &wireConfig{}})
+	ProvideValue(c, "b", &wireRepo{config: &wireConfig{}})
+
+	_, err := c.GetByType(reflect.TypeOf((*wireRepo)(nil)))
+	if err == nil || !strings.Contains(err.Error(), "ambiguous services for type *quark.wireRepo: a, b") {
+		t.Errorf("GetByType: expected ambiguity error, got %v", err)
+	}
+
+	// An exact match wins over assignable services
+	ProvideValue[wireGreeter](c, "greeter", &wireRepo{config: &wireConfig{}})
+	if _, err := c.GetByType(reflect.TypeOf((*wireGreeter)(nil)).Elem()); err != nil {
+		t.Errorf("GetByType: unexpected error: %v", err)
+	}
+}
+
+func TestProvideTypeAndResolveType(t *testing.T) {
+	c := NewContainer()
+
+	ProvideType(c, func(c *Container) (wireGreeter, error) {
+		return &wireRepo{config: &wireConfig{Greeting: "typed"}}, nil
+	})
+	c.MustWire(newWireService)
+
+	greeter, err := ResolveType[wireGreeter](c)
+	if err != nil {
+		t.Fatalf("ResolveType: unexpected error: %v", err)
+	}
+	if greeter.Greet() != "typed" {
+		t.Errorf("ResolveType: unexpected greeting %q", greeter.Greet())
+	}
+
+	svc := MustResolveType[*wireService](c)
+	if svc.greeter != greeter {
+		t.Error("expected wired service to receive the typed service")
+	}
+
+	if _, err := ResolveType[*wireConfig](c); err == nil {
+		t.Error("ResolveType: expected error for unregistered type")
+	}
+}